@@ -0,0 +1,54 @@
+package config
+
+import "time"
+
+// ActivityConfig mengatur input, validasi dan analytics aktivitas lari serta
+// penurunan avg_pace profil dari aktivitas.
+type ActivityConfig struct {
+	ImportMaxBytes    int64         // ukuran maksimal file GPX/TCX/FIT
+	AnalyticsCacheTTL time.Duration // umur cache analytics di Redis
+
+	// Validasi kewajaran aktivitas baru / yang diubah
+	MinPace       float64       // menit per km, pace rata-rata tercepat yang diterima
+	MaxPace       float64       // menit per km, pace rata-rata paling lambat yang diterima
+	MaxSpeedKmh   float64       // kecepatan tertinggi (rata-rata 30 detik atau split tercepat)
+	MaxDistanceKm float64       // jarak maksimal satu aktivitas
+	MaxDuration   time.Duration // durasi maksimal satu aktivitas
+
+	// Penurunan avg_pace profil: rata-rata tertimbang jarak dari aktivitas terbaru
+	PaceWindow        time.Duration // hanya aktivitas dalam rentang ini
+	PaceWindowRuns    int           // maksimal aktivitas terbaru yang dipakai
+	PaceOutlierFactor float64       // aktivitas dengan |pace - median| > faktor * MAD dibuang
+
+	// Aktivitas dari connector dianggap sama dengan aktivitas yang sudah ada (input manual /
+	// impor file) bila waktunya bertumpuk (± DedupWindow) dan selisih jaraknya maksimal DedupDistancePct.
+	DedupWindow      time.Duration
+	DedupDistancePct float64
+}
+
+// SetupActivity membaca konfigurasi aktivitas dari environment:
+//
+//	ACTIVITY_IMPORT_MAX_MB (20), ACTIVITY_ANALYTICS_CACHE_MINUTES (10),
+//	ACTIVITY_MIN_PACE (2.5), ACTIVITY_MAX_PACE (20), ACTIVITY_MAX_SPEED_KMH (30),
+//	ACTIVITY_MAX_DISTANCE_KM (250), ACTIVITY_MAX_DURATION_HOURS (48),
+//	PROFILE_PACE_WINDOW_DAYS (90), PROFILE_PACE_WINDOW_RUNS (20), PROFILE_PACE_OUTLIER_MAD (3),
+//	ACTIVITY_DEDUP_WINDOW_MINUTES (60), ACTIVITY_DEDUP_DISTANCE_PCT (5)
+func SetupActivity() *ActivityConfig {
+	return &ActivityConfig{
+		ImportMaxBytes:    int64(envFloat("ACTIVITY_IMPORT_MAX_MB", 20) * 1024 * 1024),
+		AnalyticsCacheTTL: time.Duration(envFloat("ACTIVITY_ANALYTICS_CACHE_MINUTES", 10) * float64(time.Minute)),
+
+		MinPace:       envFloat("ACTIVITY_MIN_PACE", 2.5),
+		MaxPace:       envFloat("ACTIVITY_MAX_PACE", 20),
+		MaxSpeedKmh:   envFloat("ACTIVITY_MAX_SPEED_KMH", 30),
+		MaxDistanceKm: envFloat("ACTIVITY_MAX_DISTANCE_KM", 250),
+		MaxDuration:   time.Duration(envFloat("ACTIVITY_MAX_DURATION_HOURS", 48) * float64(time.Hour)),
+
+		PaceWindow:        time.Duration(envFloat("PROFILE_PACE_WINDOW_DAYS", 90) * 24 * float64(time.Hour)),
+		PaceWindowRuns:    int(envFloat("PROFILE_PACE_WINDOW_RUNS", 20)),
+		PaceOutlierFactor: envFloat("PROFILE_PACE_OUTLIER_MAD", 3),
+
+		DedupWindow:      time.Duration(envFloat("ACTIVITY_DEDUP_WINDOW_MINUTES", 60) * float64(time.Minute)),
+		DedupDistancePct: envFloat("ACTIVITY_DEDUP_DISTANCE_PCT", 5) / 100,
+	}
+}
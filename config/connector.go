@@ -0,0 +1,70 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ConnectorConfig mengatur sinkronisasi aktivitas dari provider pihak ketiga
+// (Strava, mock, ...): OAuth2, enkripsi token, backfill dan deduplikasi.
+type ConnectorConfig struct {
+	TokenKey        []byte        // AES-256 untuk access / refresh token di database
+	CallbackBaseURL string        // base URL publik API, redirect_uri = <base>/connectors/<provider>/callback
+	AppRedirectURL  string        // opsional, callback OAuth redirect ke sini dengan ?provider=&status=
+	StateTTL        time.Duration // umur state OAuth2 di Redis
+	JobInterval     time.Duration // interval job webhook & backfill
+
+	BackfillDays     int // riwayat yang diambil saat akun pertama kali dihubungkan
+	BackfillMaxPages int // halaman maksimal per akun per putaran job, sisanya dilanjutkan putaran berikutnya
+	EventMaxAttempts int // event webhook yang gagal sebanyak ini ditandai failed
+}
+
+// SetupConnector membaca CONNECTOR_TOKEN_KEY (base64 32 byte), CONNECTOR_CALLBACK_BASE_URL
+// (default http://localhost:<PORT>), CONNECTOR_APP_REDIRECT_URL, CONNECTOR_STATE_TTL_MINUTES (10),
+// CONNECTOR_JOB_INTERVAL_SECONDS (60), CONNECTOR_BACKFILL_DAYS (30), CONNECTOR_BACKFILL_MAX_PAGES (5),
+// dan CONNECTOR_EVENT_MAX_ATTEMPTS (5). Aplikasi berhenti jika CONNECTOR_TOKEN_KEY kosong
+// kecuali GIN_MODE=debug/test.
+func SetupConnector() *ConnectorConfig {
+	cfg := &ConnectorConfig{
+		CallbackBaseURL: strings.TrimRight(os.Getenv("CONNECTOR_CALLBACK_BASE_URL"), "/"),
+		AppRedirectURL:  os.Getenv("CONNECTOR_APP_REDIRECT_URL"),
+		StateTTL:        time.Duration(envFloat("CONNECTOR_STATE_TTL_MINUTES", 10) * float64(time.Minute)),
+		JobInterval:     time.Duration(envFloat("CONNECTOR_JOB_INTERVAL_SECONDS", 60) * float64(time.Second)),
+
+		BackfillDays:     int(envFloat("CONNECTOR_BACKFILL_DAYS", 30)),
+		BackfillMaxPages: int(envFloat("CONNECTOR_BACKFILL_MAX_PAGES", 5)),
+		EventMaxAttempts: int(envFloat("CONNECTOR_EVENT_MAX_ATTEMPTS", 5)),
+	}
+
+	if cfg.CallbackBaseURL == "" {
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		cfg.CallbackBaseURL = "http://localhost:" + port
+	}
+
+	if raw := os.Getenv("CONNECTOR_TOKEN_KEY"); raw != "" {
+		key, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil || len(key) != 32 {
+			log.Fatalf("❌ CONNECTOR_TOKEN_KEY harus base64 dari 32 byte acak")
+		}
+		cfg.TokenKey = key
+	} else {
+		// Key acak hanya untuk development: token tersimpan tidak terbaca lagi setelah restart
+		if mode := os.Getenv("GIN_MODE"); mode != "debug" && mode != "test" {
+			log.Fatal("❌ CONNECTOR_TOKEN_KEY wajib diisi di luar GIN_MODE=debug/test")
+		}
+		cfg.TokenKey = make([]byte, 32)
+		if _, err := rand.Read(cfg.TokenKey); err != nil {
+			log.Fatalf("❌ Gagal membuat key enkripsi token connector: %v", err)
+		}
+		log.Println("⚠️ CONNECTOR_TOKEN_KEY kosong, token akun terhubung tidak bisa dibaca setelah restart")
+	}
+
+	return cfg
+}
@@ -49,14 +49,26 @@ func SetupDatabaseConnection() *gorm.DB {
 			&entity.RunGroupMember{},
 			&entity.RunGroupSchedule{},
 			&entity.RunActivity{},
+			&entity.RunActivitySplit{},
+			&entity.PersonalRecord{},
+			&entity.UserBadge{},
 			&entity.DirectMatch{},
 			&entity.DirectChatMessage{},
 			&entity.GroupChatMessage{},
 			&entity.UserPhoto{},
 			&entity.SafetyLog{},
 			&entity.UserBiometric{},
+			&entity.BiometricLoginHistory{},
 			&entity.Notification{},
 			&entity.UserDeviceToken{},
+			&entity.DataExport{},
+			&entity.MatchWeight{},
+			&entity.DiscoveryPreference{},
+			&entity.CandidatePass{},
+			&entity.FaceVerification{},
+			&entity.PhotoUpload{},
+			&entity.ConnectorAccount{},
+			&entity.ConnectorEvent{},
 		); err != nil {
 			log.Fatalf("❌ AutoMigrate gagal: %v", err)
 		}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FaceVerificationConfig adalah parameter pipeline verifikasi wajah.
+type FaceVerificationConfig struct {
+	MatchThreshold  float64       // similarity >= ini langsung verified
+	ReviewThreshold float64       // similarity di antara ini dan MatchThreshold masuk review manual
+	ChallengeTTL    time.Duration // masa berlaku challenge gesture
+	ReviewerIds     map[uuid.UUID]bool
+	// SkipWhenUnavailable: saat provider wajah down, upload foto verifikasi tetap
+	// diterima dan jawaban challenge masuk review manual (policy "skip").
+	// Default false (policy "fail_closed"): request ditolak, user diminta mencoba lagi.
+	SkipWhenUnavailable bool
+}
+
+// SetupFaceVerification membaca FACE_MATCH_THRESHOLD (default 0.80),
+// FACE_REVIEW_THRESHOLD (default 0.60), FACE_CHALLENGE_TTL_SECONDS (default 120)
+// FACE_REVIEWER_IDS (daftar user id dipisah koma yang boleh mereview) dan
+// FACE_SERVICE_UNAVAILABLE_POLICY (fail_closed | skip, default fail_closed).
+func SetupFaceVerification() *FaceVerificationConfig {
+	cfg := &FaceVerificationConfig{
+		MatchThreshold:  envFloat("FACE_MATCH_THRESHOLD", 0.80),
+		ReviewThreshold: envFloat("FACE_REVIEW_THRESHOLD", 0.60),
+		ChallengeTTL:    time.Duration(envFloat("FACE_CHALLENGE_TTL_SECONDS", 120)) * time.Second,
+		ReviewerIds:     make(map[uuid.UUID]bool),
+	}
+
+	switch policy := os.Getenv("FACE_SERVICE_UNAVAILABLE_POLICY"); policy {
+	case "", "fail_closed":
+	case "skip":
+		cfg.SkipWhenUnavailable = true
+	default:
+		log.Printf("⚠️ FACE_SERVICE_UNAVAILABLE_POLICY=%q tidak dikenal, memakai fail_closed", policy)
+	}
+	if cfg.ReviewThreshold > cfg.MatchThreshold {
+		log.Printf("⚠️ FACE_REVIEW_THRESHOLD lebih besar dari FACE_MATCH_THRESHOLD, review manual dinonaktifkan")
+		cfg.ReviewThreshold = cfg.MatchThreshold
+	}
+
+	for _, raw := range strings.Split(os.Getenv("FACE_REVIEWER_IDS"), ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			log.Printf("⚠️ FACE_REVIEWER_IDS: %q bukan UUID, diabaikan", raw)
+			continue
+		}
+		cfg.ReviewerIds[id] = true
+	}
+
+	return cfg
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKey adalah satu kunci di key ring. PrivateKey hanya terisi untuk kunci
+// yang masih bisa dipakai menandatangani; kunci lama yang sudah dirotasi cukup
+// menyimpan PublicKey agar token yang belum kadaluarsa tetap bisa diverifikasi.
+type JWTKey struct {
+	Kid        string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// JWTKeyRing menyimpan kunci aktif untuk signing dan semua kunci verifikasi.
+type JWTKeyRing struct {
+	active *JWTKey
+	keys   map[string]*JWTKey
+}
+
+// SetupJWTKeyRing memuat kunci JWT dari environment.
+//
+// Mode 1 (rotasi): JWT_KEYS_DIR berisi file <kid>.pem (private atau public key),
+// JWT_ACTIVE_KID menentukan kunci yang dipakai untuk signing.
+// Mode 2 (satu kunci): JWT_PRIVATE_KEY berisi PEM private key, JWT_KEY_ID opsional.
+//
+// Aplikasi berhenti jika tidak ada kunci yang dikonfigurasi.
+func SetupJWTKeyRing() *JWTKeyRing {
+	ring := &JWTKeyRing{keys: make(map[string]*JWTKey)}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		if err := ring.loadDir(dir); err != nil {
+			log.Fatalf("❌ Gagal memuat JWT key dari %s: %v", dir, err)
+		}
+
+		activeKid := os.Getenv("JWT_ACTIVE_KID")
+		if activeKid == "" {
+			log.Fatal("❌ JWT_ACTIVE_KID wajib diisi jika JWT_KEYS_DIR digunakan")
+		}
+		if err := ring.setActive(activeKid); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+	} else if pemData := os.Getenv("JWT_PRIVATE_KEY"); pemData != "" {
+		key, err := parseJWTKey(os.Getenv("JWT_KEY_ID"), []byte(pemData))
+		if err != nil {
+			log.Fatalf("❌ JWT_PRIVATE_KEY tidak valid: %v", err)
+		}
+		if key.PrivateKey == nil {
+			log.Fatal("❌ JWT_PRIVATE_KEY harus berisi private key")
+		}
+		ring.keys[key.Kid] = key
+		ring.active = key
+	} else {
+		log.Fatal("❌ JWT signing key belum dikonfigurasi (set JWT_KEYS_DIR + JWT_ACTIVE_KID atau JWT_PRIVATE_KEY)")
+	}
+
+	log.Printf("✅ JWT key ring dimuat: active kid=%s (%s), %d kunci verifikasi", ring.active.Kid, ring.active.Method.Alg(), len(ring.keys))
+	return ring
+}
+
+// Active mengembalikan kunci yang dipakai untuk menandatangani token baru.
+func (r *JWTKeyRing) Active() *JWTKey {
+	return r.active
+}
+
+// Lookup mencari kunci verifikasi berdasarkan kid.
+func (r *JWTKeyRing) Lookup(kid string) (*JWTKey, bool) {
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Keys mengembalikan semua kunci verifikasi, diurutkan berdasarkan kid.
+func (r *JWTKeyRing) Keys() []*JWTKey {
+	kids := make([]string, 0, len(r.keys))
+	for kid := range r.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := make([]*JWTKey, 0, len(kids))
+	for _, kid := range kids {
+		keys = append(keys, r.keys[kid])
+	}
+	return keys
+}
+
+func (r *JWTKeyRing) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pem" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		kid := strings.TrimSuffix(entry.Name(), ".pem")
+		key, err := parseJWTKey(kid, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		r.keys[kid] = key
+	}
+
+	if len(r.keys) == 0 {
+		return fmt.Errorf("tidak ada file .pem")
+	}
+	return nil
+}
+
+func (r *JWTKeyRing) setActive(kid string) error {
+	key, ok := r.keys[kid]
+	if !ok {
+		return fmt.Errorf("JWT_ACTIVE_KID %q tidak ditemukan di key ring", kid)
+	}
+	if key.PrivateKey == nil {
+		return fmt.Errorf("JWT_ACTIVE_KID %q hanya berisi public key", kid)
+	}
+	r.active = key
+	return nil
+}
+
+// parseJWTKey membaca PEM RSA/Ed25519 (private atau public) dan menentukan
+// algoritma signing-nya: RS256 untuk RSA, EdDSA untuk Ed25519.
+func parseJWTKey(kid string, data []byte) (*JWTKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("format PEM tidak valid")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("tipe PEM %q tidak didukung", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &JWTKey{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.PublicKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.PrivateKey, key.PublicKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.PublicKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("hanya kunci RSA dan Ed25519 yang didukung")
+	}
+
+	if rsaKey, ok := key.PublicKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("kunci RSA minimal 2048 bit")
+	}
+
+	if kid == "" {
+		kid, err = keyThumbprint(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	key.Kid = kid
+
+	return key, nil
+}
+
+// keyThumbprint membuat kid default dari SHA-256 public key (DER).
+func keyThumbprint(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:12]), nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Nama faktor skor kompatibilitas (dipakai sebagai key bobot di env maupun preferensi user).
+const (
+	FactorPace              = "pace"
+	FactorDistance          = "distance"
+	FactorPreferredDistance = "preferred_distance"
+	FactorPreferredTime     = "preferred_time"
+	FactorActivity          = "activity"
+	FactorSharedGroups      = "shared_groups"
+	FactorMutualMatches     = "mutual_matches"
+)
+
+// MatchingConfig adalah parameter matching engine per environment.
+type MatchingConfig struct {
+	MaxRadiusKm    float64            // radius pencarian kandidat
+	PaceTolerance  float64            // selisih pace (min/km) yang membuat skor pace = 0
+	MinScore       float64            // skor minimum kandidat
+	ActivityTarget float64            // km dalam ActivityDays yang dianggap skor aktivitas penuh
+	ActivityDays   int                // jendela aktivitas terbaru
+	Weights        map[string]float64 // bobot default per faktor
+
+	PassCooldownDays    int     // kandidat yang di-pass disembunyikan selama ini (berlipat tiap pass ulang)
+	PassMaxCooldownDays int     // batas atas cooldown
+	PassDecay           float64 // penalti skor per pass setelah kandidat muncul lagi
+	PassDecayDays       int     // pass lebih lama dari ini tidak lagi memberi penalti
+	DailyImpressionCap  int     // jumlah kandidat maksimal yang ditampilkan per user per hari
+
+	RequestTTLDays      int // request pending kedaluwarsa setelah ini
+	UnmatchCooldownDays int // setelah unmatch, pasangan yang sama baru bisa match lagi setelah ini
+}
+
+// DefaultMatchWeights dipakai bila MATCH_WEIGHTS kosong.
+var DefaultMatchWeights = map[string]float64{
+	FactorPace:              0.35,
+	FactorDistance:          0.20,
+	FactorPreferredDistance: 0.10,
+	FactorPreferredTime:     0.10,
+	FactorActivity:          0.10,
+	FactorSharedGroups:      0.10,
+	FactorMutualMatches:     0.05,
+}
+
+// IsMatchFactor mengecek apakah nama faktor dikenal.
+func IsMatchFactor(name string) bool {
+	_, ok := DefaultMatchWeights[name]
+	return ok
+}
+
+// SetupMatching membaca MATCH_MAX_RADIUS_KM, MATCH_PACE_TOLERANCE, MATCH_MIN_SCORE,
+// MATCH_ACTIVITY_TARGET_KM, MATCH_ACTIVITY_DAYS, MATCH_WEIGHTS
+// (format "pace=0.4,distance=0.2,..."; faktor yang tidak disebut bernilai 0),
+// MATCH_PASS_COOLDOWN_DAYS, MATCH_PASS_MAX_COOLDOWN_DAYS, MATCH_PASS_DECAY,
+// MATCH_PASS_DECAY_DAYS, MATCH_DAILY_IMPRESSION_CAP, MATCH_REQUEST_TTL_DAYS dan
+// MATCH_UNMATCH_COOLDOWN_DAYS.
+func SetupMatching() *MatchingConfig {
+	cfg := &MatchingConfig{
+		MaxRadiusKm:    envFloat("MATCH_MAX_RADIUS_KM", 10),
+		PaceTolerance:  envFloat("MATCH_PACE_TOLERANCE", 2),
+		MinScore:       envFloat("MATCH_MIN_SCORE", 0.3),
+		ActivityTarget: envFloat("MATCH_ACTIVITY_TARGET_KM", 40),
+		ActivityDays:   int(envFloat("MATCH_ACTIVITY_DAYS", 28)),
+		Weights:        make(map[string]float64, len(DefaultMatchWeights)),
+
+		PassCooldownDays:    int(envFloat("MATCH_PASS_COOLDOWN_DAYS", 7)),
+		PassMaxCooldownDays: int(envFloat("MATCH_PASS_MAX_COOLDOWN_DAYS", 90)),
+		PassDecay:           envFloat("MATCH_PASS_DECAY", 0.15),
+		PassDecayDays:       int(envFloat("MATCH_PASS_DECAY_DAYS", 60)),
+		DailyImpressionCap:  int(envFloat("MATCH_DAILY_IMPRESSION_CAP", 200)),
+
+		RequestTTLDays:      int(envFloat("MATCH_REQUEST_TTL_DAYS", 7)),
+		UnmatchCooldownDays: int(envFloat("MATCH_UNMATCH_COOLDOWN_DAYS", 30)),
+	}
+
+	raw := strings.TrimSpace(os.Getenv("MATCH_WEIGHTS"))
+	if raw != "" {
+		for _, pair := range strings.Split(raw, ",") {
+			name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			name = strings.TrimSpace(name)
+			w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if !ok || err != nil || w < 0 || !IsMatchFactor(name) {
+				log.Printf("⚠️ MATCH_WEIGHTS: entri %q diabaikan", pair)
+				continue
+			}
+			cfg.Weights[name] = w
+		}
+	}
+	if len(cfg.Weights) == 0 {
+		for name, w := range DefaultMatchWeights {
+			cfg.Weights[name] = w
+		}
+	}
+
+	return cfg
+}
+
+func envFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"run-sync/helper"
+	"strings"
+	"time"
+)
+
+// PhotoConfig mengatur validasi & pemrosesan foto yang diunggah user.
+type PhotoConfig struct {
+	MaxBytes      int64
+	AllowedTypes  []string
+	MinDimension  int
+	MaxDimension  int
+	MaxPixels     int
+	ThumbnailSize int
+	UploadURLTTL  time.Duration // masa berlaku URL upload langsung ke storage
+
+	MaxProfilePhotos int // batas foto galeri per tipe, 0 = tanpa batas
+	MaxRunPhotos     int
+}
+
+// SetupPhoto membaca PHOTO_MAX_MB (default 10), PHOTO_ALLOWED_TYPES (default
+// image/jpeg,image/png), PHOTO_MIN_DIMENSION (200), PHOTO_MAX_DIMENSION (8000), PHOTO_MAX_MEGAPIXELS (25),
+// PHOTO_THUMBNAIL_SIZE (320), PHOTO_UPLOAD_URL_TTL_MINUTES (15), PHOTO_MAX_PROFILE (6)
+// dan PHOTO_MAX_RUN (30).
+func SetupPhoto() *PhotoConfig {
+	allowed := []string{"image/jpeg", "image/png"}
+	if raw := os.Getenv("PHOTO_ALLOWED_TYPES"); raw != "" {
+		allowed = nil
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(strings.ToLower(t)); t != "" {
+				allowed = append(allowed, t)
+			}
+		}
+	}
+
+	return &PhotoConfig{
+		MaxBytes:      int64(envFloat("PHOTO_MAX_MB", 10) * 1024 * 1024),
+		AllowedTypes:  allowed,
+		MinDimension:  int(envFloat("PHOTO_MIN_DIMENSION", 200)),
+		MaxDimension:  int(envFloat("PHOTO_MAX_DIMENSION", 8000)),
+		MaxPixels:     int(envFloat("PHOTO_MAX_MEGAPIXELS", 25) * 1e6),
+		ThumbnailSize: int(envFloat("PHOTO_THUMBNAIL_SIZE", 320)),
+		UploadURLTTL:  time.Duration(envFloat("PHOTO_UPLOAD_URL_TTL_MINUTES", 15) * float64(time.Minute)),
+
+		MaxProfilePhotos: int(envFloat("PHOTO_MAX_PROFILE", 6)),
+		MaxRunPhotos:     int(envFloat("PHOTO_MAX_RUN", 30)),
+	}
+}
+
+// MaxPhotos mengembalikan batas jumlah foto untuk tipe; foto verifikasi tidak dibatasi.
+func (c *PhotoConfig) MaxPhotos(photoType string) int {
+	switch photoType {
+	case "profile":
+		return c.MaxProfilePhotos
+	case "run":
+		return c.MaxRunPhotos
+	}
+	return 0
+}
+
+// ImageOptions mengubah konfigurasi menjadi aturan helper.ProcessImage.
+func (c *PhotoConfig) ImageOptions() helper.ImageOptions {
+	return helper.ImageOptions{
+		MaxBytes:      c.MaxBytes,
+		AllowedTypes:  c.AllowedTypes,
+		MinDimension:  c.MinDimension,
+		MaxDimension:  c.MaxDimension,
+		MaxPixels:     c.MaxPixels,
+		ThumbnailSize: c.ThumbnailSize,
+	}
+}
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// StorageConfig mengatur signed URL dan sweep asset orphan di object storage.
+type StorageConfig struct {
+	SignedURLTTL  time.Duration // masa berlaku signed URL (foto verifikasi, selfie review)
+	SweepInterval time.Duration // jarak antar sweep orphan
+	OrphanGrace   time.Duration // asset lebih muda dari ini tidak disapu (upload yang sedang berjalan)
+}
+
+// SetupStorage membaca STORAGE_SIGNED_URL_TTL_MINUTES (default 15),
+// STORAGE_SWEEP_INTERVAL_HOURS (default 6) dan STORAGE_ORPHAN_GRACE_HOURS (default 24).
+func SetupStorage() *StorageConfig {
+	return &StorageConfig{
+		SignedURLTTL:  time.Duration(envFloat("STORAGE_SIGNED_URL_TTL_MINUTES", 15) * float64(time.Minute)),
+		SweepInterval: time.Duration(envFloat("STORAGE_SWEEP_INTERVAL_HOURS", 6) * float64(time.Hour)),
+		OrphanGrace:   time.Duration(envFloat("STORAGE_ORPHAN_GRACE_HOURS", 24) * float64(time.Hour)),
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// WebAuthnConfig adalah identitas relying party untuk passkey/biometrik.
+type WebAuthnConfig struct {
+	RPID    string   // domain, misal "runsync.id"
+	RPName  string   // nama yang ditampilkan authenticator
+	Origins []string // origin yang diizinkan, termasuk "android:apk-key-hash:..." untuk aplikasi Android
+}
+
+// SetupWebAuthn membaca WEBAUTHN_RP_ID, WEBAUTHN_RP_NAME dan WEBAUTHN_ORIGINS (dipisah koma).
+func SetupWebAuthn() *WebAuthnConfig {
+	cfg := &WebAuthnConfig{
+		RPID:   os.Getenv("WEBAUTHN_RP_ID"),
+		RPName: os.Getenv("WEBAUTHN_RP_NAME"),
+	}
+	if cfg.RPID == "" {
+		cfg.RPID = "localhost"
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = "Run-Sync"
+	}
+
+	for _, o := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		if o = strings.TrimRight(strings.TrimSpace(o), "/"); o != "" {
+			cfg.Origins = append(cfg.Origins, o)
+		}
+	}
+	if len(cfg.Origins) == 0 {
+		cfg.Origins = []string{"http://localhost:3000"}
+		log.Println("⚠️ WEBAUTHN_ORIGINS belum diisi, hanya http://localhost:3000 yang diizinkan")
+	}
+
+	return cfg
+}
+
+// IsAllowedOrigin mengecek origin dari clientDataJSON.
+func (c *WebAuthnConfig) IsAllowedOrigin(origin string) bool {
+	origin = strings.TrimRight(origin, "/")
+	for _, o := range c.Origins {
+		if o == origin {
+			return true
+		}
+	}
+	return false
+}
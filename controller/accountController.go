@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"run-sync/data/request"
+	"run-sync/helper"
+	"run-sync/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountController interface {
+	ScheduleDeletion(ctx *gin.Context)
+	CancelDeletion(ctx *gin.Context)
+	DeleteUser(ctx *gin.Context)
+	RequestExport(ctx *gin.Context)
+	GetExport(ctx *gin.Context)
+	DownloadExport(ctx *gin.Context)
+}
+
+type accountController struct {
+	service     service.AccountService
+	redisHelper *helper.RedisHelper
+}
+
+func NewAccountController(s service.AccountService, redisHelper *helper.RedisHelper) AccountController {
+	return &accountController{service: s, redisHelper: redisHelper}
+}
+
+// ScheduleDeletion - POST /users/me/deletion
+func (c *accountController) ScheduleDeletion(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.DeleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.ScheduleDeletion(userId, req.Password)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menjadwalkan hapus akun", "ACCOUNT_DELETION_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Akun dijadwalkan untuk dihapus", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// CancelDeletion - DELETE /users/me/deletion
+func (c *accountController) CancelDeletion(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	if err := c.service.CancelDeletion(userId); err != nil {
+		res := helper.BuildErrorResponse("Gagal membatalkan hapus akun", "ACCOUNT_DELETION_CANCEL_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Penghapusan akun dibatalkan", nil)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DeleteUser - DELETE /users/:id, alias ScheduleDeletion untuk akun sendiri: wajib
+// konfirmasi password dan tetap melalui masa tenggang sebelum data dihapus permanen
+func (c *accountController) DeleteUser(ctx *gin.Context) {
+	requesterId := ctx.MustGet("user_id").(uuid.UUID)
+	userId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID user tidak valid", "INVALID_ID", "id", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	if userId != requesterId {
+		res := helper.BuildErrorResponse("Akses ditolak", "FORBIDDEN", "id", "Anda hanya dapat menghapus akun sendiri", nil)
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+
+	c.ScheduleDeletion(ctx)
+}
+
+// RequestExport - POST /users/me/export
+func (c *accountController) RequestExport(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.DataExportRequest
+	// Body kosong diperbolehkan (format default zip)
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.redisHelper.AllowRequest("data_export:"+userId.String(), 3, 24*time.Hour); err != nil {
+		res := helper.BuildErrorResponse("Terlalu banyak permintaan", "RATE_LIMIT", "body", err.Error(), nil)
+		ctx.JSON(http.StatusTooManyRequests, res)
+		return
+	}
+
+	result, err := c.service.RequestExport(userId, req.Format)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrInvalidExportFormat) {
+			status = http.StatusBadRequest
+		}
+		res := helper.BuildErrorResponse("Gagal membuat export data", "DATA_EXPORT_FAILED", "format", err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Export data sedang diproses", result)
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+// GetExport - GET /users/me/export/:exportId
+func (c *accountController) GetExport(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	exportId, err := uuid.Parse(ctx.Param("exportId"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID export tidak valid", "INVALID_ID", "exportId", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.GetExport(userId, exportId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Export tidak ditemukan", "DATA_EXPORT_NOT_FOUND", "exportId", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Berhasil mengambil status export", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// DownloadExport - GET /users/me/export/:exportId/download
+func (c *accountController) DownloadExport(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	exportId, err := uuid.Parse(ctx.Param("exportId"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID export tidak valid", "INVALID_ID", "exportId", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	path, filename, err := c.service.ExportFile(userId, exportId)
+	if err != nil {
+		res := helper.BuildErrorResponse("File export tidak tersedia", "DATA_EXPORT_UNAVAILABLE", "exportId", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	ctx.FileAttachment(path, filename)
+}
@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"time"
 
 	"run-sync/data/request"
+	"run-sync/entity"
 	"run-sync/helper"
 	"run-sync/service"
 
@@ -21,6 +24,9 @@ type AuthController interface {
 	Login(ctx *gin.Context)
 	ResendOTP(ctx *gin.Context)
 	RefreshToken(ctx *gin.Context)
+	ForgotPassword(ctx *gin.Context)
+	VerifyResetPassword(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
 }
 
 type authController struct {
@@ -28,6 +34,8 @@ type authController struct {
 	jwtService  service.JWTService
 	otpHelper   *helper.RedisHelper
 	emailHelper *helper.EmailHelper
+	otpSender   *helper.OTPDispatcher
+	notifSvc    service.NotificationService
 }
 
 func NewAuthController(
@@ -35,12 +43,45 @@ func NewAuthController(
 	jwtService service.JWTService,
 	otpHelper *helper.RedisHelper,
 	emailHelper *helper.EmailHelper,
+	otpSender *helper.OTPDispatcher,
+	notifSvc service.NotificationService,
 ) AuthController {
 	return &authController{
 		userService: userService,
 		jwtService:  jwtService,
 		otpHelper:   otpHelper,
 		emailHelper: emailHelper,
+		otpSender:   otpSender,
+		notifSvc:    notifSvc,
+	}
+}
+
+const (
+	resetPasswordOTPTTL   = 15 * time.Minute
+	resetPasswordTokenTTL = 10 * time.Minute
+)
+
+// respondOTPError memetakan error dari RedisHelper.CheckOTP/IssueOTP ke response HTTP.
+func respondOTPError(ctx *gin.Context, err error, remaining int) {
+	var locked *helper.OTPLockedError
+	switch {
+	case errors.As(err, &locked):
+		ctx.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())))
+		res := helper.BuildErrorResponse("Terlalu banyak percobaan, silakan coba lagi nanti", "OTP_LOCKED", "body", err.Error(), map[string]interface{}{
+			"retry_after": int(locked.RetryAfter.Seconds()),
+		})
+		ctx.JSON(http.StatusTooManyRequests, res)
+	case errors.Is(err, helper.ErrOTPMismatch):
+		res := helper.BuildErrorResponse("Kode OTP salah", "INVALID_OTP", "body", fmt.Sprintf("sisa percobaan: %d", remaining), map[string]interface{}{
+			"remaining_attempts": remaining,
+		})
+		ctx.JSON(http.StatusBadRequest, res)
+	case errors.Is(err, helper.ErrOTPExpired):
+		res := helper.BuildErrorResponse("Kode OTP tidak valid atau sudah kadaluarsa", "INVALID_OTP", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+	default:
+		res := helper.BuildErrorResponse("Gagal memproses OTP", "OTP_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
 	}
 }
 
@@ -61,10 +102,6 @@ func (c *authController) Register(ctx *gin.Context) {
 		return
 	}
 
-	// Generate and send OTP
-	otp := helper.GenerateOTPCode(6)
-	hashedOTP := helper.HashOTP(otp)
-
 	// Check rate limit for OTP
 	identifier := req.PhoneNumber
 	if err := c.otpHelper.AllowRequest(identifier, 5, 15*time.Minute); err != nil {
@@ -73,22 +110,30 @@ func (c *authController) Register(ctx *gin.Context) {
 		return
 	}
 
-	// Save OTP to Redis (15 minutes expiry)
-	if err := c.otpHelper.SaveOTP("register", identifier, hashedOTP, 15*time.Minute); err != nil {
-		res := helper.BuildErrorResponse("Gagal menyimpan OTP", "OTP_SAVE_FAILED", "body", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+	// Generate and save OTP to Redis (15 minutes expiry)
+	otp, err := c.otpHelper.IssueOTP("register", identifier, 15*time.Minute)
+	if err != nil {
+		respondOTPError(ctx, err, 0)
 		return
 	}
 
-	// Send OTP via WhatsApp
-	waMessage := fmt.Sprintf("🏃 Run-Sync\n\nKode verifikasi Anda: *%s*\n\nKode ini berlaku selama 15 menit.\nJangan bagikan kode ini kepada siapapun.", otp)
-	if err := helper.SendOTPViaWhatsApp(identifier, waMessage); err != nil {
-		log.Printf("⚠️ Gagal kirim OTP via WhatsApp ke %s: %v", identifier, err)
+	// OTP register membuktikan kepemilikan nomor telepon, jadi hanya dikirim via
+	// WhatsApp / SMS; email yang belum terverifikasi tidak dipakai sebagai fallback
+	delivery := c.otpSender.Send(
+		helper.OTPRecipient{Phone: identifier},
+		helper.OTPMessage{Purpose: "register", Code: otp, TTL: 15 * time.Minute, Lang: helper.ResolveEmailLanguage(ctx.GetHeader("Accept-Language"))},
+		helper.StringValue(req.OTPChannel),
+	)
+
+	message := fmt.Sprintf("Kode OTP telah dikirim via %s ke %s", delivery.Channel, delivery.Destination)
+	if !delivery.Delivered {
+		message = "Kode OTP gagal dikirim, silakan minta ulang kode OTP"
 	}
 
 	response := helper.BuildResponse(true, "User berhasil dibuat. Silakan verifikasi dengan kode OTP.", map[string]interface{}{
-		"user":    result,
-		"message": "Kode OTP telah dikirim ke WhatsApp Anda",
+		"user":     result,
+		"message":  message,
+		"delivery": delivery,
 	})
 
 	ctx.JSON(http.StatusCreated, response)
@@ -103,18 +148,9 @@ func (c *authController) VerifyOTP(ctx *gin.Context) {
 		return
 	}
 
-	// Get stored OTP from Redis
-	storedHash, err := c.otpHelper.GetOTP("register", req.PhoneNumber)
-	if err != nil {
-		res := helper.BuildErrorResponse("Kode OTP tidak valid atau sudah kadaluarsa", "INVALID_OTP", "body", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
-		return
-	}
-
-	// Verify OTP
-	if storedHash != helper.HashOTP(req.OTPCode) {
-		res := helper.BuildErrorResponse("Kode OTP salah", "INVALID_OTP", "body", "OTP does not match", nil)
-		ctx.JSON(http.StatusBadRequest, res)
+	// Verify OTP (attempt-limited, OTP is deleted on success)
+	if remaining, err := c.otpHelper.CheckOTP("register", req.PhoneNumber, req.OTPCode); err != nil {
+		respondOTPError(ctx, err, remaining)
 		return
 	}
 
@@ -126,9 +162,6 @@ func (c *authController) VerifyOTP(ctx *gin.Context) {
 		return
 	}
 
-	// Delete OTP from Redis
-	c.otpHelper.DeleteOTP("register", req.PhoneNumber)
-
 	// Generate JWT tokens
 	expiryTime := time.Now().Add(1 * time.Hour)
 	accessToken := c.jwtService.GenerateToken(user.Id, user.PhoneNumber, user.Email, expiryTime)
@@ -211,24 +244,33 @@ func (c *authController) ResendOTP(ctx *gin.Context) {
 		return
 	}
 
-	// Generate new OTP
-	otp := helper.GenerateOTPCode(6)
-	hashedOTP := helper.HashOTP(otp)
-
-	// Save OTP to Redis
-	if err := c.otpHelper.SaveOTP("register", req.PhoneNumber, hashedOTP, 15*time.Minute); err != nil {
-		res := helper.BuildErrorResponse("Gagal mengirim OTP", "OTP_SEND_FAILED", "body", err.Error(), nil)
-		ctx.JSON(http.StatusInternalServerError, res)
+	// Generate and save new OTP
+	otp, err := c.otpHelper.IssueOTP("register", req.PhoneNumber, 15*time.Minute)
+	if err != nil {
+		respondOTPError(ctx, err, 0)
 		return
 	}
 
-	// Send OTP via WhatsApp
-	waMessage := fmt.Sprintf("🏃 Run-Sync\n\nKode verifikasi baru Anda: *%s*\n\nKode ini berlaku selama 15 menit.\nJangan bagikan kode ini kepada siapapun.", otp)
-	if err := helper.SendOTPViaWhatsApp(req.PhoneNumber, waMessage); err != nil {
-		log.Printf("⚠️ Gagal kirim OTP via WhatsApp ke %s: %v", req.PhoneNumber, err)
+	// Channel dari request menimpa preferensi user
+	preferred := req.Channel
+	if preferred == "" {
+		preferred = helper.StringValue(user.OTPChannel)
 	}
 
-	response := helper.BuildResponse(true, "Kode OTP baru telah dikirim ke WhatsApp", nil)
+	delivery := c.otpSender.Send(
+		helper.OTPRecipient{Phone: user.PhoneNumber},
+		helper.OTPMessage{Purpose: "register", Code: otp, TTL: 15 * time.Minute, Lang: helper.ResolveEmailLanguage(ctx.GetHeader("Accept-Language"))},
+		preferred,
+	)
+	if !delivery.Delivered {
+		res := helper.BuildErrorResponse("Gagal mengirim OTP", "OTP_SEND_FAILED", "body", "semua channel pengiriman gagal", delivery)
+		ctx.JSON(http.StatusBadGateway, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, fmt.Sprintf("Kode OTP baru telah dikirim via %s", delivery.Channel), map[string]interface{}{
+		"delivery": delivery,
+	})
 
 	ctx.JSON(http.StatusOK, response)
 }
@@ -309,3 +351,173 @@ func (c *authController) RefreshToken(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// ForgotPassword - Send reset password OTP via WhatsApp (or verified email)
+// Response selalu sama agar endpoint ini tidak bisa dipakai untuk mengecek
+// apakah sebuah nomor/email terdaftar.
+func (c *authController) ForgotPassword(ctx *gin.Context) {
+	var req request.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// Check rate limit per identifier
+	if err := c.otpHelper.AllowRequest("reset_password:"+req.Identifier, 3, resetPasswordOTPTTL); err != nil {
+		res := helper.BuildErrorResponse("Terlalu banyak permintaan", "RATE_LIMIT", "body", err.Error(), nil)
+		ctx.JSON(http.StatusTooManyRequests, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Jika akun terdaftar, kode OTP reset password telah dikirim", nil)
+
+	user, err := c.userService.FindByEmailOrPhone(req.Identifier)
+	if err != nil || !user.IsActive {
+		ctx.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Generate new OTP. Saat identifier terkunci, response tetap generik.
+	otp, err := c.otpHelper.IssueOTP("reset_password", user.Id, resetPasswordOTPTTL)
+	if err != nil {
+		var locked *helper.OTPLockedError
+		if errors.As(err, &locked) {
+			ctx.JSON(http.StatusOK, response)
+			return
+		}
+		res := helper.BuildErrorResponse("Gagal mengirim OTP", "OTP_SEND_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	// Email hanya dipakai jika sudah terverifikasi, dan diutamakan jika user
+	// memintanya dengan email. Status pengiriman tidak dikembalikan ke client.
+	recipient := helper.OTPRecipient{Phone: user.PhoneNumber}
+	preferred := helper.StringValue(user.OTPChannel)
+	if user.Email != nil && user.EmailVerified {
+		recipient.Email = user.Email
+		if helper.IsEmail(req.Identifier) {
+			preferred = string(helper.OTPChannelEmail)
+		}
+	}
+
+	delivery := c.otpSender.Send(
+		recipient,
+		helper.OTPMessage{Purpose: "reset_password", Code: otp, TTL: resetPasswordOTPTTL, Lang: helper.ResolveEmailLanguage(ctx.GetHeader("Accept-Language"))},
+		preferred,
+	)
+	if !delivery.Delivered {
+		log.Printf("⚠️ OTP reset password untuk user %s tidak terkirim: %+v", user.Id, delivery.Attempts)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// VerifyResetPassword - Verify reset password OTP and issue a one-time reset token
+func (c *authController) VerifyResetPassword(ctx *gin.Context) {
+	var req request.VerifyResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	user, err := c.userService.FindByEmailOrPhone(req.Identifier)
+	if err != nil {
+		res := helper.BuildErrorResponse("Kode OTP tidak valid atau sudah kadaluarsa", "INVALID_OTP", "body", "OTP tidak ditemukan", nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// Verify OTP (attempt-limited, OTP is deleted on success)
+	if remaining, err := c.otpHelper.CheckOTP("reset_password", user.Id, req.OTPCode); err != nil {
+		respondOTPError(ctx, err, remaining)
+		return
+	}
+
+	// Reset token hanya disimpan dalam bentuk hash
+	resetToken := helper.GenerateSecureToken(32)
+	if err := c.otpHelper.SaveOTP("reset_password_token", user.Id, helper.HashOTP(resetToken), resetPasswordTokenTTL); err != nil {
+		res := helper.BuildErrorResponse("Gagal memverifikasi OTP", "OTP_VERIFY_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+	if err := c.otpHelper.SetResetPasswordVerified(user.Id, resetPasswordTokenTTL); err != nil {
+		res := helper.BuildErrorResponse("Gagal memverifikasi OTP", "OTP_VERIFY_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Kode OTP valid. Silakan buat password baru.", map[string]interface{}{
+		"reset_token": resetToken,
+		"expires_in":  int(resetPasswordTokenTTL.Seconds()),
+	})
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ResetPassword - Set new password and revoke all existing sessions
+func (c *authController) ResetPassword(ctx *gin.Context) {
+	var req request.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	invalid := helper.BuildErrorResponse("Sesi reset password tidak valid atau sudah kadaluarsa", "INVALID_RESET_TOKEN", "body", "silakan ulangi proses lupa password", nil)
+
+	user, err := c.userService.FindByEmailOrPhone(req.Identifier)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	verified, err := c.otpHelper.IsResetPasswordVerified(user.Id)
+	if err != nil || !verified {
+		ctx.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	storedHash, err := c.otpHelper.GetOTP("reset_password_token", user.Id)
+	if err != nil || !helper.CompareOTPHash(storedHash, helper.HashOTP(req.ResetToken)) {
+		ctx.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	userId, err := uuid.Parse(user.Id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, invalid)
+		return
+	}
+
+	if err := c.userService.ResetPassword(userId, req.NewPassword); err != nil {
+		res := helper.BuildErrorResponse("Gagal mengubah password", "RESET_PASSWORD_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	// Token reset hanya bisa dipakai sekali
+	c.otpHelper.DeleteOTP("reset_password_token", user.Id)
+	c.otpHelper.DeleteResetPasswordVerified(user.Id)
+
+	// Logout dari semua device
+	if err := c.jwtService.RevokeAllTokens(user.Id); err != nil {
+		log.Printf("⚠️ Gagal mencabut sesi user %s setelah reset password: %v", user.Id, err)
+	}
+
+	if err := c.notifSvc.Send(
+		userId,
+		entity.NotifPasswordChanged,
+		"Password berhasil diubah",
+		"Password akun Run-Sync Anda baru saja diubah. Jika ini bukan Anda, segera hubungi tim kami.",
+		nil, nil, nil,
+	); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi password_changed ke %s: %v", user.Id, err)
+	}
+
+	response := helper.BuildResponse(true, "Password berhasil diubah. Silakan login kembali.", nil)
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"run-sync/data/request"
 	"run-sync/helper"
@@ -17,6 +19,10 @@ type BiometricController interface {
 	LoginStart(ctx *gin.Context)
 	LoginFinish(ctx *gin.Context)
 	GetCredentials(ctx *gin.Context)
+	RenameCredential(ctx *gin.Context)
+	DisableCredential(ctx *gin.Context)
+	EnableCredential(ctx *gin.Context)
+	GetLoginHistory(ctx *gin.Context)
 	DeleteCredential(ctx *gin.Context)
 }
 
@@ -37,7 +43,7 @@ func (c *biometricController) RegisterStart(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.service.RegisterStart(userId, req)
+	result, err := c.service.RegisterStart(userId, ctx.GetHeader("Origin"), req)
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal memulai registrasi biometrik", "BIOMETRIC_REGISTER_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
@@ -76,7 +82,7 @@ func (c *biometricController) LoginStart(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.service.LoginStart(req)
+	result, err := c.service.LoginStart(ctx.GetHeader("Origin"), req)
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal memulai login biometrik", "BIOMETRIC_LOGIN_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
@@ -95,7 +101,13 @@ func (c *biometricController) LoginFinish(ctx *gin.Context) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := c.service.LoginFinish(req)
+	client := service.BiometricClientInfo{
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		Country:   ctx.GetHeader("CF-IPCountry"),
+	}
+
+	user, accessToken, refreshToken, err := c.service.LoginFinish(req, client)
 	if err != nil {
 		res := helper.BuildErrorResponse("Login biometrik gagal", "BIOMETRIC_LOGIN_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusUnauthorized, res)
@@ -124,6 +136,108 @@ func (c *biometricController) GetCredentials(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+func (c *biometricController) RenameCredential(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	credentialId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID credential tidak valid", "INVALID_ID", "id", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req request.BiometricRenameRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.RenameCredential(userId, credentialId, req)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengganti nama perangkat", "BIOMETRIC_UPDATE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Nama perangkat berhasil diganti", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *biometricController) DisableCredential(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	credentialId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID credential tidak valid", "INVALID_ID", "id", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.DisableCredential(userId, credentialId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menonaktifkan biometrik", "BIOMETRIC_UPDATE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Biometrik berhasil dinonaktifkan", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *biometricController) EnableCredential(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	credentialId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID credential tidak valid", "INVALID_ID", "id", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req request.BiometricEnableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.EnableCredential(userId, credentialId, req)
+	if errors.Is(err, service.ErrBiometricPasswordMismatch) {
+		res := helper.BuildErrorResponse("Password salah", "INVALID_PASSWORD", "password", err.Error(), nil)
+		ctx.JSON(http.StatusUnauthorized, res)
+		return
+	}
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengaktifkan biometrik", "BIOMETRIC_UPDATE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Biometrik berhasil diaktifkan kembali", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *biometricController) GetLoginHistory(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	credentialId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID credential tidak valid", "INVALID_ID", "id", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	result, err := c.service.GetLoginHistory(userId, credentialId, page, limit)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengambil riwayat login biometrik", "BIOMETRIC_FETCH_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Berhasil mengambil riwayat login biometrik", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
 func (c *biometricController) DeleteCredential(ctx *gin.Context) {
 	userId := ctx.MustGet("user_id").(uuid.UUID)
 	credentialId, _ := uuid.Parse(ctx.Param("id"))
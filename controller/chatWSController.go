@@ -120,6 +120,16 @@ func (c *chatWSController) HandleDirectChat(ctx *gin.Context) {
 		return
 	}
 
+	// Hanya peserta match yang masih aktif (accepted) yang bisa masuk room
+	userUUID, _ := uuid.Parse(userID)
+	matchUUID, _ := uuid.Parse(matchID)
+	if !c.isOpenDirectRoom(matchUUID, userUUID) {
+		ctx.JSON(http.StatusForbidden, helper.BuildErrorResponse(
+			"Obrolan tidak tersedia", "FORBIDDEN", "matchId", "match tidak aktif atau kamu bukan bagian dari match ini", nil,
+		))
+		return
+	}
+
 	roomID := "direct:" + matchID
 
 	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
@@ -225,6 +235,14 @@ func (c *chatWSController) GetDirectHistory(ctx *gin.Context) {
 		return
 	}
 
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	if !c.isOpenDirectRoom(matchID, userId) {
+		ctx.JSON(http.StatusForbidden, helper.BuildErrorResponse(
+			"Obrolan tidak tersedia", "FORBIDDEN", "matchId", "match tidak aktif atau kamu bukan bagian dari match ini", nil,
+		))
+		return
+	}
+
 	messages, err := c.directChatRepo.FindByMatchId(matchID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, helper.BuildErrorResponse(
@@ -383,6 +401,21 @@ func (c *chatWSController) handleDirectMessage(client *ws.Client, raw []byte, ma
 		senderUUID, _ := uuid.Parse(client.UserID)
 		matchUUID, _ := uuid.Parse(matchID)
 
+		// Match bisa di-unmatch saat room masih terbuka: tolak pesan baru
+		match, err := c.directMatchRepo.FindById(matchUUID)
+		if err != nil || match.Status != "accepted" {
+			closed, _ := json.Marshal(map[string]string{
+				"type":    "closed",
+				"room_id": matchID,
+				"message": "Obrolan ini sudah ditutup",
+			})
+			select {
+			case client.Send <- closed:
+			default:
+			}
+			return
+		}
+
 		msg := entity.DirectChatMessage{
 			Id:        uuid.New(),
 			MatchId:   matchUUID,
@@ -398,11 +431,6 @@ func (c *chatWSController) handleDirectMessage(client *ws.Client, raw []byte, ma
 			}
 
 			// Kirim FCM push ke penerima (user lain dalam match)
-			match, err := c.directMatchRepo.FindById(matchUUID)
-			if err != nil {
-				log.Printf("WS: Failed to find match %s: %v", matchID, err)
-				return
-			}
 			recipientId := match.User1Id
 			if match.User1Id == senderUUID {
 				recipientId = match.User2Id
@@ -543,6 +571,19 @@ func (c *chatWSController) handleGroupMessage(client *ws.Client, raw []byte, gro
 	}
 }
 
+// isOpenDirectRoom mengecek user adalah peserta match dan match masih accepted
+// (room ditutup setelah unmatch).
+func (c *chatWSController) isOpenDirectRoom(matchID, userID uuid.UUID) bool {
+	match, err := c.directMatchRepo.FindById(matchID)
+	if err != nil {
+		return false
+	}
+	if match.User1Id != userID && match.User2Id != userID {
+		return false
+	}
+	return match.Status == "accepted"
+}
+
 func (c *chatWSController) getUserResponse(userID uuid.UUID) *response.UserResponse {
 	user, err := c.userRepo.FindById(userID)
 	if err != nil {
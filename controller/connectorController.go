@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"run-sync/config"
+	"run-sync/helper"
+	"run-sync/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ConnectorController interface {
+	List(ctx *gin.Context)
+	Link(ctx *gin.Context)
+	Callback(ctx *gin.Context)
+	Unlink(ctx *gin.Context)
+	Backfill(ctx *gin.Context)
+	VerifyWebhook(ctx *gin.Context)
+	Webhook(ctx *gin.Context)
+}
+
+type connectorController struct {
+	service service.ConnectorService
+	cfg     *config.ConnectorConfig
+}
+
+func NewConnectorController(s service.ConnectorService, cfg *config.ConnectorConfig) ConnectorController {
+	return &connectorController{service: s, cfg: cfg}
+}
+
+// connectorErrorStatus memetakan error connector ke status HTTP.
+func connectorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrConnectorUnknownProvider), errors.Is(err, service.ErrConnectorNotLinked):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrConnectorInvalidState), errors.Is(err, helper.ErrConnectorAuthFailed),
+		errors.Is(err, helper.ErrConnectorWebhookInvalid):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrConnectorLinkedElsewhere), errors.Is(err, service.ErrConnectorRevoked):
+		return http.StatusConflict
+	case errors.Is(err, helper.ErrConnectorRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, helper.ErrConnectorUnavailable):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+// List - GET /connectors
+func (c *connectorController) List(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	result, err := c.service.List(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengambil daftar connector", "FETCH_FAILED", "", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Daftar connector berhasil diambil", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Link - POST /connectors/:provider/link, mengembalikan URL persetujuan OAuth2 provider
+func (c *connectorController) Link(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	result, err := c.service.StartLink(userId, ctx.Param("provider"))
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal memulai penghubungan akun", "LINK_FAILED", "provider", err.Error(), nil)
+		ctx.JSON(connectorErrorStatus(err), res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Buka authorize_url untuk menghubungkan akun", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Callback - GET /connectors/:provider/callback (redirect OAuth2 dari provider)
+func (c *connectorController) Callback(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+
+	if denied := ctx.Query("error"); denied != "" {
+		if c.redirectToApp(ctx, provider, "denied", denied) {
+			return
+		}
+		res := helper.BuildErrorResponse("Penghubungan akun dibatalkan", "LINK_DENIED", "error", denied, nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	state, code := ctx.Query("state"), ctx.Query("code")
+	if state == "" || code == "" {
+		if c.redirectToApp(ctx, provider, "failed", "state dan code wajib diisi") {
+			return
+		}
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "query", "state dan code wajib diisi", nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.CompleteLink(provider, state, code)
+	if err != nil {
+		if c.redirectToApp(ctx, provider, "failed", err.Error()) {
+			return
+		}
+		res := helper.BuildErrorResponse("Gagal menghubungkan akun", "LINK_FAILED", "code", err.Error(), nil)
+		ctx.JSON(connectorErrorStatus(err), res)
+		return
+	}
+
+	if c.redirectToApp(ctx, provider, "connected", "") {
+		return
+	}
+	response := helper.BuildResponse(true, "Akun berhasil dihubungkan", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// redirectToApp mengarahkan browser kembali ke aplikasi bila CONNECTOR_APP_REDIRECT_URL diisi.
+func (c *connectorController) redirectToApp(ctx *gin.Context, provider, status, message string) bool {
+	if c.cfg.AppRedirectURL == "" {
+		return false
+	}
+	target, err := url.Parse(c.cfg.AppRedirectURL)
+	if err != nil {
+		return false
+	}
+	q := target.Query()
+	q.Set("provider", provider)
+	q.Set("status", status)
+	if message != "" {
+		q.Set("message", message)
+	}
+	target.RawQuery = q.Encode()
+	ctx.Redirect(http.StatusFound, target.String())
+	return true
+}
+
+// Unlink - DELETE /connectors/:provider
+func (c *connectorController) Unlink(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	if err := c.service.Unlink(userId, ctx.Param("provider")); err != nil {
+		res := helper.BuildErrorResponse("Gagal memutus akun", "UNLINK_FAILED", "provider", err.Error(), nil)
+		ctx.JSON(connectorErrorStatus(err), res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Akun berhasil diputus, aktivitas yang sudah tersinkron tetap disimpan", nil)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Backfill - POST /connectors/:provider/backfill
+func (c *connectorController) Backfill(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	result, err := c.service.Backfill(userId, ctx.Param("provider"))
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menjadwalkan backfill", "BACKFILL_FAILED", "provider", err.Error(), nil)
+		ctx.JSON(connectorErrorStatus(err), res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Backfill dijadwalkan", result)
+	ctx.JSON(http.StatusAccepted, response)
+}
+
+// VerifyWebhook - GET /connectors/:provider/webhook (handshake pendaftaran webhook).
+// Provider mengharapkan body mentah, bukan format response standar.
+func (c *connectorController) VerifyWebhook(ctx *gin.Context) {
+	result, err := c.service.VerifyWebhook(ctx.Param("provider"), ctx.Request.URL.Query())
+	if err != nil {
+		res := helper.BuildErrorResponse("Verifikasi webhook gagal", "WEBHOOK_INVALID", "query", err.Error(), nil)
+		ctx.JSON(connectorErrorStatus(err), res)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Webhook - POST /connectors/:provider/webhook. Event hanya diantrekan agar provider
+// segera menerima 200; aktivitas diambil oleh job connector.
+func (c *connectorController) Webhook(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		res := helper.BuildErrorResponse("Payload webhook tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	if err := c.service.HandleWebhook(ctx.Param("provider"), ctx.Request.Header, body); err != nil {
+		res := helper.BuildErrorResponse("Webhook ditolak", "WEBHOOK_INVALID", "body", err.Error(), nil)
+		ctx.JSON(connectorErrorStatus(err), res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Event diterima", nil)
+	ctx.JSON(http.StatusOK, response)
+}
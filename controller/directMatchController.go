@@ -1,9 +1,12 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"run-sync/data/request"
+	responseDto "run-sync/data/response"
 	"run-sync/helper"
 	"run-sync/service"
 
@@ -13,9 +16,16 @@ import (
 
 type DirectMatchController interface {
 	GetCandidates(ctx *gin.Context)
+	PassCandidate(ctx *gin.Context)
+	UndoPass(ctx *gin.Context)
+	GetMatchWeights(ctx *gin.Context)
+	UpdateMatchWeights(ctx *gin.Context)
+	ResetMatchWeights(ctx *gin.Context)
 	SendMatchRequest(ctx *gin.Context)
 	AcceptMatch(ctx *gin.Context)
 	RejectMatch(ctx *gin.Context)
+	WithdrawMatch(ctx *gin.Context)
+	Unmatch(ctx *gin.Context)
 	FindById(ctx *gin.Context)
 	FindUserMatches(ctx *gin.Context)
 	FindMatchesByStatus(ctx *gin.Context)
@@ -32,14 +42,117 @@ func NewDirectMatchController(s service.DirectMatchService) DirectMatchControlle
 
 func (c *directMatchController) GetCandidates(ctx *gin.Context) {
 	userId := ctx.MustGet("user_id").(uuid.UUID)
-	candidates, err := c.service.GetCandidates(userId)
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	page, limit = helper.NormalizePage(page, limit)
+
+	candidates, total, err := c.service.GetCandidates(userId, page, limit)
+	if errors.Is(err, service.ErrCandidateLimitReached) {
+		res := helper.BuildErrorResponse("Batas kandidat harian tercapai", "CANDIDATE_LIMIT_REACHED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusTooManyRequests, res)
+		return
+	}
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal mengambil kandidat match", "CANDIDATES_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	response := helper.BuildResponse(true, "Berhasil mengambil kandidat match", candidates)
+	response := helper.BuildResponsePagination(true, "Berhasil mengambil kandidat match", candidates, responseDto.PaginatedResponse{
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	})
+	ctx.JSON(http.StatusOK, response)
+}
+
+// PassCandidate - POST /match/candidates/:userId/pass
+func (c *directMatchController) PassCandidate(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	candidateId, err := uuid.Parse(ctx.Param("userId"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID user tidak valid", "INVALID_ID", "userId", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.PassCandidate(userId, candidateId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal melewati kandidat", "PASS_FAILED", "userId", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Kandidat dilewati", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UndoPass - DELETE /match/candidates/:userId/pass
+func (c *directMatchController) UndoPass(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	candidateId, err := uuid.Parse(ctx.Param("userId"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID user tidak valid", "INVALID_ID", "userId", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.service.UndoPass(userId, candidateId); err != nil {
+		res := helper.BuildErrorResponse("Gagal membatalkan pass", "UNDO_PASS_FAILED", "userId", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Pass dibatalkan", nil)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// GetMatchWeights - GET /match/weights
+func (c *directMatchController) GetMatchWeights(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	result, err := c.service.GetMatchWeights(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengambil bobot match", "MATCH_WEIGHTS_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Berhasil mengambil bobot match", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateMatchWeights - PUT /match/weights
+func (c *directMatchController) UpdateMatchWeights(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.UpdateMatchWeightsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.UpdateMatchWeights(userId, req)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menyimpan bobot match", "MATCH_WEIGHTS_FAILED", "weights", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Bobot match berhasil disimpan", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ResetMatchWeights - DELETE /match/weights
+func (c *directMatchController) ResetMatchWeights(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	result, err := c.service.ResetMatchWeights(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mereset bobot match", "MATCH_WEIGHTS_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Bobot match dikembalikan ke default", result)
 	ctx.JSON(http.StatusOK, response)
 }
 
@@ -93,6 +206,38 @@ func (c *directMatchController) RejectMatch(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// WithdrawMatch - PATCH /match/:id/withdraw
+func (c *directMatchController) WithdrawMatch(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	matchId, _ := uuid.Parse(ctx.Param("id"))
+
+	result, err := c.service.WithdrawMatch(matchId, userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menarik match request", "WITHDRAW_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Match request berhasil ditarik", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Unmatch - PATCH /match/:id/unmatch
+func (c *directMatchController) Unmatch(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	matchId, _ := uuid.Parse(ctx.Param("id"))
+
+	result, err := c.service.Unmatch(matchId, userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengakhiri match", "UNMATCH_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Match berhasil diakhiri", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
 func (c *directMatchController) FindById(ctx *gin.Context) {
 	matchId, _ := uuid.Parse(ctx.Param("id"))
 	match, err := c.service.FindById(matchId)
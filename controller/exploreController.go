@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"run-sync/data/request"
+	responseDto "run-sync/data/response"
 	"run-sync/helper"
 	"run-sync/service"
 
@@ -33,14 +34,20 @@ func (c *exploreController) FindNearbyRunners(ctx *gin.Context) {
 		return
 	}
 
-	results, err := c.service.FindNearbyRunners(userId, req)
+	req.Page, req.Limit = helper.NormalizePage(req.Page, req.Limit)
+
+	results, total, err := c.service.FindNearbyRunners(userId, req)
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal mencari runner", "EXPLORE_FAILED", "query", err.Error(), nil)
 		ctx.JSON(http.StatusInternalServerError, res)
 		return
 	}
 
-	response := helper.BuildResponse(true, "Berhasil mengambil data runner terdekat", results)
+	response := helper.BuildResponsePagination(true, "Berhasil mengambil data runner terdekat", results, responseDto.PaginatedResponse{
+		Page:  req.Page,
+		Limit: req.Limit,
+		Total: total,
+	})
 	ctx.JSON(http.StatusOK, response)
 }
 
@@ -53,13 +60,19 @@ func (c *exploreController) FindNearbyGroups(ctx *gin.Context) {
 		return
 	}
 
-	results, err := c.service.FindNearbyGroups(userId, req)
+	req.Page, req.Limit = helper.NormalizePage(req.Page, req.Limit)
+
+	results, total, err := c.service.FindNearbyGroups(userId, req)
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal mencari grup", "EXPLORE_FAILED", "query", err.Error(), nil)
 		ctx.JSON(http.StatusInternalServerError, res)
 		return
 	}
 
-	response := helper.BuildResponse(true, "Berhasil mengambil data grup terdekat", results)
+	response := helper.BuildResponsePagination(true, "Berhasil mengambil data grup terdekat", results, responseDto.PaginatedResponse{
+		Page:  req.Page,
+		Limit: req.Limit,
+		Total: total,
+	})
 	ctx.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"net/http"
+
+	"run-sync/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSController interface {
+	GetJWKS(ctx *gin.Context)
+}
+
+type jwksController struct {
+	jwtService service.JWTService
+}
+
+func NewJWKSController(jwtService service.JWTService) JWKSController {
+	return &jwksController{jwtService: jwtService}
+}
+
+// GET /.well-known/jwks.json
+// Response tidak dibungkus helper.BuildResponse karena harus mengikuti format
+// standar JWK Set (RFC 7517) yang dibaca langsung oleh library JWT.
+func (c *jwksController) GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.jwtService.JWKS())
+}
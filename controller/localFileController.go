@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"run-sync/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocalFileController interface {
+	Serve(ctx *gin.Context)
+	Upload(ctx *gin.Context)
+}
+
+type localFileController struct {
+	storage        *helper.LocalStorage
+	maxBytes       int64
+	privateFolders []string
+}
+
+// NewLocalFileController: file di privateFolders hanya disajikan lewat signed URL.
+func NewLocalFileController(storage *helper.LocalStorage, maxBytes int64, privateFolders []string) LocalFileController {
+	return &localFileController{storage: storage, maxBytes: maxBytes, privateFolders: privateFolders}
+}
+
+// GET /files/*key
+// URL publik disajikan langsung; signed URL (expires & signature) diperiksa dulu dan
+// wajib untuk folder privat.
+func (c *localFileController) Serve(ctx *gin.Context) {
+	// Key dinormalisasi agar "../" tidak bisa melewati pengecekan folder privat
+	key := strings.TrimPrefix(path.Clean("/"+ctx.Param("key")), "/")
+
+	if signature := ctx.Query("signature"); signature != "" || c.isPrivate(key) {
+		if err := c.storage.VerifySignature(key, ctx.Query("expires"), signature); err != nil {
+			res := helper.BuildErrorResponse("Akses file ditolak", "INVALID_SIGNATURE", "signature", err.Error(), nil)
+			ctx.JSON(http.StatusForbidden, res)
+			return
+		}
+	}
+
+	filePath, err := c.storage.Path(key)
+	if err == nil {
+		var info os.FileInfo
+		if info, err = os.Stat(filePath); err == nil && info.IsDir() {
+			err = fs.ErrNotExist
+		}
+	}
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, helper.ErrStorageObjectNotFound) {
+		res := helper.BuildErrorResponse("File tidak ditemukan", "NOT_FOUND", "key", key, nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal membaca file", "READ_FAILED", "key", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	ctx.File(filePath)
+}
+
+func (c *localFileController) isPrivate(key string) bool {
+	for _, folder := range c.privateFolders {
+		if strings.HasPrefix(key, folder+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// PUT /files/*key
+// Tujuan upload langsung dari LocalStorage.PresignUpload; signature wajib ada.
+func (c *localFileController) Upload(ctx *gin.Context) {
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+
+	if err := c.storage.VerifyUploadSignature(key, ctx.Query("expires"), ctx.Query("signature")); err != nil {
+		res := helper.BuildErrorResponse("Upload ditolak", "INVALID_SIGNATURE", "signature", err.Error(), nil)
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
+
+	err := c.storage.Write(key, ctx.Request.Body, c.maxBytes)
+	var tooLarge *http.MaxBytesError
+	if errors.Is(err, helper.ErrImageTooLarge) || errors.As(err, &tooLarge) {
+		res := helper.BuildErrorResponse("Ukuran file terlalu besar", "PAYLOAD_TOO_LARGE", "body", err.Error(), nil)
+		ctx.JSON(http.StatusRequestEntityTooLarge, res)
+		return
+	}
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menyimpan file", "WRITE_FAILED", "key", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -1,9 +1,15 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
+	"run-sync/config"
 	"run-sync/data/request"
+	responseDto "run-sync/data/response"
 	"run-sync/helper"
 	"run-sync/service"
 
@@ -13,20 +19,23 @@ import (
 
 type RunActivityController interface {
 	Create(ctx *gin.Context)
+	Import(ctx *gin.Context)
 	Update(ctx *gin.Context)
 	FindById(ctx *gin.Context)
 	FindByUserId(ctx *gin.Context)
 	FindAll(ctx *gin.Context)
 	Delete(ctx *gin.Context)
 	GetUserStats(ctx *gin.Context)
+	GetAnalytics(ctx *gin.Context)
 }
 
 type runActivityController struct {
 	service service.RunActivityService
+	cfg     *config.ActivityConfig
 }
 
-func NewRunActivityController(s service.RunActivityService) RunActivityController {
-	return &runActivityController{service: s}
+func NewRunActivityController(s service.RunActivityService, cfg *config.ActivityConfig) RunActivityController {
+	return &runActivityController{service: s, cfg: cfg}
 }
 
 func (c *runActivityController) Create(ctx *gin.Context) {
@@ -40,8 +49,12 @@ func (c *runActivityController) Create(ctx *gin.Context) {
 
 	result, err := c.service.Create(userId, req)
 	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrImplausibleActivity) {
+			status = http.StatusUnprocessableEntity
+		}
 		res := helper.BuildErrorResponse("Gagal membuat aktivitas lari", "CREATE_FAILED", "body", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(status, res)
 		return
 	}
 
@@ -49,7 +62,64 @@ func (c *runActivityController) Create(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, response)
 }
 
+// Import - POST /runs/activities/import (multipart/form-data, file di field "file")
+func (c *runActivityController) Import(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			res := helper.BuildErrorResponse("Ukuran file terlalu besar", "PAYLOAD_TOO_LARGE", "file", err.Error(), nil)
+			ctx.JSON(http.StatusRequestEntityTooLarge, res)
+			return
+		}
+		res := helper.BuildErrorResponse("File aktivitas wajib diunggah", "INVALID_REQUEST", "file", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	if file.Size > c.cfg.ImportMaxBytes {
+		res := helper.BuildErrorResponse("Ukuran file terlalu besar", "PAYLOAD_TOO_LARGE", "file", fmt.Sprintf("maksimal %d KB", c.cfg.ImportMaxBytes/1024), nil)
+		ctx.JSON(http.StatusRequestEntityTooLarge, res)
+		return
+	}
+	f, err := file.Open()
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal membaca file", "INVALID_REQUEST", "file", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal membaca file", "INVALID_REQUEST", "file", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.Import(userId, file.Filename, ctx.PostForm("activity_type"), data)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, service.ErrDuplicateActivityFile):
+			status = http.StatusConflict
+		case errors.Is(err, helper.ErrActivityFileUnsupported):
+			status = http.StatusUnsupportedMediaType
+		case errors.Is(err, helper.ErrActivityFileInvalid), errors.Is(err, helper.ErrActivityNoTrack),
+			errors.Is(err, service.ErrImplausibleActivity):
+			status = http.StatusUnprocessableEntity
+		}
+		res := helper.BuildErrorResponse("Gagal mengimpor aktivitas lari", "IMPORT_FAILED", "file", err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Aktivitas lari berhasil diimpor", result)
+	ctx.JSON(http.StatusCreated, response)
+}
+
 func (c *runActivityController) Update(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
 	activityId, _ := uuid.Parse(ctx.Param("id"))
 	var req request.UpdateRunActivityRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -58,10 +128,17 @@ func (c *runActivityController) Update(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.service.Update(activityId, req)
+	result, err := c.service.Update(userId, activityId, req)
 	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, service.ErrActivityNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrImplausibleActivity):
+			status = http.StatusUnprocessableEntity
+		}
 		res := helper.BuildErrorResponse("Gagal mengubah aktivitas lari", "UPDATE_FAILED", "body", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(status, res)
 		return
 	}
 
@@ -70,8 +147,9 @@ func (c *runActivityController) Update(ctx *gin.Context) {
 }
 
 func (c *runActivityController) FindById(ctx *gin.Context) {
+	viewerId := ctx.MustGet("user_id").(uuid.UUID)
 	activityId, _ := uuid.Parse(ctx.Param("id"))
-	activity, err := c.service.FindById(activityId)
+	activity, err := c.service.FindById(viewerId, activityId)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -82,10 +160,11 @@ func (c *runActivityController) FindById(ctx *gin.Context) {
 }
 
 func (c *runActivityController) FindByUserId(ctx *gin.Context) {
+	viewerId := ctx.MustGet("user_id").(uuid.UUID)
 	userId, _ := uuid.Parse(ctx.Param("userId"))
-	activities, err := c.service.FindByUserId(userId)
+	activities, err := c.service.FindByUserId(viewerId, userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(activityAccessStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -93,23 +172,50 @@ func (c *runActivityController) FindByUserId(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// activityAccessStatus memetakan error baca aktivitas user lain ke status HTTP.
+func activityAccessStatus(err error) int {
+	if errors.Is(err, service.ErrActivityAccessDenied) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// FindAll - GET /runs/activities?page=1&limit=20, feed aktivitas sendiri dan user yang terhubung
 func (c *runActivityController) FindAll(ctx *gin.Context) {
-	activities, err := c.service.FindAll()
+	viewerId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.Pagination
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		res := helper.BuildErrorResponse("Parameter tidak valid", "INVALID_PARAMS", "query", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	req.Page, req.Limit = helper.NormalizePage(req.Page, req.Limit)
+
+	activities, total, err := c.service.FindAll(viewerId, req.Page, req.Limit)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	response := helper.BuildResponse(true, "Berhasil mengambil semua data aktivitas lari", activities)
+	response := helper.BuildResponsePagination(true, "Berhasil mengambil data aktivitas lari", activities, responseDto.PaginatedResponse{
+		Page:  req.Page,
+		Limit: req.Limit,
+		Total: total,
+	})
 	ctx.JSON(http.StatusOK, response)
 }
 
 func (c *runActivityController) Delete(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
 	activityId, _ := uuid.Parse(ctx.Param("id"))
-	err := c.service.Delete(activityId)
+	err := c.service.Delete(userId, activityId)
 	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, service.ErrActivityNotFound) {
+			status = http.StatusNotFound
+		}
 		res := helper.BuildErrorResponse("Gagal menghapus aktivitas lari", "DELETE_FAILED", "body", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(status, res)
 		return
 	}
 
@@ -118,13 +224,56 @@ func (c *runActivityController) Delete(ctx *gin.Context) {
 }
 
 func (c *runActivityController) GetUserStats(ctx *gin.Context) {
+	viewerId := ctx.MustGet("user_id").(uuid.UUID)
 	userId, _ := uuid.Parse(ctx.Param("userId"))
-	stats, err := c.service.GetUserStats(userId)
+	stats, err := c.service.GetUserStats(viewerId, userId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(activityAccessStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	response := helper.BuildResponse(true, "Berhasil mengambil statistik pengguna", stats)
 	ctx.JSON(http.StatusOK, response)
 }
+
+// GetAnalytics - GET /runs/users/:userId/analytics?weeks=12&months=6
+func (c *runActivityController) GetAnalytics(ctx *gin.Context) {
+	viewerId := ctx.MustGet("user_id").(uuid.UUID)
+	userId, err := uuid.Parse(ctx.Param("userId"))
+	if err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "userId", "user id tidak valid", nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	weeks, err := strconv.Atoi(ctx.DefaultQuery("weeks", strconv.Itoa(service.DefaultAnalyticsWeeks)))
+	if err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "weeks", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+	months, err := strconv.Atoi(ctx.DefaultQuery("months", strconv.Itoa(service.DefaultAnalyticsMonths)))
+	if err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "months", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.GetAnalytics(viewerId, userId, weeks, months)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidAnalyticsRange):
+			status = http.StatusBadRequest
+		case errors.Is(err, service.ErrAnalyticsUserNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrActivityAccessDenied):
+			status = http.StatusForbidden
+		}
+		res := helper.BuildErrorResponse("Gagal mengambil analytics latihan", "ANALYTICS_FAILED", "userId", err.Error(), nil)
+		ctx.JSON(status, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Berhasil mengambil analytics latihan", result)
+	ctx.JSON(http.StatusOK, response)
+}
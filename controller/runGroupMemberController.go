@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 
 	"run-sync/data/request"
@@ -130,6 +131,11 @@ func (c *runGroupMemberController) JoinGroup(ctx *gin.Context) {
 	}
 
 	result, err := c.service.JoinGroup(userId, groupId)
+	if errors.Is(err, service.ErrWomenOnly) || errors.Is(err, service.ErrFaceReviewRequired) {
+		res := helper.BuildErrorResponse("Grup ini khusus perempuan", "WOMEN_ONLY", "body", err.Error(), nil)
+		ctx.JSON(http.StatusForbidden, res)
+		return
+	}
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal bergabung dengan grup", "JOIN_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
@@ -18,6 +18,8 @@ type RunnerProfileController interface {
 	FindByUserId(ctx *gin.Context)
 	FindAll(ctx *gin.Context)
 	Delete(ctx *gin.Context)
+	GetDiscoveryPreference(ctx *gin.Context)
+	UpdateDiscoveryPreference(ctx *gin.Context)
 }
 
 type runnerProfileController struct {
@@ -115,3 +117,38 @@ func (c *runnerProfileController) Delete(ctx *gin.Context) {
 	response := helper.BuildResponse(true, "Profil runner berhasil dihapus", nil)
 	ctx.JSON(http.StatusOK, response)
 }
+
+// GetDiscoveryPreference - GET /profiles/me/discovery
+func (c *runnerProfileController) GetDiscoveryPreference(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	result, err := c.service.GetDiscoveryPreference(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengambil preferensi discovery", "DISCOVERY_PREFERENCE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Berhasil mengambil preferensi discovery", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateDiscoveryPreference - PUT /profiles/me/discovery
+func (c *runnerProfileController) UpdateDiscoveryPreference(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.UpdateDiscoveryPreferenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.UpdateDiscoveryPreference(userId, req)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal menyimpan preferensi discovery", "DISCOVERY_PREFERENCE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Preferensi discovery berhasil disimpan", result)
+	ctx.JSON(http.StatusOK, response)
+}
@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"fmt"
+	"log"
 	"net/http"
+	"time"
 
 	"run-sync/data/request"
+	"run-sync/entity"
 	"run-sync/helper"
 	"run-sync/service"
 
@@ -18,18 +22,35 @@ type UserController interface {
 	FindByEmail(ctx *gin.Context)
 	FindByPhone(ctx *gin.Context)
 	FindAll(ctx *gin.Context)
-	Delete(ctx *gin.Context)
 	ChangePassword(ctx *gin.Context)
+	RequestEmailChange(ctx *gin.Context)
+	ConfirmEmailChange(ctx *gin.Context)
+	UpdateOTPChannel(ctx *gin.Context)
 }
 
 type userController struct {
-	service service.UserService
+	service     service.UserService
+	otpHelper   *helper.RedisHelper
+	emailHelper *helper.EmailHelper
+	notifSvc    service.NotificationService
 }
 
-func NewUserController(s service.UserService) UserController {
-	return &userController{service: s}
+func NewUserController(
+	s service.UserService,
+	otpHelper *helper.RedisHelper,
+	emailHelper *helper.EmailHelper,
+	notifSvc service.NotificationService,
+) UserController {
+	return &userController{
+		service:     s,
+		otpHelper:   otpHelper,
+		emailHelper: emailHelper,
+		notifSvc:    notifSvc,
+	}
 }
 
+const emailChangeOTPTTL = 15 * time.Minute
+
 func (c *userController) Create(ctx *gin.Context) {
 	var req request.CreateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -116,35 +137,147 @@ func (c *userController) FindAll(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (c *userController) Delete(ctx *gin.Context) {
+func (c *userController) ChangePassword(ctx *gin.Context) {
 	userId, _ := uuid.Parse(ctx.Param("id"))
-	err := c.service.Delete(userId)
+	var req request.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	err := c.service.ChangePassword(userId, req)
 	if err != nil {
-		res := helper.BuildErrorResponse("Gagal menghapus user", "DELETE_FAILED", "body", err.Error(), nil)
+		res := helper.BuildErrorResponse("Gagal mengubah password", "CHANGE_PASSWORD_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	response := helper.BuildResponse(true, "User berhasil dihapus", nil)
+	response := helper.BuildResponse(true, "Password berhasil diubah", nil)
 	ctx.JSON(http.StatusOK, response)
 }
 
-func (c *userController) ChangePassword(ctx *gin.Context) {
-	userId, _ := uuid.Parse(ctx.Param("id"))
-	var req request.ChangePasswordRequest
+// RequestEmailChange - Save new email as pending and send verification code to it
+func (c *userController) RequestEmailChange(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	var req request.ChangeEmailRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	err := c.service.ChangePassword(userId, req)
+	if err := c.otpHelper.AllowRequest("email_change:"+userId.String(), 3, emailChangeOTPTTL); err != nil {
+		res := helper.BuildErrorResponse("Terlalu banyak permintaan", "RATE_LIMIT", "body", err.Error(), nil)
+		ctx.JSON(http.StatusTooManyRequests, res)
+		return
+	}
+
+	pendingEmail, err := c.service.RequestEmailChange(userId, req.NewEmail)
 	if err != nil {
-		res := helper.BuildErrorResponse("Gagal mengubah password", "CHANGE_PASSWORD_FAILED", "body", err.Error(), nil)
+		res := helper.BuildErrorResponse("Gagal mengubah email", "EMAIL_CHANGE_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
-	response := helper.BuildResponse(true, "Password berhasil diubah", nil)
+	// Kode baru menggantikan kode sebelumnya, sehingga hanya email pending terakhir yang bisa dikonfirmasi
+	otp, err := c.otpHelper.IssueOTP("email_change", userId.String(), emailChangeOTPTTL)
+	if err != nil {
+		respondOTPError(ctx, err, 0)
+		return
+	}
+
+	lang := helper.ResolveEmailLanguage(ctx.GetHeader("Accept-Language"))
+	subject, plain, html := helper.BuildEmailChangeEmail(lang, otp)
+	if err := c.emailHelper.Send(pendingEmail, subject, plain, html); err != nil {
+		res := helper.BuildErrorResponse("Gagal mengirim email verifikasi", "EMAIL_SEND_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadGateway, res)
+		return
+	}
+
+	if err := c.notifSvc.Send(
+		userId,
+		entity.NotifEmailChangeRequest,
+		"Permintaan ganti email",
+		fmt.Sprintf("Kode verifikasi telah dikirim ke %s. Jika ini bukan Anda, segera ganti password Anda.", helper.MaskEmail(pendingEmail)),
+		nil, nil, nil,
+	); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi email_change_request ke %s: %v", userId, err)
+	}
+
+	response := helper.BuildResponse(true, "Kode verifikasi telah dikirim ke email baru", map[string]interface{}{
+		"pending_email": pendingEmail,
+		"expires_in":    int(emailChangeOTPTTL.Seconds()),
+	})
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ConfirmEmailChange - Verify code, move pending email to email and notify the old address
+func (c *userController) ConfirmEmailChange(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	var req request.ConfirmEmailChangeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if remaining, err := c.otpHelper.CheckOTP("email_change", userId.String(), req.OTPCode); err != nil {
+		respondOTPError(ctx, err, remaining)
+		return
+	}
+
+	oldEmail, user, err := c.service.ConfirmEmailChange(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengubah email", "EMAIL_CHANGE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	// Pemberitahuan ke email lama agar pemilik akun sadar jika perubahan ini bukan dari dirinya
+	if oldEmail != nil {
+		lang := helper.ResolveEmailLanguage(ctx.GetHeader("Accept-Language"))
+		subject, plain, html := helper.BuildEmailChangedNoticeEmail(lang, *user.Email)
+		if err := c.emailHelper.Send(*oldEmail, subject, plain, html); err != nil {
+			log.Printf("⚠️ Gagal kirim pemberitahuan ganti email ke %s: %v", *oldEmail, err)
+		}
+	}
+
+	if err := c.notifSvc.Send(
+		userId,
+		entity.NotifAccountVerified,
+		"Email berhasil diverifikasi",
+		fmt.Sprintf("Email akun Anda sekarang %s.", *user.Email),
+		nil, nil, nil,
+	); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi account_verified ke %s: %v", userId, err)
+	}
+
+	response := helper.BuildResponse(true, "Email berhasil diubah", user)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// UpdateOTPChannel - Set preferred OTP delivery channel (null = default order)
+func (c *userController) UpdateOTPChannel(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	var req request.UpdateOTPChannelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	if err := c.service.UpdateOTPChannel(userId, req.Channel); err != nil {
+		res := helper.BuildErrorResponse("Gagal mengubah channel OTP", "UPDATE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Channel OTP berhasil diubah", map[string]interface{}{
+		"otp_channel": req.Channel,
+	})
 	ctx.JSON(http.StatusOK, response)
 }
@@ -1,9 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"run-sync/data/request"
+	responseDto "run-sync/data/response"
 	"run-sync/helper"
 	"run-sync/service"
 
@@ -13,13 +17,20 @@ import (
 
 type UserPhotoController interface {
 	Create(ctx *gin.Context)
+	RequestUpload(ctx *gin.Context)
+	CompleteUpload(ctx *gin.Context)
 	Update(ctx *gin.Context)
 	FindById(ctx *gin.Context)
 	FindByUserId(ctx *gin.Context)
 	FindMyPhotos(ctx *gin.Context)
 	FindPrimaryPhoto(ctx *gin.Context)
 	Delete(ctx *gin.Context)
+	Reorder(ctx *gin.Context)
+	StartFaceChallenge(ctx *gin.Context)
 	VerifyFace(ctx *gin.Context)
+	GetFaceVerificationStatus(ctx *gin.Context)
+	FaceReviewQueue(ctx *gin.Context)
+	ReviewFaceVerification(ctx *gin.Context)
 }
 
 type userPhotoController struct {
@@ -30,27 +41,123 @@ func NewUserPhotoController(s service.UserPhotoService) UserPhotoController {
 	return &userPhotoController{service: s}
 }
 
+// Create - POST /media/photos
+// Menerima multipart/form-data (field type, is_primary, file) atau JSON base64 (client lama).
 func (c *userPhotoController) Create(ctx *gin.Context) {
 	userId := ctx.MustGet("user_id").(uuid.UUID)
-	var req request.UploadUserPhotoRequest
+
+	var (
+		result responseDto.UserPhotoResponse
+		err    error
+	)
+	if strings.HasPrefix(ctx.ContentType(), "multipart/form-data") {
+		var req request.UploadUserPhotoFormRequest
+		if err := ctx.ShouldBind(&req); err != nil {
+			c.invalidUpload(ctx, err)
+			return
+		}
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			c.invalidUpload(ctx, err)
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.invalidUpload(ctx, err)
+			return
+		}
+		defer f.Close()
+		result, err = c.service.CreateFromFile(userId, req, f)
+	} else {
+		var req request.UploadUserPhotoRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			c.invalidUpload(ctx, err)
+			return
+		}
+		result, err = c.service.Create(userId, req)
+	}
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengunggah foto", "CREATE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(photoUploadStatus(err), res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Foto berhasil diunggah", result)
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// RequestUpload - POST /media/photos/uploads
+// Mengembalikan URL upload langsung ke storage; setelah upload, panggil endpoint complete.
+func (c *userPhotoController) RequestUpload(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.RequestPhotoUploadRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		c.invalidUpload(ctx, err)
 		return
 	}
 
-	result, err := c.service.Create(userId, req)
+	result, err := c.service.RequestUpload(userId, req)
 	if err != nil {
-		res := helper.BuildErrorResponse("Gagal mengunggah foto", "CREATE_FAILED", "body", err.Error(), nil)
+		res := helper.BuildErrorResponse("Gagal membuat URL upload", "UPLOAD_REQUEST_FAILED", "body", err.Error(), nil)
+		ctx.JSON(photoUploadStatus(err), res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "URL upload dibuat", result)
+	ctx.JSON(http.StatusCreated, response)
+}
+
+// CompleteUpload - POST /media/photos/uploads/:id/complete
+func (c *userPhotoController) CompleteUpload(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	uploadId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID tidak valid", "INVALID_ID", "id", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
 		return
 	}
 
+	result, err := c.service.CompleteUpload(userId, uploadId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal memproses upload", "UPLOAD_COMPLETE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(photoUploadStatus(err), res)
+		return
+	}
+
 	response := helper.BuildResponse(true, "Foto berhasil diunggah", result)
 	ctx.JSON(http.StatusCreated, response)
 }
 
+func (c *userPhotoController) invalidUpload(ctx *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		res := helper.BuildErrorResponse("Ukuran request terlalu besar", "PAYLOAD_TOO_LARGE", "body", err.Error(), nil)
+		ctx.JSON(http.StatusRequestEntityTooLarge, res)
+		return
+	}
+	res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+	ctx.JSON(http.StatusBadRequest, res)
+}
+
+// photoUploadStatus memetakan error validasi gambar ke status HTTP.
+func photoUploadStatus(err error) int {
+	switch {
+	case errors.Is(err, helper.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, helper.ErrImageTypeNotAllowed):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, helper.ErrImageDimensions):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrPhotoLimitReached):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrPhotoUploadNotFound):
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
 func (c *userPhotoController) Update(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
 	photoId, _ := uuid.Parse(ctx.Param("id"))
 	var req request.UpdateUserPhotoRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -59,10 +166,10 @@ func (c *userPhotoController) Update(ctx *gin.Context) {
 		return
 	}
 
-	result, err := c.service.Update(photoId, req)
+	result, err := c.service.Update(userId, photoId, req)
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal mengubah foto", "UPDATE_FAILED", "body", err.Error(), nil)
-		ctx.JSON(http.StatusBadRequest, res)
+		ctx.JSON(photoUploadStatus(err), res)
 		return
 	}
 
@@ -96,7 +203,7 @@ func (c *userPhotoController) FindByUserId(ctx *gin.Context) {
 
 func (c *userPhotoController) FindMyPhotos(ctx *gin.Context) {
 	userId := ctx.MustGet("user_id").(uuid.UUID)
-	photos, err := c.service.FindByUserId(userId)
+	photos, err := c.service.FindMyPhotos(userId)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -119,8 +226,9 @@ func (c *userPhotoController) FindPrimaryPhoto(ctx *gin.Context) {
 }
 
 func (c *userPhotoController) Delete(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
 	photoId, _ := uuid.Parse(ctx.Param("id"))
-	err := c.service.Delete(photoId)
+	err := c.service.Delete(userId, photoId)
 	if err != nil {
 		res := helper.BuildErrorResponse("Gagal menghapus foto", "DELETE_FAILED", "body", err.Error(), nil)
 		ctx.JSON(http.StatusBadRequest, res)
@@ -131,6 +239,42 @@ func (c *userPhotoController) Delete(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// Reorder - PUT /media/me/photos/order
+func (c *userPhotoController) Reorder(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+	var req request.ReorderUserPhotosRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	photos, err := c.service.Reorder(userId, req)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengurutkan foto", "REORDER_FAILED", "photo_ids", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Urutan foto disimpan", photos)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// StartFaceChallenge - POST /media/photos/verify-face/challenge
+func (c *userPhotoController) StartFaceChallenge(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	result, err := c.service.StartFaceChallenge(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal membuat challenge verifikasi", "CHALLENGE_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Challenge verifikasi dibuat", result)
+	ctx.JSON(http.StatusCreated, response)
+}
+
 func (c *userPhotoController) VerifyFace(ctx *gin.Context) {
 	userId := ctx.MustGet("user_id").(uuid.UUID)
 	var req request.FaceVerifyRequest
@@ -150,3 +294,67 @@ func (c *userPhotoController) VerifyFace(ctx *gin.Context) {
 	response := helper.BuildResponse(true, "Verifikasi wajah selesai", result)
 	ctx.JSON(http.StatusOK, response)
 }
+
+// GetFaceVerificationStatus - GET /media/me/face-verification
+func (c *userPhotoController) GetFaceVerificationStatus(ctx *gin.Context) {
+	userId := ctx.MustGet("user_id").(uuid.UUID)
+
+	result, err := c.service.GetFaceVerificationStatus(userId)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengambil status verifikasi", "NOT_FOUND", "id", err.Error(), nil)
+		ctx.JSON(http.StatusNotFound, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Berhasil mengambil status verifikasi wajah", result)
+	ctx.JSON(http.StatusOK, response)
+}
+
+// FaceReviewQueue - GET /admin/face-reviews
+func (c *userPhotoController) FaceReviewQueue(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	page, limit = helper.NormalizePage(page, limit)
+
+	items, total, err := c.service.FaceReviewQueue(page, limit)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mengambil antrean review", "FETCH_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusInternalServerError, res)
+		return
+	}
+
+	response := helper.BuildResponsePagination(true, "Berhasil mengambil antrean review wajah", items, responseDto.PaginatedResponse{
+		Page:  page,
+		Limit: limit,
+		Total: total,
+	})
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ReviewFaceVerification - PATCH /admin/face-reviews/:id
+func (c *userPhotoController) ReviewFaceVerification(ctx *gin.Context) {
+	reviewerId := ctx.MustGet("user_id").(uuid.UUID)
+	attemptId, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		res := helper.BuildErrorResponse("ID tidak valid", "INVALID_ID", "id", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	var req request.ReviewFaceVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		res := helper.BuildErrorResponse("Permintaan tidak valid", "INVALID_REQUEST", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	result, err := c.service.ReviewFaceVerification(reviewerId, attemptId, req)
+	if err != nil {
+		res := helper.BuildErrorResponse("Gagal mereview verifikasi wajah", "REVIEW_FAILED", "body", err.Error(), nil)
+		ctx.JSON(http.StatusBadRequest, res)
+		return
+	}
+
+	response := helper.BuildResponse(true, "Review verifikasi wajah disimpan", result)
+	ctx.JSON(http.StatusOK, response)
+}
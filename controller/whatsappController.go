@@ -18,11 +18,12 @@ type WhatsAppController interface {
 
 type whatsappController struct {
 	emailHelper *helper.EmailHelper
+	otpHelper   *helper.RedisHelper
 	redisClient *redis.Client
 }
 
-func NewWhatsAppController(emailHelper *helper.EmailHelper, redisClient *redis.Client) WhatsAppController {
-	return &whatsappController{emailHelper: emailHelper, redisClient: redisClient}
+func NewWhatsAppController(emailHelper *helper.EmailHelper, otpHelper *helper.RedisHelper) WhatsAppController {
+	return &whatsappController{emailHelper: emailHelper, otpHelper: otpHelper, redisClient: otpHelper.Client}
 }
 
 type waRegisterRequest struct {
@@ -42,19 +43,28 @@ func (w *whatsappController) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate short OTP and save mapping phone->email with OTP hash in Redis
-	otp := helper.GenerateOTPCode(6)
-	data := map[string]string{"email": req.Email, "otp_hash": helper.HashOTP(otp)}
-	key := "whatsapp:register:" + req.Phone
+	if err := w.otpHelper.AllowRequest("whatsapp_link:"+req.Phone, 5, 15*time.Minute); err != nil {
+		c.JSON(http.StatusTooManyRequests, helper.BuildErrorResponse("Terlalu banyak permintaan", "RATE_LIMIT", "body", err.Error(), nil))
+		return
+	}
 
-	// store for 24 hours
+	// Generate short OTP (bound to purpose + phone), valid for 24 hours
+	otp, err := w.otpHelper.IssueOTP("whatsapp_link", req.Phone, 24*time.Hour)
+	if err != nil {
+		respondOTPError(c, err, 0)
+		return
+	}
+
+	// Save mapping phone->email in Redis for 24 hours
+	data := map[string]string{"email": req.Email}
+	key := "whatsapp:register:" + req.Phone
 	if err := helper.SetJSONToRedis(context.Background(), w.redisClient, key, data, 24*time.Hour); err != nil {
 		c.JSON(http.StatusInternalServerError, helper.BuildErrorResponse("Gagal menyimpan data pendaftaran WhatsApp", "SAVE_FAILED", "body", err.Error(), nil))
 		return
 	}
 
 	// Send OTP message via WhatsApp if client available
-	msg := "Kode verifikasi WhatsApp Anda: " + otp
+	msg := helper.BuildOTPText(helper.OTPMessage{Purpose: "whatsapp_link", Code: otp, TTL: 24 * time.Hour, Lang: helper.ResolveEmailLanguage(c.GetHeader("Accept-Language"))})
 	if err := helper.SendOTPViaWhatsApp(req.Phone, msg); err != nil {
 		// still return success but warn user that message could not be sent
 		c.JSON(http.StatusOK, helper.BuildResponse(false, "Pendaftaran tersimpan, namun pengiriman WhatsApp gagal", gin.H{"warning": err.Error()}))
@@ -83,9 +93,9 @@ func (w *whatsappController) Verify(c *gin.Context) {
 		return
 	}
 
-	otpHash, ok := stored["otp_hash"]
-	if !ok || otpHash != helper.HashOTP(req.Code) {
-		c.JSON(http.StatusBadRequest, helper.BuildErrorResponse("Kode verifikasi tidak valid", "INVALID_CODE", "body", "", nil))
+	// Verify OTP (attempt-limited, OTP is deleted on success)
+	if remaining, err := w.otpHelper.CheckOTP("whatsapp_link", req.Phone, req.Code); err != nil {
+		respondOTPError(c, err, remaining)
 		return
 	}
 
@@ -10,6 +10,7 @@ CREATE INDEX IF NOT EXISTS idx_users_active ON users(is_active, is_verified);
 -- Runner profiles indexes
 CREATE INDEX IF NOT EXISTS idx_runner_profiles_user_id ON runner_profiles(user_id);
 CREATE INDEX IF NOT EXISTS idx_runner_profiles_experience ON runner_profiles(running_experience_level);
+CREATE INDEX IF NOT EXISTS idx_runner_profiles_location ON runner_profiles(latitude, longitude) WHERE is_active;
 
 -- Run groups indexes
 CREATE INDEX IF NOT EXISTS idx_run_groups_status ON run_groups(status);
@@ -22,6 +23,7 @@ CREATE INDEX IF NOT EXISTS idx_run_groups_women_only ON run_groups(is_women_only
 CREATE INDEX IF NOT EXISTS idx_run_group_members_group_id ON run_group_members(run_group_id);
 CREATE INDEX IF NOT EXISTS idx_run_group_members_user_id ON run_group_members(user_id);
 CREATE INDEX IF NOT EXISTS idx_run_group_members_status ON run_group_members(status);
+CREATE INDEX IF NOT EXISTS idx_run_group_members_group_joined ON run_group_members(group_id, user_id) WHERE status = 'joined';
 
 -- Run activities indexes
 CREATE INDEX IF NOT EXISTS idx_run_activities_user_id ON run_activities(user_id);
@@ -51,3 +53,4 @@ CREATE INDEX IF NOT EXISTS idx_user_photos_primary ON user_photos(user_id, is_pr
 CREATE INDEX IF NOT EXISTS idx_safety_logs_user_id ON safety_logs(user_id);
 CREATE INDEX IF NOT EXISTS idx_safety_logs_group_id ON safety_logs(run_group_id);
 CREATE INDEX IF NOT EXISTS idx_safety_logs_created ON safety_logs(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_safety_logs_blocked ON safety_logs(user_id, match_id) WHERE status = 'blocked';
@@ -12,8 +12,24 @@ type LoginRequest struct {
 
 type ResendOTPRequest struct {
 	PhoneNumber string `json:"phone_number" binding:"required"`
+	Channel     string `json:"channel" binding:"omitempty,oneof=whatsapp sms"` // OTP register hanya via channel nomor telepon
 }
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+type ForgotPasswordRequest struct {
+	Identifier string `json:"identifier" binding:"required"`
+}
+
+type VerifyResetPasswordRequest struct {
+	Identifier string `json:"identifier" binding:"required"`
+	OTPCode    string `json:"otp_code" binding:"required,len=6"`
+}
+
+type ResetPasswordRequest struct {
+	Identifier  string `json:"identifier" binding:"required"`
+	ResetToken  string `json:"reset_token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
@@ -1,23 +1,67 @@
 package request
 
+// Field credential mengikuti format JSON standar WebAuthn (PublicKeyCredential.toJSON()),
+// sehingga output navigator.credentials.create/get bisa dikirim apa adanya.
+
 type BiometricRegisterStartRequest struct {
 	DeviceName string `json:"device_name" binding:"required"`
 }
 
+type WebAuthnAttestationResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON" binding:"required"`
+	AttestationObject string   `json:"attestationObject" binding:"required"`
+	Transports        []string `json:"transports"`
+}
+
+type WebAuthnCredProps struct {
+	Rk *bool `json:"rk"`
+}
+
+type WebAuthnClientExtensionResults struct {
+	CredProps *WebAuthnCredProps `json:"credProps"`
+}
+
+type WebAuthnRegistrationCredential struct {
+	Id                     string                         `json:"id" binding:"required"`
+	RawId                  string                         `json:"rawId" binding:"required"`
+	Type                   string                         `json:"type" binding:"required,eq=public-key"`
+	Response               WebAuthnAttestationResponse    `json:"response" binding:"required"`
+	ClientExtensionResults WebAuthnClientExtensionResults `json:"clientExtensionResults"`
+}
+
 type BiometricRegisterFinishRequest struct {
-	CredentialId string `json:"credential_id" binding:"required"`
-	PublicKey    string `json:"public_key" binding:"required"`
-	DeviceName   string `json:"device_name" binding:"required"`
-	Challenge    string `json:"challenge" binding:"required"`
-	Signature    string `json:"signature" binding:"required"`
+	DeviceName string                         `json:"device_name"`
+	Credential WebAuthnRegistrationCredential `json:"credential" binding:"required"`
 }
 
+// Identifier kosong = login passkey tanpa username (discoverable credential).
 type BiometricLoginStartRequest struct {
-	Identifier string `json:"identifier" binding:"required"`
+	Identifier string `json:"identifier"`
+}
+
+type WebAuthnAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AuthenticatorData string `json:"authenticatorData" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	UserHandle        string `json:"userHandle"`
+}
+
+type WebAuthnAssertionCredential struct {
+	Id       string                    `json:"id" binding:"required"`
+	RawId    string                    `json:"rawId" binding:"required"`
+	Type     string                    `json:"type" binding:"required,eq=public-key"`
+	Response WebAuthnAssertionResponse `json:"response" binding:"required"`
 }
 
 type BiometricLoginFinishRequest struct {
-	CredentialId string `json:"credential_id" binding:"required"`
-	Challenge    string `json:"challenge" binding:"required"`
-	Signature    string `json:"signature" binding:"required"`
+	Credential WebAuthnAssertionCredential `json:"credential" binding:"required"`
+}
+
+type BiometricRenameRequest struct {
+	DeviceName string `json:"device_name" binding:"required,max=255"`
+}
+
+// BiometricEnableRequest: mengaktifkan ulang credential wajib login ulang dengan password.
+type BiometricEnableRequest struct {
+	Password string `json:"password" binding:"required"`
 }
@@ -7,3 +7,8 @@ type CreateDirectMatchRequest struct {
 type UpdateDirectMatchStatusRequest struct {
 	Status string `json:"status" binding:"required,oneof=pending accepted rejected"`
 }
+
+// UpdateMatchWeightsRequest: bobot per faktor (0..1), faktor yang tidak disebut memakai 0.
+type UpdateMatchWeightsRequest struct {
+	Weights map[string]float64 `json:"weights" binding:"required"`
+}
@@ -1,17 +1,37 @@
 package request
 
 type CreateRunActivityRequest struct {
-	Distance float64 `json:"distance" binding:"required"`
-	Duration int     `json:"duration" binding:"required"`
-	AvgPace  float64 `json:"avg_pace" binding:"required"`
-	Calories int     `json:"calories"`
-	Source   string  `json:"source" binding:"required"`
+	Distance     float64                   `json:"distance" binding:"required"`
+	Duration     int                       `json:"duration" binding:"required"`
+	AvgPace      float64                   `json:"avg_pace" binding:"required"`
+	Calories     int                       `json:"calories"`
+	Source       string                    `json:"source" binding:"required"`
+	ActivityType string                    `json:"activity_type" binding:"omitempty,oneof=easy tempo interval long_run race"`
+	AvgHeartRate int                       `json:"avg_heart_rate" binding:"omitempty,min=30,max=250"`
+	MaxHeartRate int                       `json:"max_heart_rate" binding:"omitempty,min=30,max=250"`
+	AvgCadence   int                       `json:"avg_cadence" binding:"omitempty,min=60,max=260"`
+	Splits       []RunActivitySplitRequest `json:"splits" binding:"omitempty,dive"`
 }
 
 type UpdateRunActivityRequest struct {
-	Distance *float64 `json:"distance"`
-	Duration *int     `json:"duration"`
-	AvgPace  *float64 `json:"avg_pace"`
-	Calories *int     `json:"calories"`
-	Source   *string  `json:"source"`
+	Distance     *float64                   `json:"distance"`
+	Duration     *int                       `json:"duration"`
+	AvgPace      *float64                   `json:"avg_pace"`
+	Calories     *int                       `json:"calories"`
+	Source       *string                    `json:"source"`
+	ActivityType *string                    `json:"activity_type" binding:"omitempty,oneof=easy tempo interval long_run race"`
+	AvgHeartRate *int                       `json:"avg_heart_rate" binding:"omitempty,min=0,max=250"`
+	MaxHeartRate *int                       `json:"max_heart_rate" binding:"omitempty,min=0,max=250"`
+	AvgCadence   *int                       `json:"avg_cadence" binding:"omitempty,min=0,max=260"`
+	Splits       *[]RunActivitySplitRequest `json:"splits" binding:"omitempty,dive"` // menggantikan semua split; [] untuk menghapus
+}
+
+// RunActivitySplitRequest adalah split per km yang diinput manual, berurutan dari km pertama.
+type RunActivitySplitRequest struct {
+	Distance      float64 `json:"distance" binding:"omitempty,gt=0,max=1"` // km, default 1
+	Duration      int     `json:"duration" binding:"required,min=1"`       // detik
+	ElevationGain float64 `json:"elevation_gain" binding:"omitempty,min=0"`
+	ElevationLoss float64 `json:"elevation_loss" binding:"omitempty,min=0"`
+	AvgHeartRate  int     `json:"avg_heart_rate" binding:"omitempty,min=30,max=250"`
+	MaxHeartRate  int     `json:"max_heart_rate" binding:"omitempty,min=30,max=250"`
 }
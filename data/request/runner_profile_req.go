@@ -4,6 +4,7 @@ type CreateRunnerProfileRequest struct {
 	Name              *string `json:"name"`
 	Gender            *string `json:"gender"`
 	AvgPace           float64 `json:"avg_pace" binding:"required"`
+	AvgPaceManual     bool    `json:"avg_pace_manual"` // true: avg_pace tidak ditimpa pace dari aktivitas
 	PreferredDistance int     `json:"preferred_distance" binding:"required"`
 	PreferredTime     string  `json:"preferred_time" binding:"required"`
 	Latitude          float64 `json:"latitude"`
@@ -16,6 +17,7 @@ type UpdateRunnerProfileRequest struct {
 	Name              *string  `json:"name"`
 	Gender            *string  `json:"gender"`
 	AvgPace           *float64 `json:"avg_pace"`
+	AvgPaceManual     *bool    `json:"avg_pace_manual"` // default true bila avg_pace dikirim; false = kembali ke pace otomatis
 	PreferredDistance *int     `json:"preferred_distance"`
 	PreferredTime     *string  `json:"preferred_time"`
 	Latitude          *float64 `json:"latitude"`
@@ -23,3 +25,15 @@ type UpdateRunnerProfileRequest struct {
 	WomenOnlyMode     *bool    `json:"women_only_mode"`
 	Image             *string  `json:"image"`
 }
+
+// UpdateDiscoveryPreferenceRequest: field yang tidak dikirim tidak diubah; 0 berarti tanpa batas.
+type UpdateDiscoveryPreferenceRequest struct {
+	MaxRadiusKm       *float64 `json:"max_radius_km" binding:"omitempty,min=0,max=100"`
+	MinPace           *float64 `json:"min_pace" binding:"omitempty,min=0,max=15"`
+	MaxPace           *float64 `json:"max_pace" binding:"omitempty,min=0,max=15"`
+	MinDistance       *int     `json:"min_distance" binding:"omitempty,min=0,max=100"`
+	MaxDistance       *int     `json:"max_distance" binding:"omitempty,min=0,max=100"`
+	GenderPreference  *string  `json:"gender_preference" binding:"omitempty,oneof=any male female"`
+	HideFromDiscovery *bool    `json:"hide_from_discovery"`
+	Incognito         *bool    `json:"incognito"`
+}
@@ -1,16 +1,46 @@
 package request
 
 type UploadUserPhotoRequest struct {
-	Image     string `json:"image" binding:"required"`
-	Type      string `json:"type" binding:"required,oneof=profile run verification"`
-	IsPrimary bool   `json:"is_primary"`
+	Image     string  `json:"image" binding:"required"`
+	Type      string  `json:"type" binding:"required,oneof=profile run verification"`
+	IsPrimary bool    `json:"is_primary"`                          // hanya untuk type profile
+	Caption   *string `json:"caption" binding:"omitempty,max=200"` // hanya untuk type run
+}
+
+// UploadUserPhotoFormRequest adalah field multipart/form-data POST /media/photos (file di field "file").
+type UploadUserPhotoFormRequest struct {
+	Type      string  `form:"type" binding:"required,oneof=profile run verification"`
+	IsPrimary bool    `form:"is_primary"`
+	Caption   *string `form:"caption" binding:"omitempty,max=200"`
+}
+
+// RequestPhotoUploadRequest meminta URL upload langsung ke storage.
+type RequestPhotoUploadRequest struct {
+	Type        string  `json:"type" binding:"required,oneof=profile run verification"`
+	IsPrimary   bool    `json:"is_primary"`
+	Caption     *string `json:"caption" binding:"omitempty,max=200"`
+	ContentType string  `json:"content_type" binding:"required"` // image/jpeg atau image/png
 }
 
 type UpdateUserPhotoRequest struct {
-	Type      *string `json:"type"`
-	IsPrimary *bool   `json:"is_primary"`
+	Type      *string `json:"type" binding:"omitempty,oneof=profile run"`
+	IsPrimary *bool   `json:"is_primary"`                          // hanya true; foto utama diganti dengan memilih foto lain
+	Caption   *string `json:"caption" binding:"omitempty,max=200"` // string kosong menghapus caption
+}
+
+// ReorderUserPhotosRequest berisi semua id foto satu tipe dalam urutan baru.
+type ReorderUserPhotosRequest struct {
+	Type     string   `json:"type" binding:"required,oneof=profile run"`
+	PhotoIds []string `json:"photo_ids" binding:"required,min=1,dive,uuid"`
 }
 
 type FaceVerifyRequest struct {
-	Image string `json:"image" binding:"required"` // base64 dari kamera
+	ChallengeId  string `json:"challenge_id" binding:"required,uuid"` // dari POST /media/photos/verify-face/challenge
+	Image        string `json:"image" binding:"required"`             // base64 selfie tampak depan dari kamera
+	GestureImage string `json:"gesture_image" binding:"required"`     // base64 selfie saat melakukan gesture challenge
+}
+
+type ReviewFaceVerificationRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve reject"`
+	Note     string `json:"note"`
 }
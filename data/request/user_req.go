@@ -6,12 +6,24 @@ type CreateUserRequest struct {
 	PhoneNumber string  `json:"phone_number" binding:"required"`
 	Gender      *string `json:"gender"`
 	Password    string  `json:"password" binding:"required,min=6"`
+	OTPChannel  *string `json:"otp_channel" binding:"omitempty,oneof=whatsapp sms email"`
 }
 
 type UpdateUserRequest struct {
-	Name         *string `json:"name"`
-	Gender       *string `json:"gender"`
-	PendingEmail *string `json:"pending_email"`
+	Name   *string `json:"name"`
+	Gender *string `json:"gender"`
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+type UpdateOTPChannelRequest struct {
+	Channel *string `json:"channel" binding:"omitempty,oneof=whatsapp sms email"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	OTPCode string `json:"otp_code" binding:"required,len=6"`
 }
 
 type ChangePasswordRequest struct {
@@ -26,3 +38,11 @@ type VerifyEmailRequest struct {
 type VerifyPhoneRequest struct {
 	Token string `json:"token" binding:"required"`
 }
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type DataExportRequest struct {
+	Format string `json:"format" binding:"omitempty,oneof=json zip"` // default zip
+}
@@ -0,0 +1,20 @@
+package response
+
+import "time"
+
+type PersonalRecordResponse struct {
+	Category   string    `json:"category"` // 1k, 5k, 10k, half_marathon, marathon, longest_run
+	Label      string    `json:"label"`
+	ActivityId string    `json:"activity_id"`
+	Distance   float64   `json:"distance"` // km
+	Duration   int       `json:"duration"` // detik
+	Pace       float64   `json:"pace"`     // menit per km
+	AchievedAt time.Time `json:"achieved_at"`
+}
+
+type UserBadgeResponse struct {
+	Badge    string    `json:"badge"` // first_group_run, monthly_100km, streak_7_day
+	Name     string    `json:"name"`
+	Period   string    `json:"period,omitempty"` // YYYY-MM untuk badge bulanan
+	EarnedAt time.Time `json:"earned_at"`
+}
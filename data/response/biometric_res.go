@@ -2,16 +2,92 @@ package response
 
 import "time"
 
-type BiometricChallengeResponse struct {
-	Challenge string `json:"challenge"`
+// Opsi WebAuthn mengikuti PublicKeyCredentialCreationOptions / RequestOptions
+// (dalam format JSON, binary sebagai base64url) agar bisa langsung dipakai client.
+
+type WebAuthnRelyingParty struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUserEntity struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParam struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	Id         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type WebAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUserEntity             `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParam      `json:"pubKeyCredParams"`
+	Timeout                int                            `json:"timeout"`
+	Attestation            string                         `json:"attestation"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	Extensions             map[string]interface{}         `json:"extensions,omitempty"`
+}
+
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RPId             string                         `json:"rpId"`
+	Timeout          int                            `json:"timeout"`
+	UserVerification string                         `json:"userVerification"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+}
+
+type BiometricRegisterOptionsResponse struct {
+	PublicKey WebAuthnCreationOptions `json:"publicKey"`
+}
+
+type BiometricLoginOptionsResponse struct {
+	PublicKey WebAuthnRequestOptions `json:"publicKey"`
 }
 
 type BiometricCredentialResponse struct {
-	Id           string     `json:"id"`
-	UserId       string     `json:"user_id"`
-	CredentialId string     `json:"credential_id"`
-	DeviceName   string     `json:"device_name"`
-	IsActive     bool       `json:"is_active"`
-	LastUsedAt   *time.Time `json:"last_used_at"`
-	CreatedAt    time.Time  `json:"created_at"`
+	Id             string     `json:"id"`
+	UserId         string     `json:"user_id"`
+	CredentialId   string     `json:"credential_id"`
+	DeviceName     string     `json:"device_name"`
+	Algorithm      int        `json:"algorithm"`
+	Transports     []string   `json:"transports"`
+	IsDiscoverable bool       `json:"is_discoverable"`
+	BackupEligible bool       `json:"backup_eligible"`
+	IsActive       bool       `json:"is_active"`
+	DisabledAt     *time.Time `json:"disabled_at"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type BiometricLoginHistoryResponse struct {
+	Id        string    `json:"id"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Location  string    `json:"location"`
+	IsUnusual bool      `json:"is_unusual"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BiometricLoginHistoryListResponse struct {
+	History []BiometricLoginHistoryResponse `json:"history"`
+	Page    int                             `json:"page"`
+	Limit   int                             `json:"limit"`
+	Total   int64                           `json:"total"`
 }
@@ -0,0 +1,25 @@
+package response
+
+import "time"
+
+// ConnectorResponse adalah status satu provider aktivitas untuk user; token tidak pernah dikirim.
+type ConnectorResponse struct {
+	Provider       string     `json:"provider"`
+	Connected      bool       `json:"connected"`
+	Status         string     `json:"status,omitempty"` // active | revoked
+	ExternalUserId string     `json:"external_user_id,omitempty"`
+	Scope          string     `json:"scope,omitempty"`
+	BackfillStatus string     `json:"backfill_status,omitempty"` // pending | running | completed | failed
+	NextSyncAt     *time.Time `json:"next_sync_at,omitempty"`    // sinkronisasi ditahan rate limit provider
+	LastSyncedAt   *time.Time `json:"last_synced_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	ImportedCount  int        `json:"imported_count"`
+	MergedCount    int        `json:"merged_count"` // aktivitas yang sudah ada lalu ditautkan (tidak diduplikasi)
+	ConnectedAt    *time.Time `json:"connected_at,omitempty"`
+}
+
+type ConnectorAuthorizeResponse struct {
+	Provider     string    `json:"provider"`
+	AuthorizeUrl string    `json:"authorize_url"` // buka di browser; provider redirect ke /connectors/:provider/callback
+	ExpiresAt    time.Time `json:"expires_at"`
+}
@@ -22,4 +22,18 @@ type DirectMatchDetailResponse struct {
 	Status                 string        `json:"status"`
 	CreatedAt              time.Time     `json:"created_at"`
 	MatchedAt              *time.Time    `json:"matched_at,omitempty"`
+	ExpiresAt              *time.Time    `json:"expires_at,omitempty"`
+	ClosedAt               *time.Time    `json:"closed_at,omitempty"`
+}
+
+type MatchWeightsResponse struct {
+	Weights  map[string]float64 `json:"weights"`
+	Defaults map[string]float64 `json:"defaults"`
+	Custom   bool               `json:"custom"`
+}
+
+type CandidatePassResponse struct {
+	PassedUserId string    `json:"passed_user_id"`
+	PassCount    int       `json:"pass_count"`
+	HiddenUntil  time.Time `json:"hidden_until"`
 }
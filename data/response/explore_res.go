@@ -12,6 +12,7 @@ type ExploreRunnerResponse struct {
 	Image             *string `json:"image,omitempty"`
 	DistanceKm        float64 `json:"distance_km"` // Distance from requester
 	WomenOnlyMode     bool    `json:"women_only_mode"`
+	VerifiedRunner    bool    `json:"verified_runner"` // badge: wajah sudah terverifikasi
 }
 
 type ExploreGroupResponse struct {
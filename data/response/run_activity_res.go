@@ -3,24 +3,68 @@ package response
 import "time"
 
 type RunActivityResponse struct {
-	Id        string    `json:"id"`
-	UserId    string    `json:"user_id"`
-	Distance  float64   `json:"distance"`
-	Duration  int       `json:"duration"`
-	AvgPace   float64   `json:"avg_pace"`
-	Calories  int       `json:"calories"`
-	Source    string    `json:"source"`
-	CreatedAt time.Time `json:"created_at"`
+	Id           string     `json:"id"`
+	UserId       string     `json:"user_id"`
+	Distance     float64    `json:"distance"`
+	Duration     int        `json:"duration"`
+	AvgPace      float64    `json:"avg_pace"`
+	Calories     int        `json:"calories"`
+	Source       string     `json:"source"`
+	ActivityType string     `json:"activity_type"`
+	StartTime    *time.Time `json:"start_time,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 type RunActivityDetailResponse struct {
-	Id        string        `json:"id"`
-	UserId    string        `json:"user_id"`
-	User      *UserResponse `json:"user,omitempty"`
-	Distance  float64       `json:"distance"`
-	Duration  int           `json:"duration"`
-	AvgPace   float64       `json:"avg_pace"`
-	Calories  int           `json:"calories"`
-	Source    string        `json:"source"`
-	CreatedAt time.Time     `json:"created_at"`
+	Id            string                     `json:"id"`
+	UserId        string                     `json:"user_id"`
+	User          *UserResponse              `json:"user,omitempty"`
+	Distance      float64                    `json:"distance"`
+	Duration      int                        `json:"duration"`
+	AvgPace       float64                    `json:"avg_pace"`
+	Calories      int                        `json:"calories"`
+	Source        string                     `json:"source"`
+	StartTime     *time.Time                 `json:"start_time,omitempty"`
+	ElapsedTime   int                        `json:"elapsed_time,omitempty"`   // detik, termasuk berhenti
+	ElevationGain float64                    `json:"elevation_gain,omitempty"` // meter
+	Polyline      string                     `json:"polyline,omitempty"`       // encoded polyline presisi 5
+	ActivityType  string                     `json:"activity_type"`
+	AvgHeartRate  int                        `json:"avg_heart_rate,omitempty"`
+	MaxHeartRate  int                        `json:"max_heart_rate,omitempty"`
+	AvgCadence    int                        `json:"avg_cadence,omitempty"`
+	MaxSpeed      float64                    `json:"max_speed,omitempty"`   // km/jam
+	SyncedFrom    *string                    `json:"synced_from,omitempty"` // provider connector yang tertaut
+	Splits        []RunActivitySplitResponse `json:"splits"`
+	CreatedAt     time.Time                  `json:"created_at"`
+}
+
+type RunActivitySplitResponse struct {
+	SplitNumber   int     `json:"split_number"`
+	Distance      float64 `json:"distance"` // km
+	Duration      int     `json:"duration"` // detik
+	Pace          float64 `json:"pace"`     // menit per km
+	ElevationGain float64 `json:"elevation_gain"`
+	ElevationLoss float64 `json:"elevation_loss"`
+	AvgHeartRate  int     `json:"avg_heart_rate,omitempty"`
+	MaxHeartRate  int     `json:"max_heart_rate,omitempty"`
+}
+
+// FastestSplitResponse adalah split 1 km tercepat user.
+type FastestSplitResponse struct {
+	ActivityId   string    `json:"activity_id"`
+	SplitNumber  int       `json:"split_number"`
+	Duration     int       `json:"duration"`
+	Pace         float64   `json:"pace"`
+	AvgHeartRate int       `json:"avg_heart_rate,omitempty"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// HeartRateZoneResponse adalah total waktu di satu zona detak jantung.
+type HeartRateZoneResponse struct {
+	Zone       int     `json:"zone"`
+	Name       string  `json:"name"`
+	MinBpm     int     `json:"min_bpm"`
+	MaxBpm     int     `json:"max_bpm"`
+	Seconds    int     `json:"seconds"`
+	Percentage float64 `json:"percentage"`
 }
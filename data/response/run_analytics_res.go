@@ -0,0 +1,60 @@
+package response
+
+import "time"
+
+// TrainingAnalyticsResponse merangkum volume, tren pace, streak dan beban latihan.
+// Semua periode memakai kalender WIB (Asia/Jakarta), minggu dimulai hari Senin.
+type TrainingAnalyticsResponse struct {
+	UserId       string                    `json:"user_id"`
+	Timezone     string                    `json:"timezone"`
+	Weekly       []VolumePointResponse     `json:"weekly"`
+	Monthly      []VolumePointResponse     `json:"monthly"`
+	PaceTrend    PaceTrendResponse         `json:"pace_trend"`
+	Streak       StreakResponse            `json:"streak"`
+	TrainingLoad TrainingLoadResponse      `json:"training_load"`
+	Comparison   PeriodComparisonsResponse `json:"comparison"`
+	GeneratedAt  time.Time                 `json:"generated_at"`
+}
+
+type VolumePointResponse struct {
+	PeriodStart string  `json:"period_start"` // YYYY-MM-DD
+	Runs        int     `json:"runs"`
+	Distance    float64 `json:"distance"` // km
+	Duration    int     `json:"duration"` // detik
+	AvgPace     float64 `json:"avg_pace"` // menit per km, 0 bila tidak ada lari
+}
+
+type PaceTrendResponse struct {
+	SlopePerWeek float64 `json:"slope_per_week"` // perubahan pace (menit/km) per minggu, negatif = makin cepat
+	Direction    string  `json:"direction"`      // improving, declining, stable, insufficient_data
+}
+
+type StreakResponse struct {
+	Current     int     `json:"current"` // hari beruntun sampai hari ini / kemarin
+	Longest     int     `json:"longest"`
+	LastRunDate *string `json:"last_run_date"`
+}
+
+// TrainingLoadResponse membandingkan beban akut (7 hari) dengan beban kronis
+// (rata-rata mingguan 28 hari) dalam km.
+type TrainingLoadResponse struct {
+	AcuteKm   float64 `json:"acute_km"`
+	ChronicKm float64 `json:"chronic_km"`
+	Ratio     float64 `json:"ratio"`
+	Status    string  `json:"status"` // no_data, insufficient_history, low, optimal, high, very_high
+}
+
+type PeriodComparisonsResponse struct {
+	Week  PeriodComparisonResponse `json:"week"`
+	Month PeriodComparisonResponse `json:"month"`
+}
+
+// PeriodComparisonResponse membandingkan periode berjalan dengan periode sebelumnya
+// sampai titik waktu yang sama (misal Senin-Rabu minggu ini vs Senin-Rabu minggu lalu).
+type PeriodComparisonResponse struct {
+	Current           VolumePointResponse `json:"current"`
+	Previous          VolumePointResponse `json:"previous"`
+	DistanceChangePct *float64            `json:"distance_change_pct"` // nil bila periode sebelumnya kosong
+	DurationChangePct *float64            `json:"duration_change_pct"`
+	RunsChange        int                 `json:"runs_change"`
+}
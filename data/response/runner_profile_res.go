@@ -6,11 +6,13 @@ type RunnerProfileResponse struct {
 	Id                string    `json:"id"`
 	UserId            string    `json:"user_id"`
 	AvgPace           float64   `json:"avg_pace"`
+	AvgPaceManual     bool      `json:"avg_pace_manual"` // false: avg_pace diturunkan dari aktivitas terbaru
 	PreferredDistance int       `json:"preferred_distance"`
 	PreferredTime     string    `json:"preferred_time"`
 	Latitude          float64   `json:"latitude"`
 	Longitude         float64   `json:"longitude"`
 	WomenOnlyMode     bool      `json:"women_only_mode"`
+	VerifiedRunner    bool      `json:"verified_runner"` // badge: wajah sudah terverifikasi
 	Image             *string   `json:"image"`
 	IsActive          bool      `json:"is_active"`
 	CreatedAt         time.Time `json:"created_at"`
@@ -22,13 +24,28 @@ type RunnerProfileDetailResponse struct {
 	UserId            string        `json:"user_id"`
 	User              *UserResponse `json:"user,omitempty"`
 	AvgPace           float64       `json:"avg_pace"`
+	AvgPaceManual     bool          `json:"avg_pace_manual"`
 	PreferredDistance int           `json:"preferred_distance"`
 	PreferredTime     string        `json:"preferred_time"`
 	Latitude          float64       `json:"latitude"`
 	Longitude         float64       `json:"longitude"`
 	WomenOnlyMode     bool          `json:"women_only_mode"`
+	VerifiedRunner    bool          `json:"verified_runner"` // badge: wajah sudah terverifikasi
 	Image             *string       `json:"image"`
 	IsActive          bool          `json:"is_active"`
 	CreatedAt         time.Time     `json:"created_at"`
 	UpdatedAt         time.Time     `json:"updated_at"`
 }
+
+type DiscoveryPreferenceResponse struct {
+	RunnerProfileId   string    `json:"runner_profile_id"`
+	MaxRadiusKm       float64   `json:"max_radius_km"`
+	MinPace           float64   `json:"min_pace"`
+	MaxPace           float64   `json:"max_pace"`
+	MinDistance       int       `json:"min_distance"`
+	MaxDistance       int       `json:"max_distance"`
+	GenderPreference  string    `json:"gender_preference"`
+	HideFromDiscovery bool      `json:"hide_from_discovery"`
+	Incognito         bool      `json:"incognito"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
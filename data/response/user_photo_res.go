@@ -3,18 +3,68 @@ package response
 import "time"
 
 type UserPhotoResponse struct {
-	Id        string    `json:"id"`
-	UserId    string    `json:"user_id"`
-	Url       string    `json:"url"`
-	Type      string    `json:"type"`
-	IsPrimary bool      `json:"is_primary"`
-	CreatedAt time.Time `json:"created_at"`
-	Warning   string    `json:"warning,omitempty"`
+	Id           string    `json:"id"`
+	UserId       string    `json:"user_id"`
+	Url          string    `json:"url"`
+	ThumbnailUrl string    `json:"thumbnail_url,omitempty"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	Type         string    `json:"type"`
+	IsPrimary    bool      `json:"is_primary"`
+	Position     int       `json:"position"`
+	Caption      *string   `json:"caption,omitempty"`
+	ReviewStatus string    `json:"review_status,omitempty"` // hanya untuk foto verifikasi
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// PhotoUploadResponse adalah tujuan upload langsung. Jika Fields terisi, kirim
+// multipart/form-data (Fields + file di FileField), selain itu kirim isi file mentah.
+type PhotoUploadResponse struct {
+	UploadId  string            `json:"upload_id"`
+	Method    string            `json:"method"`
+	Url       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	FileField string            `json:"file_field,omitempty"`
+	MaxBytes  int64             `json:"max_bytes"`
+	ExpiresAt time.Time         `json:"expires_at"`
 }
 
 type FaceVerifyResponse struct {
-	Matched    bool               `json:"matched"`
-	Similarity float32            `json:"similarity"`
-	IsVerified bool               `json:"is_verified"`
-	Photo      *UserPhotoResponse `json:"photo,omitempty"` // diisi jika wajah cocok
+	Matched        bool               `json:"matched"`
+	Similarity     float32            `json:"similarity"`
+	LivenessPassed bool               `json:"liveness_passed"`
+	IsVerified     bool               `json:"is_verified"`
+	Status         string             `json:"status"` // status verifikasi user: unverified, pending, verified, rejected
+	Message        string             `json:"message"`
+	Photo          *UserPhotoResponse `json:"photo,omitempty"` // diisi jika wajah cocok
+}
+
+type FaceChallengeResponse struct {
+	ChallengeId string    `json:"challenge_id"`
+	Gesture     string    `json:"gesture"`
+	Instruction string    `json:"instruction"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type FaceVerificationStatusResponse struct {
+	Status     string     `json:"status"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	Reason     string     `json:"reason,omitempty"` // alasan percobaan terakhir ditolak
+}
+
+type FaceReviewResponse struct {
+	Id                   string     `json:"id"`
+	UserId               string     `json:"user_id"`
+	UserName             *string    `json:"user_name"`
+	VerificationPhotoUrl string     `json:"verification_photo_url"`
+	SelfieUrl            string     `json:"selfie_url"`
+	Similarity           float32    `json:"similarity"`
+	ProfileSimilarity    *float32   `json:"profile_similarity,omitempty"`
+	LivenessPassed       bool       `json:"liveness_passed"`
+	Reason               string     `json:"reason"`
+	Status               string     `json:"status"`
+	ReviewNote           string     `json:"review_note,omitempty"`
+	ReviewedAt           *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
 }
@@ -3,28 +3,51 @@ package response
 import "time"
 
 type UserResponse struct {
-	Id          string    `json:"id"`
-	Name        *string   `json:"name"`
-	Email       *string   `json:"email"`
-	PhoneNumber string    `json:"phone_number"`
-	Gender      *string   `json:"gender"`
-	HasProfile  bool      `json:"has_profile"`
-	IsVerified  bool      `json:"is_verified"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id                  string     `json:"id"`
+	Name                *string    `json:"name"`
+	Email               *string    `json:"email"`
+	EmailVerified       bool       `json:"email_verified"`
+	PhoneNumber         string     `json:"phone_number"`
+	OTPChannel          *string    `json:"otp_channel"`
+	Gender              *string    `json:"gender"`
+	HasProfile          bool       `json:"has_profile"`
+	IsVerified          bool       `json:"is_verified"`
+	IsActive            bool       `json:"is_active"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type UserDetailResponse struct {
-	Id          string    `json:"id"`
-	Name        *string   `json:"name"`
-	Email       *string   `json:"email"`
-	PhoneNumber string    `json:"phone_number"`
-	Gender      *string   `json:"gender"`
-	HasProfile  bool      `json:"has_profile"`
-	IsVerified  bool      `json:"is_verified"`
-	IsActive    bool      `json:"is_active"`
-	Token       string    `json:"token,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	Id                  string     `json:"id"`
+	Name                *string    `json:"name"`
+	Email               *string    `json:"email"`
+	EmailVerified       bool       `json:"email_verified"`
+	PhoneNumber         string     `json:"phone_number"`
+	OTPChannel          *string    `json:"otp_channel"`
+	Gender              *string    `json:"gender"`
+	HasProfile          bool       `json:"has_profile"`
+	IsVerified          bool       `json:"is_verified"`
+	IsActive            bool       `json:"is_active"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	Token               string     `json:"token,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+	GracePeriodDays     int       `json:"grace_period_days"`
+}
+
+type DataExportResponse struct {
+	Id          string     `json:"id"`
+	Format      string     `json:"format"`
+	Status      string     `json:"status"` // pending | processing | completed | failed | expired
+	FileSize    int64      `json:"file_size,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	DownloadUrl string     `json:"download_url,omitempty"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
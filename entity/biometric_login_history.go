@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BiometricLoginHistory mencatat setiap login biometrik yang berhasil per credential.
+// Location adalah kunci lokasi kasar (kode negara dari CDN atau prefix jaringan IP)
+// yang dipakai untuk mendeteksi login dari lokasi tidak biasa.
+type BiometricLoginHistory struct {
+	Id          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	BiometricId uuid.UUID `gorm:"type:uuid;not null;index" json:"biometric_id"`
+	UserId      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	IPAddress   string    `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent   string    `gorm:"type:text" json:"user_agent"`
+	Location    string    `gorm:"type:varchar(64)" json:"location"`
+	IsUnusual   bool      `gorm:"default:false" json:"is_unusual"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CandidatePass mencatat kandidat yang di-skip user di daftar kandidat match.
+// Satu baris per pasangan; PassCount bertambah setiap kali kandidat di-pass lagi
+// dan menentukan lamanya cooldown serta penalti skor setelah muncul kembali.
+type CandidatePass struct {
+	UserId       uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	PassedUserId uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"passed_user_id"`
+	PassCount    int       `gorm:"not null;default:1" json:"pass_count"`
+	PassedAt     time.Time `gorm:"not null" json:"passed_at"`
+	HiddenUntil  time.Time `gorm:"not null;index" json:"hidden_until"`
+}
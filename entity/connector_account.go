@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status akun terhubung
+const (
+	ConnectorActive  = "active"
+	ConnectorRevoked = "revoked" // akses dicabut di provider atau refresh token ditolak, user perlu menghubungkan ulang
+)
+
+// Status backfill riwayat aktivitas
+const (
+	BackfillPending   = "pending"
+	BackfillRunning   = "running"
+	BackfillCompleted = "completed"
+	BackfillFailed    = "failed"
+)
+
+// ConnectorAccount adalah akun provider aktivitas (Strava, ...) yang dihubungkan user
+// lewat OAuth2. AccessToken dan RefreshToken disimpan terenkripsi (helper.TokenCipher).
+type ConnectorAccount struct {
+	Id             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserId         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_connector_accounts_user_provider" json:"user_id"`
+	Provider       string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_connector_accounts_user_provider;uniqueIndex:idx_connector_accounts_external" json:"provider"`
+	ExternalUserId string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_connector_accounts_external" json:"external_user_id"`
+	AccessToken    string     `gorm:"type:text;not null" json:"-"`
+	RefreshToken   string     `gorm:"type:text" json:"-"`
+	TokenExpiresAt time.Time  `json:"-"`
+	Scope          string     `gorm:"type:varchar(255)" json:"scope"`
+	Status         string     `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	BackfillStatus string     `gorm:"type:varchar(20);not null;default:'pending';index" json:"backfill_status"`
+	BackfillAfter  time.Time  `json:"backfill_after"` // awal rentang riwayat yang diambil
+	BackfillPage   int        `json:"-"`              // halaman berikutnya yang diambil
+	NextSyncAt     *time.Time `json:"next_sync_at"`   // ditahan rate limit provider sampai waktu ini
+	LastSyncedAt   *time.Time `json:"last_synced_at"` // aktivitas terakhir berhasil diambil
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	ImportedCount  int        `json:"imported_count"` // aktivitas baru dari provider
+	MergedCount    int        `json:"merged_count"`   // aktivitas manual / impor file yang ditautkan ke provider
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
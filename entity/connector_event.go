@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status event webhook provider
+const (
+	ConnectorEventPending = "pending"
+	ConnectorEventDone    = "done"
+	ConnectorEventFailed  = "failed"  // gagal sampai batas percobaan
+	ConnectorEventIgnored = "ignored" // akun tidak terhubung atau aktivitas bukan lari
+)
+
+// ConnectorEvent adalah antrean event webhook. Receiver hanya menyimpan event dan
+// langsung membalas 200; aktivitas diambil oleh job agar rate limit provider dihormati.
+type ConnectorEvent struct {
+	Id                 uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	Provider           string    `gorm:"type:varchar(20);not null" json:"provider"`
+	ExternalUserId     string    `gorm:"type:varchar(64);not null;index" json:"external_user_id"`
+	ExternalActivityId string    `gorm:"type:varchar(64)" json:"external_activity_id"`
+	Action             string    `gorm:"type:varchar(20);not null" json:"action"` // create, update, delete, deauthorize
+	Status             string    `gorm:"type:varchar(20);not null;default:'pending';index:idx_connector_events_due" json:"status"`
+	Attempts           int       `json:"attempts"`
+	Error              string    `gorm:"type:text" json:"error"`
+	NextAttemptAt      time.Time `gorm:"index:idx_connector_events_due" json:"next_attempt_at"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
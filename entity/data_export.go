@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status job export data user
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportCompleted  = "completed"
+	DataExportFailed     = "failed"
+	DataExportExpired    = "expired"
+)
+
+// DataExport adalah job "download data saya". File hasil export disimpan di
+// DATA_EXPORT_DIR dan dihapus setelah ExpiresAt.
+type DataExport struct {
+	Id          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserId      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Format      string     `gorm:"type:varchar(10);not null" json:"format"` // json | zip
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	FilePath    string     `gorm:"type:text" json:"-"`
+	FileSize    int64      `json:"file_size"`
+	Error       string     `gorm:"type:text" json:"error"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
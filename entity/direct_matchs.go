@@ -10,7 +10,10 @@ type DirectMatch struct {
 	Id        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
 	User1Id   uuid.UUID `gorm:"not null;index"`
 	User2Id   uuid.UUID `gorm:"not null;index"`
-	Status    string    `gorm:"type:varchar(50)"` // pending, accepted, rejected
+	Status    string    `gorm:"type:varchar(50)"` // pending, accepted, rejected, expired, withdrawn, unmatched
 	CreatedAt time.Time
 	MatchedAt *time.Time
+	ExpiresAt *time.Time `gorm:"index"` // batas waktu request pending
+	ClosedAt  *time.Time // waktu match berakhir (expired, withdrawn, unmatched)
+	ClosedBy  *uuid.UUID `gorm:"type:uuid"` // user yang menarik request / unmatch
 }
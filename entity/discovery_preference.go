@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DiscoveryPreference adalah preferensi pencarian milik satu runner profile.
+// Nilai 0 / kosong berarti tidak dibatasi (radius memakai default environment).
+// Preferensi berlaku dua arah: kandidat hanya muncul jika preferensi kedua
+// pihak saling mencakup.
+type DiscoveryPreference struct {
+	Id uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	RunnerProfileId uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"runner_profile_id"`
+	RunnerProfile   *RunnerProfile `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	UserId          uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+
+	MaxRadiusKm      float64 `json:"max_radius_km"`
+	MinPace          float64 `gorm:"type:decimal(4,2)" json:"min_pace"`
+	MaxPace          float64 `gorm:"type:decimal(4,2)" json:"max_pace"`
+	MinDistance      int     `json:"min_distance"`                                            // preferred distance (km)
+	MaxDistance      int     `json:"max_distance"`                                            // preferred distance (km)
+	GenderPreference string  `gorm:"type:varchar(20);default:'any'" json:"gender_preference"` // any, male, female
+
+	HideFromDiscovery bool `gorm:"default:false" json:"hide_from_discovery"` // tidak muncul di explore/kandidat sama sekali
+	Incognito         bool `gorm:"default:false" json:"incognito"`           // hanya terlihat oleh user yang sudah ia kirimi match request
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status verifikasi wajah di users.face_verification_status
+const (
+	FaceUnverified = "unverified"
+	FacePending    = "pending" // menunggu review manual
+	FaceVerified   = "verified"
+	FaceRejected   = "rejected"
+)
+
+// Status satu percobaan verifikasi wajah
+const (
+	FaceAttemptChallenged = "challenged"     // challenge gesture sudah dikirim, menunggu selfie
+	FaceAttemptReview     = "pending_review" // similarity borderline, masuk antrean review
+	FaceAttemptApproved   = "approved"
+	FaceAttemptRejected   = "rejected"
+	FaceAttemptExpired    = "expired"
+)
+
+// FaceVerification adalah satu percobaan verifikasi wajah: challenge liveness
+// (gesture acak dari server), hasil perbandingan wajah dan keputusan review.
+type FaceVerification struct {
+	Id                 uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserId             uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	PhotoId            uuid.UUID  `gorm:"type:uuid;not null" json:"photo_id"` // foto verifikasi yang dibandingkan
+	Gesture            string     `gorm:"type:varchar(30);not null" json:"gesture"`
+	ChallengeExpiresAt time.Time  `json:"challenge_expires_at"`
+	Status             string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	SelfieUrl          string     `gorm:"type:varchar(255)" json:"selfie_url"`
+	Similarity         float32    `json:"similarity"`                   // selfie vs foto verifikasi
+	ProfileSimilarity  *float32   `json:"profile_similarity,omitempty"` // foto verifikasi vs foto profil terbaik
+	LivenessPassed     bool       `json:"liveness_passed"`
+	Reason             string     `gorm:"type:text" json:"reason"`
+	ReviewerId         *uuid.UUID `gorm:"type:uuid" json:"reviewer_id,omitempty"`
+	ReviewNote         string     `gorm:"type:text" json:"review_note"`
+	ReviewedAt         *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MatchWeight adalah bobot faktor kompatibilitas yang diatur user sendiri,
+// menimpa bobot default environment untuk faktor tersebut.
+type MatchWeight struct {
+	UserId    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Factor    string    `gorm:"type:varchar(50);primaryKey" json:"factor"`
+	Weight    float64   `gorm:"not null" json:"weight"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -13,9 +13,12 @@ const (
 	NotifGroupMessage  = "group_message"  // pesan baru di group chat
 
 	// --- Match ---
-	NotifMatchRequest  = "match_request"  // seseorang mengirim request match
-	NotifMatchAccepted = "match_accepted" // request match diterima
-	NotifMatchRejected = "match_rejected" // request match ditolak
+	NotifMatchRequest   = "match_request"   // seseorang mengirim request match
+	NotifMatchAccepted  = "match_accepted"  // request match diterima
+	NotifMatchRejected  = "match_rejected"  // request match ditolak
+	NotifMatchExpired   = "match_expired"   // request match kedaluwarsa tanpa jawaban
+	NotifMatchWithdrawn = "match_withdrawn" // pengirim menarik request match
+	NotifMatchUnmatched = "match_unmatched" // match diakhiri, obrolan ditutup
 
 	// --- Group ---
 	NotifGroupInvite        = "group_invite"         // diundang masuk ke group
@@ -31,7 +34,9 @@ const (
 	NotifGroupScheduleStart = "group_schedule_start" // grup run mau dimulai (reminder H-1 jam)
 
 	// --- Activity ---
-	NotifActivityLogged = "activity_logged" // aktivitas lari berhasil dicatat
+	NotifActivityLogged  = "activity_logged"  // aktivitas lari berhasil dicatat
+	NotifPersonalRecord  = "personal_record"  // personal record baru
+	NotifBadgeEarned     = "badge_earned"     // badge pencapaian baru
 
 	// --- Safety ---
 	NotifUserReported   = "user_reported"   // akun kamu dilaporkan oleh pengguna lain
@@ -39,10 +44,21 @@ const (
 	NotifAutoSuspended  = "auto_suspended"  // akun disuspend otomatis karena banyak laporan
 
 	// --- Account / System ---
-	NotifAccountVerified    = "account_verified"    // email berhasil diverifikasi
-	NotifProfileIncomplete  = "profile_incomplete"  // profil runner belum dilengkapi
-	NotifPasswordChanged    = "password_changed"    // password berhasil diubah
-	NotifEmailChangeRequest = "email_change_request" // ada permintaan ganti email
+	NotifAccountVerified          = "account_verified"           // email berhasil diverifikasi
+	NotifProfileIncomplete        = "profile_incomplete"         // profil runner belum dilengkapi
+	NotifPasswordChanged          = "password_changed"           // password berhasil diubah
+	NotifEmailChangeRequest       = "email_change_request"       // ada permintaan ganti email
+	NotifAccountDeletionScheduled = "account_deletion_scheduled" // akun dijadwalkan untuk dihapus
+	NotifDataExportReady          = "data_export_ready"          // export data akun siap diunduh
+
+	// --- Biometric ---
+	NotifBiometricRegistered   = "biometric_registered"    // perangkat biometrik baru didaftarkan
+	NotifBiometricUnusualLogin = "biometric_unusual_login" // login biometrik dari lokasi yang tidak biasa
+	NotifBiometricDisabled     = "biometric_disabled"      // credential biometrik dinonaktifkan
+
+	// --- Face Verification ---
+	NotifFaceVerified = "face_verified" // verifikasi wajah berhasil, badge verified runner aktif
+	NotifFaceRejected = "face_rejected" // verifikasi wajah gagal / ditolak reviewer
 )
 
 // Notification is the persisted notification record.
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalRecord adalah best effort user per kategori; dihitung ulang dari seluruh
+// riwayat aktivitas setiap kali riwayat berubah.
+type PersonalRecord struct {
+	Id         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_personal_records_user_category"`
+	Category   string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_personal_records_user_category"` // 1k, 5k, 10k, half_marathon, marathon, longest_run
+	ActivityId uuid.UUID `gorm:"type:uuid;not null;index"`
+	Distance   float64   // km
+	Duration   int       // seconds
+	Pace       float64   // menit per km
+	AchievedAt time.Time // waktu mulai aktivitas
+	UpdatedAt  time.Time
+}
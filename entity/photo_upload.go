@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status upload langsung ke storage
+const (
+	PhotoUploadPending   = "pending"   // URL upload sudah diberikan, menunggu complete
+	PhotoUploadCompleted = "completed" // file sudah diproses menjadi UserPhoto
+	PhotoUploadFailed    = "failed"    // file tidak lolos validasi
+)
+
+// PhotoUpload adalah sesi upload foto langsung dari client ke storage. File mentah
+// disimpan di Key (folder staging) sampai client memanggil complete.
+type PhotoUpload struct {
+	Id          uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+	UserId      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Key         string     `gorm:"type:varchar(255);not null" json:"key"`
+	ContentType string     `gorm:"type:varchar(50);not null" json:"content_type"`
+	Type        string     `gorm:"type:varchar(50);not null" json:"type"` // profile, run, verification
+	IsPrimary   bool       `json:"is_primary"`
+	Caption     *string    `gorm:"type:varchar(200)" json:"caption,omitempty"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	PhotoId     *uuid.UUID `gorm:"type:uuid" json:"photo_id,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
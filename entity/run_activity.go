@@ -7,12 +7,29 @@ import (
 )
 
 type RunActivity struct {
-	Id        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	UserId    uuid.UUID `gorm:"not null;index"`
-	Distance  float64   // km
-	Duration  int       // seconds
-	AvgPace   float64
-	Calories  int
-	Source    string `gorm:"type:varchar(50)"` // manual, garmin, strava
+	Id            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId        uuid.UUID `gorm:"not null;index;uniqueIndex:idx_run_activities_user_file"`
+	Distance      float64   // km
+	Duration      int       // seconds (waktu bergerak untuk aktivitas impor)
+	AvgPace       float64
+	Calories      int
+	Source        string     `gorm:"type:varchar(50)"` // manual, garmin, strava, mock, gpx, tcx, fit
+	StartTime     *time.Time `gorm:"index"`            // waktu mulai dari file impor
+	ElapsedTime   int        // seconds, termasuk berhenti
+	ElevationGain float64    // meter
+	Polyline      string     `gorm:"type:text"`                                                 // encoded polyline rute
+	FileHash      *string    `gorm:"type:varchar(64);uniqueIndex:idx_run_activities_user_file"` // sha256 file impor, menolak upload ganda
+	ActivityType  string     `gorm:"type:varchar(20);default:'easy';index"`                     // easy, tempo, interval, long_run, race
+	AvgHeartRate  int        // bpm, 0 = tidak ada data
+	MaxHeartRate  int        // bpm
+	AvgCadence    int        // langkah per menit
+	MaxSpeed      float64    // km/jam, kecepatan rata-rata tertinggi 30 detik (impor) atau split tercepat
+
+	// Aktivitas dari connector (atau aktivitas manual yang ditautkan saat deduplikasi)
+	ExternalProvider *string `gorm:"type:varchar(20);uniqueIndex:idx_run_activities_external"`
+	ExternalId       *string `gorm:"type:varchar(64);uniqueIndex:idx_run_activities_external"`
+
+	Splits []*RunActivitySplit `gorm:"foreignKey:ActivityId"`
+
 	CreatedAt time.Time
 }
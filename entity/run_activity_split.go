@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+// RunActivitySplit adalah satu split per kilometer; split terakhir boleh kurang dari 1 km.
+type RunActivitySplit struct {
+	Id            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ActivityId    uuid.UUID `gorm:"type:uuid;not null;index"`
+	SplitNumber   int       `gorm:"not null"` // 1 = kilometer pertama
+	Distance      float64   // km
+	Duration      int       // seconds
+	Pace          float64   // menit per km
+	ElevationGain float64   // meter
+	ElevationLoss float64   // meter
+	AvgHeartRate  int       // bpm, 0 = tidak ada data
+	MaxHeartRate  int       // bpm
+}
@@ -13,6 +13,7 @@ type RunnerProfile struct {
 	User   *User     `gorm:"constraint:OnDelete:CASCADE;"`
 
 	AvgPace           float64 `gorm:"type:decimal(4,2)" json:"avg_pace"`      // contoh 5.45
+	AvgPaceManual     bool    `gorm:"default:false" json:"avg_pace_manual"`   // true: avg_pace diisi user, tidak diturunkan dari aktivitas
 	PreferredDistance int     `json:"preferred_distance"`                     // 5,10,21
 	PreferredTime     string  `gorm:"type:varchar(50)" json:"preferred_time"` // morning/evening
 	Latitude          float64 `json:"latitude"`
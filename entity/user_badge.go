@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserBadge adalah pencapaian yang sudah diraih user. Badge tidak dicabut walau
+// aktivitas pemicunya dihapus.
+type UserBadge struct {
+	Id       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_badges_user_badge"`
+	Badge    string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_badges_user_badge"`           // first_group_run, monthly_100km, streak_7_day
+	Period   string    `gorm:"type:varchar(7);not null;default:'';uniqueIndex:idx_user_badges_user_badge"` // "2006-01" untuk badge bulanan, kosong untuk badge sekali
+	EarnedAt time.Time
+}
@@ -6,13 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserBiometric adalah credential WebAuthn (passkey/biometrik) milik user.
+// CredentialId dan PublicKey (COSE key) disimpan dalam base64url.
 type UserBiometric struct {
-	Id           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	UserId       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
-	CredentialId string     `gorm:"type:text;not null;uniqueIndex" json:"credential_id"`
-	PublicKey    string     `gorm:"type:text;not null" json:"public_key"`
-	DeviceName   string     `gorm:"type:varchar(255)" json:"device_name"`
-	IsActive     bool       `gorm:"default:true" json:"is_active"`
-	LastUsedAt   *time.Time `json:"last_used_at"`
-	CreatedAt    time.Time  `json:"created_at"`
+	Id                uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId            uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CredentialId      string     `gorm:"type:text;not null;uniqueIndex" json:"credential_id"`
+	PublicKey         string     `gorm:"type:text;not null" json:"public_key"`
+	Algorithm         int        `gorm:"default:0" json:"algorithm"` // COSE alg (-7 ES256, -257 RS256); 0 = credential lama (HMAC), harus didaftarkan ulang
+	SignCount         int64      `gorm:"default:0" json:"sign_count"`
+	AAGUID            string     `gorm:"type:varchar(36)" json:"aaguid"`
+	Transports        string     `gorm:"type:varchar(255)" json:"transports"` // dipisah koma, misal "internal,hybrid"
+	AttestationFormat string     `gorm:"type:varchar(32)" json:"attestation_format"`
+	IsDiscoverable    bool       `gorm:"default:false" json:"is_discoverable"`
+	BackupEligible    bool       `gorm:"default:false" json:"backup_eligible"`
+	BackupState       bool       `gorm:"default:false" json:"backup_state"`
+	DeviceName        string     `gorm:"type:varchar(255)" json:"device_name"`
+	IsActive          bool       `gorm:"default:true" json:"is_active"`
+	DisabledAt        *time.Time `json:"disabled_at"`
+	DisabledReason    string     `gorm:"type:varchar(32)" json:"disabled_reason"` // user | sign_count
+	LastUsedAt        *time.Time `json:"last_used_at"`
+	CreatedAt         time.Time  `json:"created_at"`
 }
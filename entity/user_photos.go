@@ -6,11 +6,25 @@ import (
 	"github.com/google/uuid"
 )
 
+// Status review wajah untuk foto verifikasi.
+const (
+	PhotoReviewPending  = "pending"
+	PhotoReviewApproved = "approved"
+	PhotoReviewRejected = "rejected"
+)
+
 type UserPhoto struct {
-	Id        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	UserId    uuid.UUID `gorm:"type:uuid;not null;index"`
-	Url       string    `gorm:"type:varchar(255);not null"`
-	Type      string    `gorm:"type:varchar(50)"` // profile, run, verification
-	IsPrimary bool
-	CreatedAt time.Time
+	Id           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserId       uuid.UUID `gorm:"type:uuid;not null;index"`
+	Url          string    `gorm:"type:varchar(255);not null"`
+	ThumbnailUrl string    `gorm:"type:varchar(255)"`
+	Width        int
+	Height       int
+	Type         string `gorm:"type:varchar(50)"` // profile, run, verification
+	IsPrimary    bool
+	Position     int     `gorm:"not null;default:0"`                 // urutan di galeri per tipe, mulai 0
+	Caption      *string `gorm:"type:varchar(200)"`                  // hanya untuk foto run
+	ReviewStatus string  `gorm:"type:varchar(20);default:'pending'"` // hanya untuk verification: pending, approved, rejected
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
 }
@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache analytics aktivitas memakai versi per user: invalidasi cukup menaikkan versi,
+// entri versi lama kedaluwarsa sendiri sesuai TTL-nya.
+func (r *RedisHelper) activityAnalyticsKey(userId, variant string) (string, error) {
+	version, err := r.Client.Get(r.Ctx, "activity_analytics_version:"+userId).Int64()
+	if err != nil && err != redis.Nil {
+		return "", err
+	}
+	return fmt.Sprintf("activity_analytics:%s:%d:%s", userId, version, variant), nil
+}
+
+// GetActivityAnalytics mengambil analytics yang di-cache; found=false bila belum ada
+// atau sudah diinvalidasi.
+func (r *RedisHelper) GetActivityAnalytics(userId, variant string, dest interface{}) (found bool, err error) {
+	key, err := r.activityAnalyticsKey(userId, variant)
+	if err != nil {
+		return false, err
+	}
+	err = GetJSONFromRedis(r.Ctx, r.Client, key, dest)
+	if err == redis.Nil {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *RedisHelper) SaveActivityAnalytics(userId, variant string, data interface{}, ttl time.Duration) error {
+	key, err := r.activityAnalyticsKey(userId, variant)
+	if err != nil {
+		return err
+	}
+	return SetJSONToRedis(r.Ctx, r.Client, key, data, ttl)
+}
+
+// InvalidateActivityAnalytics membuang semua analytics user yang di-cache.
+func (r *RedisHelper) InvalidateActivityAnalytics(userId string) error {
+	key := "activity_analytics_version:" + userId
+	if err := r.Client.Incr(r.Ctx, key).Err(); err != nil {
+		return err
+	}
+	return r.Client.Expire(r.Ctx, key, 30*24*time.Hour).Err()
+}
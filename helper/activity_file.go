@@ -0,0 +1,318 @@
+package helper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrActivityFileUnsupported = errors.New("format file aktivitas tidak didukung (gunakan GPX, TCX atau FIT)")
+	ErrActivityFileInvalid     = errors.New("file aktivitas rusak atau tidak bisa dibaca")
+	ErrActivityNoTrack         = errors.New("file aktivitas tidak berisi titik rute dengan waktu")
+)
+
+// Format file aktivitas yang bisa diimpor
+const (
+	ActivityFormatGPX = "gpx"
+	ActivityFormatTCX = "tcx"
+	ActivityFormatFIT = "fit"
+)
+
+// TrackPoint adalah satu titik rekaman dari file aktivitas. Nilai yang tidak direkam
+// device dibiarkan nol / nil.
+type TrackPoint struct {
+	Time        time.Time
+	Lat         float64
+	Lon         float64
+	HasPosition bool
+	Elevation   *float64 // meter
+	Distance    *float64 // meter kumulatif dari device (TCX/FIT)
+	HeartRate   int      // bpm
+	Cadence     int      // langkah per menit
+}
+
+// ActivityFile adalah hasil parse file GPX/TCX/FIT.
+type ActivityFile struct {
+	Format string
+	Hash   string // sha256 isi file (setelah gzip dibuka), dipakai menolak upload ganda
+	Points []TrackPoint
+}
+
+// TrackSummary adalah ringkasan rute yang disimpan di RunActivity.
+type TrackSummary struct {
+	StartTime     time.Time
+	DistanceKm    float64
+	MovingTime    int // detik
+	ElapsedTime   int // detik
+	ElevationGain float64
+	Polyline      string // encoded polyline (presisi 5)
+	AvgHeartRate  int    // rata-rata tertimbang waktu bergerak, 0 = tidak ada data
+	MaxHeartRate  int
+	AvgCadence    int
+	MaxSpeedKmh   float64 // kecepatan rata-rata tertinggi selama minimal 30 detik
+	Splits        []TrackSplit
+}
+
+const (
+	movingSpeedThreshold = 0.8  // m/s; di bawah ini dianggap berhenti
+	elevationHysteresis  = 3.0  // m; kenaikan lebih kecil dianggap noise GPS/barometer
+	polylineMinSpacing   = 5.0  // m; titik yang lebih rapat tidak dimasukkan ke polyline
+	maxSpeedWindow       = 30.0 // detik; lonjakan GPS yang lebih singkat tidak dihitung sebagai kecepatan
+	maxActivityFileBytes = 64 << 20
+)
+
+// ParseActivityFile mendeteksi format dari isi file (nama file hanya sebagai petunjuk),
+// membuka gzip (.gpx.gz dll) lalu membaca semua titik rute yang punya waktu.
+func ParseActivityFile(filename string, data []byte) (*ActivityFile, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		unzipped, err := gunzip(data)
+		if err != nil {
+			return nil, err
+		}
+		data = unzipped
+		filename = strings.TrimSuffix(filename, filepath.Ext(filename))
+	}
+
+	var (
+		points []TrackPoint
+		format = detectActivityFormat(filename, data)
+		err    error
+	)
+	switch format {
+	case ActivityFormatGPX:
+		points, err = parseGPX(data)
+	case ActivityFormatTCX:
+		points, err = parseTCX(data)
+	case ActivityFormatFIT:
+		points, err = parseFIT(data)
+	default:
+		return nil, ErrActivityFileUnsupported
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrActivityFileInvalid, err)
+	}
+
+	timed := points[:0]
+	for _, p := range points {
+		if !p.Time.IsZero() {
+			timed = append(timed, p)
+		}
+	}
+	if len(timed) < 2 {
+		return nil, ErrActivityNoTrack
+	}
+
+	sum := sha256.Sum256(data)
+	return &ActivityFile{Format: format, Hash: hex.EncodeToString(sum[:]), Points: timed}, nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrActivityFileInvalid, err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, maxActivityFileBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrActivityFileInvalid, err)
+	}
+	if len(out) > maxActivityFileBytes {
+		return nil, fmt.Errorf("%w: isi gzip terlalu besar", ErrActivityFileInvalid)
+	}
+	return out, nil
+}
+
+func detectActivityFormat(filename string, data []byte) string {
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return ActivityFormatFIT
+	}
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	switch {
+	case bytes.Contains(head, []byte("<gpx")):
+		return ActivityFormatGPX
+	case bytes.Contains(head, []byte("<TrainingCenterDatabase")):
+		return ActivityFormatTCX
+	}
+	// Root element bisa didahului komentar panjang; pakai ekstensi sebagai cadangan
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".gpx":
+		return ActivityFormatGPX
+	case ".tcx":
+		return ActivityFormatTCX
+	}
+	return ""
+}
+
+// SummarizeTrack menghitung jarak, waktu bergerak, elevasi, detak jantung, cadence, split
+// per km dan polyline dari titik rute. Jarak dihitung dari koordinat (haversine); file
+// tanpa koordinat (treadmill) memakai jarak kumulatif dari device.
+func SummarizeTrack(points []TrackPoint) TrackSummary {
+	summary := TrackSummary{}
+	if len(points) == 0 {
+		return summary
+	}
+	summary.StartTime = points[0].Time
+	summary.ElapsedTime = int(points[len(points)-1].Time.Sub(points[0].Time).Seconds())
+
+	var (
+		distance         float64
+		moving           float64
+		gainRef, lossRef *float64 // elevasi acuan hysteresis naik / turun
+		hr, cadence      weightedAvg
+		splits           splitBuilder
+		prev             = points[0]
+	)
+	if prev.Elevation != nil {
+		g, l := *prev.Elevation, *prev.Elevation
+		gainRef, lossRef = &g, &l
+	}
+	for _, p := range points[1:] {
+		dt := p.Time.Sub(prev.Time).Seconds()
+		step := segmentDistance(prev, p)
+		movingDt := 0.0
+		if dt > 0 && step/dt >= movingSpeedThreshold {
+			movingDt = dt
+		}
+		distance += step
+		moving += movingDt
+		if p.HeartRate > summary.MaxHeartRate {
+			summary.MaxHeartRate = p.HeartRate
+		}
+		if p.HeartRate > 0 {
+			hr.add(float64(p.HeartRate), movingDt)
+		}
+		if p.Cadence > 0 {
+			cadence.add(float64(p.Cadence), movingDt)
+		}
+
+		var gain, loss float64
+		if p.Elevation != nil {
+			if gainRef == nil {
+				g, l := *p.Elevation, *p.Elevation
+				gainRef, lossRef = &g, &l
+			}
+			switch {
+			case *p.Elevation-*gainRef >= elevationHysteresis:
+				gain = *p.Elevation - *gainRef
+				*gainRef = *p.Elevation
+			case *p.Elevation < *gainRef:
+				*gainRef = *p.Elevation
+			}
+			switch {
+			case *lossRef-*p.Elevation >= elevationHysteresis:
+				loss = *lossRef - *p.Elevation
+				*lossRef = *p.Elevation
+			case *p.Elevation > *lossRef:
+				*lossRef = *p.Elevation
+			}
+		}
+		summary.ElevationGain += gain
+
+		splits.add(step, movingDt, p.HeartRate, gain, loss)
+		prev = p
+	}
+
+	summary.DistanceKm = math.Round(distance/10) / 100
+	summary.MovingTime = int(math.Round(moving))
+	summary.ElevationGain = math.Round(summary.ElevationGain*10) / 10
+	summary.AvgHeartRate = hr.value()
+	summary.AvgCadence = cadence.value()
+	summary.Splits = splits.finish()
+	summary.MaxSpeedKmh = maxSustainedSpeed(points)
+	summary.Polyline = EncodePolyline(points)
+	return summary
+}
+
+// maxSustainedSpeed mengembalikan kecepatan rata-rata tertinggi (km/jam) pada jendela
+// waktu minimal maxSpeedWindow, sehingga satu titik GPS yang meloncat tidak berpengaruh.
+func maxSustainedSpeed(points []TrackPoint) float64 {
+	cumulative := make([]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		cumulative[i] = cumulative[i-1] + segmentDistance(points[i-1], points[i])
+	}
+
+	var best float64
+	start := 0
+	for end := 1; end < len(points); end++ {
+		for start+1 < end && points[end].Time.Sub(points[start+1].Time).Seconds() >= maxSpeedWindow {
+			start++
+		}
+		dt := points[end].Time.Sub(points[start].Time).Seconds()
+		if dt < maxSpeedWindow {
+			continue
+		}
+		if speed := (cumulative[end] - cumulative[start]) / dt; speed > best {
+			best = speed
+		}
+	}
+	return math.Round(best*3.6*10) / 10
+}
+
+// segmentDistance mengembalikan jarak (meter) antara dua titik berurutan.
+func segmentDistance(a, b TrackPoint) float64 {
+	if a.HasPosition && b.HasPosition {
+		return haversineMeters(a.Lat, a.Lon, b.Lat, b.Lon)
+	}
+	if a.Distance != nil && b.Distance != nil && *b.Distance > *a.Distance {
+		return *b.Distance - *a.Distance
+	}
+	return 0
+}
+
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// EncodePolyline membuat Google encoded polyline (presisi 5) dari titik yang punya
+// koordinat, melewati titik yang berjarak < 5 m dari titik terakhir yang dipakai.
+func EncodePolyline(points []TrackPoint) string {
+	var (
+		b                strings.Builder
+		lastLat, lastLon int64
+		last             *TrackPoint
+	)
+	for i := range points {
+		p := &points[i]
+		if !p.HasPosition {
+			continue
+		}
+		if last != nil && haversineMeters(last.Lat, last.Lon, p.Lat, p.Lon) < polylineMinSpacing && i != len(points)-1 {
+			continue
+		}
+		lat, lon := int64(math.Round(p.Lat*1e5)), int64(math.Round(p.Lon*1e5))
+		encodePolylineValue(&b, lat-lastLat)
+		encodePolylineValue(&b, lon-lastLon)
+		lastLat, lastLon, last = lat, lon, p
+	}
+	return b.String()
+}
+
+func encodePolylineValue(b *strings.Builder, v int64) {
+	v <<= 1
+	if v < 0 {
+		v = ^v
+	}
+	for v >= 0x20 {
+		b.WriteByte(byte((0x20 | (v & 0x1f)) + 63))
+		v >>= 5
+	}
+	b.WriteByte(byte(v + 63))
+}
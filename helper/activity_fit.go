@@ -0,0 +1,222 @@
+package helper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Decoder FIT minimal: hanya message record (global 20) yang dibaca, message lain
+// dilewati berdasarkan definisinya. Developer field dilewati.
+const (
+	fitMesgRecord     = 20
+	fitFieldTimestamp = 253
+	fitFieldLat       = 0
+	fitFieldLon       = 1
+	fitFieldAltitude  = 2
+	fitFieldHeartRate = 3
+	fitFieldCadence   = 4
+	fitFieldDistance  = 5
+	fitFieldEnhAlt    = 78
+	fitEpochOffset    = 631065600 // 1989-12-31T00:00:00Z dalam unix detik
+)
+
+type fitField struct {
+	num  byte
+	size int
+}
+
+type fitDefinition struct {
+	global    uint16
+	bigEndian bool
+	fields    []fitField
+	devSize   int
+}
+
+func parseFIT(data []byte) ([]TrackPoint, error) {
+	if len(data) < 12 {
+		return nil, errors.New("header FIT terlalu pendek")
+	}
+	headerSize := int(data[0])
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	if headerSize < 12 || headerSize+dataSize > len(data) {
+		return nil, errors.New("ukuran data FIT tidak valid")
+	}
+	body := data[headerSize : headerSize+dataSize]
+
+	var (
+		defs          = map[byte]*fitDefinition{}
+		points        []TrackPoint
+		lastTimestamp uint32
+		pos           int
+	)
+	for pos < len(body) {
+		header := body[pos]
+		pos++
+
+		// Compressed timestamp header: selalu data message dengan offset waktu 5 bit
+		if header&0x80 != 0 {
+			local := (header >> 5) & 0x03
+			offset := uint32(header & 0x1f)
+			lastTimestamp += (offset - lastTimestamp&0x1f) & 0x1f
+			def := defs[local]
+			if def == nil {
+				return nil, fmt.Errorf("data message tanpa definisi (local %d)", local)
+			}
+			point, n, err := readFITMessage(body[pos:], def, &lastTimestamp, true)
+			if err != nil {
+				return nil, err
+			}
+			pos += n
+			if point != nil {
+				points = append(points, *point)
+			}
+			continue
+		}
+
+		local := header & 0x0f
+		if header&0x40 != 0 {
+			def, n, err := readFITDefinition(body[pos:], header&0x20 != 0)
+			if err != nil {
+				return nil, err
+			}
+			defs[local] = def
+			pos += n
+			continue
+		}
+
+		def := defs[local]
+		if def == nil {
+			return nil, fmt.Errorf("data message tanpa definisi (local %d)", local)
+		}
+		point, n, err := readFITMessage(body[pos:], def, &lastTimestamp, false)
+		if err != nil {
+			return nil, err
+		}
+		pos += n
+		if point != nil {
+			points = append(points, *point)
+		}
+	}
+	return points, nil
+}
+
+func readFITDefinition(buf []byte, hasDevFields bool) (*fitDefinition, int, error) {
+	if len(buf) < 5 {
+		return nil, 0, errors.New("definition message terpotong")
+	}
+	def := &fitDefinition{bigEndian: buf[1] == 1}
+	if def.bigEndian {
+		def.global = binary.BigEndian.Uint16(buf[2:4])
+	} else {
+		def.global = binary.LittleEndian.Uint16(buf[2:4])
+	}
+	count := int(buf[4])
+	pos := 5
+	if len(buf) < pos+count*3 {
+		return nil, 0, errors.New("definition message terpotong")
+	}
+	for i := 0; i < count; i++ {
+		def.fields = append(def.fields, fitField{num: buf[pos], size: int(buf[pos+1])})
+		pos += 3
+	}
+	if hasDevFields {
+		if len(buf) < pos+1 {
+			return nil, 0, errors.New("definition message terpotong")
+		}
+		devCount := int(buf[pos])
+		pos++
+		if len(buf) < pos+devCount*3 {
+			return nil, 0, errors.New("definition message terpotong")
+		}
+		for i := 0; i < devCount; i++ {
+			def.devSize += int(buf[pos+1])
+			pos += 3
+		}
+	}
+	return def, pos, nil
+}
+
+// readFITMessage membaca satu data message; hanya record yang menghasilkan TrackPoint.
+func readFITMessage(buf []byte, def *fitDefinition, lastTimestamp *uint32, compressed bool) (*TrackPoint, int, error) {
+	size := def.devSize
+	for _, f := range def.fields {
+		size += f.size
+	}
+	if len(buf) < size {
+		return nil, 0, errors.New("data message terpotong")
+	}
+
+	var (
+		point    TrackPoint
+		lat, lon *int32
+		hasTime  = compressed
+		pos      int
+	)
+	for _, f := range def.fields {
+		raw := buf[pos : pos+f.size]
+		pos += f.size
+
+		value, valid := fitUint(raw, def.bigEndian)
+		if !valid {
+			continue
+		}
+		switch {
+		case f.num == fitFieldTimestamp && f.size == 4:
+			*lastTimestamp = uint32(value)
+			hasTime = true
+		case def.global != fitMesgRecord:
+		case f.num == fitFieldLat && f.size == 4:
+			v := int32(value)
+			lat = &v
+		case f.num == fitFieldLon && f.size == 4:
+			v := int32(value)
+			lon = &v
+		case f.num == fitFieldAltitude && f.size == 2 && point.Elevation == nil:
+			e := float64(value)/5 - 500
+			point.Elevation = &e
+		case f.num == fitFieldEnhAlt && f.size == 4:
+			e := float64(value)/5 - 500
+			point.Elevation = &e
+		case f.num == fitFieldDistance && f.size == 4:
+			d := float64(value) / 100
+			point.Distance = &d
+		case f.num == fitFieldHeartRate && f.size == 1:
+			point.HeartRate = int(value)
+		case f.num == fitFieldCadence && f.size == 1:
+			// cadence lari FIT = rpm satu kaki
+			point.Cadence = int(value) * 2
+		}
+	}
+
+	if def.global != fitMesgRecord || !hasTime {
+		return nil, size, nil
+	}
+	point.Time = time.Unix(int64(*lastTimestamp)+fitEpochOffset, 0).UTC()
+	if lat != nil && lon != nil {
+		const semicircle = 180.0 / (1 << 31)
+		point.Lat, point.Lon, point.HasPosition = float64(*lat)*semicircle, float64(*lon)*semicircle, true
+	}
+	return &point, size, nil
+}
+
+// fitUint membaca field 1/2/4 byte; nilai "invalid" FIT (semua bit 1, atau 0x7FFFFFFF
+// untuk sint32) dianggap tidak ada.
+func fitUint(raw []byte, bigEndian bool) (uint64, bool) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+	switch len(raw) {
+	case 1:
+		return uint64(raw[0]), raw[0] != 0xff
+	case 2:
+		v := order.Uint16(raw)
+		return uint64(v), v != 0xffff
+	case 4:
+		v := order.Uint32(raw)
+		return uint64(v), v != 0xffffffff && v != 0x7fffffff
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Struktur GPX 1.1 yang dibaca; ekstensi Garmin TrackPointExtension (hr, cad) dicocokkan
+// berdasarkan nama lokal sehingga prefix namespace apa pun diterima.
+type gpxFile struct {
+	Tracks []struct {
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+type gpxPoint struct {
+	Lat       float64  `xml:"lat,attr"`
+	Lon       float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele"`
+	Time      string   `xml:"time"`
+	HeartRate int      `xml:"extensions>TrackPointExtension>hr"`
+	Cadence   int      `xml:"extensions>TrackPointExtension>cad"`
+}
+
+func parseGPX(data []byte) ([]TrackPoint, error) {
+	var file gpxFile
+	if err := newActivityXMLDecoder(data).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	var points []TrackPoint
+	for _, trk := range file.Tracks {
+		for _, seg := range trk.Segments {
+			for _, p := range seg.Points {
+				points = append(points, TrackPoint{
+					Time:        parseActivityTime(p.Time),
+					Lat:         p.Lat,
+					Lon:         p.Lon,
+					HasPosition: p.Lat != 0 || p.Lon != 0,
+					Elevation:   p.Elevation,
+					HeartRate:   p.HeartRate,
+					// cad di GPX Garmin = rpm satu kaki
+					Cadence: p.Cadence * 2,
+				})
+			}
+		}
+	}
+	return points, nil
+}
+
+func newActivityXMLDecoder(data []byte) *xml.Decoder {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	// Export lama sering mendeklarasikan encoding lain padahal isinya ASCII
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) { return input, nil }
+	return dec
+}
+
+func parseActivityTime(raw string) time.Time {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04:05.000"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC()
+		}
+	}
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC()
+	}
+	return time.Time{}
+}
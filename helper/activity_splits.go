@@ -0,0 +1,123 @@
+package helper
+
+import "math"
+
+// TrackSplit adalah ringkasan satu kilometer rute. Split terakhir bisa lebih pendek.
+type TrackSplit struct {
+	SplitNumber   int
+	DistanceKm    float64
+	MovingTime    int // detik
+	ElevationGain float64
+	ElevationLoss float64
+	AvgHeartRate  int
+	MaxHeartRate  int
+}
+
+const (
+	splitLengthMeters   = 1000.0
+	minFinalSplitMeters = 50.0 // sisa rute yang lebih pendek digabung ke split sebelumnya
+)
+
+// weightedAvg menghitung rata-rata tertimbang waktu (detak jantung, cadence).
+type weightedAvg struct {
+	sum, weight float64
+}
+
+func (w *weightedAvg) add(value, weight float64) {
+	w.sum += value * weight
+	w.weight += weight
+}
+
+func (w weightedAvg) value() int {
+	if w.weight <= 0 {
+		return 0
+	}
+	return int(math.Round(w.sum / w.weight))
+}
+
+// splitBuilder memotong rute per 1 km. Segmen yang melewati batas km dibagi
+// proporsional jarak; kenaikan elevasi dihitung di split tempat titik akhirnya berada.
+type splitBuilder struct {
+	splits  []TrackSplit
+	current splitAccumulator
+}
+
+type splitAccumulator struct {
+	meters, seconds float64
+	gain, loss      float64
+	maxHR           int
+	hr              weightedAvg
+}
+
+func (b *splitBuilder) add(meters, movingSeconds float64, heartRate int, gain, loss float64) {
+	for meters > 0 {
+		room := splitLengthMeters - b.current.meters
+		part := math.Min(meters, room)
+		share := part / meters
+		b.current.meters += part
+		b.current.seconds += movingSeconds * share
+		if heartRate > 0 {
+			b.current.hr.add(float64(heartRate), movingSeconds*share)
+		}
+		if heartRate > b.current.maxHR {
+			b.current.maxHR = heartRate
+		}
+		meters -= part
+		movingSeconds -= movingSeconds * share
+		if b.current.meters >= splitLengthMeters {
+			if meters > 0 {
+				b.flush()
+			} else {
+				// Elevasi titik akhir tepat di batas km masuk split ini
+				b.current.gain += gain
+				b.current.loss += loss
+				gain, loss = 0, 0
+				b.flush()
+			}
+		}
+	}
+	b.current.gain += gain
+	b.current.loss += loss
+}
+
+func (b *splitBuilder) flush() {
+	c := b.current
+	b.splits = append(b.splits, TrackSplit{
+		SplitNumber:   len(b.splits) + 1,
+		DistanceKm:    math.Round(c.meters/10) / 100,
+		MovingTime:    int(math.Round(c.seconds)),
+		ElevationGain: math.Round(c.gain*10) / 10,
+		ElevationLoss: math.Round(c.loss*10) / 10,
+		AvgHeartRate:  c.hr.value(),
+		MaxHeartRate:  c.maxHR,
+	})
+	b.current = splitAccumulator{}
+}
+
+func (b *splitBuilder) finish() []TrackSplit {
+	c := b.current
+	switch {
+	case c.meters >= minFinalSplitMeters:
+		b.flush()
+	case c.meters > 0 && len(b.splits) > 0:
+		last := &b.splits[len(b.splits)-1]
+		lastSeconds := float64(last.MovingTime)
+		last.DistanceKm = math.Round((last.DistanceKm*1000+c.meters)/10) / 100
+		last.MovingTime = int(math.Round(lastSeconds + c.seconds))
+		last.ElevationGain = math.Round((last.ElevationGain+c.gain)*10) / 10
+		last.ElevationLoss = math.Round((last.ElevationLoss+c.loss)*10) / 10
+		if c.hr.weight > 0 {
+			last.AvgHeartRate = weightedAvg{
+				sum:    float64(last.AvgHeartRate)*lastSeconds + c.hr.sum,
+				weight: lastSeconds + c.hr.weight,
+			}.value()
+		}
+		if c.maxHR > last.MaxHeartRate {
+			last.MaxHeartRate = c.maxHR
+		}
+	case c.meters > 0:
+		b.flush()
+	}
+	b.current = splitAccumulator{}
+	return b.splits
+}
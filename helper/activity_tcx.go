@@ -0,0 +1,58 @@
+package helper
+
+// Struktur Garmin TCX v2 yang dibaca. RunCadence ada di ekstensi ActivityExtension (TPX).
+type tcxFile struct {
+	Activities []struct {
+		Laps []struct {
+			Tracks []struct {
+				Points []tcxPoint `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+type tcxPoint struct {
+	Time       string   `xml:"Time"`
+	Lat        *float64 `xml:"Position>LatitudeDegrees"`
+	Lon        *float64 `xml:"Position>LongitudeDegrees"`
+	Elevation  *float64 `xml:"AltitudeMeters"`
+	Distance   *float64 `xml:"DistanceMeters"`
+	HeartRate  int      `xml:"HeartRateBpm>Value"`
+	Cadence    int      `xml:"Cadence"`
+	RunCadence int      `xml:"Extensions>TPX>RunCadence"`
+}
+
+func parseTCX(data []byte) ([]TrackPoint, error) {
+	var file tcxFile
+	if err := newActivityXMLDecoder(data).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	var points []TrackPoint
+	for _, activity := range file.Activities {
+		for _, lap := range activity.Laps {
+			for _, trk := range lap.Tracks {
+				for _, p := range trk.Points {
+					point := TrackPoint{
+						Time:      parseActivityTime(p.Time),
+						Elevation: p.Elevation,
+						Distance:  p.Distance,
+						HeartRate: p.HeartRate,
+					}
+					if p.Lat != nil && p.Lon != nil {
+						point.Lat, point.Lon, point.HasPosition = *p.Lat, *p.Lon, true
+					}
+					// Cadence / RunCadence TCX = rpm satu kaki
+					switch {
+					case p.RunCadence > 0:
+						point.Cadence = p.RunCadence * 2
+					case p.Cadence > 0:
+						point.Cadence = p.Cadence * 2
+					}
+					points = append(points, point)
+				}
+			}
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,33 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// CandidateImpressions mengembalikan jumlah kandidat match yang sudah
+// ditampilkan ke user pada hari tertentu (format tanggal YYYY-MM-DD).
+func (r *RedisHelper) CandidateImpressions(userId, day string) (int, error) {
+	key := fmt.Sprintf("match_impressions:%s:%s", userId, day)
+	count, err := r.Client.Get(r.Ctx, key).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return count, err
+}
+
+// AddCandidateImpressions menambah counter impresi harian; key disimpan 48 jam
+// agar tetap valid di sekitar pergantian hari.
+func (r *RedisHelper) AddCandidateImpressions(userId, day string, n int) (int, error) {
+	key := fmt.Sprintf("match_impressions:%s:%s", userId, day)
+	count, err := r.Client.IncrBy(r.Ctx, key, int64(n)).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == int64(n) {
+		r.Client.Expire(r.Ctx, key, 48*time.Hour)
+	}
+	return int(count), nil
+}
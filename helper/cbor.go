@@ -0,0 +1,175 @@
+package helper
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Decoder CBOR (RFC 8949) minimal untuk kebutuhan WebAuthn: attestation object
+// dan COSE key. Hanya mendukung item dengan panjang definit; WebAuthn mewajibkan
+// authenticator memakai CTAP2 canonical CBOR sehingga indefinite length tidak muncul.
+//
+// Hasil decode: uint64/int64 untuk integer, []byte, string, []interface{},
+// map[interface{}]interface{}, bool dan nil.
+
+const cborMaxDepth = 16
+
+var errCBORTruncated = errors.New("cbor: data terpotong")
+
+// CBORDecode mendecode satu item CBOR di awal data dan mengembalikan jumlah byte
+// yang dipakai, sehingga data setelahnya (misal extensions di authData) bisa dibaca.
+func CBORDecode(data []byte) (interface{}, int, error) {
+	return cborDecode(data, 0)
+}
+
+func cborDecode(data []byte, depth int) (interface{}, int, error) {
+	if depth > cborMaxDepth {
+		return nil, 0, errors.New("cbor: nesting terlalu dalam")
+	}
+	if len(data) == 0 {
+		return nil, 0, errCBORTruncated
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+
+	// Simple values & float (major 7)
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, 1, nil
+		case 21:
+			return true, 1, nil
+		case 22, 23:
+			return nil, 1, nil
+		default:
+			return nil, 0, fmt.Errorf("cbor: simple value %d tidak didukung", info)
+		}
+	}
+
+	arg, n, err := cborArgument(data, info)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch major {
+	case 0: // unsigned int
+		return arg, n, nil
+	case 1: // negative int
+		if arg > 1<<63-1 {
+			return nil, 0, errors.New("cbor: integer negatif terlalu besar")
+		}
+		return -1 - int64(arg), n, nil
+	case 2, 3: // byte string, text string
+		if arg > uint64(len(data)-n) {
+			return nil, 0, errCBORTruncated
+		}
+		end := n + int(arg)
+		if major == 2 {
+			b := make([]byte, arg)
+			copy(b, data[n:end])
+			return b, end, nil
+		}
+		return string(data[n:end]), end, nil
+	case 4: // array
+		if arg > uint64(len(data)) {
+			return nil, 0, errCBORTruncated
+		}
+		items := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			item, used, err := cborDecode(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			n += used
+		}
+		return items, n, nil
+	case 5: // map
+		if arg > uint64(len(data)) {
+			return nil, 0, errCBORTruncated
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, used, err := cborDecode(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += used
+			switch key.(type) {
+			case uint64, int64, string:
+			default:
+				return nil, 0, errors.New("cbor: tipe key map tidak didukung")
+			}
+
+			val, used, err := cborDecode(data[n:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			n += used
+			m[key] = val
+		}
+		return m, n, nil
+	case 6: // tag: abaikan tag, ambil isinya
+		item, used, err := cborDecode(data[n:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		return item, n + used, nil
+	}
+
+	return nil, 0, fmt.Errorf("cbor: major type %d tidak didukung", major)
+}
+
+func cborArgument(data []byte, info byte) (uint64, int, error) {
+	switch {
+	case info < 24:
+		return uint64(info), 1, nil
+	case info == 24:
+		if len(data) < 2 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(data[1]), 2, nil
+	case info == 25:
+		if len(data) < 3 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data[1:3])), 3, nil
+	case info == 26:
+		if len(data) < 5 {
+			return 0, 0, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data[1:5])), 5, nil
+	case info == 27:
+		if len(data) < 9 {
+			return 0, 0, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data[1:9]), 9, nil
+	}
+	return 0, 0, errors.New("cbor: indefinite length tidak didukung")
+}
+
+// cborInt membaca integer CBOR (positif atau negatif) sebagai int64.
+func cborInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case uint64:
+		if n > 1<<63-1 {
+			return 0, false
+		}
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+	return 0, false
+}
+
+// cborMapGet mengambil value map CBOR dengan key integer (COSE memakai key negatif).
+func cborMapGet(m map[interface{}]interface{}, key int64) (interface{}, bool) {
+	if key >= 0 {
+		v, ok := m[uint64(key)]
+		return v, ok
+	}
+	v, ok := m[key]
+	return v, ok
+}
@@ -0,0 +1,55 @@
+package helper
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen dikembalikan saat circuit breaker sedang terbuka.
+var ErrCircuitOpen = errors.New("circuit breaker terbuka, layanan sedang tidak tersedia")
+
+// CircuitBreaker memutus panggilan ke layanan eksternal setelah threshold kegagalan
+// beruntun, lalu mengizinkan percobaan lagi setelah cooldown (half-open).
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow mengembalikan ErrCircuitOpen selama cooldown masih berjalan.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Success menutup kembali circuit.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// Failure mencatat kegagalan; saat half-open satu kegagalan langsung membuka circuit lagi.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
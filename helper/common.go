@@ -1,32 +1,33 @@
 package helper
 
 import (
-	"context"
+	crand "crypto/rand"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
-	"fmt"
 	"log"
-	"math/rand"
-	"net/url"
-	"os"
-	"path/filepath"
+	"math/big"
+	"net"
 	"regexp"
-	"slices"
 	"strings"
+	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"golang.org/x/crypto/bcrypt"
 )
 
 var emailRegex = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
 
-// GenerateOTPCode generates a random OTP code of specified length
+// GenerateOTPCode generates a random OTP code of specified length using crypto/rand
 func GenerateOTPCode(length int) string {
 	digits := "0123456789"
+	max := big.NewInt(int64(len(digits)))
 	code := make([]byte, length)
 	for i := range code {
-		code[i] = digits[rand.Intn(len(digits))]
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			panic("Failed to generate OTP code")
+		}
+		code[i] = digits[n.Int64()]
 	}
 	return string(code)
 }
@@ -37,43 +38,24 @@ func HashOTP(otp string) string {
 	return hex.EncodeToString(hash[:])
 }
 
-// ExtractPublicIDFromURL extracts Cloudinary public ID from a URL
-func ExtractPublicIDFromURL(rawURL string) (string, error) {
-	parsed, err := url.Parse(rawURL)
-	if err != nil {
-		return "", err
-	}
-
-	parts := strings.Split(parsed.Path, "/")
-	if len(parts) < 3 {
-		return "", fmt.Errorf("url path tidak valid")
-	}
-
-	index := slices.Index(parts, "upload")
-	if index == -1 || index+1 >= len(parts) {
-		return "", fmt.Errorf("url tidak mengandung /upload/")
-	}
+// HashOTPFor hashes an OTP bound to its purpose and identifier, so a code issued
+// for one flow or account can never be accepted by another
+func HashOTPFor(purpose, identifier, otp string) string {
+	return HashOTP(purpose + ":" + identifier + ":" + otp)
+}
 
-	publicID := strings.Join(parts[index+1:], "/")
-	publicID = strings.TrimSuffix(publicID, filepath.Ext(publicID))
-	return publicID, nil
+// CompareOTPHash compares two OTP/token hashes in constant time
+func CompareOTPHash(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
 
-// DeleteFromCloudinary deletes an asset from Cloudinary by public ID
-func DeleteFromCloudinary(publicID string) error {
-	cld, err := cloudinary.NewFromParams(
-		os.Getenv("CLD_NAME"),
-		os.Getenv("CLD_API_KEY"),
-		os.Getenv("CLD_API_SECRET"),
-	)
-	if err != nil {
-		return err
+// GenerateSecureToken generates a random hex token from n bytes of crypto/rand
+func GenerateSecureToken(n int) string {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		panic("Failed to generate secure token")
 	}
-
-	_, err = cld.Upload.Destroy(context.Background(), uploader.DestroyParams{
-		PublicID: publicID,
-	})
-	return err
+	return hex.EncodeToString(b)
 }
 
 // LowerStringPtr converts a string pointer to lowercase
@@ -123,3 +105,50 @@ func HashPassword(password string) string {
 func IsEmail(input string) bool {
 	return emailRegex.MatchString(input)
 }
+
+// ClientLocation membuat kunci lokasi kasar untuk deteksi login tidak biasa.
+// Kode negara dari CDN (misal header CF-IPCountry) dipakai jika ada; jika tidak,
+// dipakai prefix jaringan IP (/16 untuk IPv4, /32 untuk IPv6) agar pergantian
+// IP dalam satu jaringan operator tidak dianggap lokasi baru.
+func ClientLocation(ip, country string) string {
+	if country = strings.ToUpper(strings.TrimSpace(country)); country != "" && country != "XX" {
+		return "country:" + country
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return "net:" + (&net.IPNet{IP: v4.Mask(net.CIDRMask(16, 32)), Mask: net.CIDRMask(16, 32)}).String()
+	}
+	return "net:" + (&net.IPNet{IP: parsed.Mask(net.CIDRMask(32, 128)), Mask: net.CIDRMask(32, 128)}).String()
+}
+
+// NormalizePage mengembalikan page (>= 1) dan limit (default 20, maksimal 100).
+func NormalizePage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	return page, limit
+}
+
+// JakartaLocation adalah zona waktu aplikasi (WIB); fallback UTC+7 bila tzdata tidak tersedia.
+var JakartaLocation = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}()
+
+// JakartaDate mengembalikan tanggal (YYYY-MM-DD) waktu t di zona WIB.
+func JakartaDate(t time.Time) string {
+	return t.In(JakartaLocation).Format("2006-01-02")
+}
@@ -0,0 +1,267 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mockConnectorPageSize = 10
+	mockTokenLifetime     = time.Hour
+)
+
+// MockConnectorProvider adalah provider offline untuk development & testing. Halaman
+// otorisasi langsung redirect ke callback dengan code "mock:<athlete id>", dan setiap
+// atlet punya riwayat lari deterministik (sekitar 2 dari 3 hari, sesekali bersepeda).
+//
+// Webhook: POST {"owner_id":"1001","object_id":"1001-20261019","aspect_type":"create"}
+// dengan header X-Mock-Signature = hex(HMAC-SHA256(body, CONNECTOR_MOCK_WEBHOOK_SECRET));
+// aspect_type: create | update | delete | deauthorize. Webhook yang valid juga menerapkan
+// perubahannya di sisi "provider" (aktivitas terhapus, token atlet dicabut) agar konfirmasi
+// lewat API berperilaku seperti provider sungguhan. Kuota API disimulasikan per menit.
+type MockConnectorProvider struct {
+	webhookSecret string
+	rateLimit     int // request per menit, 0 = tanpa batas
+
+	mu           sync.Mutex
+	windowStart  time.Time
+	used         int
+	deleted      map[string]bool // external id aktivitas yang dihapus lewat webhook
+	deauthorized map[string]bool // atlet yang mencabut akses lewat webhook
+}
+
+func NewMockConnectorProvider(webhookSecret string, rateLimit int) *MockConnectorProvider {
+	return &MockConnectorProvider{
+		webhookSecret: webhookSecret,
+		rateLimit:     rateLimit,
+		deleted:       make(map[string]bool),
+		deauthorized:  make(map[string]bool),
+	}
+}
+
+func (p *MockConnectorProvider) Name() string { return "mock" }
+
+// AuthorizeURL melewati halaman persetujuan; athlete id diturunkan dari state dan
+// bisa diganti dengan mengubah parameter code sebelum membuka URL.
+func (p *MockConnectorProvider) AuthorizeURL(state, redirectURI string) string {
+	athlete := 1000 + mockHash(state)%9000
+	q := url.Values{"code": {fmt.Sprintf("mock:%d", athlete)}, "state": {state}, "scope": {"activity:read_all"}}
+	return redirectURI + "?" + q.Encode()
+}
+
+func (p *MockConnectorProvider) ExchangeCode(code, redirectURI string) (OAuthToken, error) {
+	athlete, ok := strings.CutPrefix(code, "mock:")
+	if !ok || !isMockAthlete(athlete) {
+		return OAuthToken{}, ErrConnectorAuthFailed
+	}
+	p.mu.Lock()
+	delete(p.deauthorized, athlete)
+	p.mu.Unlock()
+	token := p.issueToken(athlete)
+	token.ExternalUserId = athlete
+	return token, nil
+}
+
+func (p *MockConnectorProvider) RefreshToken(refreshToken string) (OAuthToken, error) {
+	athlete, ok := strings.CutPrefix(refreshToken, "mock-refresh.")
+	if !ok || !isMockAthlete(athlete) || p.isDeauthorized(athlete) {
+		return OAuthToken{}, ErrConnectorAuthFailed
+	}
+	return p.issueToken(athlete), nil
+}
+
+func (p *MockConnectorProvider) issueToken(athlete string) OAuthToken {
+	expiresAt := time.Now().Add(mockTokenLifetime)
+	return OAuthToken{
+		AccessToken:  fmt.Sprintf("mock-access.%s.%d", athlete, expiresAt.Unix()),
+		RefreshToken: "mock-refresh." + athlete,
+		ExpiresAt:    expiresAt,
+		Scope:        "activity:read_all",
+	}
+}
+
+func (p *MockConnectorProvider) Revoke(accessToken string) error { return nil }
+
+func (p *MockConnectorProvider) ListActivities(accessToken string, after time.Time, page int) ([]ProviderActivity, error) {
+	athlete, err := p.authorize(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(JakartaLocation)
+	var all []ProviderActivity
+	for day := after.In(JakartaLocation); !day.After(now); day = day.AddDate(0, 0, 1) {
+		a, scheduled := mockActivity(athlete, day)
+		if scheduled && a.StartTime.After(after) && a.StartTime.Before(now) && !p.isDeleted(a.ExternalId) {
+			all = append(all, a)
+		}
+	}
+
+	start := (page - 1) * mockConnectorPageSize
+	if page < 1 || start >= len(all) {
+		return []ProviderActivity{}, nil
+	}
+	return all[start:min(start+mockConnectorPageSize, len(all))], nil
+}
+
+// GetActivity mengembalikan aktivitas untuk id "<athlete>-<YYYYMMDD>" meskipun hari itu
+// tidak ada di riwayat terjadwal, sehingga webhook create bisa dicoba untuk tanggal apa saja.
+func (p *MockConnectorProvider) GetActivity(accessToken, externalId string) (*ProviderActivity, error) {
+	athlete, err := p.authorize(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	owner, date, ok := strings.Cut(externalId, "-")
+	day, parseErr := time.ParseInLocation("20060102", date, JakartaLocation)
+	if !ok || owner != athlete || parseErr != nil || day.After(time.Now()) || p.isDeleted(externalId) {
+		return nil, ErrConnectorActivityNotFound
+	}
+	a, _ := mockActivity(athlete, day)
+	return &a, nil
+}
+
+func (p *MockConnectorProvider) VerifySubscription(query url.Values) (interface{}, error) {
+	if query.Get("hub.mode") != "subscribe" || query.Get("hub.verify_token") != p.webhookSecret {
+		return nil, ErrConnectorWebhookInvalid
+	}
+	return map[string]string{"hub.challenge": query.Get("hub.challenge")}, nil
+}
+
+func (p *MockConnectorProvider) ParseWebhook(header http.Header, body []byte) ([]WebhookEvent, error) {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write(body)
+	signature, err := hex.DecodeString(header.Get("X-Mock-Signature"))
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrConnectorWebhookInvalid
+	}
+
+	var e struct {
+		OwnerId    string `json:"owner_id"`
+		ObjectId   string `json:"object_id"`
+		AspectType string `json:"aspect_type"`
+	}
+	if err := json.Unmarshal(body, &e); err != nil || !isMockAthlete(e.OwnerId) {
+		return nil, ErrConnectorWebhookInvalid
+	}
+	switch e.AspectType {
+	case WebhookDeauthorize:
+		p.mu.Lock()
+		p.deauthorized[e.OwnerId] = true
+		p.mu.Unlock()
+		return []WebhookEvent{{ExternalUserId: e.OwnerId, Action: WebhookDeauthorize}}, nil
+	case WebhookActivityCreate, WebhookActivityUpdate, WebhookActivityDelete:
+		if e.ObjectId == "" {
+			return nil, ErrConnectorWebhookInvalid
+		}
+		p.mu.Lock()
+		if e.AspectType == WebhookActivityDelete {
+			p.deleted[e.ObjectId] = true
+		} else {
+			delete(p.deleted, e.ObjectId)
+		}
+		p.mu.Unlock()
+		return []WebhookEvent{{ExternalUserId: e.OwnerId, ExternalActivityId: e.ObjectId, Action: e.AspectType}}, nil
+	}
+	return nil, ErrConnectorWebhookInvalid
+}
+
+// authorize memeriksa kuota per menit lalu access token "mock-access.<athlete>.<expiry unix>".
+func (p *MockConnectorProvider) authorize(accessToken string) (string, error) {
+	if err := p.consumeQuota(); err != nil {
+		return "", err
+	}
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 || parts[0] != "mock-access" || !isMockAthlete(parts[1]) || p.isDeauthorized(parts[1]) {
+		return "", ErrConnectorAuthFailed
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() >= expiry {
+		return "", ErrConnectorAuthFailed
+	}
+	return parts[1], nil
+}
+
+func (p *MockConnectorProvider) isDeleted(externalId string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.deleted[externalId]
+}
+
+func (p *MockConnectorProvider) isDeauthorized(athlete string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.deauthorized[athlete]
+}
+
+func (p *MockConnectorProvider) consumeQuota() error {
+	if p.rateLimit <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(p.windowStart) >= time.Minute {
+		p.windowStart, p.used = now, 0
+	}
+	if p.used >= p.rateLimit {
+		return &RateLimitError{Provider: p.Name(), RetryAfter: p.windowStart.Add(time.Minute).Sub(now)}
+	}
+	p.used++
+	return nil
+}
+
+// mockActivity membangkitkan aktivitas deterministik atlet pada tanggal day (WIB);
+// scheduled=false berarti hari itu tidak ada di riwayat.
+func mockActivity(athlete string, day time.Time) (ProviderActivity, bool) {
+	date := day.In(JakartaLocation).Format("20060102")
+	seed := mockHash(athlete + date)
+	y, m, d := day.In(JakartaLocation).Date()
+
+	distance := 3 + float64(seed%1200)/100
+	pace := 4.8 + float64((seed>>8)%220)/100
+	moving := int(math.Round(distance * pace * 60))
+	sport := "Run"
+	if seed%11 == 0 {
+		sport = "Ride"
+	}
+	avgHR := 135 + int(seed%30)
+
+	return ProviderActivity{
+		ExternalId:    athlete + "-" + date,
+		Sport:         sport,
+		IsRun:         sport == "Run",
+		StartTime:     time.Date(y, m, d, 6, int((seed>>4)%90), 0, 0, JakartaLocation),
+		Distance:      distance,
+		MovingTime:    moving,
+		ElapsedTime:   moving + int((seed>>16)%300),
+		ElevationGain: float64(seed % 80),
+		AvgHeartRate:  avgHR,
+		MaxHeartRate:  avgHR + 15 + int(seed%10),
+		AvgCadence:    160 + int(seed%20),
+		Calories:      int(distance * 62),
+		MaxSpeedKmh:   math.Round(60/pace*1.25*10) / 10,
+	}, seed%3 != 0
+}
+
+func mockHash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+func isMockAthlete(id string) bool {
+	n, err := strconv.Atoi(id)
+	return err == nil && n > 0
+}
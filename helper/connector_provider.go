@@ -0,0 +1,131 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrConnectorAuthFailed: kode otorisasi tidak valid, atau token dicabut / kedaluwarsa di sisi provider.
+	ErrConnectorAuthFailed = errors.New("otorisasi ke provider gagal")
+	// ErrConnectorUnavailable: provider tidak bisa dihubungi atau mengembalikan 5xx.
+	ErrConnectorUnavailable = errors.New("provider aktivitas sedang tidak tersedia")
+	// ErrConnectorRateLimited dibungkus RateLimitError; cocokkan dengan errors.Is.
+	ErrConnectorRateLimited = errors.New("batas rate limit provider tercapai")
+	// ErrConnectorActivityNotFound: aktivitas dihapus, privat, atau bukan milik akun tersebut.
+	ErrConnectorActivityNotFound = errors.New("aktivitas tidak ditemukan di provider")
+	// ErrConnectorWebhookInvalid: signature / verify token salah atau payload tidak dikenal.
+	ErrConnectorWebhookInvalid = errors.New("webhook provider tidak valid")
+)
+
+// RateLimitError dikembalikan saat kuota API provider habis (atau hampir habis);
+// pemanggil sebaiknya menunda request berikutnya selama RetryAfter.
+type RateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %v, coba lagi dalam %s", e.Provider, ErrConnectorRateLimited, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Is(target error) bool { return target == ErrConnectorRateLimited }
+
+// Aksi event webhook yang sudah dinormalisasi
+const (
+	WebhookActivityCreate = "create"
+	WebhookActivityUpdate = "update"
+	WebhookActivityDelete = "delete"
+	WebhookDeauthorize    = "deauthorize"
+)
+
+// OAuthToken adalah hasil tukar kode / refresh token OAuth2.
+type OAuthToken struct {
+	AccessToken    string
+	RefreshToken   string
+	ExpiresAt      time.Time
+	Scope          string
+	ExternalUserId string // id atlet di provider, kosong saat refresh
+}
+
+// ProviderActivity adalah aktivitas dari provider dalam satuan RunActivity.
+type ProviderActivity struct {
+	ExternalId    string
+	Sport         string // jenis olahraga menurut provider (Run, TrailRun, Ride, ...)
+	IsRun         bool   // hanya lari yang disinkronkan
+	WorkoutType   string // race / long_run / interval, kosong = tidak diketahui
+	StartTime     time.Time
+	Distance      float64 // km
+	MovingTime    int     // detik
+	ElapsedTime   int     // detik
+	ElevationGain float64 // meter
+	AvgHeartRate  int
+	MaxHeartRate  int
+	AvgCadence    int // langkah per menit
+	Calories      int
+	MaxSpeedKmh   float64 // rata-rata tertinggi ~30 detik; 0 bila provider hanya punya kecepatan sesaat
+	Polyline      string
+}
+
+// WebhookEvent adalah event webhook provider yang sudah dinormalisasi.
+type WebhookEvent struct {
+	ExternalUserId     string
+	ExternalActivityId string // kosong untuk deauthorize
+	Action             string // WebhookActivityCreate, ...
+}
+
+// ConnectorProvider adalah integrasi satu layanan aktivitas pihak ketiga
+// (Strava, Garmin, ...) dengan OAuth2 dan webhook.
+type ConnectorProvider interface {
+	Name() string
+	// AuthorizeURL adalah halaman persetujuan OAuth2 yang dibuka user.
+	AuthorizeURL(state, redirectURI string) string
+	ExchangeCode(code, redirectURI string) (OAuthToken, error)
+	RefreshToken(refreshToken string) (OAuthToken, error)
+	// Revoke mencabut akses aplikasi di sisi provider (best effort saat unlink).
+	Revoke(accessToken string) error
+	// ListActivities mengembalikan aktivitas yang dimulai setelah after, urut dari yang
+	// terlama. page dimulai dari 1; slice kosong berarti halaman sudah habis.
+	ListActivities(accessToken string, after time.Time, page int) ([]ProviderActivity, error)
+	GetActivity(accessToken, externalId string) (*ProviderActivity, error)
+	// VerifySubscription menjawab handshake pendaftaran webhook (GET), hasilnya dikirim sebagai JSON.
+	VerifySubscription(query url.Values) (interface{}, error)
+	// ParseWebhook memvalidasi dan mengurai event webhook (POST).
+	ParseWebhook(header http.Header, body []byte) ([]WebhookEvent, error)
+}
+
+// NewConnectorProvidersFromEnv mengaktifkan provider yang dikonfigurasi:
+// strava bila STRAVA_CLIENT_ID dan STRAVA_CLIENT_SECRET diisi, dan mock (offline,
+// deterministik) saat GIN_MODE=debug/test kecuali CONNECTOR_MOCK_ENABLED=false.
+// Strava wajib punya STRAVA_SUBSCRIPTION_ID karena event webhook-nya tidak ditandatangani.
+func NewConnectorProvidersFromEnv() map[string]ConnectorProvider {
+	providers := make(map[string]ConnectorProvider)
+
+	if id, secret := os.Getenv("STRAVA_CLIENT_ID"), os.Getenv("STRAVA_CLIENT_SECRET"); id != "" && secret != "" {
+		subId := os.Getenv("STRAVA_SUBSCRIPTION_ID")
+		if n, err := strconv.ParseInt(subId, 10, 64); err != nil || n <= 0 {
+			log.Fatalf("❌ STRAVA_SUBSCRIPTION_ID wajib diisi (angka) bila connector Strava diaktifkan")
+		}
+		providers["strava"] = NewStravaProvider(id, secret, os.Getenv("STRAVA_WEBHOOK_VERIFY_TOKEN"), subId)
+	}
+
+	mode := os.Getenv("GIN_MODE")
+	if (mode == "debug" || mode == "test") && os.Getenv("CONNECTOR_MOCK_ENABLED") != "false" {
+		secret := os.Getenv("CONNECTOR_MOCK_WEBHOOK_SECRET")
+		if secret == "" {
+			secret = "mock-secret"
+		}
+		providers["mock"] = NewMockConnectorProvider(secret, envInt("CONNECTOR_MOCK_RATE_LIMIT", 30))
+	}
+
+	for name := range providers {
+		log.Printf("✅ Connector provider: %s", name)
+	}
+	return providers
+}
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SaveConnectorState menyimpan state OAuth2 connector sampai provider redirect ke callback.
+func (r *RedisHelper) SaveConnectorState(state string, data interface{}, ttl time.Duration) error {
+	return SetJSONToRedis(r.Ctx, r.Client, "connector_state:"+state, data, ttl)
+}
+
+// TakeConnectorState mengambil lalu menghapus state OAuth2 (GETDEL) sehingga callback
+// tidak bisa diulang; found=false bila state tidak dikenal atau kedaluwarsa.
+func (r *RedisHelper) TakeConnectorState(state string, dest interface{}) (found bool, err error) {
+	val, err := r.Client.GetDel(r.Ctx, "connector_state:"+state).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(val), dest)
+}
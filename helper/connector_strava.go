@@ -0,0 +1,329 @@
+package helper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	stravaBaseURL  = "https://www.strava.com"
+	stravaPageSize = 50
+	// Sisakan sebagian kuota 15 menit untuk webhook saat backfill berjalan
+	stravaRateLimitReserve = 0.9
+)
+
+// StravaProvider adalah connector Strava API v3 (OAuth2 + webhook push subscription).
+// Kuota API dibaca dari header X-RateLimit-*; setelah kuota 15 menit / harian hampir
+// habis, request ditahan sampai jendela berikutnya.
+type StravaProvider struct {
+	clientId       string
+	clientSecret   string
+	verifyToken    string
+	subscriptionId int64 // 0 = tidak dicek
+	baseURL        string
+	client         *http.Client
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+func NewStravaProvider(clientId, clientSecret, verifyToken, subscriptionId string) *StravaProvider {
+	subId, _ := strconv.ParseInt(subscriptionId, 10, 64)
+	return &StravaProvider{
+		clientId:       clientId,
+		clientSecret:   clientSecret,
+		verifyToken:    verifyToken,
+		subscriptionId: subId,
+		baseURL:        stravaBaseURL,
+		client:         &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+func (p *StravaProvider) Name() string { return "strava" }
+
+func (p *StravaProvider) AuthorizeURL(state, redirectURI string) string {
+	q := url.Values{
+		"client_id":       {p.clientId},
+		"redirect_uri":    {redirectURI},
+		"response_type":   {"code"},
+		"approval_prompt": {"auto"},
+		"scope":           {"read,activity:read_all"},
+		"state":           {state},
+	}
+	return p.baseURL + "/oauth/authorize?" + q.Encode()
+}
+
+type stravaTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresAt    int64  `json:"expires_at"`
+	Athlete      *struct {
+		Id int64 `json:"id"`
+	} `json:"athlete"`
+}
+
+func (p *StravaProvider) ExchangeCode(code, redirectURI string) (OAuthToken, error) {
+	return p.token(url.Values{"code": {code}, "grant_type": {"authorization_code"}})
+}
+
+func (p *StravaProvider) RefreshToken(refreshToken string) (OAuthToken, error) {
+	return p.token(url.Values{"refresh_token": {refreshToken}, "grant_type": {"refresh_token"}})
+}
+
+func (p *StravaProvider) token(form url.Values) (OAuthToken, error) {
+	form.Set("client_id", p.clientId)
+	form.Set("client_secret", p.clientSecret)
+
+	var res stravaTokenResponse
+	if err := p.do(http.MethodPost, "/oauth/token", "", form, &res); err != nil {
+		return OAuthToken{}, err
+	}
+	token := OAuthToken{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+		ExpiresAt:    time.Unix(res.ExpiresAt, 0),
+	}
+	if res.Athlete != nil {
+		token.ExternalUserId = strconv.FormatInt(res.Athlete.Id, 10)
+	}
+	return token, nil
+}
+
+func (p *StravaProvider) Revoke(accessToken string) error {
+	return p.do(http.MethodPost, "/oauth/deauthorize", "", url.Values{"access_token": {accessToken}}, nil)
+}
+
+type stravaActivity struct {
+	Id                 int64   `json:"id"`
+	SportType          string  `json:"sport_type"`
+	Type               string  `json:"type"`
+	WorkoutType        *int    `json:"workout_type"`
+	StartDate          string  `json:"start_date"`
+	Distance           float64 `json:"distance"` // meter
+	MovingTime         int     `json:"moving_time"`
+	ElapsedTime        int     `json:"elapsed_time"`
+	TotalElevationGain float64 `json:"total_elevation_gain"`
+	AverageHeartrate   float64 `json:"average_heartrate"`
+	MaxHeartrate       float64 `json:"max_heartrate"`
+	AverageCadence     float64 `json:"average_cadence"` // per kaki
+	Calories           float64 `json:"calories"`        // hanya di detail aktivitas
+	Map                struct {
+		Polyline        string `json:"polyline"`
+		SummaryPolyline string `json:"summary_polyline"`
+	} `json:"map"`
+}
+
+func (p *StravaProvider) ListActivities(accessToken string, after time.Time, page int) ([]ProviderActivity, error) {
+	q := url.Values{
+		"after":    {strconv.FormatInt(after.Unix(), 10)},
+		"page":     {strconv.Itoa(page)},
+		"per_page": {strconv.Itoa(stravaPageSize)},
+	}
+	var raw []stravaActivity
+	if err := p.do(http.MethodGet, "/api/v3/athlete/activities?"+q.Encode(), accessToken, nil, &raw); err != nil {
+		return nil, err
+	}
+	activities := make([]ProviderActivity, 0, len(raw))
+	for _, a := range raw {
+		activities = append(activities, a.toProviderActivity())
+	}
+	return activities, nil
+}
+
+func (p *StravaProvider) GetActivity(accessToken, externalId string) (*ProviderActivity, error) {
+	var raw stravaActivity
+	if err := p.do(http.MethodGet, "/api/v3/activities/"+url.PathEscape(externalId), accessToken, nil, &raw); err != nil {
+		return nil, err
+	}
+	a := raw.toProviderActivity()
+	return &a, nil
+}
+
+func (a stravaActivity) toProviderActivity() ProviderActivity {
+	sport := a.SportType
+	if sport == "" {
+		sport = a.Type
+	}
+	start, _ := time.Parse(time.RFC3339, a.StartDate)
+	polyline := a.Map.Polyline
+	if polyline == "" {
+		polyline = a.Map.SummaryPolyline
+	}
+
+	activity := ProviderActivity{
+		ExternalId:    strconv.FormatInt(a.Id, 10),
+		Sport:         sport,
+		IsRun:         sport == "Run" || sport == "TrailRun" || sport == "VirtualRun",
+		StartTime:     start,
+		Distance:      math.Round(a.Distance) / 1000,
+		MovingTime:    a.MovingTime,
+		ElapsedTime:   a.ElapsedTime,
+		ElevationGain: math.Round(a.TotalElevationGain*10) / 10,
+		AvgHeartRate:  int(math.Round(a.AverageHeartrate)),
+		MaxHeartRate:  int(math.Round(a.MaxHeartrate)),
+		AvgCadence:    int(math.Round(a.AverageCadence * 2)),
+		Calories:      int(math.Round(a.Calories)),
+		Polyline:      polyline,
+	}
+	// workout_type lari: 0 default, 1 race, 2 long run, 3 workout
+	if a.WorkoutType != nil {
+		switch *a.WorkoutType {
+		case 1:
+			activity.WorkoutType = "race"
+		case 2:
+			activity.WorkoutType = "long_run"
+		case 3:
+			activity.WorkoutType = "interval"
+		}
+	}
+	return activity
+}
+
+func (p *StravaProvider) VerifySubscription(query url.Values) (interface{}, error) {
+	if query.Get("hub.mode") != "subscribe" || p.verifyToken == "" || query.Get("hub.verify_token") != p.verifyToken {
+		return nil, ErrConnectorWebhookInvalid
+	}
+	return map[string]string{"hub.challenge": query.Get("hub.challenge")}, nil
+}
+
+type stravaWebhookEvent struct {
+	ObjectType     string            `json:"object_type"` // activity | athlete
+	ObjectId       int64             `json:"object_id"`
+	AspectType     string            `json:"aspect_type"` // create | update | delete
+	OwnerId        int64             `json:"owner_id"`
+	SubscriptionId int64             `json:"subscription_id"`
+	Updates        map[string]string `json:"updates"`
+}
+
+// ParseWebhook: Strava tidak menandatangani event, sehingga subscription_id wajib sama
+// dengan STRAVA_SUBSCRIPTION_ID; isi aktivitas selalu diambil ulang lewat API.
+func (p *StravaProvider) ParseWebhook(header http.Header, body []byte) ([]WebhookEvent, error) {
+	var e stravaWebhookEvent
+	if err := json.Unmarshal(body, &e); err != nil || e.OwnerId == 0 {
+		return nil, ErrConnectorWebhookInvalid
+	}
+	if p.subscriptionId == 0 || e.SubscriptionId != p.subscriptionId {
+		return nil, ErrConnectorWebhookInvalid
+	}
+
+	event := WebhookEvent{ExternalUserId: strconv.FormatInt(e.OwnerId, 10)}
+	switch {
+	case e.ObjectType == "athlete" && e.Updates["authorized"] == "false":
+		event.Action = WebhookDeauthorize
+	case e.ObjectType == "activity" && (e.AspectType == "create" || e.AspectType == "update" || e.AspectType == "delete"):
+		event.Action = e.AspectType
+		event.ExternalActivityId = strconv.FormatInt(e.ObjectId, 10)
+	default:
+		return nil, nil // event lain (mis. ganti nama atlet) diabaikan
+	}
+	return []WebhookEvent{event}, nil
+}
+
+// do mengirim request ke Strava; form != nil dikirim sebagai application/x-www-form-urlencoded.
+func (p *StravaProvider) do(method, path, accessToken string, form url.Values, dest interface{}) error {
+	if wait := p.waitTime(); wait > 0 {
+		return &RateLimitError{Provider: p.Name(), RetryAfter: wait}
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, p.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrConnectorUnavailable, err)
+	}
+	defer resp.Body.Close()
+	p.trackRateLimit(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{Provider: p.Name(), RetryAfter: p.waitTime()}
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrConnectorAuthFailed
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrConnectorActivityNotFound
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("%w: strava status %d", ErrConnectorUnavailable, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if method == http.MethodPost && strings.HasPrefix(path, "/oauth/") {
+			return fmt.Errorf("%w: %s", ErrConnectorAuthFailed, strings.TrimSpace(string(msg)))
+		}
+		return fmt.Errorf("strava status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if dest == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("respon strava tidak valid: %w", err)
+	}
+	return nil
+}
+
+func (p *StravaProvider) waitTime() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Until(p.blockedUntil)
+}
+
+// trackRateLimit membaca kuota "15 menit,harian" dari header respons. Header read
+// (X-ReadRateLimit-*) diutamakan karena sinkronisasi hanya membaca data.
+func (p *StravaProvider) trackRateLimit(resp *http.Response) {
+	limit, usage := resp.Header.Get("X-ReadRateLimit-Limit"), resp.Header.Get("X-ReadRateLimit-Usage")
+	if limit == "" {
+		limit, usage = resp.Header.Get("X-RateLimit-Limit"), resp.Header.Get("X-RateLimit-Usage")
+	}
+	limits, usages := parseRateLimitPair(limit), parseRateLimitPair(usage)
+
+	now := time.Now().UTC()
+	var until time.Time
+	// Jendela 15 menit Strava dimulai pada menit 0, 15, 30 dan 45
+	if limits[0] > 0 && (usages[0] >= limits[0]*stravaRateLimitReserve || resp.StatusCode == http.StatusTooManyRequests) {
+		until = now.Truncate(15 * time.Minute).Add(15 * time.Minute)
+	}
+	// Kuota harian direset tengah malam UTC
+	if limits[1] > 0 && usages[1] >= limits[1] {
+		until = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	if until.IsZero() && resp.StatusCode == http.StatusTooManyRequests {
+		until = now.Add(15 * time.Minute)
+	}
+	if until.IsZero() {
+		return
+	}
+
+	p.mu.Lock()
+	if until.After(p.blockedUntil) {
+		p.blockedUntil = until
+	}
+	p.mu.Unlock()
+}
+
+func parseRateLimitPair(header string) [2]float64 {
+	var pair [2]float64
+	for i, part := range strings.SplitN(header, ",", 2) {
+		pair[i], _ = strconv.ParseFloat(strings.TrimSpace(part), 64)
+	}
+	return pair
+}
@@ -1,133 +1,258 @@
 package helper
 
 import (
-	"fmt"
+	"bytes"
+	"html/template"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 
 	"gopkg.in/gomail.v2"
 )
 
-func BuildVerificationEmail(recipientEmail string, token string) (subject string, bodyPlain string, bodyHTML string) {
-	subject = "Kode Verifikasi Email Anda - Loka Kasir"
-
-	bodyPlain = fmt.Sprintf(`Halo,
-
-Terima kasih telah mendaftar di Loka Kasir.
-
-Berikut adalah kode verifikasi email Anda:
-
-KODE VERIFIKASI: %s
-
-Silakan masukkan kode ini di aplikasi Loka Kasir untuk menyelesaikan proses verifikasi email Anda.
+const (
+	EmailBrand           = "Run-Sync"
+	DefaultEmailLanguage = "id"
+)
 
-Jika Anda tidak merasa melakukan pendaftaran, abaikan email ini.
+// emailCopy berisi teks satu jenis email dalam satu bahasa. Placeholder {brand}
+// dan {new_email} diganti saat email dibuat. Code dirender di antara Before dan After.
+type emailCopy struct {
+	Subject   string
+	Title     string
+	Greeting  string
+	Before    []string
+	CodeLabel string
+	After     []string
+	Signoff   string
+}
 
-Hormat kami,
-Tim Loka Kasir`, token)
+// emailCopies: jenis email -> bahasa -> teks. Tambahkan bahasa baru di sini.
+var emailCopies = map[string]map[string]emailCopy{
+	"verification": {
+		"id": {
+			Subject:   "Kode Verifikasi Email Anda - {brand}",
+			Title:     "Kode Verifikasi",
+			Greeting:  "Halo,",
+			Before:    []string{"Terima kasih telah mendaftar di {brand}.", "Berikut adalah kode verifikasi email Anda:"},
+			CodeLabel: "KODE VERIFIKASI",
+			After:     []string{"Silakan masukkan kode ini di aplikasi {brand} untuk menyelesaikan proses verifikasi email Anda.", "Jika Anda tidak merasa melakukan pendaftaran, abaikan email ini."},
+			Signoff:   "Hormat kami,\nTim {brand}",
+		},
+		"en": {
+			Subject:   "Your Email Verification Code - {brand}",
+			Title:     "Verification Code",
+			Greeting:  "Hi,",
+			Before:    []string{"Thank you for signing up for {brand}.", "Here is your email verification code:"},
+			CodeLabel: "VERIFICATION CODE",
+			After:     []string{"Enter this code in the {brand} app to finish verifying your email.", "If you did not sign up, please ignore this email."},
+			Signoff:   "Best regards,\nThe {brand} Team",
+		},
+	},
+	"link_email": {
+		"id": {
+			Subject:   "Verifikasi Email Baru Anda - {brand}",
+			Title:     "Verifikasi Email Baru",
+			Greeting:  "Halo,",
+			Before:    []string{"Anda telah meminta untuk menautkan email ini ke akun {brand} Anda.", "Berikut adalah kode verifikasi untuk menyelesaikan proses tersebut:"},
+			CodeLabel: "KODE VERIFIKASI",
+			After:     []string{"Silakan masukkan kode ini di aplikasi {brand} untuk mengonfirmasi penautan email ini ke akun Anda.", "Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini."},
+			Signoff:   "Hormat kami,\nTim {brand}",
+		},
+		"en": {
+			Subject:   "Verify Your New Email - {brand}",
+			Title:     "Verify New Email",
+			Greeting:  "Hi,",
+			Before:    []string{"You asked to link this email to your {brand} account.", "Here is the verification code to complete the process:"},
+			CodeLabel: "VERIFICATION CODE",
+			After:     []string{"Enter this code in the {brand} app to confirm linking this email to your account.", "If you did not make this request, please ignore this email."},
+			Signoff:   "Best regards,\nThe {brand} Team",
+		},
+	},
+	"password_reset": {
+		"id": {
+			Subject:   "Kode Verifikasi Reset Password - {brand}",
+			Title:     "Reset Password",
+			Greeting:  "Halo,",
+			Before:    []string{"Kami menerima permintaan untuk mengganti password akun Anda di {brand}.", "Berikut adalah kode verifikasi untuk melanjutkan proses reset password:"},
+			CodeLabel: "KODE VERIFIKASI",
+			After:     []string{"Silakan masukkan kode ini di aplikasi {brand} untuk mengatur ulang password Anda.", "Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini."},
+			Signoff:   "Hormat kami,\nTim {brand}",
+		},
+		"en": {
+			Subject:   "Password Reset Code - {brand}",
+			Title:     "Reset Password",
+			Greeting:  "Hi,",
+			Before:    []string{"We received a request to reset the password of your {brand} account.", "Here is the verification code to continue:"},
+			CodeLabel: "VERIFICATION CODE",
+			After:     []string{"Enter this code in the {brand} app to set a new password.", "If you did not make this request, please ignore this email."},
+			Signoff:   "Best regards,\nThe {brand} Team",
+		},
+	},
+	"email_change": {
+		"id": {
+			Subject:   "Konfirmasi Perubahan Email - {brand}",
+			Title:     "Konfirmasi Perubahan Email",
+			Greeting:  "Halo,",
+			Before:    []string{"Anda meminta untuk menjadikan alamat ini sebagai email akun {brand} Anda.", "Berikut adalah kode verifikasi perubahan email:"},
+			CodeLabel: "KODE VERIFIKASI",
+			After:     []string{"Kode ini berlaku selama 15 menit. Email akun Anda baru akan diganti setelah kode ini dimasukkan di aplikasi {brand}.", "Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini."},
+			Signoff:   "Hormat kami,\nTim {brand}",
+		},
+		"en": {
+			Subject:   "Confirm Your Email Change - {brand}",
+			Title:     "Confirm Email Change",
+			Greeting:  "Hi,",
+			Before:    []string{"You asked to use this address as the email of your {brand} account.", "Here is your email change verification code:"},
+			CodeLabel: "VERIFICATION CODE",
+			After:     []string{"This code is valid for 15 minutes. Your account email will only change after you enter it in the {brand} app.", "If you did not make this request, please ignore this email."},
+			Signoff:   "Best regards,\nThe {brand} Team",
+		},
+	},
+	"email_changed_notice": {
+		"id": {
+			Subject:  "Email Akun Anda Telah Diganti - {brand}",
+			Title:    "Email Akun Diganti",
+			Greeting: "Halo,",
+			Before:   []string{"Email akun {brand} Anda baru saja diganti menjadi {new_email}.", "Email ini tidak akan lagi menerima notifikasi akun."},
+			After:    []string{"Jika Anda tidak melakukan perubahan ini, segera reset password Anda dan hubungi tim {brand}."},
+			Signoff:  "Hormat kami,\nTim {brand}",
+		},
+		"en": {
+			Subject:  "Your Account Email Was Changed - {brand}",
+			Title:    "Account Email Changed",
+			Greeting: "Hi,",
+			Before:   []string{"The email of your {brand} account was just changed to {new_email}.", "This address will no longer receive account notifications."},
+			After:    []string{"If you did not make this change, reset your password right away and contact the {brand} team."},
+			Signoff:  "Best regards,\nThe {brand} Team",
+		},
+	},
+}
 
-	bodyHTML = fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
+var emailHTMLLayout = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
   <head>
     <meta charset="UTF-8" />
-    <title>Kode Verifikasi</title>
+    <title>{{.Title}}</title>
   </head>
   <body style="font-family: sans-serif; line-height: 1.6; color: #333;">
-    <p>Halo,</p>
-    <p>Terima kasih telah mendaftar di <strong>Loka Kasir</strong>.</p>
-    <p>Berikut adalah kode verifikasi email Anda:</p>
-    <h2 style="color: #007BFF;">%s</h2>
-    <p>Silakan masukkan kode ini di aplikasi Loka Kasir untuk menyelesaikan proses verifikasi email Anda.</p>
-    <p>Jika Anda tidak merasa melakukan pendaftaran, abaikan email ini.</p>
+    <p>{{.Greeting}}</p>
+    {{- range .Before}}
+    <p>{{.}}</p>
+    {{- end}}
+    {{- if .Code}}
+    <h2 style="color: #007BFF;">{{.Code}}</h2>
+    {{- end}}
+    {{- range .After}}
+    <p>{{.}}</p>
+    {{- end}}
     <br />
-    <p>Hormat kami,<br />Tim Loka Kasir</p>
+    <p>{{range $i, $line := .Signoff}}{{if $i}}<br />{{end}}{{$line}}{{end}}</p>
   </body>
 </html>
-`, token)
-
-	return subject, bodyPlain, bodyHTML
+`))
+
+// ResolveEmailLanguage memilih bahasa email dari header Accept-Language.
+// Bahasa yang tidak didukung akan jatuh ke DefaultEmailLanguage.
+func ResolveEmailLanguage(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := emailCopies["verification"][lang]; ok {
+			return lang
+		}
+	}
+	return DefaultEmailLanguage
 }
 
-func BuildLinkEmailVerification(recipientEmail string, token string) (subject string, bodyPlain string, bodyHTML string) {
-	subject = "Verifikasi Email Baru Anda - Loka Kasir"
-
-	bodyPlain = fmt.Sprintf(`Halo,
-
-Anda telah meminta untuk menautkan email ini ke akun Loka Kasir Anda.
-
-Berikut adalah kode verifikasi untuk menyelesaikan proses tersebut:
-
-KODE VERIFIKASI: %s
-
-Silakan masukkan kode ini di aplikasi Loka Kasir untuk mengonfirmasi penautan email ini ke akun Anda.
+// buildEmail merender email berdasarkan jenis dan bahasa. code boleh kosong
+// untuk email pemberitahuan tanpa kode.
+func buildEmail(kind, lang, code string, vars map[string]string) (subject string, bodyPlain string, bodyHTML string) {
+	copies := emailCopies[kind]
+	c, ok := copies[lang]
+	if !ok {
+		lang = DefaultEmailLanguage
+		c = copies[lang]
+	}
 
-Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini.
+	pairs := []string{"{brand}", EmailBrand}
+	for k, v := range vars {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+	r := strings.NewReplacer(pairs...)
+	fill := func(lines []string) []string {
+		out := make([]string, len(lines))
+		for i, l := range lines {
+			out[i] = r.Replace(l)
+		}
+		return out
+	}
 
-Hormat kami,
-Tim Loka Kasir`, token)
+	before, after := fill(c.Before), fill(c.After)
+	signoff := r.Replace(c.Signoff)
+	subject = r.Replace(c.Subject)
 
-	bodyHTML = fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-  <head>
-    <meta charset="UTF-8" />
-    <title>Verifikasi Email Baru</title>
-  </head>
-  <body style="font-family: sans-serif; line-height: 1.6; color: #333;">
-    <p>Halo,</p>
-    <p>Anda telah meminta untuk menautkan email ini ke akun <strong>Loka Kasir</strong> Anda.</p>
-    <p>Berikut adalah kode verifikasi untuk menyelesaikan proses tersebut:</p>
-    <h2 style="color: #007BFF;">%s</h2>
-    <p>Silakan masukkan kode ini di aplikasi Loka Kasir untuk mengonfirmasi penautan email ini ke akun Anda.</p>
-    <p>Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini.</p>
-    <br />
-    <p>Hormat kami,<br />Tim Loka Kasir</p>
-  </body>
-</html>
-`, token)
+	var plain strings.Builder
+	plain.WriteString(c.Greeting + "\n\n")
+	for _, p := range before {
+		plain.WriteString(p + "\n\n")
+	}
+	if code != "" {
+		plain.WriteString(c.CodeLabel + ": " + code + "\n\n")
+	}
+	for _, p := range after {
+		plain.WriteString(p + "\n\n")
+	}
+	plain.WriteString(signoff)
+	bodyPlain = plain.String()
+
+	var html bytes.Buffer
+	if err := emailHTMLLayout.Execute(&html, map[string]interface{}{
+		"Lang":     lang,
+		"Title":    c.Title,
+		"Greeting": c.Greeting,
+		"Before":   before,
+		"Code":     code,
+		"After":    after,
+		"Signoff":  strings.Split(signoff, "\n"),
+	}); err != nil {
+		log.Printf("⚠️ Gagal merender email %s: %v", kind, err)
+	}
+	bodyHTML = html.String()
 
 	return subject, bodyPlain, bodyHTML
 }
 
-func BuildPasswordResetEmail(recipientEmail string, token string) (subject string, bodyPlain string, bodyHTML string) {
-	subject = "Kode Verifikasi Reset Password - Loka Kasir"
-
-	bodyPlain = fmt.Sprintf(`Halo,
-
-Kami menerima permintaan untuk mengganti password akun Anda di Loka Kasir.
-
-Berikut adalah kode verifikasi untuk melanjutkan proses reset password:
-
-KODE VERIFIKASI: %s
+func BuildVerificationEmail(lang string, token string) (subject string, bodyPlain string, bodyHTML string) {
+	return buildEmail("verification", lang, token, nil)
+}
 
-Silakan masukkan kode ini di aplikasi Loka Kasir untuk mengatur ulang password Anda.
+func BuildLinkEmailVerification(lang string, token string) (subject string, bodyPlain string, bodyHTML string) {
+	return buildEmail("link_email", lang, token, nil)
+}
 
-Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini.
+func BuildPasswordResetEmail(lang string, token string) (subject string, bodyPlain string, bodyHTML string) {
+	return buildEmail("password_reset", lang, token, nil)
+}
 
-Hormat kami,
-Tim Loka Kasir`, token)
+// BuildEmailChangeEmail dikirim ke alamat email baru berisi kode konfirmasi.
+func BuildEmailChangeEmail(lang string, token string) (subject string, bodyPlain string, bodyHTML string) {
+	return buildEmail("email_change", lang, token, nil)
+}
 
-	bodyHTML = fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-  <head>
-    <meta charset="UTF-8" />
-    <title>Reset Password</title>
-  </head>
-  <body style="font-family: sans-serif; line-height: 1.6; color: #333;">
-    <p>Halo,</p>
-    <p>Kami menerima permintaan untuk mengganti password akun Anda di <strong>Loka Kasir</strong>.</p>
-    <p>Berikut adalah kode verifikasi untuk melanjutkan proses reset password:</p>
-    <h2 style="color: #007BFF;">%s</h2>
-    <p>Silakan masukkan kode ini di aplikasi Loka Kasir untuk mengatur ulang password Anda.</p>
-    <p>Jika Anda tidak merasa melakukan permintaan ini, abaikan email ini.</p>
-    <br />
-    <p>Hormat kami,<br />Tim Loka Kasir</p>
-  </body>
-</html>
-`, token)
+// BuildEmailChangedNoticeEmail dikirim ke alamat email lama setelah email berhasil diganti.
+func BuildEmailChangedNoticeEmail(lang string, newEmail string) (subject string, bodyPlain string, bodyHTML string) {
+	return buildEmail("email_changed_notice", lang, "", map[string]string{"new_email": MaskEmail(newEmail)})
+}
 
-	return subject, bodyPlain, bodyHTML
+// MaskEmail menyamarkan bagian lokal email, misal "john.doe@mail.com" -> "j*******@mail.com".
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 1 {
+		return email
+	}
+	return email[:1] + strings.Repeat("*", at-1) + email[at:]
 }
 
 type EmailHelper struct {
@@ -138,13 +263,25 @@ type EmailHelper struct {
 	FromName string
 }
 
+// NewEmailHelper membaca konfigurasi SMTP dari environment
+// (SMTP_HOST, SMTP_PORT, SMTP_USER, SMTP_PASSWORD, SMTP_FROM_NAME).
 func NewEmailHelper() *EmailHelper {
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		port = 587
+	}
+
+	fromName := os.Getenv("SMTP_FROM_NAME")
+	if fromName == "" {
+		fromName = EmailBrand
+	}
+
 	return &EmailHelper{
-		SMTPHost: "smtp.zoho.com",
-		SMTPPort: 587,
-		Username: "support@lokakasir.id",
-		Password: "Kreativita#123",
-		FromName: "Support Loka Kasir",
+		SMTPHost: os.Getenv("SMTP_HOST"),
+		SMTPPort: port,
+		Username: os.Getenv("SMTP_USER"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		FromName: fromName,
 	}
 }
 
@@ -2,17 +2,149 @@ package helper
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
+var (
+	// ErrFaceServiceUnavailable: provider tidak bisa dihubungi, belum dikonfigurasi,
+	// mengembalikan 5xx, atau circuit breaker terbuka. Service memutuskan fail closed / skip.
+	ErrFaceServiceUnavailable = errors.New("layanan verifikasi wajah sedang tidak tersedia")
+	ErrNoFaceDetected         = errors.New("tidak ada wajah yang terdeteksi pada foto")
+	ErrMultipleFaces          = errors.New("terdeteksi lebih dari satu wajah pada foto")
+)
+
+// FacePose adalah orientasi kepala (derajat) hasil deteksi wajah.
+type FacePose struct {
+	Pitch float32 // + menengadah, - menunduk
+	Roll  float32 // kemiringan kepala
+	Yaw   float32 // + menoleh ke kiri, - menoleh ke kanan
+}
+
+// IsFrontal: wajah menghadap lurus ke depan (yaw & pitch dalam ±25°).
+func (p FacePose) IsFrontal() bool {
+	return p.Yaw >= -25 && p.Yaw <= 25 && p.Pitch >= -25 && p.Pitch <= 25
+}
+
+// FaceVerifier adalah provider deteksi & pencocokan wajah.
+type FaceVerifier interface {
+	Name() string
+	// DetectFace memastikan gambar berisi tepat satu wajah dan mengembalikan posenya
+	// (nil jika provider tidak mengembalikan pose).
+	DetectFace(image []byte) (*FacePose, error)
+	// CompareFaces mengembalikan similarity (0.0–1.0) wajah pada kedua gambar.
+	CompareFaces(source, target []byte) (float32, error)
+}
+
+// NewFaceVerifierFromEnv memilih provider dari FACE_SERVICE_PROVIDER (http | fake).
+// Default: http jika FACE_SERVICE_URL diisi, selain itu fake saat GIN_MODE=debug/test.
+// Di luar debug/test (GIN_MODE kosong = release, lihat main.go) provider fake ditolak;
+// HTTP tanpa konfigurasi selalu mengembalikan ErrFaceServiceUnavailable.
+func NewFaceVerifierFromEnv() FaceVerifier {
+	mode := os.Getenv("GIN_MODE")
+	devMode := mode == "debug" || mode == "test"
+
+	provider := strings.ToLower(os.Getenv("FACE_SERVICE_PROVIDER"))
+	if provider == "" {
+		provider = "http"
+		if os.Getenv("FACE_SERVICE_URL") == "" && devMode {
+			provider = "fake"
+		}
+	}
+	if provider == "fake" && !devMode {
+		log.Println("⚠️ FACE_SERVICE_PROVIDER=fake diabaikan di mode release")
+		provider = "http"
+	}
+
+	if provider == "fake" {
+		log.Println("✅ Face verifier: fake (deterministik, hanya untuk development & testing)")
+		return NewFakeFaceVerifier()
+	}
+
+	v := NewHTTPFaceVerifier(
+		os.Getenv("FACE_SERVICE_URL"),
+		os.Getenv("FACE_SERVICE_API_KEY"),
+		time.Duration(envInt("FACE_SERVICE_TIMEOUT_SECONDS", 10))*time.Second,
+		envInt("FACE_SERVICE_RETRIES", 2),
+		NewCircuitBreaker(
+			envInt("FACE_SERVICE_BREAKER_THRESHOLD", 5),
+			time.Duration(envInt("FACE_SERVICE_BREAKER_COOLDOWN_SECONDS", 30))*time.Second,
+		),
+	)
+	if v.baseURL == "" || v.apiKey == "" {
+		log.Println("⚠️ FACE_SERVICE_URL / FACE_SERVICE_API_KEY kosong, verifikasi wajah tidak tersedia")
+	} else {
+		log.Printf("✅ Face verifier: http (%s)", v.baseURL)
+	}
+	return v
+}
+
+func envInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v < 0 {
+		return def
+	}
+	return v
+}
+
+// DecodeBase64Image mendekode gambar base64 (boleh dengan data URI prefix).
+func DecodeBase64Image(b64 string) ([]byte, error) {
+	// Hapus data URI prefix jika ada (misal "data:image/jpeg;base64,...")
+	if idx := strings.Index(b64, ","); idx != -1 {
+		b64 = b64[idx+1:]
+	}
+	return base64.StdEncoding.DecodeString(b64)
+}
+
+var imageDownloadClient = &http.Client{Timeout: 15 * time.Second}
+
+// DownloadImage mengambil gambar tersimpan (misal URL Cloudinary).
+func DownloadImage(url string) ([]byte, error) {
+	resp, err := imageDownloadClient.Get(url) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("gagal download gambar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gagal download gambar: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// HTTPFaceVerifier memanggil Face Service (Detection & Verification API) lewat HTTP
+// dengan timeout per request, retry + backoff untuk error transien, dan circuit breaker.
+type HTTPFaceVerifier struct {
+	baseURL string
+	apiKey  string
+	retries int
+	client  *http.Client
+	breaker *CircuitBreaker
+}
+
+func NewHTTPFaceVerifier(baseURL, apiKey string, timeout time.Duration, retries int, breaker *CircuitBreaker) *HTTPFaceVerifier {
+	return &HTTPFaceVerifier{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		retries: retries,
+		client:  &http.Client{Timeout: timeout},
+		breaker: breaker,
+	}
+}
+
+func (v *HTTPFaceVerifier) Name() string { return "http" }
+
 // faceServiceDetectResponse adalah response dari Face Service Detection API
 type faceServiceDetectResponse struct {
 	Result []struct {
@@ -36,166 +168,196 @@ type faceServiceVerifyResponse struct {
 	} `json:"result"`
 }
 
-func faceServiceBaseURL() string {
-	return strings.TrimRight(os.Getenv("FACE_SERVICE_URL"), "/")
-}
+func (v *HTTPFaceVerifier) DetectFace(image []byte) (*FacePose, error) {
+	var result faceServiceDetectResponse
+	err := v.post("/api/v1/detection/detect", map[string][]byte{"file": image}, &result)
+	if err != nil {
+		// Face Service menjawab 400 jika tidak ada wajah pada gambar
+		var reqErr *faceRequestError
+		if errors.As(err, &reqErr) && strings.Contains(strings.ToLower(reqErr.body), "no face") {
+			return nil, ErrNoFaceDetected
+		}
+		return nil, err
+	}
 
-func decodeBase64ToBytes(b64 string) ([]byte, error) {
-	// Hapus data URI prefix jika ada (misal "data:image/jpeg;base64,...")
-	if idx := strings.Index(b64, ","); idx != -1 {
-		b64 = b64[idx+1:]
+	if len(result.Result) == 0 {
+		return nil, ErrNoFaceDetected
 	}
-	return base64.StdEncoding.DecodeString(b64)
+	if len(result.Result) > 1 {
+		return nil, ErrMultipleFaces
+	}
+
+	face := result.Result[0]
+	if face.Pose == nil {
+		return nil, nil
+	}
+	log.Printf("[FaceService] pose — yaw=%.2f pitch=%.2f", face.Pose.Yaw, face.Pose.Pitch)
+	return &FacePose{Pitch: face.Pose.Pitch, Roll: face.Pose.Roll, Yaw: face.Pose.Yaw}, nil
 }
 
-func downloadImageBytes(url string) ([]byte, error) {
-	resp, err := http.Get(url) //nolint:gosec
+func (v *HTTPFaceVerifier) CompareFaces(source, target []byte) (float32, error) {
+	var result faceServiceVerifyResponse
+	err := v.post("/api/v1/verification/verify", map[string][]byte{"source_image": source, "target_image": target}, &result)
 	if err != nil {
-		return nil, fmt.Errorf("gagal download gambar: %w", err)
+		var reqErr *faceRequestError
+		if errors.As(err, &reqErr) && strings.Contains(strings.ToLower(reqErr.body), "no face") {
+			return 0, nil
+		}
+		return 0, err
 	}
-	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
-}
 
-// DetectFrontFace memvalidasi bahwa gambar base64 mengandung wajah tampak depan.
-// Memanggil Face Service Detection API.
-func DetectFrontFace(base64Str string) error {
-	apiKey := os.Getenv("FACE_SERVICE_API_KEY")
-	if apiKey == "" {
-		// Face Service not configured — skip face validation
-		return nil
+	if len(result.Result) == 0 || len(result.Result[0].FaceMatches) == 0 {
+		return 0, nil
 	}
+	return result.Result[0].FaceMatches[0].Similarity, nil
+}
 
-	imageBytes, err := decodeBase64ToBytes(base64Str)
-	if err != nil {
-		return fmt.Errorf("gagal decode gambar: %w", err)
+// faceRequestError adalah jawaban 4xx (kesalahan input, tidak di-retry).
+type faceRequestError struct {
+	status int
+	body   string
+}
+
+func (e *faceRequestError) Error() string {
+	return fmt.Sprintf("face service error (%d): %s", e.status, e.body)
+}
+
+// post mengirim multipart ke Face Service. Error jaringan, 429 dan 5xx di-retry
+// dengan backoff; jika tetap gagal (atau circuit terbuka) hasilnya ErrFaceServiceUnavailable.
+func (v *HTTPFaceVerifier) post(path string, files map[string][]byte, dest interface{}) error {
+	if v.baseURL == "" || v.apiKey == "" {
+		return ErrFaceServiceUnavailable
+	}
+	if err := v.breaker.Allow(); err != nil {
+		return fmt.Errorf("%w: %v", ErrFaceServiceUnavailable, err)
 	}
 
-	// Buat multipart body
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
-	part, err := writer.CreateFormFile("file", "photo.jpg")
-	if err != nil {
-		return fmt.Errorf("gagal membuat form: %w", err)
-	}
-	if _, err = part.Write(imageBytes); err != nil {
-		return fmt.Errorf("gagal menulis gambar: %w", err)
+	for field, data := range files {
+		part, err := writer.CreateFormFile(field, field+".jpg")
+		if err != nil {
+			return fmt.Errorf("gagal membuat form: %w", err)
+		}
+		if _, err := part.Write(data); err != nil {
+			return fmt.Errorf("gagal menulis gambar: %w", err)
+		}
 	}
 	writer.Close()
 
-	url := faceServiceBaseURL() + "/api/v1/detection/detect"
-	req, err := http.NewRequest(http.MethodPost, url, &body)
-	if err != nil {
-		return fmt.Errorf("gagal membuat request: %w", err)
-	}
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	var lastErr error
+	for attempt := 0; attempt <= v.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt*attempt) * 200 * time.Millisecond)
+		}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("gagal menghubungi Face Service: %w", err)
-	}
-	defer resp.Body.Close()
+		req, err := http.NewRequest(http.MethodPost, v.baseURL+path, bytes.NewReader(body.Bytes()))
+		if err != nil {
+			return fmt.Errorf("gagal membuat request: %w", err)
+		}
+		req.Header.Set("x-api-key", v.apiKey)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	if resp.StatusCode != http.StatusOK {
+		resp, err := v.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 		raw, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("face service detection error (%d): %s", resp.StatusCode, string(raw))
-	}
-
-	var result faceServiceDetectResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("gagal parse response Face Service: %w", err)
-	}
+		resp.Body.Close()
 
-	if len(result.Result) == 0 {
-		return fmt.Errorf("tidak ada wajah yang terdeteksi pada foto")
-	}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("status %d: %s", resp.StatusCode, string(raw))
+			continue
+		}
 
-	// Validasi pose: wajah harus tampak depan
-	face := result.Result[0]
-	if face.Pose != nil {
-		yaw := face.Pose.Yaw
-		pitch := face.Pose.Pitch
-		log.Printf("[FaceService] pose — yaw=%.2f pitch=%.2f", yaw, pitch)
-		if yaw < -25 || yaw > 25 || pitch < -25 || pitch > 25 {
-			return fmt.Errorf("wajah harus menghadap lurus ke depan (tampak depan), hindari sudut miring")
+		// Provider merespons: 4xx adalah kesalahan input, bukan provider down
+		v.breaker.Success()
+		if resp.StatusCode != http.StatusOK {
+			return &faceRequestError{status: resp.StatusCode, body: string(raw)}
 		}
+		if err := json.Unmarshal(raw, dest); err != nil {
+			return fmt.Errorf("gagal parse response Face Service: %w", err)
+		}
+		return nil
 	}
 
-	return nil
+	v.breaker.Failure()
+	log.Printf("[FaceService] %s gagal setelah %d percobaan: %v", path, v.retries+1, lastErr)
+	return fmt.Errorf("%w: %v", ErrFaceServiceUnavailable, lastErr)
 }
 
-// VerifyFaces membandingkan gambar kamera (base64) dengan foto tersimpan (URL Cloudinary).
-// Mengembalikan similarity (0.0–1.0) dan apakah wajah cocok (threshold 0.80).
-func VerifyFaces(cameraBase64 string, storedPhotoURL string) (similarity float32, matched bool, err error) {
-	apiKey := os.Getenv("FACE_SERVICE_API_KEY")
-	if apiKey == "" {
-		// Face Service not configured — skip face verification
-		return 0, false, nil
-	}
-
-	sourceBytes, err := decodeBase64ToBytes(cameraBase64)
-	if err != nil {
-		return 0, false, fmt.Errorf("gagal decode gambar kamera: %w", err)
-	}
+// FakeFaceVerifier adalah provider deterministik untuk testing & development offline.
+//
+// Gambar yang isinya teks "face:<id> yaw=<n> pitch=<n> roll=<n>" menghasilkan satu
+// wajah dengan identitas & pose tersebut ("faces=0" / "faces=2" untuk kasus tanpa
+// wajah / banyak wajah). Gambar lain dianggap satu wajah tampak depan dengan
+// identitas "default". Dua wajah dengan identitas sama bernilai similarity 0.99,
+// identitas berbeda mendapat nilai tetap < 0.5 dari hash identitasnya.
+type FakeFaceVerifier struct{}
 
-	targetBytes, err := downloadImageBytes(storedPhotoURL)
-	if err != nil {
-		return 0, false, fmt.Errorf("gagal mengambil foto verifikasi: %w", err)
-	}
-
-	// Buat multipart body dengan source_image dan target_image
-	var body bytes.Buffer
-	writer := multipart.NewWriter(&body)
+func NewFakeFaceVerifier() *FakeFaceVerifier { return &FakeFaceVerifier{} }
 
-	srcPart, err := writer.CreateFormFile("source_image", "source.jpg")
-	if err != nil {
-		return 0, false, fmt.Errorf("gagal membuat source form: %w", err)
-	}
-	if _, err = srcPart.Write(sourceBytes); err != nil {
-		return 0, false, fmt.Errorf("gagal menulis source image: %w", err)
-	}
+func (f *FakeFaceVerifier) Name() string { return "fake" }
 
-	tgtPart, err := writer.CreateFormFile("target_image", "target.jpg")
-	if err != nil {
-		return 0, false, fmt.Errorf("gagal membuat target form: %w", err)
-	}
-	if _, err = tgtPart.Write(targetBytes); err != nil {
-		return 0, false, fmt.Errorf("gagal menulis target image: %w", err)
-	}
-	writer.Close()
+type fakeFace struct {
+	id    string
+	faces int
+	pose  FacePose
+}
 
-	url := faceServiceBaseURL() + "/api/v1/verification/verify"
-	req, err := http.NewRequest(http.MethodPost, url, &body)
-	if err != nil {
-		return 0, false, fmt.Errorf("gagal membuat request: %w", err)
+func parseFakeFace(image []byte) fakeFace {
+	face := fakeFace{id: "default", faces: 1}
+	text := string(image)
+	if !strings.HasPrefix(text, "face:") {
+		return face
 	}
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, false, fmt.Errorf("gagal menghubungi Face Service: %w", err)
+	for _, token := range strings.Fields(text) {
+		key, value, _ := strings.Cut(token, "=")
+		if strings.HasPrefix(token, "face:") {
+			face.id = strings.TrimPrefix(token, "face:")
+			continue
+		}
+		n, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "yaw":
+			face.pose.Yaw = float32(n)
+		case "pitch":
+			face.pose.Pitch = float32(n)
+		case "roll":
+			face.pose.Roll = float32(n)
+		case "faces":
+			face.faces = int(n)
+		}
 	}
-	defer resp.Body.Close()
+	return face
+}
 
-	if resp.StatusCode != http.StatusOK {
-		raw, _ := io.ReadAll(resp.Body)
-		return 0, false, fmt.Errorf("face service verification error (%d): %s", resp.StatusCode, string(raw))
+func (f *FakeFaceVerifier) DetectFace(image []byte) (*FacePose, error) {
+	face := parseFakeFace(image)
+	switch {
+	case len(image) == 0 || face.faces == 0:
+		return nil, ErrNoFaceDetected
+	case face.faces > 1:
+		return nil, ErrMultipleFaces
 	}
+	pose := face.pose
+	return &pose, nil
+}
 
-	var result faceServiceVerifyResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, false, fmt.Errorf("gagal parse response Face Service: %w", err)
+func (f *FakeFaceVerifier) CompareFaces(source, target []byte) (float32, error) {
+	a, b := parseFakeFace(source), parseFakeFace(target)
+	if len(source) == 0 || len(target) == 0 || a.faces != 1 || b.faces != 1 {
+		return 0, nil
 	}
-
-	if len(result.Result) == 0 || len(result.Result[0].FaceMatches) == 0 {
-		return 0, false, nil
+	if a.id == b.id {
+		return 0.99, nil
 	}
-
-	sim := result.Result[0].FaceMatches[0].Similarity
-	const threshold float32 = 0.80
-	return sim, sim >= threshold, nil
+	ids := []string{a.id, b.id}
+	sort.Strings(ids)
+	sum := sha256.Sum256([]byte(strings.Join(ids, "|")))
+	return float32(sum[0]) / 255 * 0.5, nil
 }
@@ -0,0 +1,57 @@
+package helper
+
+import (
+	"errors"
+	"strings"
+)
+
+// Nilai gender yang disimpan di users.gender.
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+var genderAliases = map[string]string{
+	"male":      GenderMale,
+	"m":         GenderMale,
+	"man":       GenderMale,
+	"l":         GenderMale,
+	"laki-laki": GenderMale,
+	"laki laki": GenderMale,
+	"lakilaki":  GenderMale,
+	"pria":      GenderMale,
+	"cowok":     GenderMale,
+
+	"female":    GenderFemale,
+	"f":         GenderFemale,
+	"woman":     GenderFemale,
+	"p":         GenderFemale,
+	"perempuan": GenderFemale,
+	"wanita":    GenderFemale,
+	"cewek":     GenderFemale,
+}
+
+// ErrInvalidGender dikembalikan bila input gender tidak dikenali.
+var ErrInvalidGender = errors.New("gender tidak valid, gunakan male atau female")
+
+// NormalizeGender mengubah input bebas (misal "Perempuan", "F", "wanita") menjadi
+// GenderMale/GenderFemale. nil atau string kosong menghasilkan nil.
+func NormalizeGender(raw *string) (*string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	key := strings.ToLower(strings.TrimSpace(*raw))
+	if key == "" {
+		return nil, nil
+	}
+	gender, ok := genderAliases[key]
+	if !ok {
+		return nil, ErrInvalidGender
+	}
+	return &gender, nil
+}
+
+// IsFemale mengecek gender yang sudah dinormalisasi.
+func IsFemale(gender *string) bool {
+	return gender != nil && *gender == GenderFemale
+}
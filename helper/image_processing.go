@@ -0,0 +1,237 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"slices"
+)
+
+var (
+	ErrImageTooLarge       = errors.New("ukuran gambar melebihi batas")
+	ErrImageTypeNotAllowed = errors.New("tipe gambar tidak didukung")
+	ErrImageDimensions     = errors.New("dimensi gambar tidak valid")
+)
+
+// ImageOptions adalah aturan validasi & pemrosesan gambar upload.
+type ImageOptions struct {
+	MaxBytes      int64
+	AllowedTypes  []string // MIME, misal image/jpeg, image/png
+	MinDimension  int      // sisi terpendek minimal (px)
+	MaxDimension  int      // sisi terpanjang maksimal (px)
+	MaxPixels     int      // lebar x tinggi maksimal, membatasi memori decode (4 byte per piksel)
+	ThumbnailSize int      // sisi terpanjang thumbnail (px), 0 = tanpa thumbnail
+}
+
+// ProcessedImage adalah gambar yang sudah divalidasi, diputar sesuai orientasi EXIF
+// dan di-encode ulang tanpa metadata (EXIF/GPS ikut terbuang).
+type ProcessedImage struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+	Thumbnail   []byte // JPEG, nil jika ThumbnailSize = 0
+}
+
+// ProcessImage memvalidasi ukuran, tipe dan dimensi gambar, lalu membuang metadata
+// dan membuat thumbnail. Dimensi dicek dari header sebelum decode penuh.
+func ProcessImage(data []byte, opts ImageOptions) (*ProcessedImage, error) {
+	if opts.MaxBytes > 0 && int64(len(data)) > opts.MaxBytes {
+		return nil, fmt.Errorf("%w (maksimal %d KB)", ErrImageTooLarge, opts.MaxBytes/1024)
+	}
+
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(opts.AllowedTypes, contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrImageTypeNotAllowed, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: gambar rusak (%v)", ErrImageTypeNotAllowed, err)
+	}
+	longest, shortest := max(cfg.Width, cfg.Height), min(cfg.Width, cfg.Height)
+	if opts.MaxDimension > 0 && longest > opts.MaxDimension {
+		return nil, fmt.Errorf("%w: sisi terpanjang maksimal %d px", ErrImageDimensions, opts.MaxDimension)
+	}
+	if shortest < opts.MinDimension {
+		return nil, fmt.Errorf("%w: sisi terpendek minimal %d px", ErrImageDimensions, opts.MinDimension)
+	}
+	if opts.MaxPixels > 0 && cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: maksimal %.1f megapiksel", ErrImageDimensions, float64(opts.MaxPixels)/1e6)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: gambar rusak (%v)", ErrImageTypeNotAllowed, err)
+	}
+	img := toRGBA(src)
+	if contentType == "image/jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	out := &ProcessedImage{ContentType: contentType, Width: img.Rect.Dx(), Height: img.Rect.Dy()}
+	var buf bytes.Buffer
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		out.ContentType = "image/jpeg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal encode gambar: %w", err)
+	}
+	out.Data = buf.Bytes()
+
+	if opts.ThumbnailSize > 0 {
+		var thumb bytes.Buffer
+		if err := jpeg.Encode(&thumb, resizeToFit(img, opts.ThumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+			return nil, fmt.Errorf("gagal membuat thumbnail: %w", err)
+		}
+		out.Thumbnail = thumb.Bytes()
+	}
+	return out, nil
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, src, b.Min, draw.Src)
+	return dst
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen APP1 Exif; 1 jika tidak ada.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xDA || size < 2 || pos+2+size > len(data) {
+			return 1 // start of scan: tidak ada Exif sebelum data gambar
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation memutar / membalik gambar agar tampil tegak tanpa tag EXIF.
+func applyOrientation(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for sy := 0; sy < h; sy++ {
+		for sx := 0; sx < w; sx++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-sx, sy
+			case 3:
+				dx, dy = w-1-sx, h-1-sy
+			case 4:
+				dx, dy = sx, h-1-sy
+			case 5:
+				dx, dy = sy, sx
+			case 6:
+				dx, dy = h-1-sy, sx
+			case 7:
+				dx, dy = h-1-sy, w-1-sx
+			case 8:
+				dx, dy = sy, w-1-sx
+			}
+			si := sy*src.Stride + sx*4
+			di := dy*dst.Stride + dx*4
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
+
+// resizeToFit memperkecil gambar (box filter) agar sisi terpanjang <= size.
+func resizeToFit(src *image.RGBA, size int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+	dw, dh := size, h*size/w
+	if h > w {
+		dw, dh = w*size/h, size
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for dy := 0; dy < dh; dy++ {
+		y0, y1 := dy*h/dh, max((dy+1)*h/dh, dy*h/dh+1)
+		for dx := 0; dx < dw; dx++ {
+			x0, x1 := dx*w/dw, max((dx+1)*w/dw, dx*w/dw+1)
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				row := y*src.Stride + x0*4
+				for x := x0; x < x1; x++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(src.Pix[row+c])
+					}
+					row += 4
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			di := dy*dst.Stride + dx*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[di+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
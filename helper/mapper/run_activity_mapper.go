@@ -11,14 +11,16 @@ func MapRunActivity(a *entity.RunActivity) *response.RunActivityResponse {
 	}
 
 	return &response.RunActivityResponse{
-		Id:        a.Id.String(),
-		UserId:    a.UserId.String(),
-		Distance:  a.Distance,
-		Duration:  a.Duration,
-		AvgPace:   a.AvgPace,
-		Calories:  a.Calories,
-		Source:    a.Source,
-		CreatedAt: a.CreatedAt,
+		Id:           a.Id.String(),
+		UserId:       a.UserId.String(),
+		Distance:     a.Distance,
+		Duration:     a.Duration,
+		AvgPace:      a.AvgPace,
+		Calories:     a.Calories,
+		Source:       a.Source,
+		ActivityType: a.ActivityType,
+		StartTime:    a.StartTime,
+		CreatedAt:    a.CreatedAt,
 	}
 }
 
@@ -28,14 +30,42 @@ func MapRunActivityDetail(a *entity.RunActivity, user *entity.User) *response.Ru
 	}
 
 	return &response.RunActivityDetailResponse{
-		Id:        a.Id.String(),
-		UserId:    a.UserId.String(),
-		User:      MapUser(user),
-		Distance:  a.Distance,
-		Duration:  a.Duration,
-		AvgPace:   a.AvgPace,
-		Calories:  a.Calories,
-		Source:    a.Source,
-		CreatedAt: a.CreatedAt,
+		Id:            a.Id.String(),
+		UserId:        a.UserId.String(),
+		User:          MapUser(user),
+		Distance:      a.Distance,
+		Duration:      a.Duration,
+		AvgPace:       a.AvgPace,
+		Calories:      a.Calories,
+		Source:        a.Source,
+		StartTime:     a.StartTime,
+		ElapsedTime:   a.ElapsedTime,
+		ElevationGain: a.ElevationGain,
+		Polyline:      a.Polyline,
+		ActivityType:  a.ActivityType,
+		AvgHeartRate:  a.AvgHeartRate,
+		MaxHeartRate:  a.MaxHeartRate,
+		AvgCadence:    a.AvgCadence,
+		MaxSpeed:      a.MaxSpeed,
+		SyncedFrom:    a.ExternalProvider,
+		Splits:        MapRunActivitySplits(a.Splits),
+		CreatedAt:     a.CreatedAt,
 	}
 }
+
+func MapRunActivitySplits(splits []*entity.RunActivitySplit) []response.RunActivitySplitResponse {
+	res := make([]response.RunActivitySplitResponse, 0, len(splits))
+	for _, s := range splits {
+		res = append(res, response.RunActivitySplitResponse{
+			SplitNumber:   s.SplitNumber,
+			Distance:      s.Distance,
+			Duration:      s.Duration,
+			Pace:          s.Pace,
+			ElevationGain: s.ElevationGain,
+			ElevationLoss: s.ElevationLoss,
+			AvgHeartRate:  s.AvgHeartRate,
+			MaxHeartRate:  s.MaxHeartRate,
+		})
+	}
+	return res
+}
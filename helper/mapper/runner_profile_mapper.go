@@ -14,6 +14,7 @@ func MapRunnerProfile(p *entity.RunnerProfile) *response.RunnerProfileResponse {
 		Id:                p.Id.String(),
 		UserId:            p.UserId.String(),
 		AvgPace:           p.AvgPace,
+		AvgPaceManual:     p.AvgPaceManual,
 		PreferredDistance: p.PreferredDistance,
 		PreferredTime:     p.PreferredTime,
 		Latitude:          p.Latitude,
@@ -36,6 +37,7 @@ func MapRunnerProfileDetail(p *entity.RunnerProfile) *response.RunnerProfileDeta
 		UserId:            p.UserId.String(),
 		User:              MapUser(p.User),
 		AvgPace:           p.AvgPace,
+		AvgPaceManual:     p.AvgPaceManual,
 		PreferredDistance: p.PreferredDistance,
 		PreferredTime:     p.PreferredTime,
 		Latitude:          p.Latitude,
@@ -11,16 +11,19 @@ func MapUser(u *entity.User) *response.UserResponse {
 	}
 
 	return &response.UserResponse{
-		Id:          u.Id.String(),
-		Name:        u.Name,
-		Email:       u.Email,
-		PhoneNumber: u.PhoneNumber,
-		Gender:      u.Gender,
-		HasProfile:  u.HasProfile,
-		IsVerified:  u.IsVerified,
-		IsActive:    u.IsActive,
-		CreatedAt:   u.CreatedAt,
-		UpdatedAt:   u.UpdatedAt,
+		Id:                  u.Id.String(),
+		Name:                u.Name,
+		Email:               u.Email,
+		EmailVerified:       u.EmailVerified,
+		PhoneNumber:         u.PhoneNumber,
+		OTPChannel:          u.OTPChannel,
+		Gender:              u.Gender,
+		HasProfile:          u.HasProfile,
+		IsVerified:          u.IsVerified,
+		IsActive:            u.IsActive,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+		CreatedAt:           u.CreatedAt,
+		UpdatedAt:           u.UpdatedAt,
 	}
 }
 
@@ -29,16 +32,19 @@ func MapUserDetail(u *entity.User) *response.UserDetailResponse {
 		return nil
 	}
 	return &response.UserDetailResponse{
-		Id:          u.Id.String(),
-		Name:        u.Name,
-		Email:       u.Email,
-		PhoneNumber: u.PhoneNumber,
-		Gender:      u.Gender,
-		HasProfile:  u.HasProfile,
-		IsVerified:  u.IsVerified,
-		IsActive:    u.IsActive,
-		Token:       u.Token,
-		CreatedAt:   u.CreatedAt,
-		UpdatedAt:   u.UpdatedAt,
+		Id:                  u.Id.String(),
+		Name:                u.Name,
+		Email:               u.Email,
+		EmailVerified:       u.EmailVerified,
+		PhoneNumber:         u.PhoneNumber,
+		OTPChannel:          u.OTPChannel,
+		Gender:              u.Gender,
+		HasProfile:          u.HasProfile,
+		IsVerified:          u.IsVerified,
+		IsActive:            u.IsActive,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+		Token:               u.Token,
+		CreatedAt:           u.CreatedAt,
+		UpdatedAt:           u.UpdatedAt,
 	}
 }
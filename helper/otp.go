@@ -4,6 +4,8 @@ package helper
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -48,29 +50,16 @@ func (r *RedisHelper) SaveOTP(prefix, identifier, otp string, expiration time.Du
 	if err != nil {
 		log.Printf("❌ Gagal menyimpan OTP ke Redis: %v", err)
 	} else {
-		log.Printf("✅ OTP berhasil disimpan [%s] = %s", key, otp)
+		log.Printf("✅ OTP berhasil disimpan [%s]", key)
 	}
 	return err
 }
 
+// VerifyOTP memverifikasi OTP yang diterbitkan lewat IssueOTP.
+// Lihat CheckOTP untuk jumlah sisa percobaan.
 func (r *RedisHelper) VerifyOTP(keyPrefix, identifier, otp string) error {
-	key := fmt.Sprintf("otp:%s:%s", keyPrefix, identifier)
-	storedHash, err := r.Client.Get(r.Ctx, key).Result()
-
-	if err == redis.Nil {
-		return fmt.Errorf("OTP tidak ditemukan atau sudah kedaluwarsa")
-	}
-	if err != nil {
-		return err
-	}
-
-	if storedHash != HashOTP(otp) {
-		return fmt.Errorf("OTP tidak cocok")
-	}
-
-	r.Client.Del(r.Ctx, key)
-
-	return nil
+	_, err := r.CheckOTP(keyPrefix, identifier, otp)
+	return err
 }
 
 func (r *RedisHelper) RetryUntilRedisKeyExpired(
@@ -141,3 +130,183 @@ func (r *RedisHelper) DeleteResetPasswordVerified(identifier string) error {
 	key := fmt.Sprintf("reset_password_verified:%s", identifier)
 	return r.Client.Del(r.Ctx, key).Err()
 }
+
+const (
+	// OTPMaxAttempts adalah jumlah salah input sebelum OTP dibatalkan dan identifier dikunci.
+	OTPMaxAttempts = 5
+
+	otpLockBase     = 5 * time.Minute
+	otpLockMax      = 24 * time.Hour
+	otpLockLevelTTL = 24 * time.Hour
+)
+
+var (
+	ErrOTPExpired  = errors.New("OTP tidak ditemukan atau sudah kedaluwarsa")
+	ErrOTPMismatch = errors.New("OTP tidak cocok")
+)
+
+// OTPLockedError dikembalikan selama identifier dikunci karena terlalu banyak
+// percobaan OTP yang salah.
+type OTPLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OTPLockedError) Error() string {
+	return fmt.Sprintf("terlalu banyak percobaan OTP, coba lagi dalam %s", e.RetryAfter.Round(time.Second))
+}
+
+// IssueOTP membuat kode OTP baru untuk purpose & identifier tertentu dan
+// menyimpan hash-nya. Kode tidak diterbitkan selama identifier masih dikunci.
+func (r *RedisHelper) IssueOTP(purpose, identifier string, ttl time.Duration) (string, error) {
+	if err := r.checkOTPLock(purpose, identifier); err != nil {
+		return "", err
+	}
+
+	otp := GenerateOTPCode(6)
+	if err := r.SaveOTP(purpose, identifier, HashOTPFor(purpose, identifier, otp), ttl); err != nil {
+		return "", err
+	}
+
+	return otp, nil
+}
+
+// CheckOTP memverifikasi OTP dengan perbandingan constant-time dan membatasi
+// jumlah percobaan. Setelah OTPMaxAttempts kali salah, OTP dihapus dan
+// identifier dikunci dengan cooldown yang berlipat ganda tiap kali terkunci
+// (5 menit, 10 menit, 20 menit, ... maksimal 24 jam).
+//
+// remaining berisi sisa percobaan jika err == ErrOTPMismatch.
+func (r *RedisHelper) CheckOTP(purpose, identifier, otp string) (remaining int, err error) {
+	if err := r.checkOTPLock(purpose, identifier); err != nil {
+		return 0, err
+	}
+
+	storedHash, err := r.GetOTP(purpose, identifier)
+	if err != nil {
+		return 0, ErrOTPExpired
+	}
+
+	if !CompareOTPHash(storedHash, HashOTPFor(purpose, identifier, otp)) {
+		attempts, err := r.IncrementOTPAttempt(purpose, identifier, otpLockLevelTTL)
+		if err != nil {
+			return 0, err
+		}
+		if attempts < OTPMaxAttempts {
+			return OTPMaxAttempts - attempts, ErrOTPMismatch
+		}
+
+		r.DeleteOTP(purpose, identifier)
+		r.ResetOTPAttempts(purpose, identifier)
+		return 0, r.lockOTP(purpose, identifier)
+	}
+
+	r.DeleteOTP(purpose, identifier)
+	r.ResetOTPAttempts(purpose, identifier)
+	r.Client.Del(r.Ctx, fmt.Sprintf("otp_lock_level:%s:%s", purpose, identifier))
+
+	return 0, nil
+}
+
+func (r *RedisHelper) checkOTPLock(purpose, identifier string) error {
+	key := fmt.Sprintf("otp_lock:%s:%s", purpose, identifier)
+
+	ttl, err := r.Client.TTL(r.Ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("gagal mengecek status OTP: %v", err)
+	}
+	if ttl > 0 {
+		return &OTPLockedError{RetryAfter: ttl}
+	}
+
+	return nil
+}
+
+func (r *RedisHelper) lockOTP(purpose, identifier string) error {
+	levelKey := fmt.Sprintf("otp_lock_level:%s:%s", purpose, identifier)
+
+	level, err := r.Client.Incr(r.Ctx, levelKey).Result()
+	if err != nil {
+		return fmt.Errorf("gagal mengunci OTP: %v", err)
+	}
+	r.Client.Expire(r.Ctx, levelKey, otpLockLevelTTL)
+
+	cooldown := otpLockBase
+	for i := int64(1); i < level && cooldown < otpLockMax; i++ {
+		cooldown *= 2
+	}
+	if cooldown > otpLockMax {
+		cooldown = otpLockMax
+	}
+
+	key := fmt.Sprintf("otp_lock:%s:%s", purpose, identifier)
+	if err := r.Client.Set(r.Ctx, key, level, cooldown).Err(); err != nil {
+		return fmt.Errorf("gagal mengunci OTP: %v", err)
+	}
+
+	log.Printf("🔒 OTP [%s:%s] dikunci selama %s (level %d)", purpose, identifier, cooldown, level)
+	return &OTPLockedError{RetryAfter: cooldown}
+}
+
+// IncrementOTPAttempt menambah counter percobaan verifikasi OTP yang gagal
+// dan mengembalikan jumlah percobaan dalam window saat ini.
+func (r *RedisHelper) IncrementOTPAttempt(prefix, identifier string, window time.Duration) (int, error) {
+	key := fmt.Sprintf("otp_attempt:%s:%s", prefix, identifier)
+
+	count, err := r.Client.Incr(r.Ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("gagal mencatat percobaan OTP: %v", err)
+	}
+
+	if count == 1 {
+		r.Client.Expire(r.Ctx, key, window)
+	}
+
+	return int(count), nil
+}
+
+func (r *RedisHelper) ResetOTPAttempts(prefix, identifier string) error {
+	key := fmt.Sprintf("otp_attempt:%s:%s", prefix, identifier)
+	return r.Client.Del(r.Ctx, key).Err()
+}
+
+// SetTokensRevokedAt menandai semua token user yang diterbitkan sebelum waktu
+// tersebut sebagai dicabut. TTL cukup selama masa berlaku refresh token.
+func (r *RedisHelper) SetTokensRevokedAt(userId string, revokedAt time.Time, ttl time.Duration) error {
+	key := fmt.Sprintf("token_revoked_at:%s", userId)
+	return r.Client.Set(r.Ctx, key, revokedAt.Unix(), ttl).Err()
+}
+
+// GetTokensRevokedAt mengembalikan waktu pencabutan token user (unix detik).
+// found bernilai false jika user tidak pernah mencabut sesinya.
+func (r *RedisHelper) GetTokensRevokedAt(userId string) (revokedAt int64, found bool, err error) {
+	key := fmt.Sprintf("token_revoked_at:%s", userId)
+	revokedAt, err = r.Client.Get(r.Ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return revokedAt, true, nil
+}
+
+// SaveWebAuthnSession menyimpan data ceremony WebAuthn dengan key challenge,
+// sehingga satu challenge hanya berlaku untuk satu sesi register/login.
+func (r *RedisHelper) SaveWebAuthnSession(kind, challenge string, data interface{}, ttl time.Duration) error {
+	key := fmt.Sprintf("webauthn:%s:%s", kind, challenge)
+	return SetJSONToRedis(r.Ctx, r.Client, key, data, ttl)
+}
+
+// TakeWebAuthnSession mengambil lalu menghapus sesi WebAuthn secara atomik
+// (GETDEL), sehingga challenge tidak bisa dipakai ulang.
+func (r *RedisHelper) TakeWebAuthnSession(kind, challenge string, dest interface{}) error {
+	key := fmt.Sprintf("webauthn:%s:%s", kind, challenge)
+	val, err := r.Client.GetDel(r.Ctx, key).Result()
+	if err == redis.Nil {
+		return fmt.Errorf("challenge tidak valid atau sudah kadaluarsa")
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(val), dest)
+}
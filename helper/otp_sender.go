@@ -0,0 +1,336 @@
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OTPChannel string
+
+const (
+	OTPChannelWhatsApp OTPChannel = "whatsapp"
+	OTPChannelEmail    OTPChannel = "email"
+	OTPChannelSMS      OTPChannel = "sms"
+	OTPChannelLog      OTPChannel = "log" // development/testing only
+)
+
+// OTPRecipient adalah tujuan pengiriman OTP. Email hanya diisi jika boleh
+// dipakai untuk flow tersebut (misal email terverifikasi untuk reset password).
+type OTPRecipient struct {
+	Phone string
+	Email *string
+}
+
+// OTPMessage berisi kode dan konteksnya. Purpose sama dengan prefix OTP di
+// RedisHelper (register, reset_password, ...) dan menentukan isi pesan.
+type OTPMessage struct {
+	Purpose string
+	Code    string
+	TTL     time.Duration
+	Lang    string
+}
+
+// OTPSender mengirim OTP lewat satu channel.
+type OTPSender interface {
+	Channel() OTPChannel
+	CanSend(to OTPRecipient) bool
+	Send(to OTPRecipient, msg OTPMessage) error
+}
+
+// OTPDeliveryAttempt adalah hasil percobaan pengiriman di satu channel.
+type OTPDeliveryAttempt struct {
+	Channel OTPChannel `json:"channel"`
+	Status  string     `json:"status"` // sent | failed | skipped
+	Error   string     `json:"error,omitempty"`
+}
+
+// OTPDeliveryResult dikembalikan ke client agar tahu ke mana kode dikirim.
+type OTPDeliveryResult struct {
+	Delivered   bool                 `json:"delivered"`
+	Channel     OTPChannel           `json:"channel,omitempty"`
+	Destination string               `json:"destination,omitempty"` // masked
+	Attempts    []OTPDeliveryAttempt `json:"attempts"`
+}
+
+// OTPDispatcher mencoba channel sesuai preferensi user lalu urutan fallback
+// sampai salah satu berhasil.
+type OTPDispatcher struct {
+	senders map[OTPChannel]OTPSender
+	order   []OTPChannel
+}
+
+func NewOTPDispatcher(order []OTPChannel, senders ...OTPSender) *OTPDispatcher {
+	d := &OTPDispatcher{senders: make(map[OTPChannel]OTPSender)}
+	for _, s := range senders {
+		d.senders[s.Channel()] = s
+	}
+	for _, ch := range order {
+		if _, ok := d.senders[ch]; ok {
+			d.order = append(d.order, ch)
+		}
+	}
+	return d
+}
+
+// NewOTPDispatcherFromEnv menyusun dispatcher dari OTP_CHANNELS (default
+// "whatsapp,sms,email"). Channel "log" ditolak saat GIN_MODE=release.
+func NewOTPDispatcherFromEnv(emailHelper *EmailHelper) *OTPDispatcher {
+	raw := os.Getenv("OTP_CHANNELS")
+	if raw == "" {
+		raw = "whatsapp,sms,email"
+	}
+
+	senders := []OTPSender{
+		&WhatsAppOTPSender{},
+		&EmailOTPSender{emailHelper: emailHelper},
+		NewSMSOTPSender(),
+	}
+
+	var order []OTPChannel
+	for _, part := range strings.Split(raw, ",") {
+		ch := OTPChannel(strings.ToLower(strings.TrimSpace(part)))
+		if ch == OTPChannelLog {
+			if os.Getenv("GIN_MODE") == "release" {
+				log.Println("⚠️ OTP channel 'log' diabaikan di mode release")
+				continue
+			}
+			senders = append(senders, NewLogOTPSender(os.Getenv("OTP_LOG_FILE")))
+		}
+		order = append(order, ch)
+	}
+
+	d := NewOTPDispatcher(order, senders...)
+	log.Printf("✅ OTP channels: %v", d.order)
+	return d
+}
+
+// Send mengirim OTP. preferred (boleh kosong) dicoba lebih dulu, lalu channel
+// lain sesuai urutan konfigurasi.
+func (d *OTPDispatcher) Send(to OTPRecipient, msg OTPMessage, preferred string) OTPDeliveryResult {
+	order := d.order
+	if p := OTPChannel(preferred); p != "" {
+		if _, ok := d.senders[p]; ok {
+			order = append([]OTPChannel{p}, d.without(p)...)
+		}
+	}
+
+	result := OTPDeliveryResult{Attempts: []OTPDeliveryAttempt{}}
+	for _, ch := range order {
+		sender := d.senders[ch]
+		if !sender.CanSend(to) {
+			result.Attempts = append(result.Attempts, OTPDeliveryAttempt{Channel: ch, Status: "skipped"})
+			continue
+		}
+
+		if err := sender.Send(to, msg); err != nil {
+			log.Printf("⚠️ Gagal kirim OTP %s via %s: %v", msg.Purpose, ch, err)
+			result.Attempts = append(result.Attempts, OTPDeliveryAttempt{Channel: ch, Status: "failed", Error: err.Error()})
+			continue
+		}
+
+		result.Attempts = append(result.Attempts, OTPDeliveryAttempt{Channel: ch, Status: "sent"})
+		result.Delivered = true
+		result.Channel = ch
+		result.Destination = maskDestination(ch, to)
+		return result
+	}
+
+	return result
+}
+
+func (d *OTPDispatcher) without(ch OTPChannel) []OTPChannel {
+	out := make([]OTPChannel, 0, len(d.order))
+	for _, c := range d.order {
+		if c != ch {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func maskDestination(ch OTPChannel, to OTPRecipient) string {
+	if ch == OTPChannelEmail && to.Email != nil {
+		return MaskEmail(*to.Email)
+	}
+	return MaskPhone(to.Phone)
+}
+
+// MaskPhone menyamarkan nomor telepon, misal "081234567890" -> "0812****7890".
+func MaskPhone(phone string) string {
+	if len(phone) <= 8 {
+		return phone
+	}
+	return phone[:4] + strings.Repeat("*", len(phone)-8) + phone[len(phone)-4:]
+}
+
+// BuildOTPText membuat isi pesan OTP untuk channel teks (WhatsApp, SMS, log).
+func BuildOTPText(msg OTPMessage) string {
+	minutes := int(msg.TTL.Minutes())
+
+	if msg.Lang == "en" {
+		label := "Your verification code"
+		switch msg.Purpose {
+		case "reset_password":
+			label = "Your password reset code"
+		case "whatsapp_link":
+			label = "Your WhatsApp verification code"
+		}
+		return fmt.Sprintf("🏃 %s\n\n%s: *%s*\n\nThis code is valid for %d minutes.\nNever share this code with anyone.", EmailBrand, label, msg.Code, minutes)
+	}
+
+	label := "Kode verifikasi Anda"
+	switch msg.Purpose {
+	case "reset_password":
+		label = "Kode reset password Anda"
+	case "whatsapp_link":
+		label = "Kode verifikasi WhatsApp Anda"
+	}
+	return fmt.Sprintf("🏃 %s\n\n%s: *%s*\n\nKode ini berlaku selama %d menit.\nJangan bagikan kode ini kepada siapapun.", EmailBrand, label, msg.Code, minutes)
+}
+
+// WhatsAppOTPSender mengirim OTP lewat client whatsmeow (lihat InitWhatsApp).
+type WhatsAppOTPSender struct{}
+
+func (s *WhatsAppOTPSender) Channel() OTPChannel { return OTPChannelWhatsApp }
+
+func (s *WhatsAppOTPSender) CanSend(to OTPRecipient) bool {
+	return to.Phone != "" && client != nil && client.IsConnected()
+}
+
+func (s *WhatsAppOTPSender) Send(to OTPRecipient, msg OTPMessage) error {
+	return SendOTPViaWhatsApp(to.Phone, BuildOTPText(msg))
+}
+
+// EmailOTPSender mengirim OTP lewat EmailHelper dengan template sesuai purpose.
+type EmailOTPSender struct {
+	emailHelper *EmailHelper
+}
+
+func (s *EmailOTPSender) Channel() OTPChannel { return OTPChannelEmail }
+
+func (s *EmailOTPSender) CanSend(to OTPRecipient) bool {
+	return to.Email != nil && *to.Email != "" && s.emailHelper.SMTPHost != ""
+}
+
+func (s *EmailOTPSender) Send(to OTPRecipient, msg OTPMessage) error {
+	var subject, plain, html string
+	switch msg.Purpose {
+	case "reset_password":
+		subject, plain, html = BuildPasswordResetEmail(msg.Lang, msg.Code)
+	case "email_change":
+		subject, plain, html = BuildEmailChangeEmail(msg.Lang, msg.Code)
+	case "whatsapp_link":
+		subject, plain, html = BuildLinkEmailVerification(msg.Lang, msg.Code)
+	default:
+		subject, plain, html = BuildVerificationEmail(msg.Lang, msg.Code)
+	}
+	return s.emailHelper.Send(*to.Email, subject, plain, html)
+}
+
+// SMSOTPSender mengirim OTP ke SMS gateway HTTP generik:
+// POST SMS_API_URL dengan body {"to","message","sender"} dan header
+// "Authorization: Bearer SMS_API_KEY".
+type SMSOTPSender struct {
+	apiURL   string
+	apiKey   string
+	senderID string
+	client   *http.Client
+}
+
+func NewSMSOTPSender() *SMSOTPSender {
+	return &SMSOTPSender{
+		apiURL:   os.Getenv("SMS_API_URL"),
+		apiKey:   os.Getenv("SMS_API_KEY"),
+		senderID: os.Getenv("SMS_SENDER_ID"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *SMSOTPSender) Channel() OTPChannel { return OTPChannelSMS }
+
+func (s *SMSOTPSender) CanSend(to OTPRecipient) bool {
+	return to.Phone != "" && s.apiURL != ""
+}
+
+func (s *SMSOTPSender) Send(to OTPRecipient, msg OTPMessage) error {
+	body, err := json.Marshal(map[string]string{
+		"to":      formatPhoneNumber(to.Phone),
+		"message": strings.ReplaceAll(BuildOTPText(msg), "*", ""),
+		"sender":  s.senderID,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.apiURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gagal menghubungi SMS gateway: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("SMS gateway mengembalikan status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LogOTPSender menulis OTP ke file (JSON per baris) atau ke log aplikasi.
+// Hanya untuk development & testing, jangan diaktifkan di production.
+type LogOTPSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLogOTPSender(path string) *LogOTPSender {
+	return &LogOTPSender{path: path}
+}
+
+func (s *LogOTPSender) Channel() OTPChannel { return OTPChannelLog }
+
+func (s *LogOTPSender) CanSend(to OTPRecipient) bool { return true }
+
+func (s *LogOTPSender) Send(to OTPRecipient, msg OTPMessage) error {
+	if s.path == "" {
+		log.Printf("📨 [OTP %s] phone=%s email=%s code=%s", msg.Purpose, to.Phone, StringValue(to.Email), msg.Code)
+		return nil
+	}
+
+	line, err := json.Marshal(map[string]interface{}{
+		"time":    time.Now().Format(time.RFC3339),
+		"purpose": msg.Purpose,
+		"phone":   to.Phone,
+		"email":   StringValue(to.Email),
+		"code":    msg.Code,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
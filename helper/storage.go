@@ -0,0 +1,127 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrStorageObjectNotFound dikembalikan saat key tidak ada di backend.
+var ErrStorageObjectNotFound = errors.New("objek tidak ditemukan di storage")
+
+// StoredObject adalah satu file di object storage.
+type StoredObject struct {
+	Key       string    // path relatif di backend, misal "run-sync/photos/<uuid>.jpg"
+	URL       string    // URL publik (tanpa tanda tangan)
+	CreatedAt time.Time // waktu upload, dipakai sweep orphan
+}
+
+// PresignedUpload adalah tujuan upload langsung dari client ke storage.
+// Client mengirim file dengan Method ke URL; jika Fields diisi, body berupa
+// multipart/form-data berisi Fields + file di FileField, selain itu body adalah
+// isi file mentah dengan Headers.
+type PresignedUpload struct {
+	Method    string            `json:"method"`
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	FileField string            `json:"file_field,omitempty"`
+}
+
+// Storage adalah backend penyimpanan file (Cloudinary, S3-compatible, filesystem lokal).
+type Storage interface {
+	Name() string
+	// Upload menyimpan data di folder dengan nama acak.
+	Upload(data []byte, folder string) (StoredObject, error)
+	// Delete menghapus objek; key yang sudah tidak ada tidak dianggap error.
+	Delete(key string) error
+	// SignedURL membuat URL sementara untuk objek yang tidak boleh dibagikan permanen.
+	SignedURL(key string, ttl time.Duration) (string, error)
+	// PresignUpload mengizinkan client mengunggah satu objek ke key sampai ttl habis.
+	PresignUpload(key, contentType string, ttl time.Duration) (PresignedUpload, error)
+	// List mengembalikan semua objek di bawah prefix.
+	List(prefix string) ([]StoredObject, error)
+	// KeyFromURL memetakan URL publik kembali ke key (false jika URL bukan milik backend ini).
+	KeyFromURL(rawURL string) (string, bool)
+}
+
+// NewStorageFromEnv memilih backend dari STORAGE_BACKEND (cloudinary | s3 | local, default cloudinary).
+// privateFolders hanya boleh dibuka lewat signed URL (dipakai backend cloudinary).
+func NewStorageFromEnv(privateFolders []string) Storage {
+	var s Storage
+	switch backend := strings.ToLower(os.Getenv("STORAGE_BACKEND")); backend {
+	case "s3":
+		s = NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+			os.Getenv("S3_PUBLIC_URL"),
+		)
+	case "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		baseURL := os.Getenv("STORAGE_PUBLIC_URL")
+		if baseURL == "" {
+			port := os.Getenv("PORT")
+			if port == "" {
+				port = "8080"
+			}
+			baseURL = "http://localhost:" + port
+		}
+		secret := os.Getenv("STORAGE_SIGNING_SECRET")
+		if secret == "" {
+			secret = GenerateSecureToken(32)
+			log.Println("⚠️ STORAGE_SIGNING_SECRET kosong, signed URL lokal tidak berlaku setelah restart")
+		}
+		s = NewLocalStorage(dir, baseURL, secret)
+	case "", "cloudinary":
+		s = NewCloudinaryStorage(os.Getenv("CLD_NAME"), os.Getenv("CLD_API_KEY"), os.Getenv("CLD_API_SECRET"), privateFolders)
+	default:
+		log.Fatalf("❌ STORAGE_BACKEND=%q tidak dikenal (cloudinary | s3 | local)", backend)
+	}
+
+	log.Printf("✅ Storage backend: %s", s.Name())
+	return s
+}
+
+// imageExtension menebak ekstensi file dari isi gambar.
+func imageExtension(data []byte) (string, error) {
+	return extensionForType(http.DetectContentType(data))
+}
+
+func extensionForType(contentType string) (string, error) {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg", nil
+	case "image/png":
+		return ".png", nil
+	case "image/webp":
+		return ".webp", nil
+	case "image/gif":
+		return ".gif", nil
+	}
+	return "", fmt.Errorf("format gambar tidak didukung")
+}
+
+func newObjectKey(folder string, data []byte) (string, error) {
+	return NewObjectKeyForType(folder, http.DetectContentType(data))
+}
+
+// NewObjectKeyForType membuat key acak untuk upload yang isinya belum diterima server.
+func NewObjectKeyForType(folder, contentType string) (string, error) {
+	ext, err := extensionForType(contentType)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(folder, "/") + "/" + uuid.NewString() + ext, nil
+}
@@ -0,0 +1,210 @@
+package helper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// CloudinaryStorage menyimpan gambar di Cloudinary. Key = "<public_id>.<format>".
+// Folder privat diunggah dengan delivery type authenticated sehingga hanya bisa
+// dibuka lewat SignedURL.
+type CloudinaryStorage struct {
+	cloudName      string
+	apiKey         string
+	apiSecret      string
+	privateFolders []string
+}
+
+func NewCloudinaryStorage(cloudName, apiKey, apiSecret string, privateFolders []string) *CloudinaryStorage {
+	return &CloudinaryStorage{cloudName: cloudName, apiKey: apiKey, apiSecret: apiSecret, privateFolders: privateFolders}
+}
+
+// deliveryType mengembalikan "authenticated" untuk key / prefix di folder privat.
+func (s *CloudinaryStorage) deliveryType(key string) string {
+	for _, folder := range s.privateFolders {
+		if strings.HasPrefix(key, strings.Trim(folder, "/")+"/") {
+			return api.Authenticated
+		}
+	}
+	return api.Upload.String()
+}
+
+func (s *CloudinaryStorage) Name() string { return "cloudinary" }
+
+func (s *CloudinaryStorage) client() (*cloudinary.Cloudinary, error) {
+	cld, err := cloudinary.NewFromParams(s.cloudName, s.apiKey, s.apiSecret)
+	if err != nil {
+		return nil, fmt.Errorf("init cloudinary gagal: %w", err)
+	}
+	return cld, nil
+}
+
+func (s *CloudinaryStorage) Upload(data []byte, folder string) (StoredObject, error) {
+	key, err := newObjectKey(folder, data)
+	if err != nil {
+		return StoredObject{}, err
+	}
+	cld, err := s.client()
+	if err != nil {
+		return StoredObject{}, err
+	}
+
+	res, err := cld.Upload.Upload(context.Background(), bytes.NewReader(data), uploader.UploadParams{
+		PublicID: strings.TrimSuffix(key, path.Ext(key)),
+		Type:     api.DeliveryType(s.deliveryType(key)),
+	})
+	if err != nil {
+		return StoredObject{}, fmt.Errorf("upload cloudinary gagal: %w", err)
+	}
+	if res.Error.Message != "" {
+		return StoredObject{}, fmt.Errorf("upload cloudinary gagal: %s", res.Error.Message)
+	}
+
+	return StoredObject{
+		Key:       res.PublicID + "." + res.Format,
+		URL:       res.SecureURL,
+		CreatedAt: res.CreatedAt,
+	}, nil
+}
+
+func (s *CloudinaryStorage) Delete(key string) error {
+	cld, err := s.client()
+	if err != nil {
+		return err
+	}
+	res, err := cld.Upload.Destroy(context.Background(), uploader.DestroyParams{
+		PublicID: strings.TrimSuffix(key, path.Ext(key)),
+		Type:     s.deliveryType(key),
+	})
+	if err != nil {
+		return fmt.Errorf("hapus cloudinary gagal: %w", err)
+	}
+	if res.Result != "ok" && res.Result != "not found" {
+		return fmt.Errorf("hapus cloudinary gagal: %s %s", res.Result, res.Error.Message)
+	}
+	return nil
+}
+
+// SignedURL memakai private download URL Cloudinary yang kedaluwarsa setelah ttl.
+func (s *CloudinaryStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	cld, err := s.client()
+	if err != nil {
+		return "", err
+	}
+	expiresAt := time.Now().Add(ttl)
+	return cld.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+		PublicID:     strings.TrimSuffix(key, path.Ext(key)),
+		Format:       strings.TrimPrefix(path.Ext(key), "."),
+		DeliveryType: s.deliveryType(key),
+		ExpiresAt:    &expiresAt,
+	})
+}
+
+// PresignUpload membuat signed upload (multipart POST) ke Upload API Cloudinary.
+// Signature mengunci public_id & timestamp; Cloudinary menolaknya setelah 1 jam,
+// jadi ttl yang lebih lama tidak berpengaruh.
+func (s *CloudinaryStorage) PresignUpload(key, contentType string, ttl time.Duration) (PresignedUpload, error) {
+	if s.cloudName == "" || s.apiKey == "" || s.apiSecret == "" {
+		return PresignedUpload{}, fmt.Errorf("CLD_NAME / CLD_API_KEY / CLD_API_SECRET belum dikonfigurasi")
+	}
+	params := url.Values{}
+	params.Set("public_id", strings.TrimSuffix(key, path.Ext(key)))
+	params.Set("timestamp", strconv.FormatInt(time.Now().Unix(), 10))
+	if deliveryType := s.deliveryType(key); deliveryType != api.Upload.String() {
+		params.Set("type", deliveryType)
+	}
+	signature, err := api.SignParameters(params, s.apiSecret)
+	if err != nil {
+		return PresignedUpload{}, fmt.Errorf("gagal menandatangani upload cloudinary: %w", err)
+	}
+
+	fields := map[string]string{
+		"api_key":   s.apiKey,
+		"public_id": params.Get("public_id"),
+		"timestamp": params.Get("timestamp"),
+		"signature": signature,
+	}
+	if deliveryType := params.Get("type"); deliveryType != "" {
+		fields["type"] = deliveryType
+	}
+
+	return PresignedUpload{
+		Method:    "POST",
+		URL:       "https://api.cloudinary.com/v1_1/" + s.cloudName + "/image/upload",
+		Fields:    fields,
+		FileField: "file",
+	}, nil
+}
+
+func (s *CloudinaryStorage) List(prefix string) ([]StoredObject, error) {
+	cld, err := s.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []StoredObject
+	cursor := ""
+	for {
+		res, err := cld.Admin.Assets(context.Background(), admin.AssetsParams{
+			AssetType:    api.Image,
+			DeliveryType: s.deliveryType(prefix + "/"),
+			Prefix:       prefix,
+			MaxResults:   500,
+			NextCursor:   cursor,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list cloudinary gagal: %w", err)
+		}
+		if res.Error.Message != "" {
+			return nil, fmt.Errorf("list cloudinary gagal: %s", res.Error.Message)
+		}
+		for _, a := range res.Assets {
+			objects = append(objects, StoredObject{
+				Key:       a.PublicID + "." + a.Format,
+				URL:       a.SecureURL,
+				CreatedAt: a.CreatedAt,
+			})
+		}
+		if res.NextCursor == "" {
+			return objects, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+// cloudinaryVersion adalah segmen versi ("v1699999999") pada delivery URL.
+var cloudinaryVersion = regexp.MustCompile(`^v\d+$`)
+
+// KeyFromURL membaca key dari https://res.cloudinary.com/<cloud>/image/<upload|authenticated>/[v123/]<public_id>.<format>.
+func (s *CloudinaryStorage) KeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host != "res.cloudinary.com" {
+		return "", false
+	}
+
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	// <cloud>/image/<upload|authenticated>/...
+	if len(parts) < 4 || parts[0] != s.cloudName || (parts[2] != api.Upload.String() && parts[2] != api.Authenticated) {
+		return "", false
+	}
+	rest := parts[3:]
+	if cloudinaryVersion.MatchString(rest[0]) {
+		rest = rest[1:]
+	}
+	if len(rest) == 0 || path.Ext(rest[len(rest)-1]) == "" {
+		return "", false
+	}
+	return strings.Join(rest, "/"), true
+}
@@ -0,0 +1,182 @@
+package helper
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalFilesPath adalah prefix route yang menyajikan file LocalStorage.
+const LocalFilesPath = "/files/"
+
+// ErrInvalidSignedURL dikembalikan saat signature URL lokal salah atau kedaluwarsa.
+var ErrInvalidSignedURL = errors.New("signed URL tidak valid atau sudah kedaluwarsa")
+
+// LocalStorage menyimpan file di filesystem dan menyajikannya lewat GET /files/<key>,
+// sehingga semua fitur upload bisa dijalankan offline.
+type LocalStorage struct {
+	dir     string
+	baseURL string
+	secret  []byte
+}
+
+func NewLocalStorage(dir, baseURL, secret string) *LocalStorage {
+	return &LocalStorage{dir: dir, baseURL: strings.TrimRight(baseURL, "/"), secret: []byte(secret)}
+}
+
+func (s *LocalStorage) Name() string { return "local" }
+
+func (s *LocalStorage) Upload(data []byte, folder string) (StoredObject, error) {
+	key, err := newObjectKey(folder, data)
+	if err != nil {
+		return StoredObject{}, err
+	}
+	path, err := s.Path(key)
+	if err != nil {
+		return StoredObject{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return StoredObject{}, fmt.Errorf("gagal membuat folder: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return StoredObject{}, fmt.Errorf("gagal menyimpan file: %w", err)
+	}
+	return StoredObject{Key: key, URL: s.url(key), CreatedAt: time.Now()}, nil
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL menambahkan ?expires=<unix>&signature=<hmac> yang diperiksa oleh VerifySignature.
+func (s *LocalStorage) SignedURL(key string, ttl time.Duration) (string, error) {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(key, expires))
+	return s.url(key) + "?" + query.Encode(), nil
+}
+
+// VerifySignature memeriksa parameter dari SignedURL.
+func (s *LocalStorage) VerifySignature(key, expires, signature string) error {
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return ErrInvalidSignedURL
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidSignedURL
+	}
+	return nil
+}
+
+// PresignUpload membuat URL PUT /files/<key> bertanda tangan; signature upload dibedakan
+// dari signature download sehingga signed URL baca tidak bisa dipakai untuk menulis.
+func (s *LocalStorage) PresignUpload(key, contentType string, ttl time.Duration) (PresignedUpload, error) {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(http.MethodPut+" "+key, expires))
+	return PresignedUpload{
+		Method:  http.MethodPut,
+		URL:     s.url(key) + "?" + query.Encode(),
+		Headers: map[string]string{"Content-Type": contentType},
+	}, nil
+}
+
+// VerifyUploadSignature memeriksa parameter dari PresignUpload.
+func (s *LocalStorage) VerifyUploadSignature(key, expires, signature string) error {
+	return s.VerifySignature(http.MethodPut+" "+key, expires, signature)
+}
+
+// Write menyimpan isi upload langsung ke key; gagal jika melebihi maxBytes.
+func (s *LocalStorage) Write(key string, r io.Reader, maxBytes int64) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return fmt.Errorf("gagal membaca upload: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return ErrImageTooLarge
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("gagal membuat folder: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (s *LocalStorage) List(prefix string) ([]StoredObject, error) {
+	root := filepath.Join(s.dir, filepath.FromSlash(prefix))
+	var objects []StoredObject
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		objects = append(objects, StoredObject{Key: key, URL: s.url(key), CreatedAt: info.ModTime()})
+		return nil
+	})
+	return objects, err
+}
+
+func (s *LocalStorage) KeyFromURL(rawURL string) (string, bool) {
+	prefix := s.baseURL + LocalFilesPath
+	if !strings.HasPrefix(rawURL, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(rawURL, prefix)
+	if i := strings.Index(key, "?"); i != -1 {
+		key = key[:i]
+	}
+	return key, key != ""
+}
+
+// Path mengembalikan lokasi file untuk key, menolak key yang keluar dari folder storage.
+func (s *LocalStorage) Path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" {
+		return "", ErrStorageObjectNotFound
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStorage) url(key string) string {
+	return s.baseURL + LocalFilesPath + key
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "|" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,316 @@
+package helper
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Storage menyimpan file di bucket S3-compatible (AWS S3, MinIO, Cloudflare R2, ...)
+// dengan path-style URL dan AWS Signature V4.
+type S3Storage struct {
+	endpoint  string // misal https://s3.ap-southeast-1.amazonaws.com atau http://localhost:9000
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	publicURL string // base URL publik objek, default <endpoint>/<bucket>
+	client    *http.Client
+}
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey, publicURL string) *S3Storage {
+	endpoint = strings.TrimRight(endpoint, "/")
+	if region == "" {
+		region = "us-east-1"
+	}
+	if publicURL == "" {
+		publicURL = endpoint + "/" + bucket
+	}
+	return &S3Storage{
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *S3Storage) Name() string { return "s3" }
+
+func (s *S3Storage) Upload(data []byte, folder string) (StoredObject, error) {
+	key, err := newObjectKey(folder, data)
+	if err != nil {
+		return StoredObject{}, err
+	}
+
+	resp, err := s.do(http.MethodPut, key, nil, data, http.DetectContentType(data))
+	if err != nil {
+		return StoredObject{}, fmt.Errorf("upload s3 gagal: %w", err)
+	}
+	resp.Body.Close()
+
+	return StoredObject{Key: key, URL: s.publicURL + "/" + s3EscapePath(key), CreatedAt: time.Now()}, nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	resp, err := s.do(http.MethodDelete, key, nil, nil, "")
+	if err != nil {
+		return fmt.Errorf("hapus s3 gagal: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SignedURL membuat presigned GET URL (SigV4 query string, maksimal 7 hari).
+func (s *S3Storage) SignedURL(key string, ttl time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, ttl)
+}
+
+// PresignUpload membuat presigned PUT URL; client mengirim isi file mentah.
+func (s *S3Storage) PresignUpload(key, contentType string, ttl time.Duration) (PresignedUpload, error) {
+	if s.endpoint == "" || s.bucket == "" || s.accessKey == "" || s.secretKey == "" {
+		return PresignedUpload{}, fmt.Errorf("S3_ENDPOINT / S3_BUCKET / S3_ACCESS_KEY / S3_SECRET_KEY belum dikonfigurasi")
+	}
+	u, err := s.presign(http.MethodPut, key, ttl)
+	if err != nil {
+		return PresignedUpload{}, err
+	}
+	return PresignedUpload{
+		Method:  http.MethodPut,
+		URL:     u,
+		Headers: map[string]string{"Content-Type": contentType},
+	}, nil
+}
+
+// presign menandatangani method+key lewat query string SigV4 (hanya header host yang ditandatangani).
+func (s *S3Storage) presign(method, key string, ttl time.Duration) (string, error) {
+	if ttl > 7*24*time.Hour {
+		ttl = 7 * 24 * time.Hour
+	}
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(ttl.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonical := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, scope, canonical))
+
+	u.RawQuery = s3CanonicalQuery(query)
+	return u.String(), nil
+}
+
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Storage) List(prefix string) ([]StoredObject, error) {
+	var objects []StoredObject
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(http.MethodGet, "", query, nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("list s3 gagal: %w", err)
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gagal parse list s3: %w", err)
+		}
+
+		for _, c := range result.Contents {
+			objects = append(objects, StoredObject{
+				Key:       c.Key,
+				URL:       s.publicURL + "/" + s3EscapePath(c.Key),
+				CreatedAt: c.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Storage) KeyFromURL(rawURL string) (string, bool) {
+	if !strings.HasPrefix(rawURL, s.publicURL+"/") {
+		return "", false
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(rawURL, s.publicURL+"/"))
+	if err != nil || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *S3Storage) objectURL(key string) string {
+	u := s.endpoint + "/" + s.bucket
+	if key != "" {
+		u += "/" + s3EscapePath(key)
+	}
+	return u
+}
+
+// do mengirim request yang ditandatangani SigV4 (header Authorization).
+func (s *S3Storage) do(method, key string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	if s.endpoint == "" || s.bucket == "" || s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT / S3_BUCKET / S3_ACCESS_KEY / S3_SECRET_KEY belum dikonfigurasi")
+	}
+
+	u, err := url.Parse(s.objectURL(key))
+	if err != nil {
+		return nil, err
+	}
+	if query != nil {
+		u.RawQuery = s3CanonicalQuery(query)
+	}
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		u.RawQuery,
+		"host:" + u.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, s.signature(now, scope, canonical)))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+		return resp, nil
+	}
+	if resp.StatusCode >= 300 {
+		raw, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrStorageObjectNotFound
+		}
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(raw))
+	}
+	return resp, nil
+}
+
+func (s *S3Storage) signature(now time.Time, scope, canonicalRequest string) string {
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format("20060102T150405Z"),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// s3Escape meng-encode sesuai aturan SigV4 (hanya A-Z a-z 0-9 - _ . ~ yang tidak di-encode).
+func s3Escape(v string) string {
+	var b strings.Builder
+	for _, c := range []byte(v) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = s3Escape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrTokenDecrypt dikembalikan saat token terenkripsi rusak atau dienkripsi dengan key lain.
+var ErrTokenDecrypt = errors.New("token terenkripsi tidak dapat dibaca")
+
+const tokenCipherPrefix = "v1:"
+
+// TokenCipher mengenkripsi token OAuth akun terhubung dengan AES-256-GCM sebelum
+// disimpan di database. Format hasil: "v1:" + base64(nonce || ciphertext).
+type TokenCipher struct {
+	aead cipher.AEAD
+}
+
+// NewTokenCipher membutuhkan key 32 byte.
+func NewTokenCipher(key []byte) (*TokenCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key enkripsi token harus 32 byte, didapat %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenCipher{aead: aead}, nil
+}
+
+// Encrypt mengembalikan string kosong untuk token kosong (mis. provider tanpa refresh token).
+func (c *TokenCipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return tokenCipherPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *TokenCipher) Decrypt(encrypted string) (string, error) {
+	if encrypted == "" {
+		return "", nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, tokenCipherPrefix))
+	if err != nil || !strings.HasPrefix(encrypted, tokenCipherPrefix) || len(raw) < c.aead.NonceSize() {
+		return "", ErrTokenDecrypt
+	}
+	nonce, sealed := raw[:c.aead.NonceSize()], raw[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrTokenDecrypt
+	}
+	return string(plain), nil
+}
@@ -0,0 +1,310 @@
+package helper
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Algoritma COSE yang didukung (https://www.iana.org/assignments/cose).
+const (
+	COSEAlgES256 int64 = -7
+	COSEAlgRS256 int64 = -257
+)
+
+// Flag authenticator data (WebAuthn Level 2, §6.1).
+const (
+	authFlagUserPresent    byte = 0x01
+	authFlagUserVerified   byte = 0x04
+	authFlagBackupEligible byte = 0x08
+	authFlagBackupState    byte = 0x10
+	authFlagAttestedData   byte = 0x40
+	authFlagExtensionData  byte = 0x80
+)
+
+// WebAuthnClientData adalah isi clientDataJSON yang ditandatangani authenticator.
+type WebAuthnClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// WebAuthnAuthenticatorData adalah hasil parsing authenticatorData.
+type WebAuthnAuthenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte // COSE key (CBOR mentah), hanya ada saat registrasi
+}
+
+func (a WebAuthnAuthenticatorData) UserPresent() bool    { return a.Flags&authFlagUserPresent != 0 }
+func (a WebAuthnAuthenticatorData) UserVerified() bool   { return a.Flags&authFlagUserVerified != 0 }
+func (a WebAuthnAuthenticatorData) BackupEligible() bool { return a.Flags&authFlagBackupEligible != 0 }
+func (a WebAuthnAuthenticatorData) BackupState() bool    { return a.Flags&authFlagBackupState != 0 }
+
+// WebAuthnAttestation adalah hasil parsing attestationObject.
+type WebAuthnAttestation struct {
+	Format   string
+	AttStmt  map[interface{}]interface{}
+	AuthData WebAuthnAuthenticatorData
+	RawAuth  []byte
+}
+
+// DecodeBase64URL menerima base64url tanpa/dengan padding, juga base64 standar,
+// karena format encoding dari client (browser, Android, iOS) tidak seragam.
+func DecodeBase64URL(s string) ([]byte, error) {
+	s = strings.TrimRight(s, "=")
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(s)
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// ParseWebAuthnClientData mendecode clientDataJSON (base64url) dan mengembalikan
+// isinya beserta byte mentah untuk di-hash.
+func ParseWebAuthnClientData(encoded string) (WebAuthnClientData, []byte, error) {
+	raw, err := DecodeBase64URL(encoded)
+	if err != nil {
+		return WebAuthnClientData{}, nil, errors.New("clientDataJSON bukan base64url yang valid")
+	}
+
+	var cd WebAuthnClientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return WebAuthnClientData{}, nil, errors.New("clientDataJSON tidak valid")
+	}
+	return cd, raw, nil
+}
+
+// ParseWebAuthnAuthenticatorData membaca struktur biner authenticatorData:
+// rpIdHash(32) | flags(1) | signCount(4) | [attestedCredentialData] | [extensions].
+func ParseWebAuthnAuthenticatorData(raw []byte) (WebAuthnAuthenticatorData, error) {
+	if len(raw) < 37 {
+		return WebAuthnAuthenticatorData{}, errors.New("authenticatorData terlalu pendek")
+	}
+
+	ad := WebAuthnAuthenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if ad.Flags&authFlagAttestedData != 0 {
+		if len(rest) < 18 {
+			return ad, errors.New("attested credential data terpotong")
+		}
+		ad.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLen {
+			return ad, errors.New("credential id terpotong")
+		}
+		ad.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		_, used, err := CBORDecode(rest)
+		if err != nil {
+			return ad, fmt.Errorf("credential public key tidak valid: %v", err)
+		}
+		ad.PublicKey = rest[:used]
+		rest = rest[used:]
+	}
+
+	if ad.Flags&authFlagExtensionData != 0 {
+		_, used, err := CBORDecode(rest)
+		if err != nil {
+			return ad, fmt.Errorf("extension data tidak valid: %v", err)
+		}
+		rest = rest[used:]
+	}
+
+	if len(rest) != 0 {
+		return ad, errors.New("authenticatorData memiliki byte sisa")
+	}
+	return ad, nil
+}
+
+// ParseWebAuthnAttestation mendecode attestationObject (CBOR) {fmt, attStmt, authData}.
+func ParseWebAuthnAttestation(encoded string) (WebAuthnAttestation, error) {
+	raw, err := DecodeBase64URL(encoded)
+	if err != nil {
+		return WebAuthnAttestation{}, errors.New("attestationObject bukan base64url yang valid")
+	}
+
+	decoded, _, err := CBORDecode(raw)
+	if err != nil {
+		return WebAuthnAttestation{}, fmt.Errorf("attestationObject tidak valid: %v", err)
+	}
+	m, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return WebAuthnAttestation{}, errors.New("attestationObject harus berupa map")
+	}
+
+	format, _ := m["fmt"].(string)
+	attStmt, _ := m["attStmt"].(map[interface{}]interface{})
+	rawAuth, _ := m["authData"].([]byte)
+	if format == "" || rawAuth == nil {
+		return WebAuthnAttestation{}, errors.New("attestationObject tidak lengkap")
+	}
+
+	authData, err := ParseWebAuthnAuthenticatorData(rawAuth)
+	if err != nil {
+		return WebAuthnAttestation{}, err
+	}
+
+	return WebAuthnAttestation{Format: format, AttStmt: attStmt, AuthData: authData, RawAuth: rawAuth}, nil
+}
+
+// VerifyStatement memverifikasi attestation statement untuk format "none" dan
+// "packed" (self attestation atau x5c). Rantai sertifikat x5c tidak divalidasi
+// ke root CA karena server tidak membatasi model authenticator. Format lain
+// diterima tanpa verifikasi statement (setara attestation "none").
+func (a WebAuthnAttestation) VerifyStatement(clientDataHash []byte) error {
+	switch a.Format {
+	case "none":
+		if len(a.AttStmt) != 0 {
+			return errors.New("attStmt harus kosong untuk format none")
+		}
+		return nil
+	case "packed":
+		alg, ok := cborInt(a.AttStmt["alg"])
+		if !ok {
+			return errors.New("attStmt packed tanpa alg")
+		}
+		sig, ok := a.AttStmt["sig"].([]byte)
+		if !ok {
+			return errors.New("attStmt packed tanpa sig")
+		}
+		signed := append(append([]byte{}, a.RawAuth...), clientDataHash...)
+
+		if x5c, ok := a.AttStmt["x5c"].([]interface{}); ok && len(x5c) > 0 {
+			der, ok := x5c[0].([]byte)
+			if !ok {
+				return errors.New("sertifikat attestation tidak valid")
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return fmt.Errorf("sertifikat attestation tidak valid: %v", err)
+			}
+			return VerifyWebAuthnSignature(alg, cert.PublicKey, signed, sig)
+		}
+
+		// Self attestation: ditandatangani dengan credential key itu sendiri
+		credAlg, pub, err := ParseCOSEPublicKey(a.AuthData.PublicKey)
+		if err != nil {
+			return err
+		}
+		if credAlg != alg {
+			return errors.New("alg attestation tidak sama dengan alg credential")
+		}
+		return VerifyWebAuthnSignature(alg, pub, signed, sig)
+	}
+
+	return nil
+}
+
+// ParseCOSEPublicKey mengubah COSE_Key (EC2 P-256 / RSA) menjadi public key Go.
+func ParseCOSEPublicKey(raw []byte) (int64, crypto.PublicKey, error) {
+	decoded, _, err := CBORDecode(raw)
+	if err != nil {
+		return 0, nil, fmt.Errorf("COSE key tidak valid: %v", err)
+	}
+	m, ok := decoded.(map[interface{}]interface{})
+	if !ok {
+		return 0, nil, errors.New("COSE key harus berupa map")
+	}
+
+	kty, _ := cborMapGetInt(m, 1)
+	alg, ok := cborMapGetInt(m, 3)
+	if !ok {
+		return 0, nil, errors.New("COSE key tanpa alg")
+	}
+
+	switch alg {
+	case COSEAlgES256:
+		crv, _ := cborMapGetInt(m, -1)
+		x, _ := cborMapGetBytes(m, -2)
+		y, _ := cborMapGetBytes(m, -3)
+		if kty != 2 || crv != 1 || len(x) != 32 || len(y) != 32 {
+			return 0, nil, errors.New("COSE key ES256 harus EC2 P-256")
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return 0, nil, errors.New("titik EC tidak berada di kurva P-256")
+		}
+		return alg, pub, nil
+	case COSEAlgRS256:
+		n, _ := cborMapGetBytes(m, -1)
+		e, _ := cborMapGetBytes(m, -2)
+		if kty != 3 || len(n) == 0 || len(e) == 0 || len(e) > 4 {
+			return 0, nil, errors.New("COSE key RS256 tidak valid")
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if pub.N.BitLen() < 2048 {
+			return 0, nil, errors.New("kunci RSA minimal 2048 bit")
+		}
+		return alg, pub, nil
+	}
+
+	return 0, nil, fmt.Errorf("algoritma COSE %d tidak didukung", alg)
+}
+
+// VerifyWebAuthnSignature memverifikasi signature ES256 (DER) atau RS256 (PKCS#1 v1.5).
+func VerifyWebAuthnSignature(alg int64, pub crypto.PublicKey, data, sig []byte) error {
+	digest := sha256.Sum256(data)
+
+	switch alg {
+	case COSEAlgES256:
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok || !ecdsa.VerifyASN1(key, digest[:], sig) {
+			return errors.New("signature tidak valid")
+		}
+		return nil
+	case COSEAlgRS256:
+		key, ok := pub.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) != nil {
+			return errors.New("signature tidak valid")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("algoritma %d tidak didukung", alg)
+}
+
+// VerifyRPIDHash memastikan authenticator membuat credential untuk RP ID server.
+func VerifyRPIDHash(authData WebAuthnAuthenticatorData, rpID string) error {
+	expected := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(authData.RPIDHash, expected[:]) {
+		return errors.New("rpIdHash tidak cocok")
+	}
+	return nil
+}
+
+func cborMapGetInt(m map[interface{}]interface{}, key int64) (int64, bool) {
+	v, ok := cborMapGet(m, key)
+	if !ok {
+		return 0, false
+	}
+	return cborInt(v)
+}
+
+func cborMapGetBytes(m map[interface{}]interface{}, key int64) ([]byte, bool) {
+	v, ok := cborMapGet(m, key)
+	if !ok {
+		return nil, false
+	}
+	b, ok := v.([]byte)
+	return b, ok
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"run-sync/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimitBody membatasi ukuran body request. Content-Length yang sudah melebihi batas
+// langsung ditolak 413; body tanpa Content-Length dipotong oleh http.MaxBytesReader
+// sehingga handler mendapat *http.MaxBytesError saat membacanya.
+func LimitBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, helper.BuildErrorResponse(
+				"Ukuran request terlalu besar", "PAYLOAD_TOO_LARGE", "body", fmt.Sprintf("maksimal %d KB", maxBytes/1024), nil,
+			))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
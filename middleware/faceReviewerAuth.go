@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"run-sync/config"
+	"run-sync/helper"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuthorizeFaceReviewer restricts the face review queue to user ids listed in FACE_REVIEWER_IDS.
+// Must be used AFTER AuthorizeJWT middleware.
+func AuthorizeFaceReviewer(cfg *config.FaceVerificationConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := c.MustGet("user_id").(uuid.UUID)
+
+		if !cfg.ReviewerIds[userId] {
+			c.AbortWithStatusJSON(http.StatusForbidden, helper.BuildErrorResponse(
+				"Akses ditolak", "FORBIDDEN", "user", "Hanya reviewer yang dapat mengakses fitur ini", nil,
+			))
+			return
+		}
+
+		c.Next()
+	}
+}
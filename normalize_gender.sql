@@ -0,0 +1,15 @@
+-- Normalisasi users.gender lama (input bebas) ke 'male' / 'female'.
+-- Jalankan sekali sebelum deploy enforcement women-only; nilai yang tidak dikenali dikosongkan.
+UPDATE users SET gender = 'female'
+WHERE LOWER(TRIM(gender)) IN ('female', 'f', 'woman', 'p', 'perempuan', 'wanita', 'cewek');
+
+UPDATE users SET gender = 'male'
+WHERE LOWER(TRIM(gender)) IN ('male', 'm', 'man', 'l', 'laki-laki', 'laki laki', 'lakilaki', 'pria', 'cowok');
+
+UPDATE users SET gender = NULL
+WHERE gender IS NOT NULL AND gender NOT IN ('male', 'female');
+
+-- Women-only mode hanya berlaku untuk perempuan
+UPDATE runner_profiles rp SET women_only_mode = false
+FROM users u
+WHERE u.id = rp.user_id AND rp.women_only_mode AND COALESCE(u.gender, '') <> 'female';
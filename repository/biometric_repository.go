@@ -10,9 +10,16 @@ import (
 type BiometricRepository interface {
 	Create(biometric *entity.UserBiometric) error
 	Update(biometric *entity.UserBiometric) error
+	FindById(id uuid.UUID) (*entity.UserBiometric, error)
 	FindByCredentialId(credentialId string) (*entity.UserBiometric, error)
 	FindByUserId(userId uuid.UUID) ([]entity.UserBiometric, error)
+	FindAllByUserId(userId uuid.UUID) ([]entity.UserBiometric, error)
 	Delete(id uuid.UUID) error
+
+	// Login history
+	CreateLoginHistory(history *entity.BiometricLoginHistory) error
+	FindLoginHistory(biometricId uuid.UUID, page, limit int) ([]entity.BiometricLoginHistory, int64, error)
+	FindRecentLocations(userId uuid.UUID, limit int) ([]string, error)
 }
 
 type biometricRepository struct {
@@ -31,21 +38,74 @@ func (r *biometricRepository) Update(biometric *entity.UserBiometric) error {
 	return r.db.Save(biometric).Error
 }
 
+func (r *biometricRepository) FindById(id uuid.UUID) (*entity.UserBiometric, error) {
+	var biometric entity.UserBiometric
+	err := r.db.First(&biometric, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &biometric, nil
+}
+
+// FindByCredentialId mengembalikan credential aktif maupun nonaktif; pemanggil
+// yang memutuskan apakah credential nonaktif boleh dipakai.
 func (r *biometricRepository) FindByCredentialId(credentialId string) (*entity.UserBiometric, error) {
 	var biometric entity.UserBiometric
-	err := r.db.Where("credential_id = ? AND is_active = true", credentialId).First(&biometric).Error
+	err := r.db.Where("credential_id = ?", credentialId).First(&biometric).Error
 	if err != nil {
 		return nil, err
 	}
 	return &biometric, nil
 }
 
+// FindByUserId hanya mengembalikan credential aktif (dipakai untuk login).
 func (r *biometricRepository) FindByUserId(userId uuid.UUID) ([]entity.UserBiometric, error) {
 	var biometrics []entity.UserBiometric
 	err := r.db.Where("user_id = ? AND is_active = true", userId).Find(&biometrics).Error
 	return biometrics, err
 }
 
+// FindAllByUserId mengembalikan semua credential user termasuk yang nonaktif.
+func (r *biometricRepository) FindAllByUserId(userId uuid.UUID) ([]entity.UserBiometric, error) {
+	var biometrics []entity.UserBiometric
+	err := r.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&biometrics).Error
+	return biometrics, err
+}
+
 func (r *biometricRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&entity.UserBiometric{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.BiometricLoginHistory{}, "biometric_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.UserBiometric{}, "id = ?", id).Error
+	})
+}
+
+func (r *biometricRepository) CreateLoginHistory(history *entity.BiometricLoginHistory) error {
+	return r.db.Create(history).Error
+}
+
+func (r *biometricRepository) FindLoginHistory(biometricId uuid.UUID, page, limit int) ([]entity.BiometricLoginHistory, int64, error) {
+	var histories []entity.BiometricLoginHistory
+	var total int64
+
+	query := r.db.Model(&entity.BiometricLoginHistory{}).Where("biometric_id = ?", biometricId)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&histories).Error
+	return histories, total, err
+}
+
+// FindRecentLocations mengembalikan lokasi dari login biometrik terakhir user
+// (semua credential), terbaru lebih dulu.
+func (r *biometricRepository) FindRecentLocations(userId uuid.UUID, limit int) ([]string, error) {
+	var locations []string
+	err := r.db.Model(&entity.BiometricLoginHistory{}).
+		Where("user_id = ?", userId).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("location", &locations).Error
+	return locations, err
 }
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"run-sync/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CandidatePassRepository interface {
+	Find(userId, passedUserId uuid.UUID) (*entity.CandidatePass, error)
+	Save(pass *entity.CandidatePass) error
+	Delete(userId, passedUserId uuid.UUID) error
+}
+
+type candidatePassRepository struct {
+	db *gorm.DB
+}
+
+func NewCandidatePassRepository(db *gorm.DB) CandidatePassRepository {
+	return &candidatePassRepository{db: db}
+}
+
+func (r *candidatePassRepository) Find(userId, passedUserId uuid.UUID) (*entity.CandidatePass, error) {
+	var pass entity.CandidatePass
+	err := r.db.Where("user_id = ? AND passed_user_id = ?", userId, passedUserId).First(&pass).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pass, nil
+}
+
+func (r *candidatePassRepository) Save(pass *entity.CandidatePass) error {
+	return r.db.Save(pass).Error
+}
+
+func (r *candidatePassRepository) Delete(userId, passedUserId uuid.UUID) error {
+	return r.db.Delete(&entity.CandidatePass{}, "user_id = ? AND passed_user_id = ?", userId, passedUserId).Error
+}
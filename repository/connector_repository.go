@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"run-sync/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ConnectorRepository interface {
+	CreateAccount(account *entity.ConnectorAccount) error
+	UpdateAccount(account *entity.ConnectorAccount) error
+	DeleteAccount(id uuid.UUID) error
+	FindAccount(userId uuid.UUID, provider string) (*entity.ConnectorAccount, error)
+	FindAccountByExternalId(provider, externalUserId string) (*entity.ConnectorAccount, error)
+	FindAccountsByUserId(userId uuid.UUID) ([]entity.ConnectorAccount, error)
+	// FindBackfillDue mengembalikan akun aktif yang backfill-nya belum selesai dan
+	// tidak sedang ditahan rate limit.
+	FindBackfillDue(now time.Time, limit int) ([]entity.ConnectorAccount, error)
+
+	CreateEvents(events []entity.ConnectorEvent) error
+	UpdateEvent(event *entity.ConnectorEvent) error
+	// FindDueEvents mengembalikan event pending yang sudah waktunya diproses, terlama dulu.
+	FindDueEvents(now time.Time, limit int) ([]entity.ConnectorEvent, error)
+}
+
+type connectorRepository struct {
+	db *gorm.DB
+}
+
+func NewConnectorRepository(db *gorm.DB) ConnectorRepository {
+	return &connectorRepository{db: db}
+}
+
+func (r *connectorRepository) CreateAccount(account *entity.ConnectorAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *connectorRepository) UpdateAccount(account *entity.ConnectorAccount) error {
+	return r.db.Save(account).Error
+}
+
+func (r *connectorRepository) DeleteAccount(id uuid.UUID) error {
+	return r.db.Delete(&entity.ConnectorAccount{}, "id = ?", id).Error
+}
+
+func (r *connectorRepository) FindAccount(userId uuid.UUID, provider string) (*entity.ConnectorAccount, error) {
+	var account entity.ConnectorAccount
+	err := r.db.First(&account, "user_id = ? AND provider = ?", userId, provider).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *connectorRepository) FindAccountByExternalId(provider, externalUserId string) (*entity.ConnectorAccount, error) {
+	var account entity.ConnectorAccount
+	err := r.db.First(&account, "provider = ? AND external_user_id = ?", provider, externalUserId).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *connectorRepository) FindAccountsByUserId(userId uuid.UUID) ([]entity.ConnectorAccount, error) {
+	var accounts []entity.ConnectorAccount
+	err := r.db.Where("user_id = ?", userId).Order("created_at").Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *connectorRepository) FindBackfillDue(now time.Time, limit int) ([]entity.ConnectorAccount, error) {
+	var accounts []entity.ConnectorAccount
+	err := r.db.Where("status = ? AND backfill_status IN ? AND (next_sync_at IS NULL OR next_sync_at <= ?)",
+		entity.ConnectorActive, []string{entity.BackfillPending, entity.BackfillRunning}, now).
+		Order("updated_at").
+		Limit(limit).
+		Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *connectorRepository) CreateEvents(events []entity.ConnectorEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.Create(&events).Error
+}
+
+func (r *connectorRepository) UpdateEvent(event *entity.ConnectorEvent) error {
+	return r.db.Save(event).Error
+}
+
+func (r *connectorRepository) FindDueEvents(now time.Time, limit int) ([]entity.ConnectorEvent, error) {
+	var events []entity.ConnectorEvent
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", entity.ConnectorEventPending, now).
+		Order("created_at").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"run-sync/entity"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -14,6 +15,10 @@ type DirectMatchRepository interface {
 	FindByUsers(user1Id, user2Id uuid.UUID) (*entity.DirectMatch, error)
 	FindUserMatches(userId uuid.UUID) ([]entity.DirectMatch, error)
 	FindMatchesByStatus(userId uuid.UUID, status string) ([]entity.DirectMatch, error)
+	FindExpiredPending(now, legacyCutoff time.Time) ([]entity.DirectMatch, error)
+	// TransitionStatus mengubah status hanya jika status saat ini masih from;
+	// false berarti match sudah diproses proses lain.
+	TransitionStatus(match *entity.DirectMatch, from string) (bool, error)
 	Delete(id uuid.UUID) error
 	DB() *gorm.DB
 }
@@ -48,16 +53,18 @@ func (r *directMatchRepository) FindByUsers(user1Id, user2Id uuid.UUID) (*entity
 	err := r.db.Where(
 		"(user1_id = ? AND user2_id = ?) OR (user1_id = ? AND user2_id = ?)",
 		user1Id, user2Id, user2Id, user1Id,
-	).First(&match).Error
+	).Order("created_at DESC").First(&match).Error
 	if err != nil {
 		return nil, err
 	}
 	return &match, nil
 }
 
+// FindUserMatches tidak menyertakan match yang sudah di-unmatch (hilang dari inbox kedua user).
 func (r *directMatchRepository) FindUserMatches(userId uuid.UUID) ([]entity.DirectMatch, error) {
 	var matches []entity.DirectMatch
-	err := r.db.Where("user1_id = ? OR user2_id = ?", userId, userId).Order("created_at DESC").Find(&matches).Error
+	err := r.db.Where("(user1_id = ? OR user2_id = ?) AND status <> ?", userId, userId, "unmatched").
+		Order("created_at DESC").Find(&matches).Error
 	return matches, err
 }
 
@@ -70,6 +77,28 @@ func (r *directMatchRepository) FindMatchesByStatus(userId uuid.UUID, status str
 	return matches, err
 }
 
+// FindExpiredPending mengembalikan request pending yang sudah lewat expires_at. Request
+// lama tanpa expires_at dianggap kedaluwarsa jika dibuat sebelum legacyCutoff.
+func (r *directMatchRepository) FindExpiredPending(now, legacyCutoff time.Time) ([]entity.DirectMatch, error) {
+	var matches []entity.DirectMatch
+	err := r.db.Where(
+		"status = ? AND ((expires_at IS NOT NULL AND expires_at <= ?) OR (expires_at IS NULL AND created_at <= ?))",
+		"pending", now, legacyCutoff,
+	).Find(&matches).Error
+	return matches, err
+}
+
+func (r *directMatchRepository) TransitionStatus(match *entity.DirectMatch, from string) (bool, error) {
+	res := r.db.Model(&entity.DirectMatch{}).
+		Where("id = ? AND status = ?", match.Id, from).
+		Updates(map[string]interface{}{
+			"status":    match.Status,
+			"closed_at": match.ClosedAt,
+			"closed_by": match.ClosedBy,
+		})
+	return res.RowsAffected == 1, res.Error
+}
+
 func (r *directMatchRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&entity.DirectMatch{}, "id = ?", id).Error
 }
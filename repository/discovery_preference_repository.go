@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"run-sync/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DiscoveryPreferenceRepository interface {
+	FindByUserId(userId uuid.UUID) (*entity.DiscoveryPreference, error)
+	Save(pref *entity.DiscoveryPreference) error
+}
+
+type discoveryPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewDiscoveryPreferenceRepository(db *gorm.DB) DiscoveryPreferenceRepository {
+	return &discoveryPreferenceRepository{db: db}
+}
+
+func (r *discoveryPreferenceRepository) FindByUserId(userId uuid.UUID) (*entity.DiscoveryPreference, error) {
+	var pref entity.DiscoveryPreference
+	err := r.db.Where("user_id = ?", userId).First(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+func (r *discoveryPreferenceRepository) Save(pref *entity.DiscoveryPreference) error {
+	return r.db.Save(pref).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"run-sync/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FaceVerificationRepository interface {
+	Create(v *entity.FaceVerification) error
+	Update(v *entity.FaceVerification) error
+	FindById(id uuid.UUID) (*entity.FaceVerification, error)
+	FindLatestByUser(userId uuid.UUID) (*entity.FaceVerification, error)
+	// FindReviewQueue mengembalikan percobaan pending_review, terlama lebih dulu.
+	FindReviewQueue(page, limit int) ([]entity.FaceVerification, int64, error)
+	// ExpireOpenChallenges menutup challenge yang belum dijawab milik user.
+	ExpireOpenChallenges(userId uuid.UUID) error
+}
+
+type faceVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewFaceVerificationRepository(db *gorm.DB) FaceVerificationRepository {
+	return &faceVerificationRepository{db: db}
+}
+
+func (r *faceVerificationRepository) Create(v *entity.FaceVerification) error {
+	return r.db.Create(v).Error
+}
+
+func (r *faceVerificationRepository) Update(v *entity.FaceVerification) error {
+	return r.db.Save(v).Error
+}
+
+func (r *faceVerificationRepository) FindById(id uuid.UUID) (*entity.FaceVerification, error) {
+	var v entity.FaceVerification
+	if err := r.db.First(&v, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *faceVerificationRepository) FindLatestByUser(userId uuid.UUID) (*entity.FaceVerification, error) {
+	var v entity.FaceVerification
+	if err := r.db.Where("user_id = ?", userId).Order("created_at DESC").First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *faceVerificationRepository) FindReviewQueue(page, limit int) ([]entity.FaceVerification, int64, error) {
+	var items []entity.FaceVerification
+	var total int64
+
+	query := r.db.Model(&entity.FaceVerification{}).Where("status = ?", entity.FaceAttemptReview)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at ASC").Offset((page - 1) * limit).Limit(limit).Find(&items).Error
+	return items, total, err
+}
+
+func (r *faceVerificationRepository) ExpireOpenChallenges(userId uuid.UUID) error {
+	return r.db.Model(&entity.FaceVerification{}).
+		Where("user_id = ? AND status = ?", userId, entity.FaceAttemptChallenged).
+		Update("status", entity.FaceAttemptExpired).Error
+}
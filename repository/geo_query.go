@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+)
+
+// earthRadiusKm sama dengan radius yang dipakai service.Haversine
+const earthRadiusKm = 6371.0
+
+// haversineSQL membangun ekspresi jarak (km) Haversine untuk kolom lat/lng.
+// Parameter: lat, lat, lng (titik acuan). Bentuk asin+sqrt dipakai (bukan acos)
+// agar titik yang sama persis tidak menghasilkan error domain di Postgres.
+func haversineSQL(latCol, lngCol string) string {
+	return fmt.Sprintf(
+		"(%[1]f * asin(LEAST(1, sqrt(power(sin(radians(%[2]s - ?) / 2), 2) + cos(radians(?)) * cos(radians(%[2]s)) * power(sin(radians(%[3]s - ?) / 2), 2)))))",
+		2*earthRadiusKm, latCol, lngCol,
+	)
+}
+
+// boundingBox mengembalikan batas lat/lng kasar untuk radius tertentu, sehingga
+// index (latitude, longitude) bisa dipakai sebelum Haversine dihitung.
+func boundingBox(lat, lng, radiusKm float64) (minLat, maxLat, minLng, maxLng float64) {
+	const kmPerDegree = 111.045
+	latDelta := radiusKm / kmPerDegree
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if cosLat < 0.01 {
+		cosLat = 0.01
+	}
+	lngDelta := radiusKm / (kmPerDegree * cosLat)
+	return lat - latDelta, lat + latDelta, lng - lngDelta, lng + lngDelta
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"run-sync/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MatchWeightRepository interface {
+	FindByUserId(userId uuid.UUID) ([]entity.MatchWeight, error)
+	ReplaceForUser(userId uuid.UUID, weights []entity.MatchWeight) error
+	DeleteByUserId(userId uuid.UUID) error
+}
+
+type matchWeightRepository struct {
+	db *gorm.DB
+}
+
+func NewMatchWeightRepository(db *gorm.DB) MatchWeightRepository {
+	return &matchWeightRepository{db: db}
+}
+
+func (r *matchWeightRepository) FindByUserId(userId uuid.UUID) ([]entity.MatchWeight, error) {
+	var weights []entity.MatchWeight
+	err := r.db.Where("user_id = ?", userId).Find(&weights).Error
+	return weights, err
+}
+
+// ReplaceForUser mengganti seluruh bobot user dalam satu transaksi.
+func (r *matchWeightRepository) ReplaceForUser(userId uuid.UUID, weights []entity.MatchWeight) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.MatchWeight{}, "user_id = ?", userId).Error; err != nil {
+			return err
+		}
+		if len(weights) == 0 {
+			return nil
+		}
+		return tx.Create(&weights).Error
+	})
+}
+
+func (r *matchWeightRepository) DeleteByUserId(userId uuid.UUID) error {
+	return r.db.Delete(&entity.MatchWeight{}, "user_id = ?", userId).Error
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"run-sync/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PhotoUploadRepository interface {
+	Create(upload *entity.PhotoUpload) error
+	Update(upload *entity.PhotoUpload) error
+	FindById(id uuid.UUID) (*entity.PhotoUpload, error)
+}
+
+type photoUploadRepository struct {
+	db *gorm.DB
+}
+
+func NewPhotoUploadRepository(db *gorm.DB) PhotoUploadRepository {
+	return &photoUploadRepository{db: db}
+}
+
+func (r *photoUploadRepository) Create(upload *entity.PhotoUpload) error {
+	return r.db.Create(upload).Error
+}
+
+func (r *photoUploadRepository) Update(upload *entity.PhotoUpload) error {
+	return r.db.Save(upload).Error
+}
+
+func (r *photoUploadRepository) FindById(id uuid.UUID) (*entity.PhotoUpload, error) {
+	var upload entity.PhotoUpload
+	if err := r.db.First(&upload, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
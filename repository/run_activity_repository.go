@@ -1,22 +1,75 @@
 package repository
 
 import (
+	"fmt"
 	"run-sync/entity"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RunActivityRepository interface {
 	Create(activity *entity.RunActivity) error
 	Update(activity *entity.RunActivity) error
+	// UpdateWithSplits menyimpan aktivitas dan mengganti seluruh split-nya dalam satu transaksi.
+	UpdateWithSplits(activity *entity.RunActivity, splits []*entity.RunActivitySplit) error
 	FindById(id uuid.UUID) (*entity.RunActivity, error)
 	FindByUserId(userId uuid.UUID) ([]entity.RunActivity, error)
-	FindAll() ([]entity.RunActivity, error)
+	// FindRecentByUserId mengembalikan maksimal limit aktivitas terbaru sejak since (tanpa split).
+	FindRecentByUserId(userId uuid.UUID, since time.Time, limit int) ([]entity.RunActivity, error)
+	// FindByExternalId mencari aktivitas yang tertaut ke aktivitas provider (gorm.ErrRecordNotFound bila belum ada).
+	FindByExternalId(provider, externalId string) (*entity.RunActivity, error)
+	// FindUnlinkedBetween mengembalikan aktivitas user yang belum tertaut ke provider
+	// dengan waktu aktivitas (start_time, atau created_at untuk input manual) dalam [from, to].
+	FindUnlinkedBetween(userId uuid.UUID, from, to time.Time) ([]entity.RunActivity, error)
+	// ExistsByFileHash mengecek apakah file yang sama sudah pernah diimpor user.
+	ExistsByFileHash(userId uuid.UUID, hash string) (bool, error)
+	// FindVisible mengembalikan satu halaman aktivitas (tanpa split) milik viewer dan
+	// user yang terhubung dengannya, terbaru dulu, beserta total.
+	FindVisible(viewerId uuid.UUID, limit, offset int) ([]entity.RunActivity, int64, error)
+	// IsConnected bernilai true bila viewer dan owner punya match accepted atau sama-sama
+	// anggota joined di satu run group.
+	IsConnected(viewerId, ownerId uuid.UUID) (bool, error)
 	Delete(id uuid.UUID) error
 	GetUserStats(userId uuid.UUID) (map[string]interface{}, error)
+	// FindFastestSplit mengembalikan split 1 km penuh tercepat user, nil jika belum ada.
+	FindFastestSplit(userId uuid.UUID) (*FastestSplit, error)
+	// GetHeartRateSamples mengembalikan total detik per bpm dari split (atau rata-rata
+	// aktivitas bila aktivitas tidak punya split dengan detak jantung).
+	GetHeartRateSamples(userId uuid.UUID) ([]HeartRateSample, error)
+	// MaxHeartRate mengembalikan detak jantung tertinggi yang pernah tercatat user.
+	MaxHeartRate(userId uuid.UUID) (int, error)
+	// GetVolumeSeries mengagregasi volume per minggu / bulan (unit "week" atau "month",
+	// date_trunc di zona Asia/Jakarta) untuk aktivitas sejak since.
+	GetVolumeSeries(userId uuid.UUID, unit string, since time.Time) ([]VolumeBucket, error)
+	// GetVolumeTotals menjumlahkan volume aktivitas pada rentang [from, to).
+	GetVolumeTotals(userId uuid.UUID, from, to time.Time) (VolumeBucket, error)
+	// GetActivityDays mengembalikan tanggal (WIB, YYYY-MM-DD) yang memiliki aktivitas.
+	GetActivityDays(userId uuid.UUID) ([]string, error)
 }
 
+type FastestSplit struct {
+	entity.RunActivitySplit
+	RecordedAt time.Time // start_time aktivitas, atau created_at untuk input manual
+}
+
+type HeartRateSample struct {
+	HeartRate int
+	Seconds   int
+}
+
+type VolumeBucket struct {
+	PeriodStart time.Time // awal periode (jam dinding WIB)
+	Runs        int
+	Distance    float64 // km
+	Duration    int     // seconds
+}
+
+// activityTimeWIB adalah waktu aktivitas (start_time file impor, atau created_at) dalam WIB.
+const activityTimeWIB = "(COALESCE(start_time, created_at) AT TIME ZONE 'Asia/Jakarta')"
+
 type runActivityRepository struct {
 	db *gorm.DB
 }
@@ -30,12 +83,33 @@ func (r *runActivityRepository) Create(activity *entity.RunActivity) error {
 }
 
 func (r *runActivityRepository) Update(activity *entity.RunActivity) error {
-	return r.db.Save(activity).Error
+	return r.db.Omit(clause.Associations).Save(activity).Error
+}
+
+func (r *runActivityRepository) UpdateWithSplits(activity *entity.RunActivity, splits []*entity.RunActivitySplit) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(activity).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("activity_id = ?", activity.Id).Delete(&entity.RunActivitySplit{}).Error; err != nil {
+			return err
+		}
+		for _, split := range splits {
+			split.ActivityId = activity.Id
+		}
+		if len(splits) > 0 {
+			if err := tx.Create(&splits).Error; err != nil {
+				return err
+			}
+		}
+		activity.Splits = splits
+		return nil
+	})
 }
 
 func (r *runActivityRepository) FindById(id uuid.UUID) (*entity.RunActivity, error) {
 	var activity entity.RunActivity
-	err := r.db.First(&activity, "id = ?", id).Error
+	err := r.db.Preload("Splits", orderSplits).First(&activity, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -44,18 +118,89 @@ func (r *runActivityRepository) FindById(id uuid.UUID) (*entity.RunActivity, err
 
 func (r *runActivityRepository) FindByUserId(userId uuid.UUID) ([]entity.RunActivity, error) {
 	var activities []entity.RunActivity
-	err := r.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&activities).Error
+	err := r.db.Preload("Splits", orderSplits).Where("user_id = ?", userId).Order("created_at DESC").Find(&activities).Error
+	return activities, err
+}
+
+func (r *runActivityRepository) FindRecentByUserId(userId uuid.UUID, since time.Time, limit int) ([]entity.RunActivity, error) {
+	var activities []entity.RunActivity
+	err := r.db.Where("user_id = ? AND COALESCE(start_time, created_at) >= ?", userId, since).
+		Order("COALESCE(start_time, created_at) DESC").
+		Limit(limit).
+		Find(&activities).Error
 	return activities, err
 }
 
-func (r *runActivityRepository) FindAll() ([]entity.RunActivity, error) {
+func (r *runActivityRepository) FindByExternalId(provider, externalId string) (*entity.RunActivity, error) {
+	var activity entity.RunActivity
+	err := r.db.Preload("Splits", orderSplits).
+		First(&activity, "external_provider = ? AND external_id = ?", provider, externalId).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+func (r *runActivityRepository) FindUnlinkedBetween(userId uuid.UUID, from, to time.Time) ([]entity.RunActivity, error) {
 	var activities []entity.RunActivity
-	err := r.db.Order("created_at DESC").Find(&activities).Error
+	err := r.db.Where("user_id = ? AND external_id IS NULL AND COALESCE(start_time, created_at) BETWEEN ? AND ?", userId, from, to).
+		Find(&activities).Error
 	return activities, err
 }
 
+func orderSplits(db *gorm.DB) *gorm.DB {
+	return db.Order("split_number ASC")
+}
+
+func (r *runActivityRepository) ExistsByFileHash(userId uuid.UUID, hash string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entity.RunActivity{}).Where("user_id = ? AND file_hash = ?", userId, hash).Count(&count).Error
+	return count > 0, err
+}
+
+// connectedSQL memfilter user pada kolom ownerCol yang terhubung dengan viewer
+// (match accepted atau satu run group). Butuh 3 argumen viewer id, masing-masing
+// diikuti owner id bila ownerCol berupa placeholder.
+func connectedSQL(ownerCol string) string {
+	return `(EXISTS (
+		SELECT 1 FROM direct_matches dm
+		WHERE dm.status = 'accepted'
+		  AND ((dm.user1_id = ? AND dm.user2_id = ` + ownerCol + `)
+		    OR (dm.user2_id = ? AND dm.user1_id = ` + ownerCol + `)))
+	OR EXISTS (
+		SELECT 1 FROM run_group_members vm
+		INNER JOIN run_group_members om ON om.group_id = vm.group_id
+		WHERE vm.user_id = ? AND vm.status = 'joined'
+		  AND om.user_id = ` + ownerCol + ` AND om.status = 'joined'))`
+}
+
+func (r *runActivityRepository) FindVisible(viewerId uuid.UUID, limit, offset int) ([]entity.RunActivity, int64, error) {
+	query := r.db.Model(&entity.RunActivity{}).
+		Where("run_activities.user_id = ? OR "+connectedSQL("run_activities.user_id"), viewerId, viewerId, viewerId, viewerId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var activities []entity.RunActivity
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&activities).Error
+	return activities, total, err
+}
+
+func (r *runActivityRepository) IsConnected(viewerId, ownerId uuid.UUID) (bool, error) {
+	var connected bool
+	err := r.db.Raw("SELECT "+connectedSQL("?"), viewerId, ownerId, viewerId, ownerId, viewerId, ownerId).Scan(&connected).Error
+	return connected, err
+}
+
 func (r *runActivityRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&entity.RunActivity{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("activity_id = ?", id).Delete(&entity.RunActivitySplit{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.RunActivity{}, "id = ?", id).Error
+	})
 }
 
 func (r *runActivityRepository) GetUserStats(userId uuid.UUID) (map[string]interface{}, error) {
@@ -75,3 +220,87 @@ func (r *runActivityRepository) GetUserStats(userId uuid.UUID) (map[string]inter
 	}
 	return result[0], nil
 }
+
+func (r *runActivityRepository) FindFastestSplit(userId uuid.UUID) (*FastestSplit, error) {
+	var splits []FastestSplit
+	err := r.db.Table("run_activity_splits s").
+		Select("s.*, COALESCE(a.start_time, a.created_at) AS recorded_at").
+		Joins("JOIN run_activities a ON a.id = s.activity_id").
+		Where("a.user_id = ? AND s.distance >= 0.99 AND s.duration > 0", userId).
+		Order("s.duration::float / s.distance ASC").
+		Limit(1).
+		Scan(&splits).Error
+	if err != nil || len(splits) == 0 {
+		return nil, err
+	}
+	return &splits[0], nil
+}
+
+func (r *runActivityRepository) GetHeartRateSamples(userId uuid.UUID) ([]HeartRateSample, error) {
+	var samples []HeartRateSample
+	err := r.db.Raw(`
+		SELECT t.heart_rate, SUM(t.seconds) AS seconds FROM (
+			SELECT s.avg_heart_rate AS heart_rate, s.duration AS seconds
+			FROM run_activity_splits s
+			JOIN run_activities a ON a.id = s.activity_id
+			WHERE a.user_id = ? AND s.avg_heart_rate > 0
+			UNION ALL
+			SELECT a.avg_heart_rate, a.duration
+			FROM run_activities a
+			WHERE a.user_id = ? AND a.avg_heart_rate > 0
+			  AND NOT EXISTS (
+				SELECT 1 FROM run_activity_splits s
+				WHERE s.activity_id = a.id AND s.avg_heart_rate > 0
+			  )
+		) t
+		GROUP BY t.heart_rate
+		ORDER BY t.heart_rate`, userId, userId).
+		Scan(&samples).Error
+	return samples, err
+}
+
+func (r *runActivityRepository) MaxHeartRate(userId uuid.UUID) (int, error) {
+	var max int
+	err := r.db.Raw(`
+		SELECT COALESCE(GREATEST(
+			(SELECT MAX(max_heart_rate) FROM run_activities WHERE user_id = ?),
+			(SELECT MAX(s.max_heart_rate) FROM run_activity_splits s
+			 JOIN run_activities a ON a.id = s.activity_id WHERE a.user_id = ?)
+		), 0)`, userId, userId).
+		Scan(&max).Error
+	return max, err
+}
+
+func (r *runActivityRepository) GetVolumeSeries(userId uuid.UUID, unit string, since time.Time) ([]VolumeBucket, error) {
+	if unit != "week" && unit != "month" {
+		return nil, fmt.Errorf("unit agregasi tidak valid: %s", unit)
+	}
+	var buckets []VolumeBucket
+	err := r.db.Model(&entity.RunActivity{}).
+		Select(fmt.Sprintf("date_trunc('%s', %s) AS period_start, COUNT(*) AS runs, "+
+			"COALESCE(SUM(distance), 0) AS distance, COALESCE(SUM(duration), 0) AS duration", unit, activityTimeWIB)).
+		Where("user_id = ? AND COALESCE(start_time, created_at) >= ?", userId, since).
+		Group("period_start").
+		Order("period_start").
+		Scan(&buckets).Error
+	return buckets, err
+}
+
+func (r *runActivityRepository) GetVolumeTotals(userId uuid.UUID, from, to time.Time) (VolumeBucket, error) {
+	var bucket VolumeBucket
+	err := r.db.Model(&entity.RunActivity{}).
+		Select("COUNT(*) AS runs, COALESCE(SUM(distance), 0) AS distance, COALESCE(SUM(duration), 0) AS duration").
+		Where("user_id = ? AND COALESCE(start_time, created_at) >= ? AND COALESCE(start_time, created_at) < ?", userId, from, to).
+		Scan(&bucket).Error
+	bucket.PeriodStart = from
+	return bucket, err
+}
+
+func (r *runActivityRepository) GetActivityDays(userId uuid.UUID) ([]string, error) {
+	var days []string
+	err := r.db.Raw(fmt.Sprintf(
+		"SELECT DISTINCT to_char(%s, 'YYYY-MM-DD') AS day FROM run_activities WHERE user_id = ? ORDER BY day",
+		activityTimeWIB), userId).
+		Scan(&days).Error
+	return days, err
+}
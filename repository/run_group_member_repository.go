@@ -16,6 +16,7 @@ type RunGroupMemberRepository interface {
 	FindByUserId(userId uuid.UUID) ([]entity.RunGroupMember, error)
 	Delete(id uuid.UUID) error
 	GetMembers(groupId uuid.UUID, status string) ([]entity.RunGroupMember, error)
+	CountNonFemaleJoined(groupId uuid.UUID) (int64, error)
 	DB() *gorm.DB
 }
 
@@ -75,6 +76,17 @@ func (r *runGroupMemberRepository) GetMembers(groupId uuid.UUID, status string)
 	return members, err
 }
 
+// CountNonFemaleJoined menghitung member aktif yang gendernya bukan female (termasuk kosong).
+func (r *runGroupMemberRepository) CountNonFemaleJoined(groupId uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&entity.RunGroupMember{}).
+		Joins("JOIN users ON users.id = run_group_members.user_id").
+		Where("run_group_members.group_id = ? AND run_group_members.status = ?", groupId, "joined").
+		Where("COALESCE(users.gender, '') <> ?", "female").
+		Count(&count).Error
+	return count, err
+}
+
 func (r *runGroupMemberRepository) DB() *gorm.DB {
 	return r.db
 }
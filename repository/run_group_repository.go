@@ -20,6 +20,41 @@ type RunGroupRepository interface {
 	FindByCreatedBy(userId uuid.UUID) ([]entity.RunGroup, error)
 	FindByMembership(userId uuid.UUID) ([]entity.RunGroup, []string, error)
 	GetMemberCount(groupId uuid.UUID) (int64, error)
+	FindNearby(q NearbyGroupQuery) ([]NearbyGroup, int64, error)
+}
+
+// NearbyGroupQuery adalah filter pencarian grup terdekat. Jumlah member dihitung
+// dengan satu agregasi, bukan query per grup.
+type NearbyGroupQuery struct {
+	UserId    uuid.UUID // grup yang sudah diikuti user ini dikecualikan
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+
+	Status      string
+	MinPace     float64 // overlap dengan rentang pace grup
+	MaxPace     float64
+	WomenOnly   *bool // nil = tidak difilter
+	ExcludeFull bool  // kecualikan grup dengan member >= max_member
+
+	// ViewerFemale=false menyembunyikan grup women-only (gender ternormalisasi requester)
+	ViewerFemale *bool
+
+	// Factors: faktor skor kompatibilitas (boleh memakai distance_km), lihat NearbyRunnerQuery.
+	Factors  []ScoreFactor
+	MinScore float64
+
+	Limit  int
+	Offset int
+}
+
+type NearbyGroup struct {
+	entity.RunGroup
+	DistanceKm   float64
+	MemberCount  int64
+	Score        float64
+	FactorScores string
+	Factors      []float64 `gorm:"-"`
 }
 
 type runGroupRepository struct {
@@ -152,3 +187,64 @@ func (r *runGroupRepository) GetMemberCount(groupId uuid.UUID) (int64, error) {
 	err := r.db.Model(&entity.RunGroupMember{}).Where("group_id = ? AND status = ?", groupId, "joined").Count(&count).Error
 	return count, err
 }
+
+func (r *runGroupRepository) FindNearby(q NearbyGroupQuery) ([]NearbyGroup, int64, error) {
+	minLat, maxLat, minLng, maxLng := boundingBox(q.Latitude, q.Longitude, q.RadiusKm)
+	distance := haversineSQL("run_groups.latitude", "run_groups.longitude")
+	distArgs := []interface{}{q.Latitude, q.Latitude, q.Longitude}
+
+	memberCounts := r.db.Model(&entity.RunGroupMember{}).
+		Select("group_id, COUNT(*) AS member_count").
+		Where("status = ?", "joined").
+		Group("group_id")
+
+	base := r.db.Table("run_groups").
+		Joins("LEFT JOIN (?) AS mc ON mc.group_id = run_groups.id", memberCounts).
+		Where("run_groups.latitude BETWEEN ? AND ?", minLat, maxLat).
+		Where("run_groups.longitude BETWEEN ? AND ?", minLng, maxLng).
+		Where(distance+" <= ?", append(distArgs, q.RadiusKm)...).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM run_group_members m
+			WHERE m.group_id = run_groups.id AND m.user_id = ? AND m.status = 'joined')`, q.UserId)
+
+	if q.Status != "" {
+		base = base.Where("run_groups.status = ?", q.Status)
+	}
+	if q.MinPace > 0 {
+		base = base.Where("run_groups.max_pace >= ?", q.MinPace)
+	}
+	if q.MaxPace > 0 {
+		base = base.Where("run_groups.min_pace <= ?", q.MaxPace)
+	}
+	if q.WomenOnly != nil {
+		base = base.Where("run_groups.is_women_only = ?", *q.WomenOnly)
+	}
+	if q.ViewerFemale != nil && !*q.ViewerFemale {
+		base = base.Where("NOT run_groups.is_women_only")
+	}
+	if q.ExcludeFull {
+		base = base.Where("(run_groups.max_member <= 0 OR COALESCE(mc.member_count, 0) < run_groups.max_member)")
+	}
+
+	inner := base.Select("run_groups.*, COALESCE(mc.member_count, 0) AS member_count, "+distance+" AS distance_km", distArgs...)
+	query := r.db.Table("(?) AS c", inner)
+	order := "distance_km ASC"
+	if len(q.Factors) > 0 {
+		query = scoredQuery(r.db, inner, q.Factors, q.MinScore, nil)
+		order = "score DESC, distance_km ASC"
+	}
+
+	var total int64
+	if err := r.db.Table("(?) AS t", query).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var results []NearbyGroup
+	if err := query.Order(order).Limit(q.Limit).Offset(q.Offset).Find(&results).Error; err != nil {
+		return nil, 0, err
+	}
+	for i := range results {
+		results[i].Factors = parseFactorScores(results[i].FactorScores)
+	}
+	return results, total, nil
+}
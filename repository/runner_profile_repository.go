@@ -2,6 +2,8 @@ package repository
 
 import (
 	"run-sync/entity"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,10 +14,80 @@ type RunnerProfileRepository interface {
 	Update(profile *entity.RunnerProfile) error
 	FindById(id uuid.UUID) (*entity.RunnerProfile, error)
 	FindByUserId(userId uuid.UUID) (*entity.RunnerProfile, error)
+	// UpdateDerivedPace mengganti avg_pace hanya bila user tidak mengisinya manual.
+	UpdateDerivedPace(userId uuid.UUID, pace float64) error
 	FindAll() ([]entity.RunnerProfile, error)
+	FindNearby(q NearbyRunnerQuery) ([]NearbyRunner, int64, error)
 	Delete(id uuid.UUID) error
 }
 
+// NearbyRunnerQuery adalah filter pencarian runner terdekat. Semua filter,
+// exclusion, ranking dan pagination dijalankan di SQL.
+type NearbyRunnerQuery struct {
+	UserId    uuid.UUID // requester, selalu dikecualikan
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+
+	MinPace       float64
+	MaxPace       float64
+	MinDistance   int // rentang preferred_distance kandidat (km)
+	MaxDistance   int
+	PreferredTime string
+	Gender        string
+	WomenOnly     *bool // nil = tidak difilter
+
+	// Women-only ditegakkan dari gender ternormalisasi (users.gender):
+	// FemaleOnly membatasi kandidat ke perempuan; ViewerFemale=false menyembunyikan
+	// kandidat yang mengaktifkan women_only_mode.
+	FemaleOnly   bool
+	ViewerFemale *bool
+
+	// Viewer, jika diisi, menerapkan preferensi discovery kandidat terhadap requester
+	// (arah sebaliknya) serta menyembunyikan kandidat hidden/incognito.
+	Viewer *DiscoveryViewer
+
+	// ExcludeMatched mengecualikan user yang masih punya match pending / accepted / rejected
+	// dengan requester, atau unmatch setelah UnmatchedSince (masih dalam cooldown).
+	// Match expired / withdrawn dan unmatch yang cooldown-nya lewat boleh muncul lagi.
+	ExcludeMatched bool
+	UnmatchedSince time.Time
+	ExcludeBlocked bool // kecualikan user yang diblokir requester
+
+	// Pass (skip) kandidat: yang masih cooldown disembunyikan; setelah muncul lagi
+	// skornya dikali GREATEST(0, 1 - PassDecay * pass_count) selama pass terakhir
+	// masih setelah PassDecaySince.
+	ExcludePassed  bool
+	PassDecay      float64
+	PassDecaySince time.Time
+
+	// Factors, jika diisi, dijumlahkan berbobot menjadi skor kompatibilitas;
+	// hasil diurutkan berdasarkan skor lalu jarak.
+	Factors  []ScoreFactor
+	MinScore float64
+
+	Limit  int
+	Offset int
+}
+
+// DiscoveryViewer adalah data requester yang dicocokkan dengan preferensi kandidat.
+type DiscoveryViewer struct {
+	Gender            string
+	AvgPace           float64
+	PreferredDistance int
+	DefaultRadiusKm   float64 // radius untuk kandidat yang belum mengatur max_radius_km
+}
+
+// NearbyRunner adalah profil hasil pencarian beserta jarak (dan skor jika diminta).
+type NearbyRunner struct {
+	entity.RunnerProfile
+	DistanceKm   float64
+	Score        float64
+	PassCount    int
+	FactorScores string    // array JSON mentah dari query
+	Factors      []float64 `gorm:"-"` // nilai per faktor sesuai urutan Query.Factors
+}
+
 type runnerProfileRepository struct {
 	db *gorm.DB
 }
@@ -50,12 +122,150 @@ func (r *runnerProfileRepository) FindByUserId(userId uuid.UUID) (*entity.Runner
 	return &profile, nil
 }
 
+func (r *runnerProfileRepository) UpdateDerivedPace(userId uuid.UUID, pace float64) error {
+	return r.db.Model(&entity.RunnerProfile{}).
+		Where("user_id = ? AND avg_pace_manual = ?", userId, false).
+		Updates(map[string]interface{}{"avg_pace": pace, "updated_at": time.Now()}).Error
+}
+
 func (r *runnerProfileRepository) FindAll() ([]entity.RunnerProfile, error) {
 	var profiles []entity.RunnerProfile
 	err := r.db.Preload("User").Find(&profiles).Error
 	return profiles, err
 }
 
+func (r *runnerProfileRepository) FindNearby(q NearbyRunnerQuery) ([]NearbyRunner, int64, error) {
+	minLat, maxLat, minLng, maxLng := boundingBox(q.Latitude, q.Longitude, q.RadiusKm)
+	distance := haversineSQL("runner_profiles.latitude", "runner_profiles.longitude")
+	distArgs := []interface{}{q.Latitude, q.Latitude, q.Longitude}
+
+	base := r.db.Table("runner_profiles").
+		Joins("INNER JOIN users ON users.id = runner_profiles.user_id").
+		Where("runner_profiles.is_active = ?", true).
+		Where("runner_profiles.user_id <> ?", q.UserId).
+		Where("users.is_active = ? AND users.is_suspended = ? AND users.deletion_scheduled_at IS NULL", true, false).
+		Where("runner_profiles.latitude BETWEEN ? AND ?", minLat, maxLat).
+		Where("runner_profiles.longitude BETWEEN ? AND ?", minLng, maxLng).
+		Where(distance+" <= ?", append(distArgs, q.RadiusKm)...)
+
+	if q.MinPace > 0 {
+		base = base.Where("runner_profiles.avg_pace >= ?", q.MinPace)
+	}
+	if q.MaxPace > 0 {
+		base = base.Where("runner_profiles.avg_pace <= ?", q.MaxPace)
+	}
+	if q.MinDistance > 0 {
+		base = base.Where("runner_profiles.preferred_distance >= ?", q.MinDistance)
+	}
+	if q.MaxDistance > 0 {
+		base = base.Where("runner_profiles.preferred_distance <= ?", q.MaxDistance)
+	}
+	if q.PreferredTime != "" {
+		base = base.Where("runner_profiles.preferred_time = ?", q.PreferredTime)
+	}
+	if q.Gender != "" {
+		base = base.Where("LOWER(users.gender) = ?", strings.ToLower(q.Gender))
+	}
+	if q.WomenOnly != nil {
+		base = base.Where("runner_profiles.women_only_mode = ?", *q.WomenOnly)
+	}
+	if q.FemaleOnly {
+		base = base.Where("users.gender = ?", "female")
+	}
+	if q.ViewerFemale != nil && !*q.ViewerFemale {
+		base = base.Where("NOT runner_profiles.women_only_mode")
+	}
+	if q.ExcludeMatched {
+		base = base.Where(`NOT EXISTS (
+			SELECT 1 FROM direct_matches dm
+			WHERE ((dm.user1_id = ? AND dm.user2_id = runner_profiles.user_id)
+			    OR (dm.user2_id = ? AND dm.user1_id = runner_profiles.user_id))
+			  AND (dm.status IN ('pending', 'accepted', 'rejected')
+			    OR (dm.status = 'unmatched' AND COALESCE(dm.closed_at, dm.created_at) > ?)))`, q.UserId, q.UserId, q.UnmatchedSince)
+	}
+	if q.ExcludeBlocked {
+		// safety_logs.match_id berisi user yang diblokir
+		base = base.Where(`NOT EXISTS (
+			SELECT 1 FROM safety_logs sl
+			WHERE sl.user_id = ? AND sl.status = 'blocked' AND sl.match_id = runner_profiles.user_id)`, q.UserId)
+	}
+
+	if q.Viewer != nil {
+		v := q.Viewer
+		base = base.Joins("LEFT JOIN discovery_preferences dp ON dp.user_id = runner_profiles.user_id").
+			Where("NOT COALESCE(dp.hide_from_discovery, false)").
+			// Incognito hanya terlihat oleh user yang sudah ia kirimi match request
+			Where(`(NOT COALESCE(dp.incognito, false) OR EXISTS (
+				SELECT 1 FROM direct_matches im
+				WHERE im.user1_id = runner_profiles.user_id AND im.user2_id = ?))`, q.UserId).
+			Where(distance+" <= COALESCE(NULLIF(dp.max_radius_km, 0), ?)", append(distArgs, v.DefaultRadiusKm)...).
+			Where("(COALESCE(dp.min_pace, 0) = 0 OR dp.min_pace <= ?)", v.AvgPace).
+			Where("(COALESCE(dp.max_pace, 0) = 0 OR dp.max_pace >= ?)", v.AvgPace).
+			Where("(COALESCE(dp.min_distance, 0) = 0 OR dp.min_distance <= ?)", v.PreferredDistance).
+			Where("(COALESCE(dp.max_distance, 0) = 0 OR dp.max_distance >= ?)", v.PreferredDistance).
+			Where("(COALESCE(dp.gender_preference, '') IN ('', 'any') OR dp.gender_preference = ?)", v.Gender)
+	}
+
+	passCount := "0"
+	var passArgs []interface{}
+	var multiplier *ScoreMultiplier
+	if q.ExcludePassed || q.PassDecay > 0 {
+		base = base.Joins("LEFT JOIN candidate_passes cp ON cp.user_id = ? AND cp.passed_user_id = runner_profiles.user_id", q.UserId)
+		if q.ExcludePassed {
+			base = base.Where("(cp.hidden_until IS NULL OR cp.hidden_until <= ?)", time.Now())
+		}
+		passCount = "CASE WHEN cp.passed_at > ? THEN cp.pass_count ELSE 0 END"
+		passArgs = []interface{}{q.PassDecaySince}
+		if q.PassDecay > 0 {
+			multiplier = &ScoreMultiplier{Expr: "GREATEST(0, 1 - ? * pass_count)", Args: []interface{}{q.PassDecay}}
+		}
+	}
+
+	inner := base.Select(
+		"runner_profiles.*, "+distance+" AS distance_km, "+passCount+" AS pass_count",
+		append(append([]interface{}{}, distArgs...), passArgs...)...,
+	)
+	query := r.db.Table("(?) AS c", inner)
+	order := "distance_km ASC"
+	if len(q.Factors) > 0 {
+		// Skor dihitung di luar subquery agar bisa memakai alias distance_km
+		query = scoredQuery(r.db, inner, q.Factors, q.MinScore, multiplier)
+		order = "score DESC, distance_km ASC"
+	}
+
+	var total int64
+	if err := r.db.Table("(?) AS t", query).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var results []NearbyRunner
+	if err := query.Order(order).Limit(q.Limit).Offset(q.Offset).Find(&results).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Muat user untuk satu halaman hasil dalam satu query
+	if len(results) > 0 {
+		userIds := make([]uuid.UUID, len(results))
+		for i, res := range results {
+			userIds[i] = res.UserId
+		}
+		var users []entity.User
+		if err := r.db.Where("id IN ?", userIds).Find(&users).Error; err != nil {
+			return nil, 0, err
+		}
+		byId := make(map[uuid.UUID]*entity.User, len(users))
+		for i := range users {
+			byId[users[i].Id] = &users[i]
+		}
+		for i := range results {
+			results[i].User = byId[results[i].UserId]
+			results[i].Factors = parseFactorScores(results[i].FactorScores)
+		}
+	}
+
+	return results, total, nil
+}
+
 func (r *runnerProfileRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&entity.RunnerProfile{}, "id = ?", id).Error
 }
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ScoreFactor adalah satu faktor skor kompatibilitas dalam bentuk ekspresi SQL
+// bernilai 0..1. Ekspresi dievaluasi terhadap baris kandidat dengan alias "c"
+// (kolom tabel asal ditambah distance_km).
+type ScoreFactor struct {
+	Expr   string
+	Args   []interface{}
+	Weight float64
+}
+
+// ScoreMultiplier adalah ekspresi SQL pengali skor total (misal penalti), dievaluasi
+// terhadap kolom inner query.
+type ScoreMultiplier struct {
+	Expr string
+	Args []interface{}
+}
+
+// scoredQuery membungkus inner query (alias c) dengan kolom f0..fn per faktor,
+// skor total berbobot (dikali multiplier jika ada) dan factor_scores (array JSON
+// nilai faktor sesuai urutan), lalu menyaring skor di bawah minScore.
+func scoredQuery(db, inner *gorm.DB, factors []ScoreFactor, minScore float64, multiplier *ScoreMultiplier) *gorm.DB {
+	cols := make([]string, len(factors))
+	names := make([]string, len(factors))
+	terms := make([]string, len(factors))
+	var args, weights []interface{}
+	for i, f := range factors {
+		cols[i] = fmt.Sprintf("(%s)::float8 AS f%d", f.Expr, i)
+		names[i] = fmt.Sprintf("f%d", i)
+		terms[i] = fmt.Sprintf("? * f%d", i)
+		args = append(args, f.Args...)
+		weights = append(weights, f.Weight)
+	}
+
+	score := "(" + strings.Join(terms, " + ") + ")"
+	if multiplier != nil {
+		score += " * (" + multiplier.Expr + ")"
+		weights = append(weights, multiplier.Args...)
+	}
+
+	factored := db.Table("(?) AS c", inner).Select("c.*, "+strings.Join(cols, ", "), args...)
+	scored := db.Table("(?) AS s1", factored).Select(
+		"s1.*, "+score+" AS score, json_build_array("+strings.Join(names, ", ")+")::text AS factor_scores",
+		weights...,
+	)
+	return db.Table("(?) AS s", scored).Where("s.score >= ?", minScore)
+}
+
+// parseFactorScores mengubah kolom factor_scores menjadi slice nilai faktor.
+func parseFactorScores(raw string) []float64 {
+	if raw == "" {
+		return nil
+	}
+	var values []float64
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil
+	}
+	return values
+}
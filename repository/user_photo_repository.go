@@ -45,7 +45,7 @@ func (r *userPhotoRepository) FindById(id uuid.UUID) (*entity.UserPhoto, error)
 
 func (r *userPhotoRepository) FindByUserId(userId uuid.UUID) ([]entity.UserPhoto, error) {
 	var photos []entity.UserPhoto
-	err := r.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&photos).Error
+	err := r.db.Where("user_id = ?", userId).Order("type, position, created_at").Find(&photos).Error
 	return photos, err
 }
 
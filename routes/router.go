@@ -18,68 +18,105 @@ import (
 )
 
 var (
-	emailHelper *helper.EmailHelper = helper.NewEmailHelper()
-	redisClient *redis.Client       = config.SetupRedisClient()
-	redisHelper *helper.RedisHelper = helper.NewRedisHelper(redisClient)
-	validate    *validator.Validate = validator.New()
-	db          *gorm.DB            = config.SetupDatabaseConnection()
-	jwtService  service.JWTService  = service.NewJwtService()
+	redisClient  *redis.Client                       = config.SetupRedisClient()
+	redisHelper  *helper.RedisHelper                 = helper.NewRedisHelper(redisClient)
+	validate     *validator.Validate                 = validator.New()
+	db           *gorm.DB                            = config.SetupDatabaseConnection()
+	emailHelper  *helper.EmailHelper                 = helper.NewEmailHelper() // after db: .env is loaded there
+	otpSender    *helper.OTPDispatcher               = helper.NewOTPDispatcherFromEnv(emailHelper)
+	jwtKeyRing   *config.JWTKeyRing                  = config.SetupJWTKeyRing()
+	jwtService   service.JWTService                  = service.NewJwtService(jwtKeyRing, redisHelper)
+	webauthnCfg  *config.WebAuthnConfig              = config.SetupWebAuthn()
+	matchingCfg  *config.MatchingConfig              = config.SetupMatching()
+	faceCfg      *config.FaceVerificationConfig      = config.SetupFaceVerification()
+	faceVerifier helper.FaceVerifier                 = helper.NewFaceVerifierFromEnv()
+	storageCfg   *config.StorageConfig               = config.SetupStorage()
+	fileStorage  helper.Storage                      = helper.NewStorageFromEnv(service.PrivateStorageFolders)
+	photoCfg     *config.PhotoConfig                 = config.SetupPhoto()
+	activityCfg  *config.ActivityConfig              = config.SetupActivity()
+	connectorCfg *config.ConnectorConfig             = config.SetupConnector()
+	connectors   map[string]helper.ConnectorProvider = helper.NewConnectorProvidersFromEnv()
 
 	// Repositories
-	userRepository     repository.UserRepository              = repository.NewUserRepository(db)
-	runnerProfileRepo  repository.RunnerProfileRepository     = repository.NewRunnerProfileRepository(db)
-	runGroupRepo       repository.RunGroupRepository          = repository.NewRunGroupRepository(db)
-	runGroupMemberRepo repository.RunGroupMemberRepository    = repository.NewRunGroupMemberRepository(db)
-	runActivityRepo    repository.RunActivityRepository       = repository.NewRunActivityRepository(db)
-	directMatchRepo    repository.DirectMatchRepository       = repository.NewDirectMatchRepository(db)
-	directChatRepo     repository.DirectChatMessageRepository = repository.NewDirectChatMessageRepository(db)
-	groupChatRepo      repository.GroupChatMessageRepository  = repository.NewGroupChatMessageRepository(db)
-	userPhotoRepo         repository.UserPhotoRepository         = repository.NewUserPhotoRepository(db)
-	runGroupScheduleRepo  repository.RunGroupScheduleRepository  = repository.NewRunGroupScheduleRepository(db)
-	safetyLogRepo         repository.SafetyLogRepository         = repository.NewSafetyLogRepository(db)
-	biometricRepo      repository.BiometricRepository         = repository.NewBiometricRepository(db)
-	notifRepo          repository.NotificationRepository      = repository.NewNotificationRepository(db)
-	deviceTokenRepo    repository.UserDeviceTokenRepository   = repository.NewUserDeviceTokenRepository(db)
+	userRepository       repository.UserRepository                = repository.NewUserRepository(db)
+	runnerProfileRepo    repository.RunnerProfileRepository       = repository.NewRunnerProfileRepository(db)
+	runGroupRepo         repository.RunGroupRepository            = repository.NewRunGroupRepository(db)
+	runGroupMemberRepo   repository.RunGroupMemberRepository      = repository.NewRunGroupMemberRepository(db)
+	runActivityRepo      repository.RunActivityRepository         = repository.NewRunActivityRepository(db)
+	directMatchRepo      repository.DirectMatchRepository         = repository.NewDirectMatchRepository(db)
+	directChatRepo       repository.DirectChatMessageRepository   = repository.NewDirectChatMessageRepository(db)
+	groupChatRepo        repository.GroupChatMessageRepository    = repository.NewGroupChatMessageRepository(db)
+	userPhotoRepo        repository.UserPhotoRepository           = repository.NewUserPhotoRepository(db)
+	runGroupScheduleRepo repository.RunGroupScheduleRepository    = repository.NewRunGroupScheduleRepository(db)
+	safetyLogRepo        repository.SafetyLogRepository           = repository.NewSafetyLogRepository(db)
+	biometricRepo        repository.BiometricRepository           = repository.NewBiometricRepository(db)
+	notifRepo            repository.NotificationRepository        = repository.NewNotificationRepository(db)
+	deviceTokenRepo      repository.UserDeviceTokenRepository     = repository.NewUserDeviceTokenRepository(db)
+	matchWeightRepo      repository.MatchWeightRepository         = repository.NewMatchWeightRepository(db)
+	discoveryPrefRepo    repository.DiscoveryPreferenceRepository = repository.NewDiscoveryPreferenceRepository(db)
+	candidatePassRepo    repository.CandidatePassRepository       = repository.NewCandidatePassRepository(db)
+	faceVerificationRepo repository.FaceVerificationRepository    = repository.NewFaceVerificationRepository(db)
+	photoUploadRepo      repository.PhotoUploadRepository         = repository.NewPhotoUploadRepository(db)
+	connectorRepo        repository.ConnectorRepository           = repository.NewConnectorRepository(db)
+
+	// Galeri foto: urutan, batas per tipe, foto utama = runner_profiles.image
+	photoGallery service.PhotoGallery = service.NewPhotoGallery(db, photoCfg)
+
+	// Personal record & badge dari riwayat aktivitas
+	recordsEngine service.RecordsEngine = service.NewRecordsEngine(db, notifSvc)
+	profilePace   service.ProfilePace   = service.NewProfilePace(runActivityRepo, runnerProfileRepo, activityCfg)
+
+	// Women-only enforcement (gender ternormalisasi + verifikasi wajah opsional)
+	womenOnlyPolicy service.WomenOnlyPolicy = service.NewWomenOnlyPolicy()
 
 	// Matching Engine
-	matchingEngine service.MatchingEngine = service.NewMatchingEngine(runnerProfileRepo, directMatchRepo, runGroupRepo, safetyLogRepo)
+	matchingEngine service.MatchingEngine = service.NewMatchingEngine(runnerProfileRepo, directMatchRepo, runGroupRepo, safetyLogRepo, matchWeightRepo, discoveryPrefRepo, matchingCfg)
 
 	// Services
-	userService          service.UserService           = service.NewUserService(userRepository)
-	runnerProfileService service.RunnerProfileService  = service.NewRunnerProfileService(runnerProfileRepo, userRepository)
-	runGroupService      service.RunGroupService       = service.NewRunGroupService(runGroupRepo, userRepository, runGroupMemberRepo)
-	runGroupMemberSvc    service.RunGroupMemberService = service.NewRunGroupMemberService(runGroupMemberRepo, userRepository, runGroupRepo, db)
-	runActivitySvc       service.RunActivityService    = service.NewRunActivityService(runActivityRepo, userRepository, runnerProfileRepo)
-	directMatchSvc       service.DirectMatchService    = service.NewDirectMatchService(directMatchRepo, userRepository, directChatRepo, runnerProfileRepo, matchingEngine, db, userPhotoRepo)
-	safetyLogSvc         service.SafetyLogService      = service.NewSafetyLogService(safetyLogRepo, userRepository, db)
-	exploreSvc           service.ExploreService        = service.NewExploreService(runnerProfileRepo, runGroupRepo, directMatchRepo, runGroupMemberRepo)
-	biometricSvc         service.BiometricService      = service.NewBiometricService(biometricRepo, userRepository, jwtService, redisHelper)
-	notifSvc             service.NotificationService        = service.NewNotificationService(notifRepo, deviceTokenRepo)
-	runGroupScheduleSvc  service.RunGroupScheduleService    = service.NewRunGroupScheduleService(runGroupScheduleRepo, runGroupRepo)
+	mediaStorageSvc      service.MediaStorageService     = service.NewMediaStorageService(fileStorage, db, storageCfg, photoCfg)
+	userService          service.UserService             = service.NewUserService(userRepository, womenOnlyPolicy)
+	runnerProfileService service.RunnerProfileService    = service.NewRunnerProfileService(runnerProfileRepo, userRepository, discoveryPrefRepo, womenOnlyPolicy, mediaStorageSvc, photoGallery, profilePace)
+	runGroupService      service.RunGroupService         = service.NewRunGroupService(runGroupRepo, userRepository, runGroupMemberRepo, womenOnlyPolicy)
+	runGroupMemberSvc    service.RunGroupMemberService   = service.NewRunGroupMemberService(runGroupMemberRepo, userRepository, runGroupRepo, db, womenOnlyPolicy)
+	runActivitySvc       service.RunActivityService      = service.NewRunActivityService(runActivityRepo, userRepository, recordsEngine, profilePace, redisHelper, activityCfg)
+	directMatchSvc       service.DirectMatchService      = service.NewDirectMatchService(directMatchRepo, userRepository, directChatRepo, runnerProfileRepo, matchingEngine, db, userPhotoRepo, candidatePassRepo, redisHelper, notifSvc, womenOnlyPolicy, matchingCfg)
+	safetyLogSvc         service.SafetyLogService        = service.NewSafetyLogService(safetyLogRepo, userRepository, db)
+	exploreSvc           service.ExploreService          = service.NewExploreService(runnerProfileRepo, runGroupRepo, discoveryPrefRepo, matchingCfg)
+	notifSvc             service.NotificationService     = service.NewNotificationService(notifRepo, deviceTokenRepo)
+	biometricSvc         service.BiometricService        = service.NewBiometricService(biometricRepo, userRepository, jwtService, redisHelper, webauthnCfg, notifSvc)
+	runGroupScheduleSvc  service.RunGroupScheduleService = service.NewRunGroupScheduleService(runGroupScheduleRepo, runGroupRepo)
+	accountSvc           service.AccountService          = service.NewAccountService(db, userRepository, jwtService, notifSvc, mediaStorageSvc)
+	userPhotoSvc         service.UserPhotoService        = service.NewUserPhotoService(userPhotoRepo, userRepository, faceVerificationRepo, photoUploadRepo, photoGallery, notifSvc, mediaStorageSvc, faceVerifier, faceCfg, photoCfg, db)
+	connectorSvc         service.ConnectorService        = service.NewConnectorService(connectorRepo, runActivitySvc, connectors, redisHelper, connectorCfg)
 
 	// Controllers
-	authController           controller.AuthController           = controller.NewAuthController(userService, jwtService, redisHelper, emailHelper)
-	userController           controller.UserController           = controller.NewUserController(userService)
-	runnerProfileController  controller.RunnerProfileController  = controller.NewRunnerProfileController(runnerProfileService)
-	runGroupController       controller.RunGroupController       = controller.NewRunGroupController(runGroupService)
-	runGroupMemberController controller.RunGroupMemberController = controller.NewRunGroupMemberController(runGroupMemberSvc)
-	runActivityController    controller.RunActivityController    = controller.NewRunActivityController(runActivitySvc)
-	directMatchController    controller.DirectMatchController    = controller.NewDirectMatchController(directMatchSvc)
-	userPhotoController      controller.UserPhotoController      = controller.NewUserPhotoController(service.NewUserPhotoService(userPhotoRepo, userRepository))
-	safetyLogController      controller.SafetyLogController      = controller.NewSafetyLogController(safetyLogSvc)
-	exploreController             controller.ExploreController             = controller.NewExploreController(exploreSvc)
-	biometricController           controller.BiometricController           = controller.NewBiometricController(biometricSvc)
-	runGroupScheduleController    controller.RunGroupScheduleController    = controller.NewRunGroupScheduleController(runGroupScheduleSvc)
+	authController             controller.AuthController             = controller.NewAuthController(userService, jwtService, redisHelper, emailHelper, otpSender, notifSvc)
+	userController             controller.UserController             = controller.NewUserController(userService, redisHelper, emailHelper, notifSvc)
+	runnerProfileController    controller.RunnerProfileController    = controller.NewRunnerProfileController(runnerProfileService)
+	runGroupController         controller.RunGroupController         = controller.NewRunGroupController(runGroupService)
+	runGroupMemberController   controller.RunGroupMemberController   = controller.NewRunGroupMemberController(runGroupMemberSvc)
+	runActivityController      controller.RunActivityController      = controller.NewRunActivityController(runActivitySvc, activityCfg)
+	directMatchController      controller.DirectMatchController      = controller.NewDirectMatchController(directMatchSvc)
+	userPhotoController        controller.UserPhotoController        = controller.NewUserPhotoController(userPhotoSvc)
+	safetyLogController        controller.SafetyLogController        = controller.NewSafetyLogController(safetyLogSvc)
+	exploreController          controller.ExploreController          = controller.NewExploreController(exploreSvc)
+	biometricController        controller.BiometricController        = controller.NewBiometricController(biometricSvc)
+	runGroupScheduleController controller.RunGroupScheduleController = controller.NewRunGroupScheduleController(runGroupScheduleSvc)
+	accountController          controller.AccountController          = controller.NewAccountController(accountSvc, redisHelper)
+	connectorController        controller.ConnectorController        = controller.NewConnectorController(connectorSvc, connectorCfg)
 
 	// WebSocket chat hub & controller (Redis Pub/Sub for cross-instance messaging)
 	chatHub          *ws.Hub                     = ws.NewHub(redisClient)
 	chatWSController controller.ChatWSController = controller.NewChatWSController(chatHub, directChatRepo, groupChatRepo, userRepository, runGroupMemberRepo, directMatchRepo, jwtService, notifSvc)
 
 	// WhatsApp controller
-	whatsappController controller.WhatsAppController = controller.NewWhatsAppController(emailHelper, redisClient)
+	whatsappController controller.WhatsAppController = controller.NewWhatsAppController(emailHelper, redisHelper)
 
 	// Notification controller
 	notifController controller.NotificationController = controller.NewNotificationController(notifSvc)
+
+	// JWKS controller (public keys for token verification by other services)
+	jwksController controller.JWKSController = controller.NewJWKSController(jwtService)
 )
 
 func SetupRouter() *gin.Engine {
@@ -91,9 +128,28 @@ func SetupRouter() *gin.Engine {
 	// Start WebSocket hub in background
 	go chatHub.Run()
 
+	// Purge akun jatuh tempo & job export data
+	go accountSvc.RunJobs()
+
+	// Expiry request match pending
+	go directMatchSvc.RunJobs()
+
+	// Sweep asset orphan di object storage
+	go mediaStorageSvc.RunJobs()
+
+	// Antrean webhook & backfill connector aktivitas
+	go connectorSvc.RunJobs()
+
 	// Reusable middleware combos
 	jwt := middleware.AuthorizeJWT(jwtService)
 	profileReq := middleware.ProfileRequired(userRepository)
+	faceReviewer := middleware.AuthorizeFaceReviewer(faceCfg)
+	// Base64 di JSON ~33% lebih besar dari file aslinya, + ruang untuk field lain / multipart
+	photoBodyLimit := middleware.LimitBody(photoCfg.MaxBytes*4/3 + 1<<20)
+	faceBodyLimit := middleware.LimitBody(2 * (photoCfg.MaxBytes*4/3 + 1<<20))
+
+	// Public keys for verifying access tokens (RFC 7517)
+	r.GET("/.well-known/jwks.json", jwksController.GetJWKS)
 
 	// Auth routes (public)
 	auth := r.Group("auth", middleware.RateLimit(redisHelper, 10, time.Minute))
@@ -104,6 +160,11 @@ func SetupRouter() *gin.Engine {
 		auth.POST("/resend-otp", authController.ResendOTP)
 		auth.POST("/refresh-token", authController.RefreshToken)
 
+		// Forgot / reset password
+		auth.POST("/forgot-password", authController.ForgotPassword)
+		auth.POST("/reset-password/verify", authController.VerifyResetPassword)
+		auth.POST("/reset-password", authController.ResetPassword)
+
 		// Biometric login (public - no JWT required)
 		auth.POST("/biometric/login/start", biometricController.LoginStart)
 		auth.POST("/biometric/login/finish", biometricController.LoginFinish)
@@ -115,6 +176,10 @@ func SetupRouter() *gin.Engine {
 		biometric.POST("/register/start", biometricController.RegisterStart)
 		biometric.POST("/register/finish", biometricController.RegisterFinish)
 		biometric.GET("/credentials", biometricController.GetCredentials)
+		biometric.PATCH("/credentials/:id", biometricController.RenameCredential)
+		biometric.POST("/credentials/:id/disable", biometricController.DisableCredential)
+		biometric.POST("/credentials/:id/enable", biometricController.EnableCredential)
+		biometric.GET("/credentials/:id/history", biometricController.GetLoginHistory)
 		biometric.DELETE("/credentials/:id", biometricController.DeleteCredential)
 	}
 
@@ -123,9 +188,17 @@ func SetupRouter() *gin.Engine {
 	{
 		users.POST("", userController.Create)
 		users.GET("", userController.FindAll)
+		users.POST("/me/email", userController.RequestEmailChange)
+		users.POST("/me/email/verify", userController.ConfirmEmailChange)
+		users.PUT("/me/otp-channel", userController.UpdateOTPChannel)
+		users.POST("/me/deletion", accountController.ScheduleDeletion)
+		users.DELETE("/me/deletion", accountController.CancelDeletion)
+		users.POST("/me/export", accountController.RequestExport)
+		users.GET("/me/export/:exportId", accountController.GetExport)
+		users.GET("/me/export/:exportId/download", accountController.DownloadExport)
 		users.GET(":id", userController.FindById)
 		users.PUT(":id", userController.Update)
-		users.DELETE(":id", userController.Delete)
+		users.DELETE(":id", accountController.DeleteUser)
 	}
 
 	// Runner profile (JWT required, no profile required for create)
@@ -133,6 +206,8 @@ func SetupRouter() *gin.Engine {
 	{
 		profiles.POST("", runnerProfileController.CreateOrUpdate)
 		profiles.GET("/me", runnerProfileController.FindByUserId)
+		profiles.GET("/me/discovery", runnerProfileController.GetDiscoveryPreference)
+		profiles.PUT("/me/discovery", runnerProfileController.UpdateDiscoveryPreference)
 		profiles.GET("/:id", runnerProfileController.FindById)
 		profiles.PUT("/:id", runnerProfileController.Update)
 		profiles.DELETE("/:id", runnerProfileController.Delete)
@@ -169,8 +244,26 @@ func SetupRouter() *gin.Engine {
 
 		// Activities
 		runs.POST("/activities", jwt, profileReq, runActivityController.Create)
-		runs.GET("/activities/:id", runActivityController.FindById)
-		runs.GET("/users/:userId/activities", runActivityController.FindByUserId)
+		runs.POST("/activities/import", jwt, profileReq, middleware.LimitBody(activityCfg.ImportMaxBytes+1<<20), runActivityController.Import) // GPX / TCX / FIT
+		runs.GET("/activities", jwt, runActivityController.FindAll)
+		runs.GET("/activities/:id", jwt, runActivityController.FindById)
+		runs.PUT("/activities/:id", jwt, runActivityController.Update)
+		runs.DELETE("/activities/:id", jwt, runActivityController.Delete)
+		runs.GET("/users/:userId/activities", jwt, runActivityController.FindByUserId)
+		runs.GET("/users/:userId/stats", jwt, runActivityController.GetUserStats)
+		runs.GET("/users/:userId/analytics", jwt, runActivityController.GetAnalytics) // ?weeks=12&months=6
+	}
+
+	// Connector aktivitas pihak ketiga (Strava, mock, ...)
+	connectorRoutes := r.Group("connectors")
+	{
+		connectorRoutes.GET("", jwt, connectorController.List)
+		connectorRoutes.POST("/:provider/link", jwt, connectorController.Link)
+		connectorRoutes.GET("/:provider/callback", connectorController.Callback) // redirect OAuth2, user dari state
+		connectorRoutes.DELETE("/:provider", jwt, connectorController.Unlink)
+		connectorRoutes.POST("/:provider/backfill", jwt, connectorController.Backfill)
+		connectorRoutes.GET("/:provider/webhook", connectorController.VerifyWebhook)
+		connectorRoutes.POST("/:provider/webhook", middleware.LimitBody(1<<20), connectorController.Webhook)
 	}
 
 	// Explore / Discover (requires profile)
@@ -184,9 +277,16 @@ func SetupRouter() *gin.Engine {
 	dating := r.Group("match", jwt, profileReq)
 	{
 		dating.GET("/candidates", directMatchController.GetCandidates)
+		dating.POST("/candidates/:userId/pass", directMatchController.PassCandidate)
+		dating.DELETE("/candidates/:userId/pass", directMatchController.UndoPass)
+		dating.GET("/weights", directMatchController.GetMatchWeights)
+		dating.PUT("/weights", directMatchController.UpdateMatchWeights)
+		dating.DELETE("/weights", directMatchController.ResetMatchWeights)
 		dating.POST("", directMatchController.SendMatchRequest)
 		dating.PATCH("/:id/accept", directMatchController.AcceptMatch)
 		dating.PATCH("/:id/reject", directMatchController.RejectMatch)
+		dating.PATCH("/:id/withdraw", directMatchController.WithdrawMatch)
+		dating.PATCH("/:id/unmatch", directMatchController.Unmatch)
 		dating.GET("/:id", directMatchController.FindById)
 		dating.GET("/me", directMatchController.FindUserMatches)
 	}
@@ -206,15 +306,27 @@ func SetupRouter() *gin.Engine {
 		chats.DELETE("/messages/:id", chatWSController.DeleteMessage)
 	}
 
+	// File dari storage lokal (STORAGE_BACKEND=local)
+	if local, ok := fileStorage.(*helper.LocalStorage); ok {
+		localFileController := controller.NewLocalFileController(local, photoCfg.MaxBytes, service.PrivateStorageFolders)
+		r.GET(helper.LocalFilesPath+"*key", localFileController.Serve)
+		r.PUT(helper.LocalFilesPath+"*key", middleware.LimitBody(photoCfg.MaxBytes), localFileController.Upload)
+	}
+
 	// User photos & safety reports
 	media := r.Group("media")
 	{
-		media.POST("/photos", jwt, userPhotoController.Create)
-		media.POST("/photos/verify-face", jwt, userPhotoController.VerifyFace)
+		media.POST("/photos", jwt, photoBodyLimit, userPhotoController.Create)              // multipart/form-data atau JSON base64
+		media.POST("/photos/uploads", jwt, userPhotoController.RequestUpload)               // URL upload langsung ke storage
+		media.POST("/photos/uploads/:id/complete", jwt, userPhotoController.CompleteUpload) // proses file yang sudah diunggah
+		media.POST("/photos/verify-face/challenge", jwt, userPhotoController.StartFaceChallenge)
+		media.POST("/photos/verify-face", jwt, faceBodyLimit, userPhotoController.VerifyFace)
 		media.GET("/photos/:id", userPhotoController.FindById)
 		media.PUT("/photos/:id", jwt, userPhotoController.Update)
 		media.DELETE("/photos/:id", jwt, userPhotoController.Delete)
 		media.GET("/me/photos", jwt, userPhotoController.FindMyPhotos)
+		media.PUT("/me/photos/order", jwt, userPhotoController.Reorder)
+		media.GET("/me/face-verification", jwt, userPhotoController.GetFaceVerificationStatus)
 		media.GET("/users/:userId/photos", userPhotoController.FindByUserId)
 		media.GET("/users/:userId/photos/primary", userPhotoController.FindPrimaryPhoto)
 
@@ -222,6 +334,12 @@ func SetupRouter() *gin.Engine {
 		media.GET("/safety/:id", safetyLogController.FindById)
 	}
 
+	// Antrean review verifikasi wajah (FACE_REVIEWER_IDS)
+	admin := r.Group("admin", jwt, faceReviewer)
+	{
+		admin.GET("/face-reviews", userPhotoController.FaceReviewQueue)              // GET   /admin/face-reviews?page=1&limit=20
+		admin.PATCH("/face-reviews/:id", userPhotoController.ReviewFaceVerification) // PATCH /admin/face-reviews/:id
+	}
 
 	// WhatsApp
 	wa := r.Group("whatsapp", middleware.RateLimit(redisHelper, 10, time.Minute))
@@ -233,11 +351,11 @@ func SetupRouter() *gin.Engine {
 	// Notifications (JWT required)
 	notif := r.Group("notifications", jwt)
 	{
-		notif.GET("", notifController.GetMyNotifications)             // GET  /notifications?page=1&limit=20
-		notif.PATCH("/read", notifController.MarkAsRead)              // PATCH /notifications/read
-		notif.PATCH("/read-all", notifController.MarkAllAsRead)       // PATCH /notifications/read-all
-		notif.POST("/device-token", notifController.RegisterDeviceToken)  // POST  /notifications/device-token
-		notif.DELETE("/device-token", notifController.RemoveDeviceToken)  // DELETE /notifications/device-token
+		notif.GET("", notifController.GetMyNotifications)                // GET  /notifications?page=1&limit=20
+		notif.PATCH("/read", notifController.MarkAsRead)                 // PATCH /notifications/read
+		notif.PATCH("/read-all", notifController.MarkAllAsRead)          // PATCH /notifications/read-all
+		notif.POST("/device-token", notifController.RegisterDeviceToken) // POST  /notifications/device-token
+		notif.DELETE("/device-token", notifController.RemoveDeviceToken) // DELETE /notifications/device-token
 	}
 
 	return r
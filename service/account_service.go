@@ -0,0 +1,752 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"run-sync/data/response"
+	"run-sync/entity"
+	"run-sync/helper"
+	"run-sync/repository"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountService menangani hapus akun (dengan masa tenggang) dan export data user.
+type AccountService interface {
+	// ScheduleDeletion menjadwalkan penghapusan akun setelah masa tenggang.
+	ScheduleDeletion(userId uuid.UUID, password string) (response.AccountDeletionResponse, error)
+	// CancelDeletion membatalkan penghapusan selama masa tenggang belum lewat.
+	CancelDeletion(userId uuid.UUID) error
+	// PurgeUser menghapus permanen akun beserta seluruh data terkait.
+	PurgeUser(userId uuid.UUID) error
+
+	// RequestExport membuat job export data; job yang masih berjalan dikembalikan apa adanya.
+	RequestExport(userId uuid.UUID, format string) (response.DataExportResponse, error)
+	GetExport(userId uuid.UUID, exportId uuid.UUID) (response.DataExportResponse, error)
+	// ExportFile mengembalikan path & nama file export yang sudah selesai.
+	ExportFile(userId uuid.UUID, exportId uuid.UUID) (string, string, error)
+
+	// RunJobs menjalankan purge akun jatuh tempo, job export tertunda dan
+	// pembersihan file export kedaluwarsa secara berkala. Dipanggil sebagai goroutine.
+	RunJobs()
+}
+
+type accountService struct {
+	db         *gorm.DB
+	userRepo   repository.UserRepository
+	jwtService JWTService
+	notifSvc   NotificationService
+	media      MediaStorageService
+	exportDir  string
+	graceDays  int
+}
+
+const (
+	defaultDeletionGraceDays = 14
+	dataExportTTL            = 48 * time.Hour
+	accountJobInterval       = 15 * time.Minute
+)
+
+// DeletedUserId dipakai sebagai pengganti sender/actor dari akun yang sudah dihapus.
+var DeletedUserId = uuid.Nil
+
+// ErrInvalidExportFormat dikembalikan untuk format export selain json / zip.
+var ErrInvalidExportFormat = errors.New("format export harus json atau zip")
+
+// NewAccountService membaca ACCOUNT_DELETION_GRACE_DAYS (default 14) dan
+// DATA_EXPORT_DIR (default ./exports).
+func NewAccountService(
+	db *gorm.DB,
+	userRepo repository.UserRepository,
+	jwtService JWTService,
+	notifSvc NotificationService,
+	media MediaStorageService,
+) AccountService {
+	graceDays, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || graceDays < 0 {
+		graceDays = defaultDeletionGraceDays
+	}
+
+	exportDir := os.Getenv("DATA_EXPORT_DIR")
+	if exportDir == "" {
+		exportDir = "./exports"
+	}
+
+	return &accountService{
+		db:         db,
+		userRepo:   userRepo,
+		jwtService: jwtService,
+		notifSvc:   notifSvc,
+		media:      media,
+		exportDir:  exportDir,
+		graceDays:  graceDays,
+	}
+}
+
+// ===================== Account deletion =====================
+
+func (s *accountService) ScheduleDeletion(userId uuid.UUID, password string) (response.AccountDeletionResponse, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return response.AccountDeletionResponse{}, errors.New("user tidak ditemukan")
+	}
+	if !helper.ComparePassword(user.Password, password) {
+		return response.AccountDeletionResponse{}, errors.New("password salah")
+	}
+
+	if user.DeletionScheduledAt == nil {
+		scheduledAt := time.Now().AddDate(0, 0, s.graceDays)
+		user.DeletionScheduledAt = &scheduledAt
+		user.UpdatedAt = time.Now()
+		if err := s.userRepo.Update(user); err != nil {
+			return response.AccountDeletionResponse{}, errors.New("gagal menjadwalkan penghapusan akun")
+		}
+
+		if err := s.notifSvc.Send(
+			userId,
+			entity.NotifAccountDeletionScheduled,
+			"Akun akan dihapus",
+			fmt.Sprintf("Akun Run-Sync Anda akan dihapus permanen pada %s. Login dan batalkan sebelum tanggal tersebut jika berubah pikiran.", scheduledAt.Format("02 Jan 2006 15:04")),
+			nil, nil, nil,
+		); err != nil {
+			log.Printf("⚠️ Gagal kirim notifikasi account_deletion_scheduled ke %s: %v", userId, err)
+		}
+	}
+
+	return response.AccountDeletionResponse{
+		DeletionScheduledAt: *user.DeletionScheduledAt,
+		GracePeriodDays:     s.graceDays,
+	}, nil
+}
+
+func (s *accountService) CancelDeletion(userId uuid.UUID) error {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("user tidak ditemukan")
+	}
+	if user.DeletionScheduledAt == nil {
+		return errors.New("tidak ada permintaan hapus akun")
+	}
+
+	return s.db.Model(&entity.User{}).Where("id = ?", userId).
+		Updates(map[string]interface{}{
+			"deletion_scheduled_at": nil,
+			"updated_at":            time.Now(),
+		}).Error
+}
+
+// PurgeUser menghapus akun secara permanen:
+//   - match yang masih berjalan ditutup; match dan pesan direct chat dianonimkan
+//     (user diganti DeletedUserId) agar riwayat obrolan pasangannya tetap utuh
+//   - pesan grup dianonimkan (sender diganti DeletedUserId)
+//   - grup yang dibuat user dan belum selesai dibatalkan, member diberi notifikasi
+//   - membership, notifikasi, device token, biometrik, foto, profil, aktivitas dan export dihapus
+//   - foto di object storage & file export dihapus, semua token dicabut
+func (s *accountService) PurgeUser(userId uuid.UUID) error {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return errors.New("user tidak ditemukan")
+	}
+
+	// Kumpulkan asset eksternal sebelum row-nya dihapus
+	var photoUrls []string
+	s.db.Model(&entity.UserPhoto{}).Where("user_id = ?", userId).Pluck("url", &photoUrls)
+	var thumbnailUrls []string
+	s.db.Model(&entity.UserPhoto{}).Where("user_id = ? AND thumbnail_url <> ''", userId).Pluck("thumbnail_url", &thumbnailUrls)
+	photoUrls = append(photoUrls, thumbnailUrls...)
+	var selfieUrls []string
+	s.db.Model(&entity.FaceVerification{}).Where("user_id = ? AND selfie_url <> ''", userId).Pluck("selfie_url", &selfieUrls)
+	photoUrls = append(photoUrls, selfieUrls...)
+	var profileImages []*string
+	s.db.Model(&entity.RunnerProfile{}).Where("user_id = ?", userId).Pluck("image", &profileImages)
+	for _, img := range profileImages {
+		if img != nil && *img != "" {
+			photoUrls = append(photoUrls, *img)
+		}
+	}
+	var exportFiles []string
+	s.db.Model(&entity.DataExport{}).Where("user_id = ? AND file_path <> ''", userId).Pluck("file_path", &exportFiles)
+
+	var cancelledGroups []entity.RunGroup
+	notifyMembers := map[uuid.UUID][]uuid.UUID{}
+
+	txErr := s.db.Transaction(func(tx *gorm.DB) error {
+		// Match yang masih berjalan ditutup, chat room yang aktif diberi penanda penutupan
+		now := time.Now()
+		var acceptedIds []uuid.UUID
+		if err := tx.Model(&entity.DirectMatch{}).
+			Where("(user1_id = ? OR user2_id = ?) AND status = ?", userId, userId, "accepted").
+			Pluck("id", &acceptedIds).Error; err != nil {
+			return err
+		}
+		for _, matchId := range acceptedIds {
+			closing := entity.DirectChatMessage{
+				Id:        uuid.New(),
+				MatchId:   matchId,
+				SenderId:  uuid.Nil,
+				Message:   "Akun ini telah dihapus. Obrolan ini ditutup.",
+				CreatedAt: now,
+			}
+			if err := tx.Create(&closing).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&entity.DirectMatch{}).
+			Where("(user1_id = ? OR user2_id = ?) AND status = ?", userId, userId, "accepted").
+			Updates(map[string]interface{}{"status": "unmatched", "closed_at": now, "closed_by": DeletedUserId}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.DirectMatch{}).
+			Where("(user1_id = ? OR user2_id = ?) AND status = ?", userId, userId, "pending").
+			Updates(map[string]interface{}{"status": "withdrawn", "closed_at": now, "closed_by": DeletedUserId}).Error; err != nil {
+			return err
+		}
+
+		// Match dan pesan direct chat tetap ada untuk pasangannya, tapi tidak lagi terhubung ke user
+		for _, column := range []string{"user1_id", "user2_id", "closed_by"} {
+			if err := tx.Model(&entity.DirectMatch{}).Where(column+" = ?", userId).
+				Update(column, DeletedUserId).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&entity.DirectChatMessage{}).Where("sender_id = ?", userId).
+			Update("sender_id", DeletedUserId).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&entity.SafetyLog{}).Error; err != nil {
+			return err
+		}
+
+		// Pesan grup tetap ada agar percakapan grup utuh, tapi tidak lagi terhubung ke user
+		if err := tx.Model(&entity.GroupChatMessage{}).Where("sender_id = ?", userId).
+			Update("sender_id", DeletedUserId).Error; err != nil {
+			return err
+		}
+
+		// Grup yang dibuat user dan belum selesai dibatalkan
+		if err := tx.Where("created_by = ? AND status IN ?", userId, []string{"open", "full"}).
+			Find(&cancelledGroups).Error; err != nil {
+			return err
+		}
+		for _, g := range cancelledGroups {
+			var memberIds []uuid.UUID
+			tx.Model(&entity.RunGroupMember{}).
+				Where("group_id = ? AND user_id <> ? AND status = ?", g.Id, userId, "joined").
+				Pluck("user_id", &memberIds)
+			notifyMembers[g.Id] = memberIds
+
+			if err := tx.Model(&entity.RunGroup{}).Where("id = ?", g.Id).
+				Updates(map[string]interface{}{"status": "cancelled", "updated_at": time.Now()}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("user_id = ?", userId).Delete(&entity.RunGroupMember{}).Error; err != nil {
+			return err
+		}
+
+		// Notifikasi milik user dihapus, notifikasi user lain yang dipicu user dianonimkan
+		if err := tx.Where("user_id = ?", userId).Delete(&entity.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.Notification{}).Where("actor_id = ?", userId).
+			Update("actor_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ? OR passed_user_id = ?", userId, userId).Delete(&entity.CandidatePass{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("activity_id IN (?)", tx.Model(&entity.RunActivity{}).Select("id").Where("user_id = ?", userId)).
+			Delete(&entity.RunActivitySplit{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{
+			&entity.UserDeviceToken{},
+			&entity.BiometricLoginHistory{},
+			&entity.UserBiometric{},
+			&entity.UserPhoto{},
+			&entity.FaceVerification{},
+			&entity.PhotoUpload{},
+			&entity.DiscoveryPreference{},
+			&entity.RunnerProfile{},
+			&entity.RunActivity{},
+			&entity.ConnectorAccount{},
+			&entity.PersonalRecord{},
+			&entity.UserBadge{},
+			&entity.DataExport{},
+			&entity.MatchWeight{},
+		} {
+			if err := tx.Where("user_id = ?", userId).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(&entity.User{}, "id = ?", userId).Error
+	})
+	if txErr != nil {
+		return txErr
+	}
+
+	if err := s.jwtService.RevokeAllTokens(userId.String()); err != nil {
+		log.Printf("⚠️ Gagal mencabut sesi user %s setelah hapus akun: %v", userId, err)
+	}
+
+	for _, url := range photoUrls {
+		s.media.Release(url)
+	}
+	for _, path := range exportFiles {
+		_ = os.Remove(path)
+	}
+
+	for _, g := range cancelledGroups {
+		refId := g.Id.String()
+		refType := "group"
+		for _, memberId := range notifyMembers[g.Id] {
+			if err := s.notifSvc.Send(
+				memberId,
+				entity.NotifGroupCancelled,
+				"Grup lari dibatalkan",
+				fmt.Sprintf("Grup lari \"%s\" dibatalkan karena pembuatnya menghapus akun.", groupDisplayName(g)),
+				nil, &refId, &refType,
+			); err != nil {
+				log.Printf("⚠️ Gagal kirim notifikasi group_cancelled ke %s: %v", memberId, err)
+			}
+		}
+	}
+
+	log.Printf("🗑️ Akun %s (%s) dihapus permanen", userId, user.PhoneNumber)
+	return nil
+}
+
+func groupDisplayName(g entity.RunGroup) string {
+	if g.Name != nil && *g.Name != "" {
+		return *g.Name
+	}
+	return g.MeetingPoint
+}
+
+// ===================== Data export =====================
+
+func (s *accountService) RequestExport(userId uuid.UUID, format string) (response.DataExportResponse, error) {
+	if format == "" {
+		format = "zip"
+	}
+	// Format dipakai sebagai ekstensi file export di disk
+	if format != "json" && format != "zip" {
+		return response.DataExportResponse{}, ErrInvalidExportFormat
+	}
+
+	var running entity.DataExport
+	err := s.db.Where("user_id = ? AND status IN ?", userId, []string{entity.DataExportPending, entity.DataExportProcessing}).
+		First(&running).Error
+	if err == nil {
+		return toDataExportResponse(running), nil
+	}
+
+	job := entity.DataExport{
+		Id:     uuid.New(),
+		UserId: userId,
+		Format: format,
+		Status: entity.DataExportPending,
+	}
+	if err := s.db.Create(&job).Error; err != nil {
+		return response.DataExportResponse{}, errors.New("gagal membuat job export")
+	}
+
+	go s.processExport(job.Id)
+
+	return toDataExportResponse(job), nil
+}
+
+func (s *accountService) GetExport(userId uuid.UUID, exportId uuid.UUID) (response.DataExportResponse, error) {
+	job, err := s.findOwnExport(userId, exportId)
+	if err != nil {
+		return response.DataExportResponse{}, err
+	}
+	return toDataExportResponse(*job), nil
+}
+
+func (s *accountService) ExportFile(userId uuid.UUID, exportId uuid.UUID) (string, string, error) {
+	job, err := s.findOwnExport(userId, exportId)
+	if err != nil {
+		return "", "", err
+	}
+	if job.Status != entity.DataExportCompleted {
+		return "", "", fmt.Errorf("export belum siap (status: %s)", job.Status)
+	}
+	if job.ExpiresAt != nil && time.Now().After(*job.ExpiresAt) {
+		return "", "", errors.New("file export sudah kedaluwarsa")
+	}
+
+	filename := fmt.Sprintf("run-sync-data-%s.%s", job.CreatedAt.Format("20060102"), job.Format)
+	return job.FilePath, filename, nil
+}
+
+func (s *accountService) findOwnExport(userId uuid.UUID, exportId uuid.UUID) (*entity.DataExport, error) {
+	var job entity.DataExport
+	if err := s.db.Where("id = ? AND user_id = ?", exportId, userId).First(&job).Error; err != nil {
+		return nil, errors.New("export tidak ditemukan")
+	}
+	return &job, nil
+}
+
+// processExport mengambil job (pending -> processing) lalu membangun file export.
+// Update bersyarat mencegah job yang sama diproses dua kali.
+func (s *accountService) processExport(exportId uuid.UUID) {
+	res := s.db.Model(&entity.DataExport{}).
+		Where("id = ? AND status = ?", exportId, entity.DataExportPending).
+		Updates(map[string]interface{}{"status": entity.DataExportProcessing, "updated_at": time.Now()})
+	if res.Error != nil || res.RowsAffected == 0 {
+		return
+	}
+
+	var job entity.DataExport
+	if err := s.db.First(&job, "id = ?", exportId).Error; err != nil {
+		return
+	}
+
+	path, size, err := s.buildExport(job)
+	if err != nil {
+		log.Printf("❌ Export data %s gagal: %v", job.Id, err)
+		s.db.Model(&job).Updates(map[string]interface{}{
+			"status":     entity.DataExportFailed,
+			"error":      err.Error(),
+			"updated_at": time.Now(),
+		})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(dataExportTTL)
+	s.db.Model(&job).Updates(map[string]interface{}{
+		"status":       entity.DataExportCompleted,
+		"file_path":    path,
+		"file_size":    size,
+		"completed_at": now,
+		"expires_at":   expiresAt,
+		"updated_at":   now,
+	})
+
+	refId := job.Id.String()
+	refType := "data_export"
+	if err := s.notifSvc.Send(
+		job.UserId,
+		entity.NotifDataExportReady,
+		"Data Anda siap diunduh",
+		fmt.Sprintf("Export data akun Run-Sync Anda sudah selesai dan bisa diunduh hingga %s.", expiresAt.Format("02 Jan 2006 15:04")),
+		nil, &refId, &refType,
+	); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi data_export_ready ke %s: %v", job.UserId, err)
+	}
+}
+
+// buildExport menulis file export. Format json berupa satu file; format zip
+// berisi satu file JSON per bagian data.
+func (s *accountService) buildExport(job entity.DataExport) (string, int64, error) {
+	sections, err := s.collectUserData(job.UserId)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := os.MkdirAll(s.exportDir, 0o700); err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(s.exportDir, job.Id.String()+"."+job.Format)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	if job.Format == "json" {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(sections); err != nil {
+			return "", 0, err
+		}
+	} else {
+		zw := zip.NewWriter(f)
+		for _, name := range exportSectionOrder {
+			w, err := zw.Create(name + ".json")
+			if err != nil {
+				return "", 0, err
+			}
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(sections[name]); err != nil {
+				return "", 0, err
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return "", 0, err
+		}
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+var exportSectionOrder = []string{
+	"export", "profile", "photos", "activities", "connected_accounts", "personal_records", "badges", "matches", "direct_messages",
+	"group_memberships", "group_messages", "notifications", "biometric_devices", "safety_reports",
+}
+
+func (s *accountService) collectUserData(userId uuid.UUID) (map[string]interface{}, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	var profile *entity.RunnerProfile
+	var p entity.RunnerProfile
+	if err := s.db.Where("user_id = ?", userId).First(&p).Error; err == nil {
+		profile = &p
+	}
+
+	var photos []entity.UserPhoto
+	var activities []entity.RunActivity
+	var connectors []entity.ConnectorAccount
+	var records []entity.PersonalRecord
+	var badges []entity.UserBadge
+	var matches []entity.DirectMatch
+	var memberships []entity.RunGroupMember
+	var groupMessages []entity.GroupChatMessage
+	var notifications []entity.Notification
+	var biometrics []entity.UserBiometric
+	var reports []entity.SafetyLog
+
+	queries := []*gorm.DB{
+		s.db.Where("user_id = ?", userId).Order("created_at").Find(&photos),
+		s.db.Preload("Splits", func(db *gorm.DB) *gorm.DB { return db.Order("split_number") }).
+			Where("user_id = ?", userId).Order("created_at").Find(&activities),
+		s.db.Where("user_id = ?", userId).Order("created_at").Find(&connectors),
+		s.db.Where("user_id = ?", userId).Order("category").Find(&records),
+		s.db.Where("user_id = ?", userId).Order("earned_at").Find(&badges),
+		s.db.Where("user1_id = ? OR user2_id = ?", userId, userId).Order("created_at").Find(&matches),
+		s.db.Where("user_id = ?", userId).Order("joined_at").Find(&memberships),
+		s.db.Where("sender_id = ?", userId).Order("created_at").Find(&groupMessages),
+		s.db.Where("user_id = ?", userId).Order("created_at").Find(&notifications),
+		s.db.Where("user_id = ?", userId).Order("created_at").Find(&biometrics),
+		s.db.Where("user_id = ?", userId).Order("created_at").Find(&reports),
+	}
+	for _, q := range queries {
+		if q.Error != nil {
+			return nil, q.Error
+		}
+	}
+
+	// Direct chat: seluruh percakapan di match milik user
+	var directMessages []entity.DirectChatMessage
+	if len(matches) > 0 {
+		matchIds := make([]uuid.UUID, 0, len(matches))
+		for _, m := range matches {
+			matchIds = append(matchIds, m.Id)
+		}
+		if err := s.db.Where("match_id IN ?", matchIds).Order("created_at").Find(&directMessages).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	sections := map[string]interface{}{
+		"export": map[string]interface{}{
+			"user_id":      userId,
+			"generated_at": time.Now(),
+			"format":       "run-sync-data-export/v1",
+		},
+		"profile": map[string]interface{}{
+			"user": map[string]interface{}{
+				"id":                    user.Id,
+				"name":                  user.Name,
+				"email":                 user.Email,
+				"email_verified":        user.EmailVerified,
+				"phone_number":          user.PhoneNumber,
+				"gender":                user.Gender,
+				"otp_channel":           user.OTPChannel,
+				"is_verified":           user.IsVerified,
+				"deletion_scheduled_at": user.DeletionScheduledAt,
+				"created_at":            user.CreatedAt,
+				"updated_at":            user.UpdatedAt,
+			},
+			"runner_profile": profile,
+		},
+		"photos":             photos,
+		"activities":         mapSlice(activities, exportActivity),
+		"connected_accounts": mapSlice(connectors, exportConnector),
+		"personal_records":   mapSlice(records, exportPersonalRecord),
+		"badges":             mapSlice(badges, exportBadge),
+		"matches":            mapSlice(matches, exportMatch),
+		"direct_messages":    mapSlice(directMessages, exportDirectMessage),
+		"group_memberships":  mapSlice(memberships, exportMembership),
+		"group_messages":     mapSlice(groupMessages, exportGroupMessage),
+		"notifications":      notifications,
+		"biometric_devices":  mapSlice(biometrics, exportBiometric),
+		"safety_reports":     mapSlice(reports, exportSafetyLog),
+	}
+	return sections, nil
+}
+
+func mapSlice[T any](items []T, fn func(T) map[string]interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(items))
+	for _, it := range items {
+		out = append(out, fn(it))
+	}
+	return out
+}
+
+func exportActivity(a entity.RunActivity) map[string]interface{} {
+	return map[string]interface{}{
+		"id": a.Id, "distance_km": a.Distance, "duration_seconds": a.Duration,
+		"avg_pace": a.AvgPace, "calories": a.Calories, "source": a.Source, "activity_type": a.ActivityType,
+		"start_time": a.StartTime, "elapsed_seconds": a.ElapsedTime, "elevation_gain": a.ElevationGain,
+		"avg_heart_rate": a.AvgHeartRate, "max_heart_rate": a.MaxHeartRate, "avg_cadence": a.AvgCadence,
+		"max_speed": a.MaxSpeed, "external_provider": a.ExternalProvider, "external_id": a.ExternalId,
+		"polyline": a.Polyline, "splits": mapSlice(a.Splits, exportSplit), "created_at": a.CreatedAt,
+	}
+}
+
+// exportConnector tanpa access / refresh token
+func exportConnector(c entity.ConnectorAccount) map[string]interface{} {
+	return map[string]interface{}{
+		"provider": c.Provider, "external_user_id": c.ExternalUserId, "scope": c.Scope, "status": c.Status,
+		"last_synced_at": c.LastSyncedAt, "imported_count": c.ImportedCount, "merged_count": c.MergedCount,
+		"connected_at": c.CreatedAt,
+	}
+}
+
+func exportSplit(sp *entity.RunActivitySplit) map[string]interface{} {
+	return map[string]interface{}{
+		"split": sp.SplitNumber, "distance_km": sp.Distance, "duration_seconds": sp.Duration, "pace": sp.Pace,
+		"elevation_gain": sp.ElevationGain, "elevation_loss": sp.ElevationLoss,
+		"avg_heart_rate": sp.AvgHeartRate, "max_heart_rate": sp.MaxHeartRate,
+	}
+}
+
+func exportPersonalRecord(r entity.PersonalRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"category": r.Category, "activity_id": r.ActivityId, "distance_km": r.Distance,
+		"duration_seconds": r.Duration, "pace": r.Pace, "achieved_at": r.AchievedAt,
+	}
+}
+
+func exportBadge(b entity.UserBadge) map[string]interface{} {
+	return map[string]interface{}{"badge": b.Badge, "period": b.Period, "earned_at": b.EarnedAt}
+}
+
+func exportMatch(m entity.DirectMatch) map[string]interface{} {
+	return map[string]interface{}{
+		"id": m.Id, "user1_id": m.User1Id, "user2_id": m.User2Id,
+		"status": m.Status, "created_at": m.CreatedAt, "matched_at": m.MatchedAt,
+	}
+}
+
+func exportDirectMessage(m entity.DirectChatMessage) map[string]interface{} {
+	return map[string]interface{}{
+		"id": m.Id, "match_id": m.MatchId, "sender_id": m.SenderId, "message": m.Message, "created_at": m.CreatedAt,
+	}
+}
+
+func exportMembership(m entity.RunGroupMember) map[string]interface{} {
+	return map[string]interface{}{
+		"group_id": m.GroupId, "role": m.Role, "status": m.Status, "joined_at": m.JoinedAt,
+	}
+}
+
+func exportGroupMessage(m entity.GroupChatMessage) map[string]interface{} {
+	return map[string]interface{}{
+		"id": m.Id, "group_id": m.GroupId, "message": m.Message, "created_at": m.CreatedAt,
+	}
+}
+
+func exportBiometric(b entity.UserBiometric) map[string]interface{} {
+	return map[string]interface{}{
+		"id": b.Id, "device_name": b.DeviceName, "is_active": b.IsActive,
+		"last_used_at": b.LastUsedAt, "created_at": b.CreatedAt,
+	}
+}
+
+func exportSafetyLog(l entity.SafetyLog) map[string]interface{} {
+	return map[string]interface{}{
+		"id": l.Id, "match_id": l.MatchId, "status": l.Status, "reason": l.Reason, "created_at": l.CreatedAt,
+	}
+}
+
+func toDataExportResponse(job entity.DataExport) response.DataExportResponse {
+	res := response.DataExportResponse{
+		Id:          job.Id.String(),
+		Format:      job.Format,
+		Status:      job.Status,
+		FileSize:    job.FileSize,
+		Error:       job.Error,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+		CreatedAt:   job.CreatedAt,
+	}
+	if job.Status == entity.DataExportCompleted {
+		res.DownloadUrl = fmt.Sprintf("/users/me/export/%s/download", job.Id)
+	}
+	return res
+}
+
+// ===================== Background jobs =====================
+
+func (s *accountService) RunJobs() {
+	// Job yang terputus karena restart diproses ulang
+	s.db.Model(&entity.DataExport{}).Where("status = ?", entity.DataExportProcessing).
+		Update("status", entity.DataExportPending)
+
+	ticker := time.NewTicker(accountJobInterval)
+	defer ticker.Stop()
+
+	for {
+		s.runJobsOnce()
+		<-ticker.C
+	}
+}
+
+func (s *accountService) runJobsOnce() {
+	// 1. Purge akun yang masa tenggangnya sudah lewat
+	var dueIds []uuid.UUID
+	s.db.Model(&entity.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).
+		Pluck("id", &dueIds)
+	for _, id := range dueIds {
+		if err := s.PurgeUser(id); err != nil {
+			log.Printf("❌ Gagal purge akun %s: %v", id, err)
+		}
+	}
+
+	// 2. Job export yang tertunda
+	var pendingIds []uuid.UUID
+	s.db.Model(&entity.DataExport{}).Where("status = ?", entity.DataExportPending).Pluck("id", &pendingIds)
+	for _, id := range pendingIds {
+		s.processExport(id)
+	}
+
+	// 3. File export kedaluwarsa
+	var expired []entity.DataExport
+	s.db.Where("status = ? AND expires_at <= ?", entity.DataExportCompleted, time.Now()).Find(&expired)
+	for _, job := range expired {
+		if job.FilePath != "" {
+			_ = os.Remove(job.FilePath)
+		}
+		s.db.Model(&job).Updates(map[string]interface{}{
+			"status":     entity.DataExportExpired,
+			"file_path":  "",
+			"updated_at": time.Now(),
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"run-sync/entity"
+)
+
+// ErrImplausibleActivity dikembalikan saat jarak, durasi, pace atau kecepatan aktivitas
+// tidak masuk akal untuk lari (mis. salah input atau rekaman dari kendaraan).
+var ErrImplausibleActivity = errors.New("aktivitas tidak wajar")
+
+// Selisih maksimal avg_pace yang dikirim dengan pace dari durasi / jarak
+const avgPaceTolerance = 0.05
+
+// checkPlausibility memvalidasi aktivitas sebelum disimpan dan mengisi MaxSpeed untuk
+// aktivitas manual dari split tercepat. Aktivitas impor dan connector memakai MaxSpeed
+// dari track / provider.
+func (s *runActivityService) checkPlausibility(a *entity.RunActivity) error {
+	if a.Distance <= 0 || a.Distance > s.cfg.MaxDistanceKm {
+		return fmt.Errorf("%w: jarak harus lebih dari 0 dan maksimal %.0f km", ErrImplausibleActivity, s.cfg.MaxDistanceKm)
+	}
+	if a.Duration <= 0 || float64(a.Duration) > s.cfg.MaxDuration.Seconds() {
+		return fmt.Errorf("%w: durasi harus lebih dari 0 dan maksimal %.0f jam", ErrImplausibleActivity, s.cfg.MaxDuration.Hours())
+	}
+
+	pace := activityPace(*a)
+	if pace < s.cfg.MinPace || pace > s.cfg.MaxPace {
+		return fmt.Errorf("%w: pace %.2f menit/km dari durasi dan jarak di luar rentang %.1f - %.1f", ErrImplausibleActivity, pace, s.cfg.MinPace, s.cfg.MaxPace)
+	}
+	if a.AvgPace > 0 && math.Abs(a.AvgPace-pace) > pace*avgPaceTolerance {
+		return fmt.Errorf("%w: avg_pace %.2f tidak sesuai dengan durasi / jarak (%.2f menit/km)", ErrImplausibleActivity, a.AvgPace, pace)
+	}
+
+	if a.FileHash == nil && a.ExternalId == nil {
+		a.MaxSpeed = fastestSplitSpeed(a.Splits)
+	}
+	if a.MaxSpeed > s.cfg.MaxSpeedKmh {
+		return fmt.Errorf("%w: kecepatan tertinggi %.1f km/jam melebihi batas %.0f km/jam", ErrImplausibleActivity, a.MaxSpeed, s.cfg.MaxSpeedKmh)
+	}
+	return nil
+}
+
+func fastestSplitSpeed(splits []*entity.RunActivitySplit) float64 {
+	var fastest float64
+	for _, sp := range splits {
+		if sp.Distance <= 0 || sp.Duration <= 0 {
+			continue
+		}
+		fastest = math.Max(fastest, sp.Distance/(float64(sp.Duration)/3600))
+	}
+	return math.Round(fastest*10) / 10
+}
@@ -1,42 +1,59 @@
 package service
 
 import (
-	"crypto/hmac"
+	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"run-sync/config"
 	"run-sync/data/request"
 	"run-sync/data/response"
 	"run-sync/entity"
 	"run-sync/helper"
 	"run-sync/repository"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type BiometricService interface {
-	// Registration flow
-	RegisterStart(userId uuid.UUID, req request.BiometricRegisterStartRequest) (response.BiometricChallengeResponse, error)
+	// Registration flow (WebAuthn attestation)
+	RegisterStart(userId uuid.UUID, origin string, req request.BiometricRegisterStartRequest) (response.BiometricRegisterOptionsResponse, error)
 	RegisterFinish(userId uuid.UUID, req request.BiometricRegisterFinishRequest) (response.BiometricCredentialResponse, error)
 
-	// Authentication flow
-	LoginStart(req request.BiometricLoginStartRequest) (response.BiometricChallengeResponse, error)
-	LoginFinish(req request.BiometricLoginFinishRequest) (response.UserResponse, string, string, error) // returns user + access token + refresh token
+	// Authentication flow (WebAuthn assertion)
+	LoginStart(origin string, req request.BiometricLoginStartRequest) (response.BiometricLoginOptionsResponse, error)
+	LoginFinish(req request.BiometricLoginFinishRequest, client BiometricClientInfo) (response.UserResponse, string, string, error) // returns user + access token + refresh token
 
 	// Credential management
 	GetCredentials(userId uuid.UUID) ([]response.BiometricCredentialResponse, error)
+	RenameCredential(userId uuid.UUID, credentialId uuid.UUID, req request.BiometricRenameRequest) (response.BiometricCredentialResponse, error)
+	DisableCredential(userId uuid.UUID, credentialId uuid.UUID) (response.BiometricCredentialResponse, error)
+	EnableCredential(userId uuid.UUID, credentialId uuid.UUID, req request.BiometricEnableRequest) (response.BiometricCredentialResponse, error)
+	GetLoginHistory(userId uuid.UUID, credentialId uuid.UUID, page, limit int) (response.BiometricLoginHistoryListResponse, error)
 	DeleteCredential(userId uuid.UUID, credentialId uuid.UUID) error
 }
 
+// BiometricClientInfo adalah informasi client saat login, untuk riwayat login
+// dan deteksi lokasi tidak biasa.
+type BiometricClientInfo struct {
+	IPAddress string
+	UserAgent string
+	Country   string // kode negara dari CDN (opsional)
+}
+
 type biometricService struct {
 	repo       repository.BiometricRepository
 	userRepo   repository.UserRepository
 	jwtService JWTService
 	redis      *helper.RedisHelper
+	webauthn   *config.WebAuthnConfig
+	notifSvc   NotificationService
 }
 
 func NewBiometricService(
@@ -44,15 +61,39 @@ func NewBiometricService(
 	userRepo repository.UserRepository,
 	jwtService JWTService,
 	redis *helper.RedisHelper,
+	webauthn *config.WebAuthnConfig,
+	notifSvc NotificationService,
 ) BiometricService {
 	return &biometricService{
 		repo:       repo,
 		userRepo:   userRepo,
 		jwtService: jwtService,
 		redis:      redis,
+		webauthn:   webauthn,
+		notifSvc:   notifSvc,
 	}
 }
 
+// ErrBiometricPasswordMismatch dikembalikan saat password untuk mengaktifkan
+// kembali credential salah.
+var ErrBiometricPasswordMismatch = errors.New("password salah")
+
+const (
+	webAuthnTimeout = 5 * time.Minute
+
+	// Jumlah login biometrik terakhir yang dipakai sebagai acuan lokasi biasa
+	recentLocationWindow = 20
+)
+
+// webAuthnSession disimpan di Redis dengan key challenge. Challenge terikat ke
+// user/credential yang diizinkan dan origin yang memulai ceremony.
+type webAuthnSession struct {
+	UserId        string   `json:"user_id,omitempty"`
+	CredentialIds []string `json:"credential_ids,omitempty"`
+	Origin        string   `json:"origin,omitempty"`
+	DeviceName    string   `json:"device_name,omitempty"`
+}
+
 // generateChallenge creates a cryptographically secure random challenge
 func generateChallenge() (string, error) {
 	bytes := make([]byte, 32)
@@ -62,158 +103,359 @@ func generateChallenge() (string, error) {
 	return base64.RawURLEncoding.EncodeToString(bytes), nil
 }
 
-// verifySignature verifies the HMAC-SHA256 signature of the challenge using the public key
-func verifySignature(publicKeyHex, challenge, signatureHex string) bool {
-	pubKeyBytes, err := hex.DecodeString(publicKeyHex)
-	if err != nil {
-		return false
+// checkStartOrigin menolak ceremony dari origin yang tidak dikenal sejak awal.
+// Origin kosong (misal request dari aplikasi native) tetap dicek saat finish.
+func (s *biometricService) checkStartOrigin(origin string) error {
+	if origin != "" && !s.webauthn.IsAllowedOrigin(origin) {
+		return errors.New("origin tidak diizinkan")
 	}
+	return nil
+}
 
-	mac := hmac.New(sha256.New, pubKeyBytes)
-	mac.Write([]byte(challenge))
-	expectedSig := mac.Sum(nil)
-
-	sigBytes, err := hex.DecodeString(signatureHex)
-	if err != nil {
-		return false
+// checkClientData memvalidasi type dan origin clientDataJSON terhadap sesi.
+func (s *biometricService) checkClientData(cd helper.WebAuthnClientData, ceremony string, session webAuthnSession) error {
+	if cd.Type != ceremony {
+		return errors.New("tipe clientData tidak valid")
+	}
+	if cd.CrossOrigin {
+		return errors.New("cross-origin tidak diizinkan")
+	}
+	if !s.webauthn.IsAllowedOrigin(cd.Origin) {
+		return errors.New("origin tidak diizinkan")
+	}
+	if session.Origin != "" && strings.TrimRight(cd.Origin, "/") != strings.TrimRight(session.Origin, "/") {
+		return errors.New("origin tidak sesuai dengan challenge")
 	}
+	return nil
+}
 
-	return hmac.Equal(expectedSig, sigBytes)
+// checkAuthenticatorData memastikan credential dibuat untuk RP ini dan user
+// terverifikasi (biometrik/PIN) oleh authenticator.
+func (s *biometricService) checkAuthenticatorData(ad helper.WebAuthnAuthenticatorData) error {
+	if err := helper.VerifyRPIDHash(ad, s.webauthn.RPID); err != nil {
+		return err
+	}
+	if !ad.UserPresent() {
+		return errors.New("user presence tidak terdeteksi")
+	}
+	if !ad.UserVerified() {
+		return errors.New("verifikasi biometrik diperlukan")
+	}
+	return nil
 }
 
-// RegisterStart generates a challenge for biometric registration
-func (s *biometricService) RegisterStart(userId uuid.UUID, req request.BiometricRegisterStartRequest) (response.BiometricChallengeResponse, error) {
+// RegisterStart generates WebAuthn creation options for biometric registration
+func (s *biometricService) RegisterStart(userId uuid.UUID, origin string, req request.BiometricRegisterStartRequest) (response.BiometricRegisterOptionsResponse, error) {
 	// Verify user exists
-	_, err := s.userRepo.FindById(userId)
+	user, err := s.userRepo.FindById(userId)
 	if err != nil {
-		return response.BiometricChallengeResponse{}, errors.New("user tidak ditemukan")
+		return response.BiometricRegisterOptionsResponse{}, errors.New("user tidak ditemukan")
+	}
+
+	if err := s.checkStartOrigin(origin); err != nil {
+		return response.BiometricRegisterOptionsResponse{}, err
 	}
 
 	// Generate challenge
 	challenge, err := generateChallenge()
 	if err != nil {
-		return response.BiometricChallengeResponse{}, err
+		return response.BiometricRegisterOptionsResponse{}, err
+	}
+
+	session := webAuthnSession{UserId: userId.String(), Origin: origin, DeviceName: req.DeviceName}
+	if err := s.redis.SaveWebAuthnSession("register", challenge, session, webAuthnTimeout); err != nil {
+		return response.BiometricRegisterOptionsResponse{}, errors.New("gagal menyimpan challenge")
+	}
+
+	// Credential yang sudah terdaftar tidak boleh dibuat ulang di authenticator yang sama
+	existing, _ := s.repo.FindAllByUserId(userId)
+	exclude := make([]response.WebAuthnCredentialDescriptor, 0, len(existing))
+	for _, c := range existing {
+		exclude = append(exclude, credentialDescriptor(c))
 	}
 
-	// Store challenge in Redis (5 minutes expiry)
-	if err := s.redis.SaveOTP("biometric_register", userId.String(), challenge, 5*time.Minute); err != nil {
-		return response.BiometricChallengeResponse{}, errors.New("gagal menyimpan challenge")
+	name := user.PhoneNumber
+	if user.Email != nil {
+		name = *user.Email
+	}
+	displayName := helper.StringValue(user.Name)
+	if displayName == "" {
+		displayName = name
 	}
 
-	return response.BiometricChallengeResponse{
-		Challenge: challenge,
+	return response.BiometricRegisterOptionsResponse{
+		PublicKey: response.WebAuthnCreationOptions{
+			Challenge: challenge,
+			RP:        response.WebAuthnRelyingParty{Id: s.webauthn.RPID, Name: s.webauthn.RPName},
+			User: response.WebAuthnUserEntity{
+				Id:          base64.RawURLEncoding.EncodeToString(userId[:]),
+				Name:        name,
+				DisplayName: displayName,
+			},
+			PubKeyCredParams: []response.WebAuthnCredentialParam{
+				{Type: "public-key", Alg: helper.COSEAlgES256},
+				{Type: "public-key", Alg: helper.COSEAlgRS256},
+			},
+			Timeout:     int(webAuthnTimeout.Milliseconds()),
+			Attestation: "none",
+			AuthenticatorSelection: response.WebAuthnAuthenticatorSelection{
+				ResidentKey:      "preferred",
+				UserVerification: "required",
+			},
+			ExcludeCredentials: exclude,
+			Extensions:         map[string]interface{}{"credProps": true},
+		},
 	}, nil
 }
 
-// RegisterFinish verifies the signature and stores the biometric credential
+// RegisterFinish verifies the attestation and stores the credential public key
 func (s *biometricService) RegisterFinish(userId uuid.UUID, req request.BiometricRegisterFinishRequest) (response.BiometricCredentialResponse, error) {
-	// Get stored challenge from Redis
-	storedChallenge, err := s.redis.GetOTP("biometric_register", userId.String())
+	cred := req.Credential
+
+	clientData, rawClientData, err := helper.ParseWebAuthnClientData(cred.Response.ClientDataJSON)
 	if err != nil {
-		return response.BiometricCredentialResponse{}, errors.New("challenge tidak valid atau sudah kadaluarsa")
+		return response.BiometricCredentialResponse{}, err
 	}
 
-	// Verify challenge matches
-	if storedChallenge != req.Challenge {
+	// Challenge hanya bisa dipakai sekali dan harus milik user ini
+	var session webAuthnSession
+	if err := s.redis.TakeWebAuthnSession("register", clientData.Challenge, &session); err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+	if session.UserId != userId.String() {
 		return response.BiometricCredentialResponse{}, errors.New("challenge tidak cocok")
 	}
+	if err := s.checkClientData(clientData, "webauthn.create", session); err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+
+	attestation, err := helper.ParseWebAuthnAttestation(cred.Response.AttestationObject)
+	if err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+	authData := attestation.AuthData
+	if err := s.checkAuthenticatorData(authData); err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+	if len(authData.CredentialID) == 0 || authData.PublicKey == nil {
+		return response.BiometricCredentialResponse{}, errors.New("attested credential data tidak ditemukan")
+	}
+
+	rawId, err := helper.DecodeBase64URL(cred.RawId)
+	if err != nil || !bytes.Equal(rawId, authData.CredentialID) {
+		return response.BiometricCredentialResponse{}, errors.New("credential id tidak cocok")
+	}
+
+	alg, _, err := helper.ParseCOSEPublicKey(authData.PublicKey)
+	if err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
 
-	// Verify signature using the provided public key
-	if !verifySignature(req.PublicKey, req.Challenge, req.Signature) {
-		return response.BiometricCredentialResponse{}, errors.New("signature tidak valid")
+	clientDataHash := sha256.Sum256(rawClientData)
+	if err := attestation.VerifyStatement(clientDataHash[:]); err != nil {
+		return response.BiometricCredentialResponse{}, fmt.Errorf("attestation tidak valid: %v", err)
 	}
 
-	// Check if credential already exists
-	existing, _ := s.repo.FindByCredentialId(req.CredentialId)
-	if existing != nil {
+	credentialId := base64.RawURLEncoding.EncodeToString(authData.CredentialID)
+	if existing, _ := s.repo.FindByCredentialId(credentialId); existing != nil {
 		return response.BiometricCredentialResponse{}, errors.New("credential sudah terdaftar")
 	}
 
+	deviceName := req.DeviceName
+	if deviceName == "" {
+		deviceName = session.DeviceName
+	}
+
+	isDiscoverable := false
+	if props := cred.ClientExtensionResults.CredProps; props != nil && props.Rk != nil {
+		isDiscoverable = *props.Rk
+	}
+
 	// Save biometric credential
 	now := time.Now()
 	biometric := entity.UserBiometric{
-		Id:           uuid.New(),
-		UserId:       userId,
-		CredentialId: req.CredentialId,
-		PublicKey:    req.PublicKey,
-		DeviceName:   req.DeviceName,
-		IsActive:     true,
-		LastUsedAt:   &now,
-		CreatedAt:    now,
+		Id:                uuid.New(),
+		UserId:            userId,
+		CredentialId:      credentialId,
+		PublicKey:         base64.RawURLEncoding.EncodeToString(authData.PublicKey),
+		Algorithm:         int(alg),
+		SignCount:         int64(authData.SignCount),
+		AAGUID:            formatAAGUID(authData.AAGUID),
+		Transports:        strings.Join(cred.Response.Transports, ","),
+		AttestationFormat: attestation.Format,
+		IsDiscoverable:    isDiscoverable,
+		BackupEligible:    authData.BackupEligible(),
+		BackupState:       authData.BackupState(),
+		DeviceName:        deviceName,
+		IsActive:          true,
+		LastUsedAt:        &now,
+		CreatedAt:         now,
 	}
 
 	if err := s.repo.Create(&biometric); err != nil {
 		return response.BiometricCredentialResponse{}, errors.New("gagal menyimpan credential biometrik")
 	}
 
-	// Delete challenge from Redis
-	s.redis.DeleteOTP("biometric_register", userId.String())
+	s.notify(userId, entity.NotifBiometricRegistered,
+		"Perangkat biometrik baru",
+		fmt.Sprintf("Biometrik di perangkat \"%s\" baru saja didaftarkan ke akun Anda. Jika ini bukan Anda, nonaktifkan perangkat tersebut dan segera ganti password.", deviceName),
+		biometric.Id)
 
-	return response.BiometricCredentialResponse{
-		Id:           biometric.Id.String(),
-		UserId:       biometric.UserId.String(),
-		CredentialId: biometric.CredentialId,
-		DeviceName:   biometric.DeviceName,
-		IsActive:     biometric.IsActive,
-		LastUsedAt:   biometric.LastUsedAt,
-		CreatedAt:    biometric.CreatedAt,
-	}, nil
+	return toBiometricCredentialResponse(biometric), nil
 }
 
-// LoginStart generates a challenge for biometric login
-func (s *biometricService) LoginStart(req request.BiometricLoginStartRequest) (response.BiometricChallengeResponse, error) {
-	// Find user by email or phone
-	user, err := s.userRepo.FindByEmailOrPhone(req.Identifier)
-	if err != nil {
-		return response.BiometricChallengeResponse{}, errors.New("user tidak ditemukan")
+// LoginStart generates WebAuthn request options. Tanpa identifier, client
+// memakai discoverable credential (passkey) dan user ditentukan dari userHandle.
+func (s *biometricService) LoginStart(origin string, req request.BiometricLoginStartRequest) (response.BiometricLoginOptionsResponse, error) {
+	if err := s.checkStartOrigin(origin); err != nil {
+		return response.BiometricLoginOptionsResponse{}, err
 	}
 
-	// Check user has biometric credentials
-	credentials, err := s.repo.FindByUserId(user.Id)
-	if err != nil || len(credentials) == 0 {
-		return response.BiometricChallengeResponse{}, errors.New("biometrik belum didaftarkan untuk akun ini")
+	session := webAuthnSession{Origin: origin}
+	allow := []response.WebAuthnCredentialDescriptor{}
+
+	if req.Identifier != "" {
+		// Find user by email or phone
+		user, err := s.userRepo.FindByEmailOrPhone(req.Identifier)
+		if err != nil {
+			return response.BiometricLoginOptionsResponse{}, errors.New("user tidak ditemukan")
+		}
+
+		// Check user has biometric credentials
+		credentials, err := s.repo.FindByUserId(user.Id)
+		if err != nil || len(credentials) == 0 {
+			return response.BiometricLoginOptionsResponse{}, errors.New("biometrik belum didaftarkan untuk akun ini")
+		}
+
+		session.UserId = user.Id.String()
+		for _, c := range credentials {
+			session.CredentialIds = append(session.CredentialIds, c.CredentialId)
+			allow = append(allow, credentialDescriptor(c))
+		}
 	}
 
 	// Generate challenge
 	challenge, err := generateChallenge()
 	if err != nil {
-		return response.BiometricChallengeResponse{}, err
+		return response.BiometricLoginOptionsResponse{}, err
 	}
 
-	// Store challenge in Redis (5 minutes expiry)
-	if err := s.redis.SaveOTP("biometric_login", user.Id.String(), challenge, 5*time.Minute); err != nil {
-		return response.BiometricChallengeResponse{}, errors.New("gagal menyimpan challenge")
+	if err := s.redis.SaveWebAuthnSession("login", challenge, session, webAuthnTimeout); err != nil {
+		return response.BiometricLoginOptionsResponse{}, errors.New("gagal menyimpan challenge")
 	}
 
-	return response.BiometricChallengeResponse{
-		Challenge: challenge,
+	return response.BiometricLoginOptionsResponse{
+		PublicKey: response.WebAuthnRequestOptions{
+			Challenge:        challenge,
+			RPId:             s.webauthn.RPID,
+			Timeout:          int(webAuthnTimeout.Milliseconds()),
+			UserVerification: "required",
+			AllowCredentials: allow,
+		},
 	}, nil
 }
 
-// LoginFinish verifies the biometric signature and returns a JWT token
-func (s *biometricService) LoginFinish(req request.BiometricLoginFinishRequest) (response.UserResponse, string, string, error) {
+// LoginFinish verifies the WebAuthn assertion and returns JWT tokens
+func (s *biometricService) LoginFinish(req request.BiometricLoginFinishRequest, client BiometricClientInfo) (response.UserResponse, string, string, error) {
+	cred := req.Credential
+
+	clientData, rawClientData, err := helper.ParseWebAuthnClientData(cred.Response.ClientDataJSON)
+	if err != nil {
+		return response.UserResponse{}, "", "", err
+	}
+
+	var session webAuthnSession
+	if err := s.redis.TakeWebAuthnSession("login", clientData.Challenge, &session); err != nil {
+		return response.UserResponse{}, "", "", err
+	}
+	if err := s.checkClientData(clientData, "webauthn.get", session); err != nil {
+		return response.UserResponse{}, "", "", err
+	}
+
 	// Find biometric credential
-	biometric, err := s.repo.FindByCredentialId(req.CredentialId)
+	rawId, err := helper.DecodeBase64URL(cred.RawId)
 	if err != nil {
-		return response.UserResponse{}, "", "", errors.New("credential biometrik tidak ditemukan")
+		return response.UserResponse{}, "", "", errors.New("credential id tidak valid")
 	}
+	credentialId := base64.RawURLEncoding.EncodeToString(rawId)
 
-	// Get stored challenge from Redis
-	storedChallenge, err := s.redis.GetOTP("biometric_login", biometric.UserId.String())
+	biometric, err := s.repo.FindByCredentialId(credentialId)
 	if err != nil {
-		return response.UserResponse{}, "", "", errors.New("challenge tidak valid atau sudah kadaluarsa")
+		return response.UserResponse{}, "", "", errors.New("credential biometrik tidak ditemukan")
+	}
+
+	if !biometric.IsActive {
+		return response.UserResponse{}, "", "", errors.New("credential biometrik dinonaktifkan, login dengan password untuk mengaktifkan kembali")
 	}
 
-	// Verify challenge matches
-	if storedChallenge != req.Challenge {
-		return response.UserResponse{}, "", "", errors.New("challenge tidak cocok")
+	// Challenge terikat ke credential milik user yang memulai login
+	if len(session.CredentialIds) > 0 && !slices.Contains(session.CredentialIds, credentialId) {
+		return response.UserResponse{}, "", "", errors.New("credential tidak sesuai dengan challenge")
+	}
+	if session.UserId != "" && session.UserId != biometric.UserId.String() {
+		return response.UserResponse{}, "", "", errors.New("credential tidak sesuai dengan challenge")
 	}
 
-	// Verify signature
-	if !verifySignature(biometric.PublicKey, req.Challenge, req.Signature) {
+	// userHandle wajib untuk discoverable credential dan harus sama dengan pemilik credential
+	if cred.Response.UserHandle != "" || session.UserId == "" {
+		userHandle, err := helper.DecodeBase64URL(cred.Response.UserHandle)
+		if err != nil || !bytes.Equal(userHandle, biometric.UserId[:]) {
+			return response.UserResponse{}, "", "", errors.New("userHandle tidak cocok")
+		}
+	}
+
+	if biometric.Algorithm == 0 {
+		return response.UserResponse{}, "", "", errors.New("credential biometrik lama tidak didukung, silakan daftarkan ulang")
+	}
+
+	rawAuthData, err := helper.DecodeBase64URL(cred.Response.AuthenticatorData)
+	if err != nil {
+		return response.UserResponse{}, "", "", errors.New("authenticatorData tidak valid")
+	}
+	authData, err := helper.ParseWebAuthnAuthenticatorData(rawAuthData)
+	if err != nil {
+		return response.UserResponse{}, "", "", err
+	}
+	if err := s.checkAuthenticatorData(authData); err != nil {
+		return response.UserResponse{}, "", "", err
+	}
+
+	// Verify signature over authenticatorData || SHA-256(clientDataJSON)
+	coseKey, err := helper.DecodeBase64URL(biometric.PublicKey)
+	if err != nil {
+		return response.UserResponse{}, "", "", errors.New("public key credential rusak")
+	}
+	alg, pub, err := helper.ParseCOSEPublicKey(coseKey)
+	if err != nil {
+		return response.UserResponse{}, "", "", err
+	}
+	signature, err := helper.DecodeBase64URL(cred.Response.Signature)
+	if err != nil {
+		return response.UserResponse{}, "", "", errors.New("signature tidak valid")
+	}
+	clientDataHash := sha256.Sum256(rawClientData)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if err := helper.VerifyWebAuthnSignature(alg, pub, signed, signature); err != nil {
 		return response.UserResponse{}, "", "", errors.New("signature biometrik tidak valid")
 	}
 
+	// Sign counter yang tidak naik menandakan authenticator kemungkinan digandakan.
+	// Authenticator yang tidak memakai counter (selalu 0, misal passkey tersinkron) dilewati.
+	newCount := int64(authData.SignCount)
+	if (newCount != 0 || biometric.SignCount != 0) && newCount <= biometric.SignCount {
+		now := time.Now()
+		biometric.IsActive = false
+		biometric.DisabledAt = &now
+		biometric.DisabledReason = "sign_count"
+		_ = s.repo.Update(biometric)
+		log.Printf("🚨 Sign counter credential %s tidak naik (%d -> %d), credential dinonaktifkan", biometric.Id, biometric.SignCount, newCount)
+		s.notify(biometric.UserId, entity.NotifBiometricDisabled,
+			"Biometrik dinonaktifkan",
+			fmt.Sprintf("Biometrik di perangkat \"%s\" dinonaktifkan karena terindikasi digandakan. Login dengan password untuk memeriksa dan mengaktifkannya kembali.", biometric.DeviceName),
+			biometric.Id)
+		return response.UserResponse{}, "", "", errors.New("credential biometrik terindikasi digandakan dan telah dinonaktifkan")
+	}
+
 	// Find user
 	user, err := s.userRepo.FindById(biometric.UserId)
 	if err != nil {
@@ -231,13 +473,14 @@ func (s *biometricService) LoginFinish(req request.BiometricLoginFinishRequest)
 		return response.UserResponse{}, "", "", errors.New("akun Anda telah disuspend")
 	}
 
-	// Update last used
+	// Update counter, backup state & last used
 	now := time.Now()
+	biometric.SignCount = newCount
+	biometric.BackupState = authData.BackupState()
 	biometric.LastUsedAt = &now
 	_ = s.repo.Update(biometric)
 
-	// Delete challenge from Redis
-	s.redis.DeleteOTP("biometric_login", biometric.UserId.String())
+	s.recordLogin(biometric, client)
 
 	// Generate JWT tokens
 	expiryTime := time.Now().Add(1 * time.Hour)
@@ -245,16 +488,19 @@ func (s *biometricService) LoginFinish(req request.BiometricLoginFinishRequest)
 	refreshToken := s.jwtService.GenerateRefreshToken(user.Id.String())
 
 	userRes := response.UserResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}
 
 	return userRes, accessToken, refreshToken, nil
@@ -262,21 +508,121 @@ func (s *biometricService) LoginFinish(req request.BiometricLoginFinishRequest)
 
 // GetCredentials returns all biometric credentials for a user
 func (s *biometricService) GetCredentials(userId uuid.UUID) ([]response.BiometricCredentialResponse, error) {
-	credentials, err := s.repo.FindByUserId(userId)
+	credentials, err := s.repo.FindAllByUserId(userId)
 	if err != nil {
 		return nil, err
 	}
 
 	var res []response.BiometricCredentialResponse
 	for _, c := range credentials {
-		res = append(res, response.BiometricCredentialResponse{
-			Id:           c.Id.String(),
-			UserId:       c.UserId.String(),
-			CredentialId: c.CredentialId,
-			DeviceName:   c.DeviceName,
-			IsActive:     c.IsActive,
-			LastUsedAt:   c.LastUsedAt,
-			CreatedAt:    c.CreatedAt,
+		res = append(res, toBiometricCredentialResponse(c))
+	}
+
+	return res, nil
+}
+
+// RenameCredential mengganti nama perangkat credential
+func (s *biometricService) RenameCredential(userId uuid.UUID, credentialId uuid.UUID, req request.BiometricRenameRequest) (response.BiometricCredentialResponse, error) {
+	biometric, err := s.findOwnCredential(userId, credentialId)
+	if err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+
+	biometric.DeviceName = strings.TrimSpace(req.DeviceName)
+	if biometric.DeviceName == "" {
+		return response.BiometricCredentialResponse{}, errors.New("nama perangkat tidak boleh kosong")
+	}
+	if err := s.repo.Update(biometric); err != nil {
+		return response.BiometricCredentialResponse{}, errors.New("gagal mengganti nama perangkat")
+	}
+
+	return toBiometricCredentialResponse(*biometric), nil
+}
+
+// DisableCredential menonaktifkan credential tanpa menghapusnya, sehingga
+// riwayat login tetap tersimpan dan credential bisa diaktifkan kembali.
+func (s *biometricService) DisableCredential(userId uuid.UUID, credentialId uuid.UUID) (response.BiometricCredentialResponse, error) {
+	biometric, err := s.findOwnCredential(userId, credentialId)
+	if err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+	if !biometric.IsActive {
+		return response.BiometricCredentialResponse{}, errors.New("credential sudah nonaktif")
+	}
+
+	now := time.Now()
+	biometric.IsActive = false
+	biometric.DisabledAt = &now
+	biometric.DisabledReason = "user"
+	if err := s.repo.Update(biometric); err != nil {
+		return response.BiometricCredentialResponse{}, errors.New("gagal menonaktifkan credential")
+	}
+
+	return toBiometricCredentialResponse(*biometric), nil
+}
+
+// EnableCredential mengaktifkan kembali credential. Password wajib diverifikasi
+// agar credential yang dinonaktifkan (termasuk karena sign counter) tidak bisa
+// diaktifkan hanya dengan sesi dari login biometrik.
+func (s *biometricService) EnableCredential(userId uuid.UUID, credentialId uuid.UUID, req request.BiometricEnableRequest) (response.BiometricCredentialResponse, error) {
+	biometric, err := s.findOwnCredential(userId, credentialId)
+	if err != nil {
+		return response.BiometricCredentialResponse{}, err
+	}
+	if biometric.IsActive {
+		return response.BiometricCredentialResponse{}, errors.New("credential sudah aktif")
+	}
+
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return response.BiometricCredentialResponse{}, errors.New("user tidak ditemukan")
+	}
+	if !helper.ComparePassword(user.Password, req.Password) {
+		return response.BiometricCredentialResponse{}, ErrBiometricPasswordMismatch
+	}
+
+	biometric.IsActive = true
+	biometric.DisabledAt = nil
+	biometric.DisabledReason = ""
+	if err := s.repo.Update(biometric); err != nil {
+		return response.BiometricCredentialResponse{}, errors.New("gagal mengaktifkan credential")
+	}
+
+	return toBiometricCredentialResponse(*biometric), nil
+}
+
+// GetLoginHistory mengembalikan riwayat login sebuah credential
+func (s *biometricService) GetLoginHistory(userId uuid.UUID, credentialId uuid.UUID, page, limit int) (response.BiometricLoginHistoryListResponse, error) {
+	if _, err := s.findOwnCredential(userId, credentialId); err != nil {
+		return response.BiometricLoginHistoryListResponse{}, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	histories, total, err := s.repo.FindLoginHistory(credentialId, page, limit)
+	if err != nil {
+		return response.BiometricLoginHistoryListResponse{}, err
+	}
+
+	res := response.BiometricLoginHistoryListResponse{
+		History: make([]response.BiometricLoginHistoryResponse, 0, len(histories)),
+		Page:    page,
+		Limit:   limit,
+		Total:   total,
+	}
+	for _, h := range histories {
+		res.History = append(res.History, response.BiometricLoginHistoryResponse{
+			Id:        h.Id.String(),
+			IPAddress: h.IPAddress,
+			UserAgent: h.UserAgent,
+			Location:  h.Location,
+			IsUnusual: h.IsUnusual,
+			CreatedAt: h.CreatedAt,
 		})
 	}
 
@@ -285,17 +631,105 @@ func (s *biometricService) GetCredentials(userId uuid.UUID) ([]response.Biometri
 
 // DeleteCredential removes a biometric credential
 func (s *biometricService) DeleteCredential(userId uuid.UUID, credentialId uuid.UUID) error {
-	// Verify credential belongs to user
-	credentials, err := s.repo.FindByUserId(userId)
+	if _, err := s.findOwnCredential(userId, credentialId); err != nil {
+		return err
+	}
+	return s.repo.Delete(credentialId)
+}
+
+// findOwnCredential memastikan credential ada dan dimiliki user
+func (s *biometricService) findOwnCredential(userId uuid.UUID, credentialId uuid.UUID) (*entity.UserBiometric, error) {
+	biometric, err := s.repo.FindById(credentialId)
 	if err != nil {
-		return errors.New("credential tidak ditemukan")
+		return nil, errors.New("credential tidak ditemukan")
+	}
+	if biometric.UserId != userId {
+		return nil, errors.New("credential tidak dimiliki oleh user ini")
 	}
+	return biometric, nil
+}
 
-	for _, c := range credentials {
-		if c.Id == credentialId {
-			return s.repo.Delete(credentialId)
+// recordLogin menyimpan riwayat login dan mengirim peringatan jika lokasi
+// berbeda dari lokasi login biometrik sebelumnya. Login pertama tidak dianggap
+// tidak biasa karena belum ada acuan.
+func (s *biometricService) recordLogin(biometric *entity.UserBiometric, client BiometricClientInfo) {
+	location := helper.ClientLocation(client.IPAddress, client.Country)
+
+	unusual := false
+	if location != "" {
+		recent, err := s.repo.FindRecentLocations(biometric.UserId, recentLocationWindow)
+		if err == nil && len(recent) > 0 && !slices.Contains(recent, location) {
+			unusual = true
 		}
 	}
 
-	return errors.New("credential tidak dimiliki oleh user ini")
+	history := entity.BiometricLoginHistory{
+		Id:          uuid.New(),
+		BiometricId: biometric.Id,
+		UserId:      biometric.UserId,
+		IPAddress:   client.IPAddress,
+		UserAgent:   client.UserAgent,
+		Location:    location,
+		IsUnusual:   unusual,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.repo.CreateLoginHistory(&history); err != nil {
+		log.Printf("⚠️ Gagal menyimpan riwayat login biometrik %s: %v", biometric.Id, err)
+	}
+
+	if unusual {
+		s.notify(biometric.UserId, entity.NotifBiometricUnusualLogin,
+			"Login dari lokasi tidak biasa",
+			fmt.Sprintf("Akun Anda baru saja login dengan biometrik \"%s\" dari lokasi yang belum pernah dipakai (IP %s). Jika ini bukan Anda, nonaktifkan perangkat tersebut dan segera ganti password.", biometric.DeviceName, client.IPAddress),
+			biometric.Id)
+	}
+}
+
+func (s *biometricService) notify(userId uuid.UUID, notifType, title, body string, credentialId uuid.UUID) {
+	refId := credentialId.String()
+	refType := "biometric"
+	if err := s.notifSvc.Send(userId, notifType, title, body, nil, &refId, &refType); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi %s ke %s: %v", notifType, userId, err)
+	}
+}
+
+func credentialDescriptor(c entity.UserBiometric) response.WebAuthnCredentialDescriptor {
+	return response.WebAuthnCredentialDescriptor{
+		Type:       "public-key",
+		Id:         c.CredentialId,
+		Transports: splitTransports(c.Transports),
+	}
+}
+
+func toBiometricCredentialResponse(c entity.UserBiometric) response.BiometricCredentialResponse {
+	return response.BiometricCredentialResponse{
+		Id:             c.Id.String(),
+		UserId:         c.UserId.String(),
+		CredentialId:   c.CredentialId,
+		DeviceName:     c.DeviceName,
+		Algorithm:      c.Algorithm,
+		Transports:     splitTransports(c.Transports),
+		IsDiscoverable: c.IsDiscoverable,
+		BackupEligible: c.BackupEligible,
+		IsActive:       c.IsActive,
+		DisabledAt:     c.DisabledAt,
+		DisabledReason: c.DisabledReason,
+		LastUsedAt:     c.LastUsedAt,
+		CreatedAt:      c.CreatedAt,
+	}
+}
+
+func splitTransports(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func formatAAGUID(b []byte) string {
+	id, err := uuid.FromBytes(b)
+	if err != nil {
+		return ""
+	}
+	return id.String()
 }
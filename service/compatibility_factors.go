@@ -0,0 +1,156 @@
+package service
+
+import (
+	"run-sync/config"
+	"run-sync/entity"
+	"time"
+)
+
+// CompatibilityFactor adalah satu faktor dalam pipeline skor kompatibilitas.
+// Faktor menghasilkan ekspresi SQL bernilai 0..1 terhadap baris kandidat (alias c,
+// termasuk kolom distance_km) sehingga ranking dan pagination tetap di database.
+type CompatibilityFactor interface {
+	Name() string
+	// Label adalah alasan yang ditampilkan ke user ("kenapa kamu cocok").
+	Label() string
+	// RunnerSQL dievaluasi terhadap baris runner_profiles.
+	RunnerSQL(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{})
+	// GroupSQL dievaluasi terhadap baris run_groups; ok=false jika faktor tidak berlaku untuk grup.
+	GroupSQL(me entity.RunnerProfile, cfg *config.MatchingConfig) (expr string, args []interface{}, ok bool)
+}
+
+type sqlFactor struct {
+	name   string
+	label  string
+	runner func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{})
+	group  func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{})
+}
+
+func (f sqlFactor) Name() string  { return f.name }
+func (f sqlFactor) Label() string { return f.label }
+
+func (f sqlFactor) RunnerSQL(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+	return f.runner(me, cfg)
+}
+
+func (f sqlFactor) GroupSQL(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}, bool) {
+	if f.group == nil {
+		return "", nil, false
+	}
+	expr, args := f.group(me, cfg)
+	return expr, args, true
+}
+
+// neutralScore dipakai bila salah satu pihak belum mengisi preferensi.
+const neutralScore = "0.5"
+
+// matchedPartnersSQL: user yang sudah accepted match dengan requester (3 argumen userId).
+const matchedPartnersSQL = `SELECT CASE WHEN dm.user1_id = ? THEN dm.user2_id ELSE dm.user1_id END
+	FROM direct_matches dm
+	WHERE dm.status = 'accepted' AND (dm.user1_id = ? OR dm.user2_id = ?)`
+
+// DefaultCompatibilityFactors mengembalikan faktor bawaan sesuai urutan tampilan breakdown.
+func DefaultCompatibilityFactors() []CompatibilityFactor {
+	return []CompatibilityFactor{
+		sqlFactor{
+			name:  config.FactorPace,
+			label: "Pace mirip",
+			runner: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				return "GREATEST(0, 1 - ABS(c.avg_pace - ?) / ?)", []interface{}{me.AvgPace, cfg.PaceTolerance}
+			},
+			group: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				// Bandingkan dengan titik tengah rentang pace grup
+				return "GREATEST(0, 1 - ABS((c.min_pace + c.max_pace) / 2 - ?) / ?)", []interface{}{me.AvgPace, cfg.PaceTolerance}
+			},
+		},
+		sqlFactor{
+			name:  config.FactorDistance,
+			label: "Lokasi dekat",
+			runner: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				return "GREATEST(0, 1 - c.distance_km / ?)", []interface{}{cfg.MaxRadiusKm}
+			},
+			group: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				return "GREATEST(0, 1 - c.distance_km / ?)", []interface{}{cfg.MaxRadiusKm}
+			},
+		},
+		sqlFactor{
+			name:   config.FactorPreferredDistance,
+			label:  "Jarak lari favorit mirip",
+			runner: preferredDistanceSQL,
+			group:  preferredDistanceSQL,
+		},
+		sqlFactor{
+			name:  config.FactorPreferredTime,
+			label: "Waktu lari sama",
+			runner: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				if me.PreferredTime == "" {
+					return neutralScore, nil
+				}
+				return "CASE WHEN c.preferred_time = '' OR c.preferred_time IS NULL THEN 0.5 WHEN c.preferred_time = ? THEN 1 ELSE 0 END",
+					[]interface{}{me.PreferredTime}
+			},
+			group: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				if me.PreferredTime == "" {
+					return neutralScore, nil
+				}
+				// Waktu grup diturunkan dari jam jadwal (WIB)
+				return `CASE WHEN (CASE
+						WHEN EXTRACT(HOUR FROM c.scheduled_at AT TIME ZONE 'Asia/Jakarta') < 11 THEN 'morning'
+						WHEN EXTRACT(HOUR FROM c.scheduled_at AT TIME ZONE 'Asia/Jakarta') < 15 THEN 'afternoon'
+						ELSE 'evening' END) = ? THEN 1 ELSE 0 END`,
+					[]interface{}{me.PreferredTime}
+			},
+		},
+		sqlFactor{
+			name:  config.FactorActivity,
+			label: "Aktif berlari belakangan ini",
+			runner: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				since := time.Now().AddDate(0, 0, -cfg.ActivityDays)
+				return `LEAST(1, COALESCE((
+					SELECT SUM(ra.distance) FROM run_activities ra
+					WHERE ra.user_id = c.user_id AND ra.created_at >= ?), 0) / ?)`,
+					[]interface{}{since, cfg.ActivityTarget}
+			},
+		},
+		sqlFactor{
+			name:  config.FactorSharedGroups,
+			label: "Tergabung di grup yang sama",
+			runner: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				return `LEAST(1, (
+					SELECT COUNT(*) FROM run_group_members a
+					JOIN run_group_members b ON b.group_id = a.group_id
+					WHERE a.user_id = ? AND b.user_id = c.user_id
+					  AND a.status = 'joined' AND b.status = 'joined')::float8 / 3)`,
+					[]interface{}{me.UserId}
+			},
+		},
+		sqlFactor{
+			name:  config.FactorMutualMatches,
+			label: "Punya teman lari yang sama",
+			runner: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				return `LEAST(1, (
+					SELECT COUNT(*) FROM direct_matches m2
+					WHERE m2.status = 'accepted'
+					  AND ((m2.user1_id = c.user_id AND m2.user2_id IN (` + matchedPartnersSQL + `))
+					    OR (m2.user2_id = c.user_id AND m2.user1_id IN (` + matchedPartnersSQL + `))))::float8 / 3)`,
+					[]interface{}{me.UserId, me.UserId, me.UserId, me.UserId, me.UserId, me.UserId}
+			},
+			group: func(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+				// Untuk grup: teman match yang sudah bergabung
+				return `LEAST(1, (
+					SELECT COUNT(*) FROM run_group_members m
+					WHERE m.group_id = c.id AND m.status = 'joined'
+					  AND m.user_id IN (` + matchedPartnersSQL + `))::float8 / 3)`,
+					[]interface{}{me.UserId, me.UserId, me.UserId}
+			},
+		},
+	}
+}
+
+func preferredDistanceSQL(me entity.RunnerProfile, cfg *config.MatchingConfig) (string, []interface{}) {
+	if me.PreferredDistance <= 0 {
+		return neutralScore, nil
+	}
+	return "CASE WHEN c.preferred_distance <= 0 THEN 0.5 ELSE 1 - ABS(c.preferred_distance - ?)::float8 / GREATEST(c.preferred_distance, ?) END",
+		[]interface{}{me.PreferredDistance, me.PreferredDistance}
+}
@@ -0,0 +1,590 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"run-sync/config"
+	"run-sync/data/response"
+	"run-sync/entity"
+	"run-sync/helper"
+	"run-sync/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConnectorService menghubungkan akun provider aktivitas pihak ketiga (OAuth2),
+// menerima webhook aktivitas baru dan menjalankan backfill riwayat.
+type ConnectorService interface {
+	// List mengembalikan semua provider yang tersedia beserta status koneksi user.
+	List(userId uuid.UUID) ([]response.ConnectorResponse, error)
+	// StartLink membuat state OAuth2 dan URL halaman persetujuan provider.
+	StartLink(userId uuid.UUID, provider string) (response.ConnectorAuthorizeResponse, error)
+	// CompleteLink dipanggil dari redirect OAuth2; user diambil dari state.
+	CompleteLink(provider, state, code string) (response.ConnectorResponse, error)
+	// Unlink mencabut akses di provider (best effort) dan menghapus token. Aktivitas tetap disimpan.
+	Unlink(userId uuid.UUID, provider string) error
+	// Backfill mengulang pengambilan riwayat CONNECTOR_BACKFILL_DAYS hari terakhir.
+	Backfill(userId uuid.UUID, provider string) (response.ConnectorResponse, error)
+
+	// VerifyWebhook menjawab handshake pendaftaran webhook provider.
+	VerifyWebhook(provider string, query url.Values) (interface{}, error)
+	// HandleWebhook memvalidasi event lalu memasukkannya ke antrean; aktivitas diambil oleh job.
+	HandleWebhook(provider string, header http.Header, body []byte) error
+
+	// RunJobs memproses antrean webhook dan backfill secara berkala. Dipanggil sebagai goroutine.
+	RunJobs()
+}
+
+var (
+	ErrConnectorUnknownProvider = errors.New("provider tidak dikenal atau belum dikonfigurasi")
+	ErrConnectorNotLinked       = errors.New("akun provider belum dihubungkan")
+	ErrConnectorRevoked         = errors.New("akses ke provider sudah dicabut, hubungkan ulang akun")
+	ErrConnectorInvalidState    = errors.New("state OAuth tidak valid atau sudah kedaluwarsa")
+	ErrConnectorLinkedElsewhere = errors.New("akun provider ini sudah terhubung ke user lain")
+)
+
+const (
+	connectorEventBatch    = 100
+	connectorBackfillBatch = 20
+	// Access token diperbarui sebelum benar-benar kedaluwarsa
+	connectorTokenLeeway = 5 * time.Minute
+)
+
+type connectorState struct {
+	UserId   uuid.UUID `json:"user_id"`
+	Provider string    `json:"provider"`
+}
+
+type connectorService struct {
+	repo        repository.ConnectorRepository
+	activities  RunActivityService
+	providers   map[string]helper.ConnectorProvider
+	cipher      *helper.TokenCipher
+	redisHelper *helper.RedisHelper
+	cfg         *config.ConnectorConfig
+}
+
+func NewConnectorService(
+	repo repository.ConnectorRepository,
+	activities RunActivityService,
+	providers map[string]helper.ConnectorProvider,
+	redisHelper *helper.RedisHelper,
+	cfg *config.ConnectorConfig,
+) ConnectorService {
+	cipher, err := helper.NewTokenCipher(cfg.TokenKey)
+	if err != nil {
+		log.Fatalf("❌ Gagal menyiapkan enkripsi token connector: %v", err)
+	}
+	return &connectorService{
+		repo:        repo,
+		activities:  activities,
+		providers:   providers,
+		cipher:      cipher,
+		redisHelper: redisHelper,
+		cfg:         cfg,
+	}
+}
+
+func (s *connectorService) List(userId uuid.UUID) ([]response.ConnectorResponse, error) {
+	accounts, err := s.repo.FindAccountsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	byProvider := make(map[string]*entity.ConnectorAccount, len(accounts))
+	names := make([]string, 0, len(s.providers)+len(accounts))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	for i := range accounts {
+		if _, ok := s.providers[accounts[i].Provider]; !ok {
+			// Provider yang sudah tidak dikonfigurasi tetap ditampilkan agar bisa di-unlink
+			names = append(names, accounts[i].Provider)
+		}
+		byProvider[accounts[i].Provider] = &accounts[i]
+	}
+	sort.Strings(names)
+
+	res := make([]response.ConnectorResponse, 0, len(names))
+	for _, name := range names {
+		res = append(res, toConnectorResponse(name, byProvider[name]))
+	}
+	return res, nil
+}
+
+func (s *connectorService) StartLink(userId uuid.UUID, provider string) (response.ConnectorAuthorizeResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return response.ConnectorAuthorizeResponse{}, ErrConnectorUnknownProvider
+	}
+
+	state := helper.GenerateSecureToken(24)
+	if err := s.redisHelper.SaveConnectorState(state, connectorState{UserId: userId, Provider: provider}, s.cfg.StateTTL); err != nil {
+		return response.ConnectorAuthorizeResponse{}, err
+	}
+	return response.ConnectorAuthorizeResponse{
+		Provider:     provider,
+		AuthorizeUrl: p.AuthorizeURL(state, s.redirectURI(provider)),
+		ExpiresAt:    time.Now().Add(s.cfg.StateTTL),
+	}, nil
+}
+
+func (s *connectorService) CompleteLink(provider, state, code string) (response.ConnectorResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return response.ConnectorResponse{}, ErrConnectorUnknownProvider
+	}
+	var st connectorState
+	if found, err := s.redisHelper.TakeConnectorState(state, &st); err != nil {
+		return response.ConnectorResponse{}, err
+	} else if !found || st.Provider != provider {
+		return response.ConnectorResponse{}, ErrConnectorInvalidState
+	}
+
+	token, err := p.ExchangeCode(code, s.redirectURI(provider))
+	if err != nil {
+		return response.ConnectorResponse{}, err
+	}
+	if token.ExternalUserId == "" {
+		return response.ConnectorResponse{}, fmt.Errorf("%w: provider tidak mengirim id akun", helper.ErrConnectorAuthFailed)
+	}
+
+	if other, err := s.repo.FindAccountByExternalId(provider, token.ExternalUserId); err == nil && other.UserId != st.UserId {
+		return response.ConnectorResponse{}, ErrConnectorLinkedElsewhere
+	}
+
+	account, err := s.repo.FindAccount(st.UserId, provider)
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !isNew {
+		return response.ConnectorResponse{}, err
+	}
+	if isNew {
+		account = &entity.ConnectorAccount{Id: uuid.New(), UserId: st.UserId, Provider: provider, CreatedAt: time.Now()}
+	}
+
+	// Akun provider lain atau akses yang sempat dicabut: riwayat diambil ulang
+	if isNew || account.ExternalUserId != token.ExternalUserId || account.Status != entity.ConnectorActive {
+		s.resetBackfill(account)
+	}
+	account.ExternalUserId = token.ExternalUserId
+	account.Scope = token.Scope
+	account.Status = entity.ConnectorActive
+	account.LastError = ""
+	if err := s.storeToken(account, token); err != nil {
+		return response.ConnectorResponse{}, err
+	}
+	account.UpdatedAt = time.Now()
+
+	if isNew {
+		err = s.repo.CreateAccount(account)
+	} else {
+		err = s.repo.UpdateAccount(account)
+	}
+	if err != nil {
+		return response.ConnectorResponse{}, err
+	}
+	return toConnectorResponse(provider, account), nil
+}
+
+func (s *connectorService) Unlink(userId uuid.UUID, provider string) error {
+	account, err := s.repo.FindAccount(userId, provider)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrConnectorNotLinked
+	} else if err != nil {
+		return err
+	}
+
+	if p, ok := s.providers[provider]; ok && account.Status == entity.ConnectorActive {
+		if token, err := s.accessToken(p, account); err != nil {
+			log.Printf("⚠️ Gagal menyiapkan token untuk mencabut akses %s user %s: %v", provider, userId, err)
+		} else if err := p.Revoke(token); err != nil {
+			log.Printf("⚠️ Gagal mencabut akses %s user %s: %v", provider, userId, err)
+		}
+	}
+	return s.repo.DeleteAccount(account.Id)
+}
+
+func (s *connectorService) Backfill(userId uuid.UUID, provider string) (response.ConnectorResponse, error) {
+	account, err := s.repo.FindAccount(userId, provider)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.ConnectorResponse{}, ErrConnectorNotLinked
+	} else if err != nil {
+		return response.ConnectorResponse{}, err
+	}
+	if account.Status != entity.ConnectorActive {
+		return response.ConnectorResponse{}, ErrConnectorRevoked
+	}
+
+	s.resetBackfill(account)
+	account.UpdatedAt = time.Now()
+	if err := s.repo.UpdateAccount(account); err != nil {
+		return response.ConnectorResponse{}, err
+	}
+	return toConnectorResponse(provider, account), nil
+}
+
+func (s *connectorService) VerifyWebhook(provider string, query url.Values) (interface{}, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrConnectorUnknownProvider
+	}
+	return p.VerifySubscription(query)
+}
+
+func (s *connectorService) HandleWebhook(provider string, header http.Header, body []byte) error {
+	p, ok := s.providers[provider]
+	if !ok {
+		return ErrConnectorUnknownProvider
+	}
+	parsed, err := p.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	events := make([]entity.ConnectorEvent, 0, len(parsed))
+	for _, e := range parsed {
+		events = append(events, entity.ConnectorEvent{
+			Id:                 uuid.New(),
+			Provider:           provider,
+			ExternalUserId:     e.ExternalUserId,
+			ExternalActivityId: e.ExternalActivityId,
+			Action:             e.Action,
+			Status:             entity.ConnectorEventPending,
+			NextAttemptAt:      now,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		})
+	}
+	return s.repo.CreateEvents(events)
+}
+
+func (s *connectorService) redirectURI(provider string) string {
+	return s.cfg.CallbackBaseURL + "/connectors/" + provider + "/callback"
+}
+
+func (s *connectorService) resetBackfill(account *entity.ConnectorAccount) {
+	account.BackfillStatus = entity.BackfillPending
+	account.BackfillAfter = time.Now().AddDate(0, 0, -s.cfg.BackfillDays)
+	account.BackfillPage = 1
+	// Penahanan rate limit yang masih berlaku tetap dihormati
+	if account.NextSyncAt != nil && account.NextSyncAt.Before(time.Now()) {
+		account.NextSyncAt = nil
+	}
+}
+
+// storeToken mengenkripsi token baru; refresh token lama dipertahankan bila provider
+// tidak mengirim yang baru.
+func (s *connectorService) storeToken(account *entity.ConnectorAccount, token helper.OAuthToken) error {
+	access, err := s.cipher.Encrypt(token.AccessToken)
+	if err != nil {
+		return err
+	}
+	account.AccessToken = access
+	if token.RefreshToken != "" {
+		if account.RefreshToken, err = s.cipher.Encrypt(token.RefreshToken); err != nil {
+			return err
+		}
+	}
+	account.TokenExpiresAt = token.ExpiresAt
+	return nil
+}
+
+// accessToken mengembalikan access token yang masih berlaku, memperbaruinya dengan
+// refresh token bila hampir kedaluwarsa. Token yang ditolak provider menandai akun revoked.
+func (s *connectorService) accessToken(p helper.ConnectorProvider, account *entity.ConnectorAccount) (string, error) {
+	if time.Until(account.TokenExpiresAt) > connectorTokenLeeway {
+		token, err := s.cipher.Decrypt(account.AccessToken)
+		if err != nil {
+			s.markRevoked(account, err)
+		}
+		return token, err
+	}
+
+	refresh, err := s.cipher.Decrypt(account.RefreshToken)
+	if err != nil {
+		s.markRevoked(account, err)
+		return "", err
+	}
+	token, err := p.RefreshToken(refresh)
+	if errors.Is(err, helper.ErrConnectorAuthFailed) {
+		s.markRevoked(account, err)
+		return "", err
+	} else if err != nil {
+		return "", err
+	}
+
+	if err := s.storeToken(account, token); err != nil {
+		return "", err
+	}
+	account.UpdatedAt = time.Now()
+	if err := s.repo.UpdateAccount(account); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+func (s *connectorService) markRevoked(account *entity.ConnectorAccount, cause error) {
+	account.Status = entity.ConnectorRevoked
+	account.LastError = cause.Error()
+	account.UpdatedAt = time.Now()
+	if err := s.repo.UpdateAccount(account); err != nil {
+		log.Printf("⚠️ Gagal menandai akun %s %s dicabut: %v", account.Provider, account.Id, err)
+	}
+}
+
+func toConnectorResponse(provider string, account *entity.ConnectorAccount) response.ConnectorResponse {
+	res := response.ConnectorResponse{Provider: provider}
+	if account == nil {
+		return res
+	}
+	connectedAt := account.CreatedAt
+	res.Connected = true
+	res.Status = account.Status
+	res.ExternalUserId = account.ExternalUserId
+	res.Scope = account.Scope
+	res.BackfillStatus = account.BackfillStatus
+	res.NextSyncAt = account.NextSyncAt
+	res.LastSyncedAt = account.LastSyncedAt
+	res.LastError = account.LastError
+	res.ImportedCount = account.ImportedCount
+	res.MergedCount = account.MergedCount
+	res.ConnectedAt = &connectedAt
+	return res
+}
+
+func (s *connectorService) RunJobs() {
+	ticker := time.NewTicker(s.cfg.JobInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processEvents()
+		s.processBackfills()
+		<-ticker.C
+	}
+}
+
+// processEvents mengambil aktivitas dari event webhook yang sudah jatuh tempo. Saat
+// provider membalas rate limit, event provider tersebut ditunda sampai kuota tersedia.
+func (s *connectorService) processEvents() {
+	events, err := s.repo.FindDueEvents(time.Now(), connectorEventBatch)
+	if err != nil {
+		log.Printf("⚠️ Gagal membaca antrean webhook connector: %v", err)
+		return
+	}
+
+	paused := make(map[string]time.Time)
+	for i := range events {
+		e := &events[i]
+		if until, ok := paused[e.Provider]; ok {
+			e.NextAttemptAt = until
+		} else {
+			status, err := s.handleEvent(e)
+			var rateLimit *helper.RateLimitError
+			switch {
+			case err == nil:
+				e.Status = status
+			case errors.As(err, &rateLimit):
+				paused[e.Provider] = time.Now().Add(rateLimit.RetryAfter)
+				e.NextAttemptAt = paused[e.Provider]
+				e.Error = err.Error()
+			case errors.Is(err, helper.ErrConnectorAuthFailed), errors.Is(err, helper.ErrTokenDecrypt):
+				// Akun sudah ditandai revoked, percobaan ulang tidak akan berhasil
+				e.Status = entity.ConnectorEventFailed
+				e.Error = err.Error()
+			default:
+				e.Attempts++
+				e.Error = err.Error()
+				if e.Attempts >= s.cfg.EventMaxAttempts {
+					e.Status = entity.ConnectorEventFailed
+				} else {
+					e.NextAttemptAt = time.Now().Add(time.Duration(1<<e.Attempts) * time.Minute)
+				}
+			}
+		}
+
+		e.UpdatedAt = time.Now()
+		if err := s.repo.UpdateEvent(e); err != nil {
+			log.Printf("⚠️ Gagal memperbarui event webhook %s: %v", e.Id, err)
+		}
+	}
+}
+
+func (s *connectorService) handleEvent(e *entity.ConnectorEvent) (string, error) {
+	p, ok := s.providers[e.Provider]
+	if !ok {
+		return entity.ConnectorEventIgnored, nil
+	}
+	account, err := s.repo.FindAccountByExternalId(e.Provider, e.ExternalUserId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entity.ConnectorEventIgnored, nil
+	} else if err != nil {
+		return "", err
+	}
+	if account.Status != entity.ConnectorActive {
+		return entity.ConnectorEventIgnored, nil
+	}
+
+	switch e.Action {
+	// Event webhook tidak selalu ditandatangani provider (Strava), sehingga deauthorize
+	// dan delete baru dijalankan setelah dikonfirmasi lewat API provider.
+	case helper.WebhookDeauthorize:
+		token, err := s.accessToken(p, account)
+		if err == nil {
+			_, err = p.ListActivities(token, time.Now(), 1)
+		}
+		if errors.Is(err, helper.ErrConnectorAuthFailed) {
+			s.markRevoked(account, ErrConnectorRevoked)
+			return entity.ConnectorEventDone, nil
+		} else if err != nil {
+			return "", err
+		}
+		return entity.ConnectorEventIgnored, nil // token masih diterima provider
+
+	case helper.WebhookActivityDelete:
+		token, err := s.accessToken(p, account)
+		if err != nil {
+			return "", err
+		}
+		if _, err := p.GetActivity(token, e.ExternalActivityId); err == nil {
+			return entity.ConnectorEventIgnored, nil // aktivitas masih ada di provider
+		} else if !errors.Is(err, helper.ErrConnectorActivityNotFound) {
+			return "", s.checkAuth(account, err)
+		}
+		if err := s.activities.RemoveExternal(account.UserId, e.Provider, e.ExternalActivityId); err != nil {
+			return "", err
+		}
+		return entity.ConnectorEventDone, nil
+
+	case helper.WebhookActivityCreate, helper.WebhookActivityUpdate:
+		token, err := s.accessToken(p, account)
+		if err != nil {
+			return "", err
+		}
+		activity, err := p.GetActivity(token, e.ExternalActivityId)
+		if errors.Is(err, helper.ErrConnectorActivityNotFound) {
+			return entity.ConnectorEventIgnored, nil
+		} else if err != nil {
+			return "", s.checkAuth(account, err)
+		}
+		result, err := s.activities.SyncExternal(account.UserId, e.Provider, []helper.ProviderActivity{*activity})
+		s.recordSync(account, result)
+		if err != nil {
+			return "", err
+		}
+		return entity.ConnectorEventDone, s.repo.UpdateAccount(account)
+	}
+	return entity.ConnectorEventIgnored, nil
+}
+
+// processBackfills mengambil riwayat akun yang baru dihubungkan, maksimal BackfillMaxPages
+// halaman per akun per putaran. Rate limit menunda akun lewat NextSyncAt dan menghentikan
+// backfill provider tersebut untuk putaran ini.
+func (s *connectorService) processBackfills() {
+	accounts, err := s.repo.FindBackfillDue(time.Now(), connectorBackfillBatch)
+	if err != nil {
+		log.Printf("⚠️ Gagal membaca antrean backfill connector: %v", err)
+		return
+	}
+
+	paused := make(map[string]bool)
+	for i := range accounts {
+		account := &accounts[i]
+		p, ok := s.providers[account.Provider]
+		if !ok || paused[account.Provider] {
+			continue
+		}
+		if err := s.backfillAccount(p, account); errors.Is(err, helper.ErrConnectorRateLimited) {
+			paused[account.Provider] = true
+		}
+	}
+}
+
+func (s *connectorService) backfillAccount(p helper.ConnectorProvider, account *entity.ConnectorAccount) error {
+	token, err := s.accessToken(p, account)
+	if err != nil {
+		if account.Status == entity.ConnectorActive {
+			s.backfillFailed(account, err)
+		}
+		return err
+	}
+
+	account.BackfillStatus = entity.BackfillRunning
+	account.NextSyncAt = nil
+	for i := 0; i < s.cfg.BackfillMaxPages; i++ {
+		var activities []helper.ProviderActivity
+		activities, err = p.ListActivities(token, account.BackfillAfter, account.BackfillPage)
+		if err != nil {
+			err = s.checkAuth(account, err)
+			break
+		}
+		if len(activities) == 0 {
+			account.BackfillStatus = entity.BackfillCompleted
+			break
+		}
+
+		var result ExternalSyncResult
+		result, err = s.activities.SyncExternal(account.UserId, account.Provider, activities)
+		s.recordSync(account, result)
+		if err != nil {
+			break
+		}
+		account.BackfillPage++
+	}
+
+	if err != nil {
+		if account.Status != entity.ConnectorActive {
+			return err
+		}
+		s.backfillFailed(account, err)
+		return err
+	}
+	account.LastError = ""
+	account.UpdatedAt = time.Now()
+	if err := s.repo.UpdateAccount(account); err != nil {
+		log.Printf("⚠️ Gagal menyimpan progres backfill %s %s: %v", account.Provider, account.Id, err)
+	}
+	return nil
+}
+
+// backfillFailed menyimpan error backfill. Rate limit dan gangguan sementara dilanjutkan
+// putaran berikutnya dari halaman yang sama; error lain menghentikan backfill.
+func (s *connectorService) backfillFailed(account *entity.ConnectorAccount, cause error) {
+	var rateLimit *helper.RateLimitError
+	switch {
+	case errors.As(cause, &rateLimit):
+		next := time.Now().Add(rateLimit.RetryAfter)
+		account.NextSyncAt = &next
+		account.BackfillStatus = entity.BackfillRunning
+	case errors.Is(cause, helper.ErrConnectorUnavailable):
+		account.BackfillStatus = entity.BackfillRunning
+	default:
+		account.BackfillStatus = entity.BackfillFailed
+	}
+	account.LastError = cause.Error()
+	account.UpdatedAt = time.Now()
+	if err := s.repo.UpdateAccount(account); err != nil {
+		log.Printf("⚠️ Gagal menyimpan status backfill %s %s: %v", account.Provider, account.Id, err)
+	}
+}
+
+// checkAuth menandai akun revoked bila provider menolak access token.
+func (s *connectorService) checkAuth(account *entity.ConnectorAccount, err error) error {
+	if errors.Is(err, helper.ErrConnectorAuthFailed) {
+		s.markRevoked(account, err)
+	}
+	return err
+}
+
+func (s *connectorService) recordSync(account *entity.ConnectorAccount, result ExternalSyncResult) {
+	now := time.Now()
+	account.ImportedCount += result.Created
+	account.MergedCount += result.Merged
+	account.LastSyncedAt = &now
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"run-sync/config"
 	"run-sync/data/request"
 	"run-sync/data/response"
 	"run-sync/entity"
+	"run-sync/helper"
 	"run-sync/repository"
 	"time"
 
@@ -13,8 +17,18 @@ import (
 )
 
 type DirectMatchService interface {
-	// GetCandidates returns compatible runners for the user via MatchingEngine
-	GetCandidates(userId uuid.UUID) ([]CandidateResult, error)
+	// GetCandidates returns compatible runners for the user via MatchingEngine,
+	// capped by a daily impression limit
+	GetCandidates(userId uuid.UUID, page, limit int) ([]CandidateResult, int64, error)
+
+	// PassCandidate hides a candidate for a cooldown that grows with every pass
+	PassCandidate(userId, candidateUserId uuid.UUID) (response.CandidatePassResponse, error)
+	UndoPass(userId, candidateUserId uuid.UUID) error
+
+	// Bobot faktor kompatibilitas milik user
+	GetMatchWeights(userId uuid.UUID) (response.MatchWeightsResponse, error)
+	UpdateMatchWeights(userId uuid.UUID, req request.UpdateMatchWeightsRequest) (response.MatchWeightsResponse, error)
+	ResetMatchWeights(userId uuid.UUID) (response.MatchWeightsResponse, error)
 
 	// SendMatchRequest creates a pending match or auto-accepts if reverse match exists
 	SendMatchRequest(senderId uuid.UUID, req request.CreateDirectMatchRequest) (response.DirectMatchDetailResponse, error)
@@ -25,20 +39,40 @@ type DirectMatchService interface {
 	// RejectMatch rejects a pending match
 	RejectMatch(matchId uuid.UUID, userId uuid.UUID) (response.DirectMatchDetailResponse, error)
 
+	// WithdrawMatch lets the sender cancel a pending request
+	WithdrawMatch(matchId uuid.UUID, userId uuid.UUID) (response.DirectMatchDetailResponse, error)
+
+	// Unmatch ends an accepted match: closes the chat room and hides it from both inboxes
+	Unmatch(matchId uuid.UUID, userId uuid.UUID) (response.DirectMatchDetailResponse, error)
+
+	// RunJobs mengubah request pending yang lewat TTL menjadi expired secara berkala.
+	// Dipanggil sebagai goroutine.
+	RunJobs()
+
 	FindById(id uuid.UUID) (response.DirectMatchDetailResponse, error)
 	FindUserMatches(userId uuid.UUID) ([]response.DirectMatchDetailResponse, error)
 	FindMatchesByStatus(userId uuid.UUID, status string) ([]response.DirectMatchDetailResponse, error)
 	Delete(id uuid.UUID) error
 }
 
+// ErrCandidateLimitReached dikembalikan saat kuota kandidat harian user habis.
+var ErrCandidateLimitReached = errors.New("batas kandidat harian tercapai, coba lagi besok")
+
+const matchJobInterval = 10 * time.Minute
+
 type directMatchService struct {
 	repo          repository.DirectMatchRepository
 	userRepo      repository.UserRepository
 	chatRepo      repository.DirectChatMessageRepository
 	profileRepo   repository.RunnerProfileRepository
 	userPhotoRepo repository.UserPhotoRepository
+	passRepo      repository.CandidatePassRepository
 	engine        MatchingEngine
 	db            *gorm.DB
+	redisHelper   *helper.RedisHelper
+	notifSvc      NotificationService
+	womenOnly     WomenOnlyPolicy
+	cfg           *config.MatchingConfig
 }
 
 func NewDirectMatchService(
@@ -49,6 +83,11 @@ func NewDirectMatchService(
 	engine MatchingEngine,
 	db *gorm.DB,
 	userPhotoRepo repository.UserPhotoRepository,
+	passRepo repository.CandidatePassRepository,
+	redisHelper *helper.RedisHelper,
+	notifSvc NotificationService,
+	womenOnly WomenOnlyPolicy,
+	cfg *config.MatchingConfig,
 ) DirectMatchService {
 	return &directMatchService{
 		repo:          repo,
@@ -56,14 +95,115 @@ func NewDirectMatchService(
 		chatRepo:      chatRepo,
 		profileRepo:   profileRepo,
 		userPhotoRepo: userPhotoRepo,
+		passRepo:      passRepo,
 		engine:        engine,
 		db:            db,
+		redisHelper:   redisHelper,
+		notifSvc:      notifSvc,
+		womenOnly:     womenOnly,
+		cfg:           cfg,
+	}
+}
+
+// GetCandidates returns compatible runners via the MatchingEngine. Every
+// returned candidate counts as one impression towards the daily cap; a page
+// that crosses the cap is truncated.
+func (s *directMatchService) GetCandidates(userId uuid.UUID, page, limit int) ([]CandidateResult, int64, error) {
+	day := helper.JakartaDate(time.Now())
+	used, err := s.redisHelper.CandidateImpressions(userId.String(), day)
+	if err != nil {
+		// Redis bermasalah: jangan blokir discovery
+		log.Printf("⚠️ Gagal membaca impresi kandidat %s: %v", userId, err)
+	}
+	remaining := s.cfg.DailyImpressionCap - used
+	if remaining <= 0 {
+		return nil, 0, ErrCandidateLimitReached
+	}
+
+	candidates, total, err := s.engine.FindDirectCandidates(userId, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(candidates) > remaining {
+		candidates = candidates[:remaining]
+	}
+
+	if len(candidates) > 0 {
+		if _, err := s.redisHelper.AddCandidateImpressions(userId.String(), day, len(candidates)); err != nil {
+			log.Printf("⚠️ Gagal mencatat impresi kandidat %s: %v", userId, err)
+		}
+	}
+
+	return candidates, total, nil
+}
+
+// PassCandidate menyembunyikan kandidat selama PassCooldownDays, berlipat dua
+// setiap kali kandidat yang sama di-pass lagi (maksimal PassMaxCooldownDays).
+func (s *directMatchService) PassCandidate(userId, candidateUserId uuid.UUID) (response.CandidatePassResponse, error) {
+	if userId == candidateUserId {
+		return response.CandidatePassResponse{}, errors.New("tidak bisa melewati diri sendiri")
+	}
+	if _, err := s.profileRepo.FindByUserId(candidateUserId); err != nil {
+		return response.CandidatePassResponse{}, errors.New("kandidat tidak ditemukan")
+	}
+
+	now := time.Now()
+	pass, err := s.passRepo.Find(userId, candidateUserId)
+	if err != nil {
+		pass = &entity.CandidatePass{UserId: userId, PassedUserId: candidateUserId}
+	}
+	pass.PassCount++
+	pass.PassedAt = now
+
+	cooldownDays := s.cfg.PassCooldownDays
+	for i := 1; i < pass.PassCount && cooldownDays < s.cfg.PassMaxCooldownDays; i++ {
+		cooldownDays *= 2
+	}
+	if cooldownDays > s.cfg.PassMaxCooldownDays {
+		cooldownDays = s.cfg.PassMaxCooldownDays
+	}
+	pass.HiddenUntil = now.AddDate(0, 0, cooldownDays)
+
+	if err := s.passRepo.Save(pass); err != nil {
+		return response.CandidatePassResponse{}, err
+	}
+
+	return response.CandidatePassResponse{
+		PassedUserId: candidateUserId.String(),
+		PassCount:    pass.PassCount,
+		HiddenUntil:  pass.HiddenUntil,
+	}, nil
+}
+
+// UndoPass menghapus catatan pass sehingga kandidat langsung bisa muncul lagi tanpa penalti.
+func (s *directMatchService) UndoPass(userId, candidateUserId uuid.UUID) error {
+	return s.passRepo.Delete(userId, candidateUserId)
+}
+
+func (s *directMatchService) GetMatchWeights(userId uuid.UUID) (response.MatchWeightsResponse, error) {
+	weights, custom, err := s.engine.UserWeights(userId)
+	if err != nil {
+		return response.MatchWeightsResponse{}, err
+	}
+	return response.MatchWeightsResponse{
+		Weights:  weights,
+		Defaults: s.engine.DefaultWeights(),
+		Custom:   custom,
+	}, nil
+}
+
+func (s *directMatchService) UpdateMatchWeights(userId uuid.UUID, req request.UpdateMatchWeightsRequest) (response.MatchWeightsResponse, error) {
+	if err := s.engine.SetUserWeights(userId, req.Weights); err != nil {
+		return response.MatchWeightsResponse{}, err
 	}
+	return s.GetMatchWeights(userId)
 }
 
-// GetCandidates returns compatible runners via the MatchingEngine.
-func (s *directMatchService) GetCandidates(userId uuid.UUID) ([]CandidateResult, error) {
-	return s.engine.FindDirectCandidates(userId)
+func (s *directMatchService) ResetMatchWeights(userId uuid.UUID) (response.MatchWeightsResponse, error) {
+	if err := s.engine.ResetUserWeights(userId); err != nil {
+		return response.MatchWeightsResponse{}, err
+	}
+	return s.GetMatchWeights(userId)
 }
 
 // SendMatchRequest creates a match request. If the receiver already has a
@@ -89,71 +229,117 @@ func (s *directMatchService) SendMatchRequest(senderId uuid.UUID, req request.Cr
 		return response.DirectMatchDetailResponse{}, errors.New("penerima tidak ditemukan")
 	}
 
-	// Kedua user harus memiliki foto verifikasi agar bisa match
-	if _, err := s.userPhotoRepo.FindVerificationPhoto(senderId); err != nil {
-		return response.DirectMatchDetailResponse{}, errors.New("kamu harus upload foto verifikasi terlebih dahulu sebelum bisa match")
+	// Kedua user harus sudah lolos verifikasi wajah agar bisa match
+	if !isVerifiedRunner(sender) {
+		return response.DirectMatchDetailResponse{}, errors.New("kamu harus menyelesaikan verifikasi wajah terlebih dahulu sebelum bisa match")
+	}
+	if !isVerifiedRunner(receiver) {
+		return response.DirectMatchDetailResponse{}, errors.New("pengguna ini belum terverifikasi, tidak bisa match")
 	}
-	if _, err := s.userPhotoRepo.FindVerificationPhoto(receiverId); err != nil {
-		return response.DirectMatchDetailResponse{}, errors.New("pengguna ini belum memiliki foto verifikasi, tidak bisa match")
+
+	// Women-only mode berlaku dua arah berdasarkan gender ternormalisasi
+	senderProfile, _ := s.profileRepo.FindByUserId(senderId)
+	receiverProfile, _ := s.profileRepo.FindByUserId(receiverId)
+	if err := s.womenOnly.CanMatch(sender, receiver, senderProfile, receiverProfile); err != nil {
+		return response.DirectMatchDetailResponse{}, err
 	}
 
-	// Check if match already exists in either direction
+	// Match terakhir antara kedua user (arah mana pun) menentukan apakah request baru boleh dibuat
 	existing, _ := s.repo.FindByUsers(senderId, receiverId)
+	if existing != nil && existing.Status == "pending" && existing.ExpiresAt != nil && time.Now().After(*existing.ExpiresAt) {
+		// Job expiry belum sempat berjalan: request lama tidak boleh lagi diterima otomatis
+		if _, err := s.expireMatch(existing, time.Now()); err != nil {
+			return response.DirectMatchDetailResponse{}, err
+		}
+		existing, _ = s.repo.FindByUsers(senderId, receiverId)
+	}
 	if existing != nil {
-		return response.DirectMatchDetailResponse{}, errors.New("match sudah ada antara kedua user")
-	}
-
-	// Check for reverse match: receiver already sent a pending request to sender
-	reverseMatch, _ := s.repo.FindByUsers(receiverId, senderId)
-	if reverseMatch != nil && reverseMatch.Status == "pending" {
-		// Auto-accept: both like each other! Use transaction
-		var result response.DirectMatchDetailResponse
-		txErr := s.db.Transaction(func(tx *gorm.DB) error {
-			now := time.Now()
-			reverseMatch.Status = "accepted"
-			reverseMatch.MatchedAt = &now
-			if err := tx.Save(reverseMatch).Error; err != nil {
-				return err
-			}
-
-			// Create initial system chat message
-			chatMsg := entity.DirectChatMessage{
-				Id:        uuid.New(),
-				MatchId:   reverseMatch.Id,
-				SenderId:  uuid.Nil, // system message
-				Message:   "Match! Kalian saling tertarik. Mulai obrolan sekarang!",
-				CreatedAt: now,
+		switch existing.Status {
+		case "pending":
+			if existing.User1Id == receiverId {
+				// Reverse match: receiver sudah mengirim request ke sender
+				return s.autoAccept(existing, receiver, sender)
 			}
-			if err := tx.Create(&chatMsg).Error; err != nil {
-				return err
+			return response.DirectMatchDetailResponse{}, errors.New("match sudah ada antara kedua user")
+		case "unmatched":
+			if existing.ClosedAt != nil {
+				availableAt := existing.ClosedAt.AddDate(0, 0, s.cfg.UnmatchCooldownDays)
+				if time.Now().Before(availableAt) {
+					return response.DirectMatchDetailResponse{}, fmt.Errorf(
+						"kalian baru saja unmatch, bisa match lagi mulai %s", helper.JakartaDate(availableAt))
+				}
 			}
-
-			return nil
-		})
-		if txErr != nil {
-			return response.DirectMatchDetailResponse{}, txErr
+		case "expired", "withdrawn":
+			// Request sebelumnya tidak dijawab / ditarik: boleh kirim ulang
+		default:
+			return response.DirectMatchDetailResponse{}, errors.New("match sudah ada antara kedua user")
 		}
-
-		result = s.buildMatchResponse(reverseMatch, receiver, sender)
-		return result, nil
 	}
 
 	// No reverse match - create a new pending match
+	now := time.Now()
+	expiresAt := now.AddDate(0, 0, s.cfg.RequestTTLDays)
 	match := entity.DirectMatch{
 		Id:        uuid.New(),
 		User1Id:   senderId,
 		User2Id:   receiverId,
 		Status:    "pending",
-		CreatedAt: time.Now(),
+		CreatedAt: now,
+		ExpiresAt: &expiresAt,
 	}
 
 	if err := s.repo.Create(&match); err != nil {
 		return response.DirectMatchDetailResponse{}, err
 	}
 
+	s.notifyMatch(receiverId, entity.NotifMatchRequest, "Request match baru",
+		displayName(sender)+" ingin lari bareng kamu.", &senderId, match.Id)
+
 	return s.buildMatchResponse(&match, sender, receiver), nil
 }
 
+// autoAccept menerima reverse match (kedua user saling tertarik) dalam transaksi
+// sekaligus membuat chat room.
+func (s *directMatchService) autoAccept(reverseMatch *entity.DirectMatch, receiver, sender *entity.User) (response.DirectMatchDetailResponse, error) {
+	if reverseMatch.ExpiresAt != nil && time.Now().After(*reverseMatch.ExpiresAt) {
+		return response.DirectMatchDetailResponse{}, errors.New("request match sudah kedaluwarsa")
+	}
+
+	txErr := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Guard status agar tidak menimpa withdraw / expiry yang berjalan bersamaan
+		res := tx.Model(&entity.DirectMatch{}).
+			Where("id = ? AND status = ?", reverseMatch.Id, "pending").
+			Updates(map[string]interface{}{"status": "accepted", "matched_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("match sudah diproses sebelumnya")
+		}
+		reverseMatch.Status = "accepted"
+		reverseMatch.MatchedAt = &now
+
+		// Create initial system chat message
+		chatMsg := entity.DirectChatMessage{
+			Id:        uuid.New(),
+			MatchId:   reverseMatch.Id,
+			SenderId:  uuid.Nil, // system message
+			Message:   "Match! Kalian saling tertarik. Mulai obrolan sekarang!",
+			CreatedAt: now,
+		}
+		return tx.Create(&chatMsg).Error
+	})
+	if txErr != nil {
+		return response.DirectMatchDetailResponse{}, txErr
+	}
+
+	s.notifyMatch(receiver.Id, entity.NotifMatchAccepted, "Match!",
+		displayName(sender)+" juga tertarik. Mulai obrolan sekarang!", &sender.Id, reverseMatch.Id)
+
+	return s.buildMatchResponse(reverseMatch, receiver, sender), nil
+}
+
 // AcceptMatch accepts a pending match with transaction: update status + create chat room.
 func (s *directMatchService) AcceptMatch(matchId uuid.UUID, userId uuid.UUID) (response.DirectMatchDetailResponse, error) {
 	match, err := s.repo.FindById(matchId)
@@ -170,13 +356,24 @@ func (s *directMatchService) AcceptMatch(matchId uuid.UUID, userId uuid.UUID) (r
 		return response.DirectMatchDetailResponse{}, errors.New("match sudah diproses sebelumnya")
 	}
 
+	// Job expiry mungkin belum sempat berjalan
+	if match.ExpiresAt != nil && time.Now().After(*match.ExpiresAt) {
+		return response.DirectMatchDetailResponse{}, errors.New("request match sudah kedaluwarsa")
+	}
+
 	txErr := s.db.Transaction(func(tx *gorm.DB) error {
 		now := time.Now()
+		res := tx.Model(&entity.DirectMatch{}).
+			Where("id = ? AND status = ?", match.Id, "pending").
+			Updates(map[string]interface{}{"status": "accepted", "matched_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("match sudah diproses sebelumnya")
+		}
 		match.Status = "accepted"
 		match.MatchedAt = &now
-		if err := tx.Save(match).Error; err != nil {
-			return err
-		}
 
 		// Create chat room with system message
 		chatMsg := entity.DirectChatMessage{
@@ -194,6 +391,10 @@ func (s *directMatchService) AcceptMatch(matchId uuid.UUID, userId uuid.UUID) (r
 
 	user1, _ := s.userRepo.FindById(match.User1Id)
 	user2, _ := s.userRepo.FindById(match.User2Id)
+
+	s.notifyMatch(match.User1Id, entity.NotifMatchAccepted, "Request match diterima",
+		displayName(user2)+" menerima request match kamu. Mulai obrolan sekarang!", &userId, match.Id)
+
 	return s.buildMatchResponse(match, user1, user2), nil
 }
 
@@ -212,16 +413,180 @@ func (s *directMatchService) RejectMatch(matchId uuid.UUID, userId uuid.UUID) (r
 		return response.DirectMatchDetailResponse{}, errors.New("match sudah diproses sebelumnya")
 	}
 
+	if match.ExpiresAt != nil && time.Now().After(*match.ExpiresAt) {
+		return response.DirectMatchDetailResponse{}, errors.New("request match sudah kedaluwarsa")
+	}
+
 	match.Status = "rejected"
-	if err := s.repo.Update(match); err != nil {
+	ok, err := s.repo.TransitionStatus(match, "pending")
+	if err != nil {
 		return response.DirectMatchDetailResponse{}, err
 	}
+	if !ok {
+		return response.DirectMatchDetailResponse{}, errors.New("match sudah diproses sebelumnya")
+	}
+
+	user1, _ := s.userRepo.FindById(match.User1Id)
+	user2, _ := s.userRepo.FindById(match.User2Id)
+
+	s.notifyMatch(match.User1Id, entity.NotifMatchRejected, "Request match ditolak",
+		displayName(user2)+" belum bisa menerima request match kamu.", &userId, match.Id)
+
+	return s.buildMatchResponse(match, user1, user2), nil
+}
+
+// WithdrawMatch menarik request yang masih pending. Hanya pengirim (User1) yang bisa.
+func (s *directMatchService) WithdrawMatch(matchId uuid.UUID, userId uuid.UUID) (response.DirectMatchDetailResponse, error) {
+	match, err := s.repo.FindById(matchId)
+	if err != nil {
+		return response.DirectMatchDetailResponse{}, errors.New("match tidak ditemukan")
+	}
+
+	if match.User1Id != userId {
+		return response.DirectMatchDetailResponse{}, errors.New("hanya pengirim yang bisa menarik request match")
+	}
+
+	if match.Status != "pending" {
+		return response.DirectMatchDetailResponse{}, errors.New("match sudah diproses sebelumnya")
+	}
+
+	now := time.Now()
+	match.Status = "withdrawn"
+	match.ClosedAt = &now
+	match.ClosedBy = &userId
+	ok, err := s.repo.TransitionStatus(match, "pending")
+	if err != nil {
+		return response.DirectMatchDetailResponse{}, err
+	}
+	if !ok {
+		return response.DirectMatchDetailResponse{}, errors.New("match sudah diproses sebelumnya")
+	}
+
+	user1, _ := s.userRepo.FindById(match.User1Id)
+	user2, _ := s.userRepo.FindById(match.User2Id)
+
+	s.notifyMatch(match.User2Id, entity.NotifMatchWithdrawn, "Request match ditarik",
+		displayName(user1)+" menarik kembali request match-nya.", &userId, match.Id)
+
+	return s.buildMatchResponse(match, user1, user2), nil
+}
+
+// Unmatch mengakhiri match yang sudah accepted oleh salah satu pihak. Chat room
+// ditutup (pesan baru ditolak), match hilang dari inbox kedua user, dan pasangan
+// ini baru bisa match lagi setelah UnmatchCooldownDays.
+func (s *directMatchService) Unmatch(matchId uuid.UUID, userId uuid.UUID) (response.DirectMatchDetailResponse, error) {
+	match, err := s.repo.FindById(matchId)
+	if err != nil {
+		return response.DirectMatchDetailResponse{}, errors.New("match tidak ditemukan")
+	}
+
+	if match.User1Id != userId && match.User2Id != userId {
+		return response.DirectMatchDetailResponse{}, errors.New("kamu bukan bagian dari match ini")
+	}
+
+	if match.Status != "accepted" {
+		return response.DirectMatchDetailResponse{}, errors.New("hanya match yang sudah diterima yang bisa di-unmatch")
+	}
+
+	now := time.Now()
+	txErr := s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entity.DirectMatch{}).
+			Where("id = ? AND status = ?", match.Id, "accepted").
+			Updates(map[string]interface{}{"status": "unmatched", "closed_at": now, "closed_by": userId})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errors.New("match sudah diproses sebelumnya")
+		}
+
+		// Penanda penutupan chat room
+		chatMsg := entity.DirectChatMessage{
+			Id:        uuid.New(),
+			MatchId:   match.Id,
+			SenderId:  uuid.Nil,
+			Message:   "Match telah berakhir. Obrolan ini ditutup.",
+			CreatedAt: now,
+		}
+		return tx.Create(&chatMsg).Error
+	})
+	if txErr != nil {
+		return response.DirectMatchDetailResponse{}, txErr
+	}
+	match.Status = "unmatched"
+	match.ClosedAt = &now
+	match.ClosedBy = &userId
+
+	otherId := match.User1Id
+	if otherId == userId {
+		otherId = match.User2Id
+	}
+	s.notifyMatch(otherId, entity.NotifMatchUnmatched, "Match berakhir",
+		"Salah satu match kamu telah berakhir dan obrolannya ditutup.", nil, match.Id)
 
 	user1, _ := s.userRepo.FindById(match.User1Id)
 	user2, _ := s.userRepo.FindById(match.User2Id)
 	return s.buildMatchResponse(match, user1, user2), nil
 }
 
+func (s *directMatchService) RunJobs() {
+	ticker := time.NewTicker(matchJobInterval)
+	defer ticker.Stop()
+
+	for {
+		s.expirePendingRequests()
+		<-ticker.C
+	}
+}
+
+// expirePendingRequests menandai request pending yang lewat TTL sebagai expired
+// dan memberi tahu pengirimnya.
+func (s *directMatchService) expirePendingRequests() {
+	now := time.Now()
+	matches, err := s.repo.FindExpiredPending(now, now.AddDate(0, 0, -s.cfg.RequestTTLDays))
+	if err != nil {
+		log.Printf("❌ Gagal mengambil request match kedaluwarsa: %v", err)
+		return
+	}
+
+	for i := range matches {
+		if _, err := s.expireMatch(&matches[i], now); err != nil {
+			log.Printf("❌ Gagal meng-expire match %s: %v", matches[i].Id, err)
+		}
+	}
+}
+
+// expireMatch menandai request pending sebagai expired dan memberi tahu pengirimnya.
+// ok=false bila request sudah diterima/ditolak/ditarik lebih dulu.
+func (s *directMatchService) expireMatch(match *entity.DirectMatch, now time.Time) (bool, error) {
+	match.Status = "expired"
+	match.ClosedAt = &now
+	ok, err := s.repo.TransitionStatus(match, "pending")
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	receiver, _ := s.userRepo.FindById(match.User2Id)
+	s.notifyMatch(match.User1Id, entity.NotifMatchExpired, "Request match kedaluwarsa",
+		"Request match kamu ke "+displayName(receiver)+" tidak dijawab dan sudah kedaluwarsa.", nil, match.Id)
+	return true, nil
+}
+
+func (s *directMatchService) notifyMatch(userId uuid.UUID, notifType, title, body string, actorId *uuid.UUID, matchId uuid.UUID) {
+	refId := matchId.String()
+	refType := "match"
+	if err := s.notifSvc.Send(userId, notifType, title, body, actorId, &refId, &refType); err != nil {
+		log.Printf("⚠️ Gagal mengirim notifikasi %s ke %s: %v", notifType, userId, err)
+	}
+}
+
+func displayName(user *entity.User) string {
+	if user == nil || helper.DerefOrEmpty(user.Name) == "" {
+		return "seseorang"
+	}
+	return helper.DerefOrEmpty(user.Name)
+}
+
 func (s *directMatchService) FindById(id uuid.UUID) (response.DirectMatchDetailResponse, error) {
 	match, err := s.repo.FindById(id)
 	if err != nil {
@@ -322,5 +687,7 @@ func (s *directMatchService) buildMatchResponse(match *entity.DirectMatch, user1
 		Status:                 match.Status,
 		CreatedAt:              match.CreatedAt,
 		MatchedAt:              match.MatchedAt,
+		ExpiresAt:              match.ExpiresAt,
+		ClosedAt:               match.ClosedAt,
 	}
 }
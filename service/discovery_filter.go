@@ -0,0 +1,82 @@
+package service
+
+import (
+	"run-sync/entity"
+	"run-sync/helper"
+	"run-sync/repository"
+	"strings"
+)
+
+// applyRunnerPreference melengkapi query runner dengan preferensi discovery requester
+// (filter yang sudah diisi, misal dari query parameter explore, tidak ditimpa) dan
+// mengaktifkan pengecekan preferensi kandidat terhadap requester.
+func applyRunnerPreference(q *repository.NearbyRunnerQuery, me *entity.RunnerProfile, pref *entity.DiscoveryPreference, defaultRadius float64) {
+	if pref != nil {
+		if q.RadiusKm <= 0 && pref.MaxRadiusKm > 0 {
+			q.RadiusKm = pref.MaxRadiusKm
+		}
+		if q.MinPace <= 0 {
+			q.MinPace = pref.MinPace
+		}
+		if q.MaxPace <= 0 {
+			q.MaxPace = pref.MaxPace
+		}
+		if q.MinDistance <= 0 {
+			q.MinDistance = pref.MinDistance
+		}
+		if q.MaxDistance <= 0 {
+			q.MaxDistance = pref.MaxDistance
+		}
+		if q.Gender == "" && pref.GenderPreference != "any" {
+			q.Gender = pref.GenderPreference
+		}
+	}
+	if q.RadiusKm <= 0 {
+		q.RadiusKm = defaultRadius
+	}
+
+	var gender string
+	if me.User != nil && me.User.Gender != nil {
+		gender = strings.ToLower(*me.User.Gender)
+	}
+	q.Viewer = &repository.DiscoveryViewer{
+		Gender:            gender,
+		AvgPace:           me.AvgPace,
+		PreferredDistance: me.PreferredDistance,
+		DefaultRadiusKm:   defaultRadius,
+	}
+}
+
+// applyRunnerWomenOnly menegakkan women-only dua arah: requester dengan
+// WomenOnlyMode hanya melihat perempuan, dan kandidat dengan WomenOnlyMode
+// tidak terlihat oleh requester yang bukan perempuan.
+func applyRunnerWomenOnly(q *repository.NearbyRunnerQuery, me *entity.RunnerProfile) {
+	female := me.User != nil && helper.IsFemale(me.User.Gender)
+	q.ViewerFemale = &female
+	q.FemaleOnly = me.WomenOnlyMode && female
+}
+
+// applyGroupWomenOnly menyembunyikan grup women-only dari requester yang bukan
+// perempuan (atau belum punya profil).
+func applyGroupWomenOnly(q *repository.NearbyGroupQuery, me *entity.RunnerProfile) {
+	female := me != nil && me.User != nil && helper.IsFemale(me.User.Gender)
+	q.ViewerFemale = &female
+}
+
+// applyGroupPreference menerapkan radius dan rentang pace requester ke pencarian grup.
+func applyGroupPreference(q *repository.NearbyGroupQuery, pref *entity.DiscoveryPreference, defaultRadius float64) {
+	if pref != nil {
+		if q.RadiusKm <= 0 && pref.MaxRadiusKm > 0 {
+			q.RadiusKm = pref.MaxRadiusKm
+		}
+		if q.MinPace <= 0 {
+			q.MinPace = pref.MinPace
+		}
+		if q.MaxPace <= 0 {
+			q.MaxPace = pref.MaxPace
+		}
+	}
+	if q.RadiusKm <= 0 {
+		q.RadiusKm = defaultRadius
+	}
+}
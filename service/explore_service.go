@@ -2,108 +2,81 @@ package service
 
 import (
 	"math"
+	"run-sync/config"
 	"run-sync/data/request"
 	"run-sync/data/response"
+	"run-sync/helper"
 	"run-sync/repository"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type ExploreService interface {
-	FindNearbyRunners(userId uuid.UUID, req request.ExploreRunnersRequest) ([]response.ExploreRunnerResponse, error)
-	FindNearbyGroups(userId uuid.UUID, req request.ExploreGroupsRequest) ([]response.ExploreGroupResponse, error)
+	FindNearbyRunners(userId uuid.UUID, req request.ExploreRunnersRequest) ([]response.ExploreRunnerResponse, int64, error)
+	FindNearbyGroups(userId uuid.UUID, req request.ExploreGroupsRequest) ([]response.ExploreGroupResponse, int64, error)
 }
 
 type exploreService struct {
-	profileRepo     repository.RunnerProfileRepository
-	groupRepo       repository.RunGroupRepository
-	directMatchRepo repository.DirectMatchRepository
-	memberRepo      repository.RunGroupMemberRepository
+	profileRepo repository.RunnerProfileRepository
+	groupRepo   repository.RunGroupRepository
+	prefRepo    repository.DiscoveryPreferenceRepository
+	cfg         *config.MatchingConfig
 }
 
 func NewExploreService(
 	profileRepo repository.RunnerProfileRepository,
 	groupRepo repository.RunGroupRepository,
-	directMatchRepo repository.DirectMatchRepository,
-	memberRepo repository.RunGroupMemberRepository,
+	prefRepo repository.DiscoveryPreferenceRepository,
+	cfg *config.MatchingConfig,
 ) ExploreService {
 	return &exploreService{
-		profileRepo:     profileRepo,
-		groupRepo:       groupRepo,
-		directMatchRepo: directMatchRepo,
-		memberRepo:      memberRepo,
+		profileRepo: profileRepo,
+		groupRepo:   groupRepo,
+		prefRepo:    prefRepo,
+		cfg:         cfg,
 	}
 }
 
-func (s *exploreService) FindNearbyRunners(userId uuid.UUID, req request.ExploreRunnersRequest) ([]response.ExploreRunnerResponse, error) {
-	if req.RadiusKm <= 0 {
-		req.RadiusKm = 10
-	}
-	if req.Limit <= 0 {
-		req.Limit = 20
-	}
-
-	profiles, err := s.profileRepo.FindAll()
+func (s *exploreService) FindNearbyRunners(userId uuid.UUID, req request.ExploreRunnersRequest) ([]response.ExploreRunnerResponse, int64, error) {
+	myProfile, err := s.profileRepo.FindByUserId(userId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-
-	// Get existing matches if we want to exclude them
-	matchedIds := make(map[uuid.UUID]bool)
-	if req.ExcludeMatchedId {
-		matches, _ := s.directMatchRepo.FindUserMatches(userId)
-		for _, m := range matches {
-			matchedIds[m.User1Id] = true
-			matchedIds[m.User2Id] = true
-		}
+	req.Page, req.Limit = helper.NormalizePage(req.Page, req.Limit)
+
+	q := repository.NearbyRunnerQuery{
+		UserId:         userId,
+		Latitude:       req.Latitude,
+		Longitude:      req.Longitude,
+		RadiusKm:       req.RadiusKm,
+		MinPace:        req.MinPace,
+		MaxPace:        req.MaxPace,
+		PreferredTime:  req.PreferredTime,
+		Gender:         req.Gender,
+		ExcludeMatched: req.ExcludeMatchedId,
+		UnmatchedSince: time.Now().AddDate(0, 0, -s.cfg.UnmatchCooldownDays),
+		ExcludeBlocked: true,
+		Limit:          req.Limit,
+		Offset:         (req.Page - 1) * req.Limit,
+	}
+	if req.WomenOnly {
+		womenOnly := true
+		q.WomenOnly = &womenOnly
 	}
 
-	var results []response.ExploreRunnerResponse
-	for _, p := range profiles {
-		// Skip self
-		if p.UserId == userId {
-			continue
-		}
-
-		// Skip already matched
-		if req.ExcludeMatchedId && matchedIds[p.UserId] {
-			continue
-		}
-
-		// Skip inactive profiles
-		if !p.IsActive {
-			continue
-		}
-
-		// Calculate distance
-		dist := haversine(req.Latitude, req.Longitude, p.Latitude, p.Longitude)
-		if dist > req.RadiusKm {
-			continue
-		}
-
-		// Pace filter
-		if req.MinPace > 0 && p.AvgPace < req.MinPace {
-			continue
-		}
-		if req.MaxPace > 0 && p.AvgPace > req.MaxPace {
-			continue
-		}
-
-		// Preferred time filter
-		if req.PreferredTime != "" && p.PreferredTime != req.PreferredTime {
-			continue
-		}
-
-		// Gender filter (via User)
-		if req.Gender != "" && p.User != nil && p.User.Gender != nil && *p.User.Gender != req.Gender {
-			continue
-		}
+	// Query parameter menimpa preferensi tersimpan; preferensi kandidat tetap berlaku
+	pref, _ := s.prefRepo.FindByUserId(userId)
+	applyRunnerPreference(&q, myProfile, pref, s.cfg.MaxRadiusKm)
+	applyRunnerWomenOnly(&q, myProfile)
 
-		// Women only filter
-		if req.WomenOnly && !p.WomenOnlyMode {
-			continue
-		}
+	rows, total, err := s.profileRepo.FindNearby(q)
+	if err != nil {
+		return nil, 0, err
+	}
 
+	results := make([]response.ExploreRunnerResponse, 0, len(rows))
+	for _, p := range rows {
 		var name *string
 		var gender *string
 		if p.User != nil {
@@ -119,68 +92,49 @@ func (s *exploreService) FindNearbyRunners(userId uuid.UUID, req request.Explore
 			PreferredDistance: p.PreferredDistance,
 			PreferredTime:     p.PreferredTime,
 			Image:             p.Image,
-			DistanceKm:        math.Round(dist*100) / 100,
+			DistanceKm:        math.Round(p.DistanceKm*100) / 100,
 			WomenOnlyMode:     p.WomenOnlyMode,
+			VerifiedRunner:    isVerifiedRunner(p.User),
 		})
-
-		if len(results) >= req.Limit {
-			break
-		}
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
-func (s *exploreService) FindNearbyGroups(userId uuid.UUID, req request.ExploreGroupsRequest) ([]response.ExploreGroupResponse, error) {
-	if req.RadiusKm <= 0 {
-		req.RadiusKm = 10
-	}
-	if req.Limit <= 0 {
-		req.Limit = 20
-	}
+func (s *exploreService) FindNearbyGroups(userId uuid.UUID, req request.ExploreGroupsRequest) ([]response.ExploreGroupResponse, int64, error) {
 	if req.Status == "" {
 		req.Status = "open"
 	}
-
-	// Get groups the user has already joined or created
-	memberships, _ := s.memberRepo.FindByUserId(userId)
-	joinedGroupIds := make(map[uuid.UUID]bool)
-	for _, m := range memberships {
-		if m.Status == "joined" {
-			joinedGroupIds[m.GroupId] = true
-		}
+	req.Page, req.Limit = helper.NormalizePage(req.Page, req.Limit)
+
+	q := repository.NearbyGroupQuery{
+		UserId:    userId,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		RadiusKm:  req.RadiusKm,
+		Status:    req.Status,
+		MinPace:   req.MinPace,
+		MaxPace:   req.MaxPace,
+		Limit:     req.Limit,
+		Offset:    (req.Page - 1) * req.Limit,
 	}
-
-	groups, err := s.groupRepo.FindByStatus(req.Status)
-	if err != nil {
-		return nil, err
+	if req.WomenOnly {
+		womenOnly := true
+		q.WomenOnly = &womenOnly
 	}
 
-	var results []response.ExploreGroupResponse
-	for _, g := range groups {
-		// Skip groups the user has already joined
-		if joinedGroupIds[g.Id] {
-			continue
-		}
-
-		dist := haversine(req.Latitude, req.Longitude, g.Latitude, g.Longitude)
-		if dist > req.RadiusKm {
-			continue
-		}
-
-		if req.MinPace > 0 && g.MaxPace < req.MinPace {
-			continue
-		}
-		if req.MaxPace > 0 && g.MinPace > req.MaxPace {
-			continue
-		}
-
-		if req.WomenOnly && !g.IsWomenOnly {
-			continue
-		}
+	pref, _ := s.prefRepo.FindByUserId(userId)
+	applyGroupPreference(&q, pref, s.cfg.MaxRadiusKm)
+	myProfile, _ := s.profileRepo.FindByUserId(userId)
+	applyGroupWomenOnly(&q, myProfile)
 
-		memberCount, _ := s.groupRepo.GetMemberCount(g.Id)
+	rows, total, err := s.groupRepo.FindNearby(q)
+	if err != nil {
+		return nil, 0, err
+	}
 
+	results := make([]response.ExploreGroupResponse, 0, len(rows))
+	for _, g := range rows {
 		results = append(results, response.ExploreGroupResponse{
 			GroupId:           g.Id.String(),
 			Name:              g.Name,
@@ -190,29 +144,13 @@ func (s *exploreService) FindNearbyGroups(userId uuid.UUID, req request.ExploreG
 			MeetingPoint:      g.MeetingPoint,
 			ScheduledAt:       g.ScheduledAt,
 			MaxMember:         g.MaxMember,
-			CurrentMembers:    int(memberCount),
+			CurrentMembers:    int(g.MemberCount),
 			IsWomenOnly:       g.IsWomenOnly,
 			Status:            g.Status,
-			DistanceKm:        math.Round(dist*100) / 100,
+			DistanceKm:        math.Round(g.DistanceKm*100) / 100,
 			CreatedBy:         g.CreatedBy.String(),
 		})
-
-		if len(results) >= req.Limit {
-			break
-		}
 	}
 
-	return results, nil
-}
-
-// haversine calculates the distance in km between two lat/lng points.
-func haversine(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371.0 // Earth radius in km
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLon := (lon2 - lon1) * math.Pi / 180
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(lat1*math.Pi/180)*math.Cos(lat2*math.Pi/180)*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return R * c
+	return results, total, nil
 }
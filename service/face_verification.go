@@ -0,0 +1,500 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"run-sync/data/request"
+	"run-sync/data/response"
+	"run-sync/entity"
+	"run-sync/helper"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// faceGesture adalah satu challenge liveness: user harus mengirim selfie dengan
+// pose kepala tertentu yang baru diketahui setelah challenge dibuat.
+type faceGesture struct {
+	instruction string
+	check       func(p helper.FacePose) bool
+}
+
+var faceGestures = map[string]faceGesture{
+	"turn_left": {
+		instruction: "Tolehkan kepala ke kiri",
+		check:       func(p helper.FacePose) bool { return p.Yaw >= 15 },
+	},
+	"turn_right": {
+		instruction: "Tolehkan kepala ke kanan",
+		check:       func(p helper.FacePose) bool { return p.Yaw <= -15 },
+	},
+	"look_up": {
+		instruction: "Angkat dagu dan lihat ke atas",
+		check:       func(p helper.FacePose) bool { return p.Pitch >= 12 },
+	},
+	"look_down": {
+		instruction: "Tundukkan kepala dan lihat ke bawah",
+		check:       func(p helper.FacePose) bool { return p.Pitch <= -12 },
+	},
+}
+
+// StartFaceChallenge membuat challenge gesture acak untuk foto verifikasi terbaru.
+// Challenge lama yang belum dijawab otomatis kedaluwarsa.
+func (s *userPhotoService) StartFaceChallenge(userId uuid.UUID) (response.FaceChallengeResponse, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return response.FaceChallengeResponse{}, errors.New("user tidak ditemukan")
+	}
+	if err := canStartFaceVerification(user); err != nil {
+		return response.FaceChallengeResponse{}, err
+	}
+
+	photo, err := s.repo.FindVerificationPhoto(userId)
+	if err != nil {
+		return response.FaceChallengeResponse{}, errors.New("foto verifikasi belum diunggah, silakan upload foto verifikasi terlebih dahulu")
+	}
+
+	if err := s.faceRepo.ExpireOpenChallenges(userId); err != nil {
+		return response.FaceChallengeResponse{}, err
+	}
+
+	names := make([]string, 0, len(faceGestures))
+	for name := range faceGestures {
+		names = append(names, name)
+	}
+	gesture := names[rand.Intn(len(names))]
+
+	now := time.Now()
+	attempt := entity.FaceVerification{
+		Id:                 uuid.New(),
+		UserId:             userId,
+		PhotoId:            photo.Id,
+		Gesture:            gesture,
+		ChallengeExpiresAt: now.Add(s.faceCfg.ChallengeTTL),
+		Status:             entity.FaceAttemptChallenged,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if err := s.faceRepo.Create(&attempt); err != nil {
+		return response.FaceChallengeResponse{}, err
+	}
+
+	return response.FaceChallengeResponse{
+		ChallengeId: attempt.Id.String(),
+		Gesture:     gesture,
+		Instruction: faceGestures[gesture].instruction,
+		ExpiresAt:   attempt.ChallengeExpiresAt,
+	}, nil
+}
+
+// VerifyFace menjawab challenge: selfie depan dibandingkan dengan foto verifikasi
+// (dan foto verifikasi dengan foto profil), selfie gesture dipakai untuk liveness.
+// Similarity di atas FACE_MATCH_THRESHOLD langsung verified, di antara
+// FACE_REVIEW_THRESHOLD dan FACE_MATCH_THRESHOLD masuk antrean review manual,
+// selebihnya ditolak.
+func (s *userPhotoService) VerifyFace(userId uuid.UUID, req request.FaceVerifyRequest) (response.FaceVerifyResponse, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return response.FaceVerifyResponse{}, errors.New("user tidak ditemukan")
+	}
+	if err := canStartFaceVerification(user); err != nil {
+		return response.FaceVerifyResponse{}, err
+	}
+
+	challengeId, _ := uuid.Parse(req.ChallengeId)
+	attempt, err := s.faceRepo.FindById(challengeId)
+	if err != nil || attempt.UserId != userId {
+		return response.FaceVerifyResponse{}, errors.New("challenge tidak ditemukan")
+	}
+	if attempt.Status != entity.FaceAttemptChallenged {
+		return response.FaceVerifyResponse{}, errors.New("challenge sudah dipakai, minta challenge baru")
+	}
+	if time.Now().After(attempt.ChallengeExpiresAt) {
+		attempt.Status = entity.FaceAttemptExpired
+		_ = s.faceRepo.Update(attempt)
+		return response.FaceVerifyResponse{}, errors.New("challenge sudah kedaluwarsa, minta challenge baru")
+	}
+
+	photo, err := s.repo.FindVerificationPhoto(userId)
+	if err != nil || photo.Id != attempt.PhotoId {
+		return response.FaceVerifyResponse{}, errors.New("foto verifikasi sudah berubah, minta challenge baru")
+	}
+
+	front, err := helper.DecodeBase64Image(req.Image)
+	if err != nil {
+		return response.FaceVerifyResponse{}, errors.New("image bukan base64 yang valid")
+	}
+	gestureImage, err := helper.DecodeBase64Image(req.GestureImage)
+	if err != nil {
+		return response.FaceVerifyResponse{}, errors.New("gesture_image bukan base64 yang valid")
+	}
+
+	// Selfie yang tidak valid menghabiskan challenge; error teknis Face Service tidak
+	reason, err := s.runFaceChecks(attempt, photo, front, gestureImage)
+	if errors.Is(err, helper.ErrFaceServiceUnavailable) {
+		if !s.faceCfg.SkipWhenUnavailable {
+			return response.FaceVerifyResponse{}, errors.New("layanan verifikasi wajah sedang tidak tersedia, coba lagi nanti")
+		}
+		// Policy skip: tanpa pengecekan otomatis, keputusan diserahkan ke reviewer
+		log.Printf("[FaceVerify] provider %s tidak tersedia, percobaan %s masuk review manual: %v", s.face.Name(), attempt.Id, err)
+		return s.queueFaceReview(user, attempt, req.Image, "layanan verifikasi wajah tidak tersedia, perlu review manual")
+	}
+	if err != nil {
+		return response.FaceVerifyResponse{}, errors.New("gagal melakukan verifikasi wajah: " + err.Error())
+	}
+	if reason != "" {
+		return s.rejectFaceAttempt(user, attempt, photo, reason)
+	}
+
+	sim := float64(attempt.Similarity)
+	switch {
+	case sim >= s.faceCfg.MatchThreshold && (attempt.ProfileSimilarity == nil || float64(*attempt.ProfileSimilarity) >= s.faceCfg.ReviewThreshold):
+		return s.approveFaceAttempt(user, attempt, photo, nil, "")
+	case sim >= s.faceCfg.MatchThreshold:
+		return s.queueFaceReview(user, attempt, req.Image, "foto verifikasi tidak mirip dengan foto profil")
+	case sim >= s.faceCfg.ReviewThreshold:
+		return s.queueFaceReview(user, attempt, req.Image, "similarity borderline")
+	default:
+		return s.rejectFaceAttempt(user, attempt, photo, "wajah tidak cocok dengan foto verifikasi")
+	}
+}
+
+// runFaceChecks menjalankan pengecekan otomatis dan mengisi hasilnya ke attempt.
+// reason tidak kosong berarti selfie ditolak; error berarti pengecekan tidak bisa diselesaikan.
+func (s *userPhotoService) runFaceChecks(attempt *entity.FaceVerification, photo *entity.UserPhoto, front, gestureImage []byte) (string, error) {
+	reason, err := s.checkFrontFace(front)
+	if err != nil || reason != "" {
+		if reason != "" {
+			reason = "selfie tidak valid: " + reason
+		}
+		return reason, err
+	}
+
+	reason, err = s.checkLiveness(attempt.Gesture, front, gestureImage)
+	if err != nil || reason != "" {
+		return reason, err
+	}
+	attempt.LivenessPassed = true
+
+	stored, err := s.media.Download(photo.Url)
+	if err != nil {
+		return "", err
+	}
+	similarity, err := s.face.CompareFaces(front, stored)
+	if err != nil {
+		return "", err
+	}
+	attempt.Similarity = similarity
+
+	attempt.ProfileSimilarity, err = s.bestProfileSimilarity(photo, stored)
+	return "", err
+}
+
+// checkFrontFace memastikan gambar berisi tepat satu wajah tampak depan.
+// reason tidak kosong berarti gambar ditolak.
+func (s *userPhotoService) checkFrontFace(image []byte) (string, error) {
+	pose, err := s.face.DetectFace(image)
+	if errors.Is(err, helper.ErrNoFaceDetected) || errors.Is(err, helper.ErrMultipleFaces) {
+		return err.Error(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if pose != nil && !pose.IsFrontal() {
+		return "wajah harus menghadap lurus ke depan (tampak depan), hindari sudut miring", nil
+	}
+	return "", nil
+}
+
+// checkLiveness memastikan selfie gesture menunjukkan pose yang diminta dan
+// orang yang sama dengan selfie depan.
+func (s *userPhotoService) checkLiveness(gesture string, front, gestureImage []byte) (string, error) {
+	g, ok := faceGestures[gesture]
+	if !ok {
+		return "gesture tidak dikenal", nil
+	}
+
+	pose, err := s.face.DetectFace(gestureImage)
+	if errors.Is(err, helper.ErrNoFaceDetected) || errors.Is(err, helper.ErrMultipleFaces) {
+		return "selfie gesture tidak valid: " + err.Error(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if pose == nil || !g.check(*pose) {
+		return "gesture tidak sesuai instruksi: " + g.instruction, nil
+	}
+
+	same, err := s.face.CompareFaces(front, gestureImage)
+	if err != nil {
+		return "", err
+	}
+	if float64(same) < s.faceCfg.MatchThreshold {
+		return "selfie gesture bukan orang yang sama", nil
+	}
+	return "", nil
+}
+
+// bestProfileSimilarity membandingkan foto verifikasi dengan setiap foto profil
+// dan mengembalikan similarity tertinggi (nil jika belum ada foto profil).
+func (s *userPhotoService) bestProfileSimilarity(verification *entity.UserPhoto, stored []byte) (*float32, error) {
+	photos, err := s.repo.FindByUserId(verification.UserId)
+	if err != nil {
+		return nil, nil
+	}
+
+	var best *float32
+	for _, p := range photos {
+		if p.Type != "profile" {
+			continue
+		}
+		image, err := s.media.Download(p.Url)
+		if err != nil {
+			log.Printf("[FaceVerify] gagal mengambil foto profil %s: %v", p.Id, err)
+			continue
+		}
+		sim, err := s.face.CompareFaces(stored, image)
+		if errors.Is(err, helper.ErrFaceServiceUnavailable) {
+			return nil, err
+		}
+		if err != nil {
+			log.Printf("[FaceVerify] gagal membandingkan foto profil %s: %v", p.Id, err)
+			continue
+		}
+		if best == nil || sim > *best {
+			v := sim
+			best = &v
+		}
+	}
+	return best, nil
+}
+
+func (s *userPhotoService) approveFaceAttempt(user *entity.User, attempt *entity.FaceVerification, photo *entity.UserPhoto, reviewerId *uuid.UUID, note string) (response.FaceVerifyResponse, error) {
+	now := time.Now()
+	attempt.Status = entity.FaceAttemptApproved
+	attempt.ReviewerId = reviewerId
+	attempt.ReviewNote = note
+	if reviewerId != nil {
+		attempt.ReviewedAt = &now
+	}
+	user.FaceVerificationStatus = entity.FaceVerified
+	user.FaceVerifiedAt = &now
+	photo.ReviewStatus = entity.PhotoReviewApproved
+	photo.ReviewedAt = &now
+
+	if err := s.saveFaceDecision(user, attempt, photo); err != nil {
+		return response.FaceVerifyResponse{}, err
+	}
+	s.notifyFace(user.Id, entity.NotifFaceVerified, "Verifikasi wajah berhasil",
+		"Selamat! Kamu sekarang memiliki badge verified runner.", attempt.Id)
+
+	p := s.toUserPhotoResponse(photo)
+	return response.FaceVerifyResponse{
+		Matched:        true,
+		Similarity:     attempt.Similarity,
+		LivenessPassed: attempt.LivenessPassed,
+		IsVerified:     true,
+		Status:         user.FaceVerificationStatus,
+		Message:        "Wajah terverifikasi",
+		Photo:          &p,
+	}, nil
+}
+
+func (s *userPhotoService) rejectFaceAttempt(user *entity.User, attempt *entity.FaceVerification, photo *entity.UserPhoto, reason string) (response.FaceVerifyResponse, error) {
+	attempt.Status = entity.FaceAttemptRejected
+	attempt.Reason = reason
+	user.FaceVerificationStatus = entity.FaceRejected
+	user.FaceVerifiedAt = nil
+	photo.ReviewStatus = entity.PhotoReviewRejected
+
+	if err := s.saveFaceDecision(user, attempt, photo); err != nil {
+		return response.FaceVerifyResponse{}, err
+	}
+	s.notifyFace(user.Id, entity.NotifFaceRejected, "Verifikasi wajah gagal",
+		"Verifikasi wajah kamu gagal: "+reason+". Silakan coba lagi.", attempt.Id)
+
+	return response.FaceVerifyResponse{
+		Similarity:     attempt.Similarity,
+		LivenessPassed: attempt.LivenessPassed,
+		Status:         user.FaceVerificationStatus,
+		Message:        reason,
+	}, nil
+}
+
+func (s *userPhotoService) queueFaceReview(user *entity.User, attempt *entity.FaceVerification, selfie, reason string) (response.FaceVerifyResponse, error) {
+	// Simpan selfie agar reviewer bisa membandingkan
+	selfieUrl, err := s.media.UploadBase64(selfie, StorageFolderVerifications)
+	if err != nil {
+		return response.FaceVerifyResponse{}, errors.New("gagal menyimpan selfie: " + err.Error())
+	}
+	attempt.SelfieUrl = selfieUrl
+	attempt.Reason = reason
+	attempt.Status = entity.FaceAttemptReview
+	user.FaceVerificationStatus = entity.FacePending
+
+	if err := s.saveFaceDecision(user, attempt, nil); err != nil {
+		return response.FaceVerifyResponse{}, err
+	}
+
+	return response.FaceVerifyResponse{
+		Similarity:     attempt.Similarity,
+		LivenessPassed: attempt.LivenessPassed,
+		Status:         user.FaceVerificationStatus,
+		Message:        "Verifikasi wajah kamu sedang ditinjau oleh tim kami",
+	}, nil
+}
+
+// saveFaceDecision menyimpan status percobaan, status user dan review foto secara atomik.
+func (s *userPhotoService) saveFaceDecision(user *entity.User, attempt *entity.FaceVerification, photo *entity.UserPhoto) error {
+	attempt.UpdatedAt = time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(attempt).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entity.User{}).Where("id = ?", user.Id).Updates(map[string]interface{}{
+			"face_verification_status": user.FaceVerificationStatus,
+			"face_verified_at":         user.FaceVerifiedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if photo != nil {
+			return tx.Model(&entity.UserPhoto{}).Where("id = ?", photo.Id).Updates(map[string]interface{}{
+				"review_status": photo.ReviewStatus,
+				"reviewed_at":   photo.ReviewedAt,
+			}).Error
+		}
+		return nil
+	})
+}
+
+func (s *userPhotoService) GetFaceVerificationStatus(userId uuid.UUID) (response.FaceVerificationStatusResponse, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return response.FaceVerificationStatusResponse{}, errors.New("user tidak ditemukan")
+	}
+
+	res := response.FaceVerificationStatusResponse{
+		Status:     faceStatus(user),
+		VerifiedAt: user.FaceVerifiedAt,
+	}
+	if res.Status == entity.FaceRejected {
+		if last, err := s.faceRepo.FindLatestByUser(userId); err == nil {
+			res.Reason = last.Reason
+		}
+	}
+	return res, nil
+}
+
+// FaceReviewQueue mengembalikan percobaan borderline yang menunggu keputusan reviewer.
+func (s *userPhotoService) FaceReviewQueue(page, limit int) ([]response.FaceReviewResponse, int64, error) {
+	items, total, err := s.faceRepo.FindReviewQueue(page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]response.FaceReviewResponse, 0, len(items))
+	for i := range items {
+		results = append(results, s.toFaceReviewResponse(&items[i]))
+	}
+	return results, total, nil
+}
+
+// ReviewFaceVerification memutuskan percobaan di antrean review (approve / reject).
+func (s *userPhotoService) ReviewFaceVerification(reviewerId, attemptId uuid.UUID, req request.ReviewFaceVerificationRequest) (response.FaceReviewResponse, error) {
+	attempt, err := s.faceRepo.FindById(attemptId)
+	if err != nil {
+		return response.FaceReviewResponse{}, errors.New("data verifikasi tidak ditemukan")
+	}
+	if attempt.Status != entity.FaceAttemptReview {
+		return response.FaceReviewResponse{}, errors.New("verifikasi ini sudah diputuskan")
+	}
+	if attempt.UserId == reviewerId {
+		return response.FaceReviewResponse{}, errors.New("tidak bisa mereview verifikasi milik sendiri")
+	}
+
+	user, err := s.userRepo.FindById(attempt.UserId)
+	if err != nil {
+		return response.FaceReviewResponse{}, errors.New("user tidak ditemukan")
+	}
+	photo, err := s.repo.FindById(attempt.PhotoId)
+	if err != nil {
+		return response.FaceReviewResponse{}, errors.New("foto verifikasi sudah tidak ada")
+	}
+
+	if req.Decision == "approve" {
+		_, err = s.approveFaceAttempt(user, attempt, photo, &reviewerId, req.Note)
+	} else {
+		now := time.Now()
+		attempt.ReviewerId = &reviewerId
+		attempt.ReviewNote = req.Note
+		attempt.ReviewedAt = &now
+		photo.ReviewedAt = &now
+		reason := "ditolak oleh reviewer"
+		if req.Note != "" {
+			reason = fmt.Sprintf("ditolak oleh reviewer (%s)", req.Note)
+		}
+		_, err = s.rejectFaceAttempt(user, attempt, photo, reason)
+	}
+	if err != nil {
+		return response.FaceReviewResponse{}, err
+	}
+
+	return s.toFaceReviewResponse(attempt), nil
+}
+
+func (s *userPhotoService) toFaceReviewResponse(v *entity.FaceVerification) response.FaceReviewResponse {
+	res := response.FaceReviewResponse{
+		Id:                v.Id.String(),
+		UserId:            v.UserId.String(),
+		SelfieUrl:         s.media.SignedURL(v.SelfieUrl),
+		Similarity:        v.Similarity,
+		ProfileSimilarity: v.ProfileSimilarity,
+		LivenessPassed:    v.LivenessPassed,
+		Reason:            v.Reason,
+		Status:            v.Status,
+		ReviewNote:        v.ReviewNote,
+		ReviewedAt:        v.ReviewedAt,
+		CreatedAt:         v.CreatedAt,
+	}
+	if user, err := s.userRepo.FindById(v.UserId); err == nil {
+		res.UserName = user.Name
+	}
+	if photo, err := s.repo.FindById(v.PhotoId); err == nil {
+		res.VerificationPhotoUrl = s.media.SignedURL(photo.Url)
+	}
+	return res
+}
+
+func (s *userPhotoService) notifyFace(userId uuid.UUID, notifType, title, body string, attemptId uuid.UUID) {
+	refId := attemptId.String()
+	refType := "face_verification"
+	if err := s.notifSvc.Send(userId, notifType, title, body, nil, &refId, &refType); err != nil {
+		log.Printf("⚠️ Gagal mengirim notifikasi %s ke %s: %v", notifType, userId, err)
+	}
+}
+
+func canStartFaceVerification(user *entity.User) error {
+	switch faceStatus(user) {
+	case entity.FaceVerified:
+		return errors.New("wajah kamu sudah terverifikasi")
+	case entity.FacePending:
+		return errors.New("verifikasi wajah kamu sedang ditinjau")
+	}
+	return nil
+}
+
+// faceStatus mengembalikan status verifikasi wajah (data lama tanpa status = unverified).
+func faceStatus(user *entity.User) string {
+	if user == nil || user.FaceVerificationStatus == "" {
+		return entity.FaceUnverified
+	}
+	return user.FaceVerificationStatus
+}
+
+// isVerifiedRunner adalah badge "verified runner" di profil dan explore.
+func isVerifiedRunner(user *entity.User) bool {
+	return faceStatus(user) == entity.FaceVerified
+}
@@ -1,10 +1,15 @@
 package service
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
 	"fmt"
-	"os"
+	"math/big"
 	"time"
 
+	"run-sync/config"
+	"run-sync/data/response"
 	"run-sync/helper"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,6 +20,10 @@ type JWTService interface {
 	GenerateRefreshToken(userId string) string
 	ValidateToken(token string) (*jwt.Token, error)
 	ValidateRefreshToken(token string) (*jwt.Token, error)
+	JWKS() response.JWKSResponse
+
+	// RevokeAllTokens mencabut semua access & refresh token user yang sudah diterbitkan.
+	RevokeAllTokens(userId string) error
 }
 
 type jwtCustomClaims struct {
@@ -27,33 +36,20 @@ type jwtCustomClaims struct {
 }
 
 type jwtService struct {
-	secretKey        string
-	refreshSecretKey string
-	issuer           string
+	keyRing *config.JWTKeyRing
+	redis   *helper.RedisHelper
+	issuer  string
 }
 
-func NewJwtService() JWTService {
-	return &jwtService{
-		issuer:           "run-sync",
-		secretKey:        getSecretKey(),
-		refreshSecretKey: getRefreshSecretKey(),
-	}
-}
-
-func getSecretKey() string {
-	secretKey := os.Getenv("JWT_SECRET")
-	if secretKey == "" {
-		secretKey = "sdfnkjsdf28fmn*(&^%^%&bjsdfgsQ$@$sadjfsdfx"
-	}
-	return secretKey
-}
+// refreshTokenTTL juga menjadi batas lama penyimpanan penanda pencabutan token.
+const refreshTokenTTL = 7 * 24 * time.Hour
 
-func getRefreshSecretKey() string {
-	secretKey := os.Getenv("JWT_REFRESH_SECRET")
-	if secretKey == "" {
-		secretKey = "rf_sdfnkjsdf28fmn*(&^%^%&bjsdfgsQ$@$sadjfsdfx"
+func NewJwtService(keyRing *config.JWTKeyRing, redis *helper.RedisHelper) JWTService {
+	return &jwtService{
+		issuer:  "run-sync",
+		keyRing: keyRing,
+		redis:   redis,
 	}
-	return secretKey
 }
 
 func (j *jwtService) GenerateToken(userId string, phoneNumber string, email *string, expiredAt time.Time) string {
@@ -64,50 +60,137 @@ func (j *jwtService) GenerateToken(userId string, phoneNumber string, email *str
 		"is_verified":  true, // Only verified users should get tokens
 		"is_active":    true, // Only active users should get tokens
 		"token_type":   "access",
+		"iat":          time.Now().Unix(),
 		"exp":          expiredAt.Unix(),
 		"iss":          j.issuer,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(j.secretKey))
-	helper.ErrorPanic(err)
-
-	return signedToken
+	return j.sign(claims)
 }
 
 func (j *jwtService) GenerateRefreshToken(userId string) string {
 	claims := jwt.MapClaims{
 		"user_id":    userId,
 		"token_type": "refresh",
-		"exp":        time.Now().Add(7 * 24 * time.Hour).Unix(), // 7 days
+		"iat":        time.Now().Unix(),
+		"exp":        time.Now().Add(refreshTokenTTL).Unix(), // 7 days
 		"iss":        j.issuer,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(j.refreshSecretKey))
+	return j.sign(claims)
+}
+
+func (j *jwtService) ValidateToken(encodedToken string) (*jwt.Token, error) {
+	return j.parse(encodedToken)
+}
+
+func (j *jwtService) ValidateRefreshToken(encodedToken string) (*jwt.Token, error) {
+	return j.parse(encodedToken)
+}
+
+func (j *jwtService) RevokeAllTokens(userId string) error {
+	return j.redis.SetTokensRevokedAt(userId, time.Now(), refreshTokenTTL)
+}
+
+// JWKS mengembalikan public key semua kunci verifikasi dalam format JWK Set,
+// agar service internal lain bisa memverifikasi token tanpa berbagi secret.
+func (j *jwtService) JWKS() response.JWKSResponse {
+	keys := make([]response.JWK, 0)
+	for _, k := range j.keyRing.Keys() {
+		jwk := response.JWK{
+			Kid: k.Kid,
+			Use: "sig",
+			Alg: k.Method.Alg(),
+		}
+
+		switch pub := k.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		keys = append(keys, jwk)
+	}
+
+	return response.JWKSResponse{Keys: keys}
+}
+
+// sign menandatangani claims dengan kunci aktif dan menyertakan kid di header.
+func (j *jwtService) sign(claims jwt.MapClaims) string {
+	key := j.keyRing.Active()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Kid
+
+	signedToken, err := token.SignedString(key.PrivateKey)
 	helper.ErrorPanic(err)
 
 	return signedToken
 }
 
-func (j *jwtService) ValidateToken(encodedToken string) (*jwt.Token, error) {
+// parse memverifikasi token dengan kunci sesuai kid di header. Semua kunci di
+// key ring diterima, sehingga token lama tetap valid selama rotasi kunci.
+func (j *jwtService) parse(encodedToken string) (*jwt.Token, error) {
 	token, err := jwt.Parse(encodedToken, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		kid, _ := t.Header["kid"].(string)
+		if kid == "" {
+			return nil, fmt.Errorf("kid tidak ditemukan di header token")
 		}
-		return []byte(j.secretKey), nil
-	})
 
-	return token, err
-}
+		key, ok := j.keyRing.Lookup(kid)
+		if !ok {
+			return nil, fmt.Errorf("kid %q tidak dikenal", kid)
+		}
 
-func (j *jwtService) ValidateRefreshToken(encodedToken string) (*jwt.Token, error) {
-	token, err := jwt.Parse(encodedToken, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		if t.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return []byte(j.refreshSecretKey), nil
-	})
 
-	return token, err
+		return key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(j.issuer),
+	)
+	if err != nil {
+		return token, err
+	}
+
+	if err := j.checkRevoked(token); err != nil {
+		token.Valid = false
+		return token, err
+	}
+
+	return token, nil
+}
+
+// checkRevoked menolak token yang diterbitkan sebelum user mencabut semua sesinya
+// (misalnya setelah reset password).
+func (j *jwtService) checkRevoked(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return fmt.Errorf("gagal membaca claims")
+	}
+
+	userId, _ := claims["user_id"].(string)
+	revokedAt, found, err := j.redis.GetTokensRevokedAt(userId)
+	if err != nil {
+		return fmt.Errorf("gagal memeriksa status token: %w", err)
+	}
+	if !found {
+		return nil
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil || issuedAt.Unix() < revokedAt {
+		return fmt.Errorf("token sudah dicabut, silakan login kembali")
+	}
+
+	return nil
 }
@@ -1,29 +1,29 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"run-sync/config"
 	"run-sync/entity"
+	"run-sync/helper"
 	"run-sync/repository"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-// ── Matching Configuration ──
-
-const (
-	DefaultMaxRadius      = 10.0 // km
-	DefaultPaceTolerance  = 2.0  // min/km
-	PaceWeight            = 0.7
-	LocationWeight        = 0.3
-	MinCompatibilityScore = 0.3
-)
-
 // ── Interface ──
 
 type MatchingEngine interface {
-	FindDirectCandidates(userId uuid.UUID) ([]CandidateResult, error)
-	FindGroupCandidates(userId uuid.UUID) ([]GroupCandidateResult, error)
-	CalculateCompatibility(profileA, profileB entity.RunnerProfile) float64
+	FindDirectCandidates(userId uuid.UUID, page, limit int) ([]CandidateResult, int64, error)
+	FindGroupCandidates(userId uuid.UUID, page, limit int) ([]GroupCandidateResult, int64, error)
+
+	// Bobot faktor: default environment ditimpa preferensi user
+	DefaultWeights() map[string]float64
+	UserWeights(userId uuid.UUID) (weights map[string]float64, custom bool, err error)
+	SetUserWeights(userId uuid.UUID, weights map[string]float64) error
+	ResetUserWeights(userId uuid.UUID) error
 }
 
 type CandidateResult struct {
@@ -31,6 +31,8 @@ type CandidateResult struct {
 	User          *entity.User
 	Compatibility float64
 	DistanceKm    float64
+	Breakdown     []FactorScore
+	PassCount     int // berapa kali kandidat ini pernah di-pass (dalam jendela decay)
 }
 
 type GroupCandidateResult struct {
@@ -38,6 +40,16 @@ type GroupCandidateResult struct {
 	Compatibility float64
 	DistanceKm    float64
 	MemberCount   int64
+	Breakdown     []FactorScore
+}
+
+// FactorScore menjelaskan kontribusi satu faktor terhadap skor kompatibilitas.
+type FactorScore struct {
+	Factor       string  `json:"factor"`
+	Label        string  `json:"label"`
+	Score        float64 `json:"score"`        // 0..1
+	Weight       float64 `json:"weight"`       // bobot ternormalisasi
+	Contribution float64 `json:"contribution"` // score * weight
 }
 
 // ── Implementation ──
@@ -47,6 +59,10 @@ type matchingEngine struct {
 	matchRepo   repository.DirectMatchRepository
 	groupRepo   repository.RunGroupRepository
 	safetyRepo  repository.SafetyLogRepository
+	weightRepo  repository.MatchWeightRepository
+	prefRepo    repository.DiscoveryPreferenceRepository
+	cfg         *config.MatchingConfig
+	factors     []CompatibilityFactor
 }
 
 func NewMatchingEngine(
@@ -54,187 +70,268 @@ func NewMatchingEngine(
 	matchRepo repository.DirectMatchRepository,
 	groupRepo repository.RunGroupRepository,
 	safetyRepo repository.SafetyLogRepository,
+	weightRepo repository.MatchWeightRepository,
+	prefRepo repository.DiscoveryPreferenceRepository,
+	cfg *config.MatchingConfig,
 ) MatchingEngine {
 	return &matchingEngine{
 		profileRepo: profileRepo,
 		matchRepo:   matchRepo,
 		groupRepo:   groupRepo,
 		safetyRepo:  safetyRepo,
+		weightRepo:  weightRepo,
+		prefRepo:    prefRepo,
+		cfg:         cfg,
+		factors:     DefaultCompatibilityFactors(),
 	}
 }
 
 // FindDirectCandidates returns nearby compatible runners, excluding already
-// matched/blocked users, sorted by compatibility score descending.
-func (e *matchingEngine) FindDirectCandidates(userId uuid.UUID) ([]CandidateResult, error) {
+// matched/blocked users and passed users still in cooldown, sorted by
+// compatibility score descending (previously passed users are penalized).
+// Radius, exclusion, scoring and pagination all run in SQL.
+func (e *matchingEngine) FindDirectCandidates(userId uuid.UUID, page, limit int) ([]CandidateResult, int64, error) {
 	myProfile, err := e.profileRepo.FindByUserId(userId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	allProfiles, err := e.profileRepo.FindAll()
-	if err != nil {
-		return nil, err
+	page, limit = helper.NormalizePage(page, limit)
+
+	q := repository.NearbyRunnerQuery{
+		UserId:         userId,
+		Latitude:       myProfile.Latitude,
+		Longitude:      myProfile.Longitude,
+		ExcludeMatched: true,
+		UnmatchedSince: time.Now().AddDate(0, 0, -e.cfg.UnmatchCooldownDays),
+		ExcludeBlocked: true,
+		ExcludePassed:  true,
+		PassDecay:      e.cfg.PassDecay,
+		PassDecaySince: time.Now().AddDate(0, 0, -e.cfg.PassDecayDays),
+		MinScore:       e.cfg.MinScore,
+		Limit:          limit,
+		Offset:         (page - 1) * limit,
 	}
 
-	// Build exclusion set: already matched or blocked
-	excludeSet := map[uuid.UUID]bool{userId: true}
-	matches, _ := e.matchRepo.FindUserMatches(userId)
-	for _, m := range matches {
-		excludeSet[m.User1Id] = true
-		excludeSet[m.User2Id] = true
-	}
+	// Preferensi discovery berlaku dua arah
+	pref, _ := e.prefRepo.FindByUserId(userId)
+	applyRunnerPreference(&q, myProfile, pref, e.cfg.MaxRadiusKm)
 
-	// Blocked users (from safety reports by this user)
-	blockedLogs, _ := e.safetyRepo.FindByUserId(userId)
-	for _, l := range blockedLogs {
-		if l.Status == "blocked" {
-			excludeSet[l.MatchId] = true // note: MatchId here refers to the reported user
-		}
+	factors, scoreFactors, err := e.scoringPipeline(*myProfile, false, q.RadiusKm)
+	if err != nil {
+		return nil, 0, err
 	}
+	q.Factors = scoreFactors
 
-	var candidates []CandidateResult
-	for _, p := range allProfiles {
-		// Skip self and excluded
-		if excludeSet[p.UserId] || !p.IsActive {
-			continue
-		}
-
-		// Women-only check
-		if myProfile.WomenOnlyMode && !p.WomenOnlyMode {
-			continue
-		}
-
-		// Distance check
-		dist := Haversine(myProfile.Latitude, myProfile.Longitude, p.Latitude, p.Longitude)
-		if dist > DefaultMaxRadius {
-			continue
-		}
+	// Women-only berdasarkan gender ternormalisasi, dua arah
+	applyRunnerWomenOnly(&q, myProfile)
 
-		// Compatibility score
-		score := e.CalculateCompatibility(*myProfile, p)
-		if score < MinCompatibilityScore {
-			continue
-		}
+	rows, total, err := e.profileRepo.FindNearby(q)
+	if err != nil {
+		return nil, 0, err
+	}
 
+	candidates := make([]CandidateResult, 0, len(rows))
+	for _, r := range rows {
 		candidates = append(candidates, CandidateResult{
-			Profile:       p,
-			User:          p.User,
-			Compatibility: math.Round(score*100) / 100,
-			DistanceKm:    math.Round(dist*100) / 100,
+			Profile:       r.RunnerProfile,
+			User:          r.User,
+			Compatibility: round2(r.Score),
+			DistanceKm:    round2(r.DistanceKm),
+			Breakdown:     buildBreakdown(factors, scoreFactors, r.Factors),
+			PassCount:     r.PassCount,
 		})
 	}
 
-	// Sort by compatibility descending
-	sortCandidates(candidates)
-
-	return candidates, nil
+	return candidates, total, nil
 }
 
 // FindGroupCandidates returns nearby open groups compatible with user's profile.
-func (e *matchingEngine) FindGroupCandidates(userId uuid.UUID) ([]GroupCandidateResult, error) {
+func (e *matchingEngine) FindGroupCandidates(userId uuid.UUID, page, limit int) ([]GroupCandidateResult, int64, error) {
 	myProfile, err := e.profileRepo.FindByUserId(userId)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	groups, err := e.groupRepo.FindByStatus("open")
+	page, limit = helper.NormalizePage(page, limit)
+
+	// Women-only harus sama: user women-only hanya melihat grup women-only dan sebaliknya
+	womenOnly := myProfile.WomenOnlyMode
+
+	q := repository.NearbyGroupQuery{
+		UserId:      userId,
+		Latitude:    myProfile.Latitude,
+		Longitude:   myProfile.Longitude,
+		Status:      "open",
+		WomenOnly:   &womenOnly,
+		ExcludeFull: true,
+		MinScore:    e.cfg.MinScore,
+		Limit:       limit,
+		Offset:      (page - 1) * limit,
+	}
+	pref, _ := e.prefRepo.FindByUserId(userId)
+	applyGroupPreference(&q, pref, e.cfg.MaxRadiusKm)
+	applyGroupWomenOnly(&q, myProfile)
+
+	factors, scoreFactors, err := e.scoringPipeline(*myProfile, true, q.RadiusKm)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
+	q.Factors = scoreFactors
 
-	var candidates []GroupCandidateResult
-	for _, g := range groups {
-		// Women-only check
-		if myProfile.WomenOnlyMode && !g.IsWomenOnly {
-			continue
-		}
-		if g.IsWomenOnly && !myProfile.WomenOnlyMode {
-			continue
-		}
+	rows, total, err := e.groupRepo.FindNearby(q)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		dist := Haversine(myProfile.Latitude, myProfile.Longitude, g.Latitude, g.Longitude)
-		if dist > DefaultMaxRadius {
-			continue
-		}
+	candidates := make([]GroupCandidateResult, 0, len(rows))
+	for _, r := range rows {
+		candidates = append(candidates, GroupCandidateResult{
+			Group:         r.RunGroup,
+			Compatibility: round2(r.Score),
+			DistanceKm:    round2(r.DistanceKm),
+			MemberCount:   r.MemberCount,
+			Breakdown:     buildBreakdown(factors, scoreFactors, r.Factors),
+		})
+	}
 
-		memberCount, _ := e.groupRepo.GetMemberCount(g.Id)
-		if g.MaxMember > 0 && int(memberCount) >= g.MaxMember {
-			continue // already full
-		}
+	return candidates, total, nil
+}
 
-		score := calculateGroupCompatibility(*myProfile, g)
-		if score < MinCompatibilityScore {
+// scoringPipeline memilih faktor yang berlaku dengan bobot > 0, menormalisasi
+// bobotnya (jumlah = 1) dan mengubahnya menjadi faktor SQL untuk repository.
+// Jika bobot user tidak menyisakan faktor yang berlaku (misal untuk grup),
+// bobot default environment dipakai. radiusKm adalah radius efektif pencarian
+// (preferensi user) yang menjadi skala faktor jarak.
+func (e *matchingEngine) scoringPipeline(me entity.RunnerProfile, group bool, radiusKm float64) ([]CompatibilityFactor, []repository.ScoreFactor, error) {
+	weights, custom, err := e.UserWeights(me.UserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg := *e.cfg
+	cfg.MaxRadiusKm = radiusKm
+
+	factors, scoreFactors := e.weightedFactors(me, group, weights, &cfg)
+	if len(factors) == 0 && custom {
+		factors, scoreFactors = e.weightedFactors(me, group, e.DefaultWeights(), &cfg)
+	}
+	if len(factors) == 0 {
+		return nil, nil, errors.New("tidak ada faktor kompatibilitas dengan bobot > 0")
+	}
+
+	return factors, scoreFactors, nil
+}
+
+func (e *matchingEngine) weightedFactors(me entity.RunnerProfile, group bool, weights map[string]float64, cfg *config.MatchingConfig) ([]CompatibilityFactor, []repository.ScoreFactor) {
+	var factors []CompatibilityFactor
+	var scoreFactors []repository.ScoreFactor
+	var total float64
+	for _, f := range e.factors {
+		w := weights[f.Name()]
+		if w <= 0 {
 			continue
 		}
 
-		candidates = append(candidates, GroupCandidateResult{
-			Group:         g,
-			Compatibility: math.Round(score*100) / 100,
-			DistanceKm:    math.Round(dist*100) / 100,
-			MemberCount:   memberCount,
-		})
+		var expr string
+		var args []interface{}
+		if group {
+			var ok bool
+			if expr, args, ok = f.GroupSQL(me, cfg); !ok {
+				continue
+			}
+		} else {
+			expr, args = f.RunnerSQL(me, cfg)
+		}
+
+		factors = append(factors, f)
+		scoreFactors = append(scoreFactors, repository.ScoreFactor{Expr: expr, Args: args, Weight: w})
+		total += w
 	}
 
-	sortGroupCandidates(candidates)
+	for i := range scoreFactors {
+		scoreFactors[i].Weight /= total
+	}
 
-	return candidates, nil
+	return factors, scoreFactors
 }
 
-// CalculateCompatibility computes a 0–1 score between two runner profiles.
-//
-//	compatibility = (paceScore * 0.7) + (locationScore * 0.3)
-//	paceScore     = 1 - (abs(paceA - paceB) / maxTolerance)
-//	locationScore = 1 - (distance / maxRadius)
-func (e *matchingEngine) CalculateCompatibility(a, b entity.RunnerProfile) float64 {
-	paceDiff := math.Abs(a.AvgPace - b.AvgPace)
-	paceScore := 1.0 - (paceDiff / DefaultPaceTolerance)
-	if paceScore < 0 {
-		paceScore = 0
+func buildBreakdown(factors []CompatibilityFactor, scoreFactors []repository.ScoreFactor, values []float64) []FactorScore {
+	breakdown := make([]FactorScore, 0, len(factors))
+	for i, f := range factors {
+		var v float64
+		if i < len(values) {
+			v = values[i]
+		}
+		breakdown = append(breakdown, FactorScore{
+			Factor:       f.Name(),
+			Label:        f.Label(),
+			Score:        round2(v),
+			Weight:       round2(scoreFactors[i].Weight),
+			Contribution: round2(v * scoreFactors[i].Weight),
+		})
 	}
+	return breakdown
+}
 
-	dist := Haversine(a.Latitude, a.Longitude, b.Latitude, b.Longitude)
-	locationScore := 1.0 - (dist / DefaultMaxRadius)
-	if locationScore < 0 {
-		locationScore = 0
-	}
+// ── Weights ──
 
-	return (paceScore * PaceWeight) + (locationScore * LocationWeight)
+// DefaultWeights mengembalikan salinan bobot environment untuk semua faktor.
+func (e *matchingEngine) DefaultWeights() map[string]float64 {
+	weights := make(map[string]float64, len(e.factors))
+	for _, f := range e.factors {
+		weights[f.Name()] = e.cfg.Weights[f.Name()]
+	}
+	return weights
 }
 
-func calculateGroupCompatibility(profile entity.RunnerProfile, group entity.RunGroup) float64 {
-	// Compare profile pace against group's pace range midpoint
-	groupMidPace := (group.MinPace + group.MaxPace) / 2.0
-	paceDiff := math.Abs(profile.AvgPace - groupMidPace)
-	paceScore := 1.0 - (paceDiff / DefaultPaceTolerance)
-	if paceScore < 0 {
-		paceScore = 0
-	}
+func (e *matchingEngine) UserWeights(userId uuid.UUID) (map[string]float64, bool, error) {
+	weights := e.DefaultWeights()
 
-	dist := Haversine(profile.Latitude, profile.Longitude, group.Latitude, group.Longitude)
-	locationScore := 1.0 - (dist / DefaultMaxRadius)
-	if locationScore < 0 {
-		locationScore = 0
+	overrides, err := e.weightRepo.FindByUserId(userId)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, o := range overrides {
+		weights[o.Factor] = o.Weight
 	}
 
-	return (paceScore * PaceWeight) + (locationScore * LocationWeight)
+	return weights, len(overrides) > 0, nil
 }
 
-// ── Sorting helpers ──
-
-func sortCandidates(c []CandidateResult) {
-	for i := 1; i < len(c); i++ {
-		for j := i; j > 0 && c[j].Compatibility > c[j-1].Compatibility; j-- {
-			c[j], c[j-1] = c[j-1], c[j]
+func (e *matchingEngine) SetUserWeights(userId uuid.UUID, weights map[string]float64) error {
+	var total float64
+	rows := make([]entity.MatchWeight, 0, len(weights))
+	for name, w := range weights {
+		if !config.IsMatchFactor(name) {
+			return fmt.Errorf("faktor %q tidak dikenal", name)
 		}
+		if w < 0 || w > 1 {
+			return fmt.Errorf("bobot %s harus di antara 0 dan 1", name)
+		}
+		total += w
+		rows = append(rows, entity.MatchWeight{UserId: userId, Factor: name, Weight: w, UpdatedAt: time.Now()})
+	}
+	if total == 0 {
+		return errors.New("minimal satu faktor harus memiliki bobot > 0")
 	}
-}
 
-func sortGroupCandidates(c []GroupCandidateResult) {
-	for i := 1; i < len(c); i++ {
-		for j := i; j > 0 && c[j].Compatibility > c[j-1].Compatibility; j-- {
-			c[j], c[j-1] = c[j-1], c[j]
+	// Faktor yang tidak disebut dinonaktifkan agar preferensi user tidak tercampur default
+	for _, f := range e.factors {
+		if _, ok := weights[f.Name()]; !ok {
+			rows = append(rows, entity.MatchWeight{UserId: userId, Factor: f.Name(), Weight: 0, UpdatedAt: time.Now()})
 		}
 	}
+
+	return e.weightRepo.ReplaceForUser(userId, rows)
+}
+
+func (e *matchingEngine) ResetUserWeights(userId uuid.UUID) error {
+	return e.weightRepo.DeleteByUserId(userId)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
 }
 
 // Haversine calculates the distance in km between two lat/lng points.
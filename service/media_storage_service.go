@@ -0,0 +1,297 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"run-sync/config"
+	"run-sync/entity"
+	"run-sync/helper"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Folder storage yang dikelola aplikasi; hanya folder ini yang disapu orphan sweep.
+const (
+	StorageFolderPhotos        = "run-sync/photos"
+	StorageFolderProfiles      = "run-sync/profiles" // gambar profil lama, sebelum gambar profil diambil dari galeri
+	StorageFolderVerifications = "run-sync/verifications"
+	StorageFolderThumbnails    = "run-sync/thumbnails"
+	StorageFolderUploads       = "run-sync/uploads" // file mentah upload langsung, dihapus setelah diproses
+)
+
+// PrivateStorageFolders hanya boleh diakses lewat signed URL (selfie & foto verifikasi,
+// file upload mentah).
+var PrivateStorageFolders = []string{StorageFolderVerifications, StorageFolderUploads}
+
+var managedStorageFolders = []string{
+	StorageFolderPhotos, StorageFolderProfiles, StorageFolderVerifications, StorageFolderThumbnails, StorageFolderUploads,
+}
+
+// UploadedImage adalah hasil upload gambar yang sudah divalidasi & dibersihkan metadatanya.
+type UploadedImage struct {
+	Url          string
+	ThumbnailUrl string
+	Width        int
+	Height       int
+}
+
+// StagedUpload adalah tujuan upload langsung client ke storage.
+type StagedUpload struct {
+	Key    string
+	Target helper.PresignedUpload
+}
+
+// MediaStorageService membungkus helper.Storage dengan aturan aplikasi: asset hanya
+// dihapus jika tidak lagi direferensikan, dan asset orphan disapu berkala.
+type MediaStorageService interface {
+	// UploadImage memvalidasi gambar (ukuran, tipe, dimensi), membuang EXIF, lalu
+	// menyimpannya beserta thumbnail bila diminta.
+	UploadImage(data []byte, folder string, withThumbnail bool) (UploadedImage, error)
+	// UploadBase64 sama dengan UploadImage tanpa thumbnail untuk gambar base64.
+	UploadBase64(base64Str, folder string) (string, error)
+	// StageUpload membuat URL upload langsung ke folder staging.
+	StageUpload(contentType string) (StagedUpload, error)
+	// FetchStaged mengambil file mentah hasil upload langsung (dibatasi ukuran maksimal foto).
+	FetchStaged(key string) ([]byte, error)
+	// DiscardStaged menghapus file mentah di folder staging.
+	DiscardStaged(key string)
+	// SignedURL mengembalikan URL sementara; URL di luar storage dikembalikan apa adanya.
+	SignedURL(url string) string
+	// Download mengambil isi asset (lewat signed URL bila perlu).
+	Download(url string) ([]byte, error)
+	// Release menghapus asset jika URL-nya sudah tidak dipakai row mana pun.
+	Release(url string)
+	// RunJobs menjalankan sweep asset orphan secara berkala.
+	RunJobs()
+}
+
+type mediaStorageService struct {
+	storage  helper.Storage
+	db       *gorm.DB
+	cfg      *config.StorageConfig
+	photoCfg *config.PhotoConfig
+}
+
+func NewMediaStorageService(storage helper.Storage, db *gorm.DB, cfg *config.StorageConfig, photoCfg *config.PhotoConfig) MediaStorageService {
+	return &mediaStorageService{storage: storage, db: db, cfg: cfg, photoCfg: photoCfg}
+}
+
+func (s *mediaStorageService) UploadImage(data []byte, folder string, withThumbnail bool) (UploadedImage, error) {
+	opts := s.photoCfg.ImageOptions()
+	if !withThumbnail {
+		opts.ThumbnailSize = 0
+	}
+	img, err := helper.ProcessImage(data, opts)
+	if err != nil {
+		return UploadedImage{}, err
+	}
+
+	obj, err := s.storage.Upload(img.Data, folder)
+	if err != nil {
+		return UploadedImage{}, err
+	}
+	log.Printf("✅ Upload %s berhasil: %s (%dx%d)", s.storage.Name(), obj.Key, img.Width, img.Height)
+
+	res := UploadedImage{Url: obj.URL, Width: img.Width, Height: img.Height}
+	if img.Thumbnail != nil {
+		thumb, err := s.storage.Upload(img.Thumbnail, StorageFolderThumbnails)
+		if err != nil {
+			// Tanpa thumbnail client memakai Url; gambar utama tetap dipakai
+			log.Printf("⚠️ Gagal upload thumbnail %s: %v", obj.Key, err)
+		} else {
+			res.ThumbnailUrl = thumb.URL
+		}
+	}
+	return res, nil
+}
+
+func (s *mediaStorageService) UploadBase64(base64Str, folder string) (string, error) {
+	data, err := helper.DecodeBase64Image(base64Str)
+	if err != nil {
+		return "", fmt.Errorf("gambar bukan base64 yang valid: %w", err)
+	}
+	if len(data) == 0 {
+		return "", errors.New("gambar tidak boleh kosong")
+	}
+	img, err := s.UploadImage(data, folder, false)
+	if err != nil {
+		return "", err
+	}
+	return img.Url, nil
+}
+
+func (s *mediaStorageService) StageUpload(contentType string) (StagedUpload, error) {
+	if !slices.Contains(s.photoCfg.AllowedTypes, contentType) {
+		return StagedUpload{}, fmt.Errorf("%w: %s", helper.ErrImageTypeNotAllowed, contentType)
+	}
+	key, err := helper.NewObjectKeyForType(StorageFolderUploads, contentType)
+	if err != nil {
+		return StagedUpload{}, fmt.Errorf("%w: %s", helper.ErrImageTypeNotAllowed, contentType)
+	}
+	target, err := s.storage.PresignUpload(key, contentType, s.photoCfg.UploadURLTTL)
+	if err != nil {
+		return StagedUpload{}, err
+	}
+	return StagedUpload{Key: key, Target: target}, nil
+}
+
+func (s *mediaStorageService) FetchStaged(key string) ([]byte, error) {
+	signed, err := s.storage.SignedURL(key, s.cfg.SignedURLTTL)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := stagedDownloadClient.Get(signed) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil file upload: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, helper.ErrStorageObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gagal mengambil file upload: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, s.photoCfg.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil file upload: %w", err)
+	}
+	if int64(len(data)) > s.photoCfg.MaxBytes {
+		return nil, fmt.Errorf("%w (maksimal %d KB)", helper.ErrImageTooLarge, s.photoCfg.MaxBytes/1024)
+	}
+	return data, nil
+}
+
+func (s *mediaStorageService) DiscardStaged(key string) {
+	if err := s.storage.Delete(key); err != nil {
+		// Sisa file akan disapu oleh orphan sweep
+		log.Printf("⚠️ Gagal hapus file staging %s: %v", key, err)
+	}
+}
+
+var stagedDownloadClient = &http.Client{Timeout: 30 * time.Second}
+
+func (s *mediaStorageService) SignedURL(url string) string {
+	key, ok := s.storage.KeyFromURL(url)
+	if !ok {
+		return url
+	}
+	signed, err := s.storage.SignedURL(key, s.cfg.SignedURLTTL)
+	if err != nil {
+		log.Printf("⚠️ Gagal membuat signed URL %s: %v", key, err)
+		return url
+	}
+	return signed
+}
+
+func (s *mediaStorageService) Download(url string) ([]byte, error) {
+	return helper.DownloadImage(s.SignedURL(url))
+}
+
+func (s *mediaStorageService) Release(url string) {
+	if url == "" {
+		return
+	}
+	key, ok := s.storage.KeyFromURL(url)
+	if !ok {
+		return
+	}
+	if s.isReferenced(url) {
+		return
+	}
+	if err := s.storage.Delete(key); err != nil {
+		// Sisa asset akan disapu oleh orphan sweep
+		log.Printf("⚠️ Gagal hapus asset %s dari %s: %v", key, s.storage.Name(), err)
+	}
+}
+
+func (s *mediaStorageService) isReferenced(url string) bool {
+	var count int64
+	s.db.Model(&entity.UserPhoto{}).Where("url = ? OR thumbnail_url = ?", url, url).Count(&count)
+	if count > 0 {
+		return true
+	}
+	s.db.Model(&entity.RunnerProfile{}).Where("image = ?", url).Count(&count)
+	if count > 0 {
+		return true
+	}
+	s.db.Model(&entity.FaceVerification{}).Where("selfie_url = ?", url).Count(&count)
+	return count > 0
+}
+
+func (s *mediaStorageService) RunJobs() {
+	ticker := time.NewTicker(s.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sweepOrphans()
+		<-ticker.C
+	}
+}
+
+// sweepOrphans menghapus asset di folder terkelola yang tidak direferensikan row mana
+// pun dan lebih tua dari masa tenggang.
+func (s *mediaStorageService) sweepOrphans() {
+	referenced, err := s.referencedKeys()
+	if err != nil {
+		log.Printf("❌ Sweep storage dibatalkan, gagal membaca referensi: %v", err)
+		return
+	}
+
+	cutoff := time.Now().Add(-s.cfg.OrphanGrace)
+	deleted := 0
+	for _, folder := range managedStorageFolders {
+		objects, err := s.storage.List(folder + "/")
+		if err != nil {
+			log.Printf("❌ Sweep storage: gagal list %s: %v", folder, err)
+			continue
+		}
+		for _, obj := range objects {
+			if referenced[obj.Key] || obj.CreatedAt.After(cutoff) {
+				continue
+			}
+			if err := s.storage.Delete(obj.Key); err != nil {
+				log.Printf("⚠️ Sweep storage: gagal hapus %s: %v", obj.Key, err)
+				continue
+			}
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		log.Printf("🧹 Sweep storage: %d asset orphan dihapus", deleted)
+	}
+}
+
+func (s *mediaStorageService) referencedKeys() (map[string]bool, error) {
+	var urls, more []string
+	if err := s.db.Model(&entity.UserPhoto{}).Pluck("url", &urls).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&entity.RunnerProfile{}).Where("image IS NOT NULL").Pluck("image", &more).Error; err != nil {
+		return nil, err
+	}
+	urls = append(urls, more...)
+	more = nil
+	if err := s.db.Model(&entity.UserPhoto{}).Where("thumbnail_url <> ''").Pluck("thumbnail_url", &more).Error; err != nil {
+		return nil, err
+	}
+	urls = append(urls, more...)
+	more = nil
+	if err := s.db.Model(&entity.FaceVerification{}).Where("selfie_url <> ''").Pluck("selfie_url", &more).Error; err != nil {
+		return nil, err
+	}
+	urls = append(urls, more...)
+
+	keys := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if key, ok := s.storage.KeyFromURL(url); ok {
+			keys[key] = true
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"run-sync/config"
+	"run-sync/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPhotoLimitReached dikembalikan saat galeri tipe tersebut sudah penuh.
+	ErrPhotoLimitReached = errors.New("jumlah foto sudah mencapai batas")
+	// ErrPrimaryNotProfile dikembalikan saat foto selain tipe profile dijadikan foto utama.
+	ErrPrimaryNotProfile = errors.New("hanya foto profile yang bisa dijadikan foto utama")
+	// ErrInvalidPhotoOrder dikembalikan saat daftar reorder tidak sama dengan isi galeri.
+	ErrInvalidPhotoOrder = errors.New("urutan foto harus berisi semua foto pada tipe tersebut tepat satu kali")
+)
+
+// PhotoGallery mengatur galeri foto user: posisi per tipe, batas jumlah per tipe dan
+// foto utama. Setiap perubahan berjalan dalam satu transaksi yang mengunci row user,
+// dan foto utama selalu disalin ke runner_profiles.image.
+type PhotoGallery interface {
+	// CheckCapacity memeriksa batas sebelum file diunggah; Add tetap memeriksa ulang.
+	CheckCapacity(userId uuid.UUID, photoType string) error
+	// Add menyimpan foto di posisi terakhir. Foto profile pertama otomatis menjadi foto utama.
+	Add(photo *entity.UserPhoto) error
+	SetPrimary(userId, photoId uuid.UUID) error
+	// ChangeType memindahkan foto antara galeri profile dan run (ke posisi terakhir).
+	ChangeType(photo *entity.UserPhoto, photoType string) error
+	// Remove menghapus foto; jika foto utama, foto profile berikutnya dipromosikan.
+	Remove(photo *entity.UserPhoto) error
+	// Reorder mengatur ulang posisi semua foto satu tipe sesuai urutan photoIds.
+	Reorder(userId uuid.UUID, photoType string, photoIds []uuid.UUID) error
+	// PrimaryImage mengembalikan URL foto utama, nil jika belum ada.
+	PrimaryImage(userId uuid.UUID) *string
+}
+
+type photoGallery struct {
+	db  *gorm.DB
+	cfg *config.PhotoConfig
+}
+
+func NewPhotoGallery(db *gorm.DB, cfg *config.PhotoConfig) PhotoGallery {
+	return &photoGallery{db: db, cfg: cfg}
+}
+
+func (g *photoGallery) CheckCapacity(userId uuid.UUID, photoType string) error {
+	return g.checkCapacity(g.db, userId, photoType)
+}
+
+func (g *photoGallery) Add(photo *entity.UserPhoto) error {
+	if photo.IsPrimary && photo.Type != "profile" {
+		return ErrPrimaryNotProfile
+	}
+
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGallery(tx, photo.UserId); err != nil {
+			return err
+		}
+		if err := g.checkCapacity(tx, photo.UserId, photo.Type); err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, photo.UserId, photo.Type)
+		if err != nil {
+			return err
+		}
+		photo.Position = position
+
+		if photo.Type == "profile" && !photo.IsPrimary {
+			var primaries int64
+			if err := tx.Model(&entity.UserPhoto{}).Where("user_id = ? AND is_primary", photo.UserId).Count(&primaries).Error; err != nil {
+				return err
+			}
+			photo.IsPrimary = primaries == 0
+		}
+		isPrimary := photo.IsPrimary
+		photo.IsPrimary = false
+		if err := tx.Create(photo).Error; err != nil {
+			return err
+		}
+		if isPrimary {
+			return setPrimary(tx, photo)
+		}
+		return nil
+	})
+}
+
+func (g *photoGallery) SetPrimary(userId, photoId uuid.UUID) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGallery(tx, userId); err != nil {
+			return err
+		}
+		var photo entity.UserPhoto
+		if err := tx.Where("id = ? AND user_id = ?", photoId, userId).First(&photo).Error; err != nil {
+			return err
+		}
+		if photo.Type != "profile" {
+			return ErrPrimaryNotProfile
+		}
+		return setPrimary(tx, &photo)
+	})
+}
+
+func (g *photoGallery) ChangeType(photo *entity.UserPhoto, photoType string) error {
+	if photo.Type == photoType {
+		return nil
+	}
+	if photo.Type == "verification" || photoType == "verification" {
+		return errors.New("tipe foto verifikasi tidak dapat diubah")
+	}
+	if photo.IsPrimary {
+		return errors.New("foto utama tidak dapat dipindah, pilih foto utama lain terlebih dahulu")
+	}
+
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGallery(tx, photo.UserId); err != nil {
+			return err
+		}
+		if err := g.checkCapacity(tx, photo.UserId, photoType); err != nil {
+			return err
+		}
+		position, err := nextPosition(tx, photo.UserId, photoType)
+		if err != nil {
+			return err
+		}
+		updates := map[string]interface{}{"type": photoType, "position": position}
+		if photoType != "run" {
+			updates["caption"] = nil
+		}
+		if err := tx.Model(&entity.UserPhoto{}).Where("id = ?", photo.Id).Updates(updates).Error; err != nil {
+			return err
+		}
+		photo.Type = photoType
+		photo.Position = position
+		if photoType != "run" {
+			photo.Caption = nil
+		}
+		return nil
+	})
+}
+
+func (g *photoGallery) Remove(photo *entity.UserPhoto) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGallery(tx, photo.UserId); err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.UserPhoto{}, "id = ?", photo.Id).Error; err != nil {
+			return err
+		}
+		if !photo.IsPrimary {
+			return nil
+		}
+
+		var next entity.UserPhoto
+		err := tx.Where("user_id = ? AND type = ?", photo.UserId, "profile").
+			Order("position ASC, created_at ASC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return syncProfileImage(tx, photo.UserId, nil)
+		}
+		if err != nil {
+			return err
+		}
+		return setPrimary(tx, &next)
+	})
+}
+
+func (g *photoGallery) Reorder(userId uuid.UUID, photoType string, photoIds []uuid.UUID) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockGallery(tx, userId); err != nil {
+			return err
+		}
+		var existing []uuid.UUID
+		if err := tx.Model(&entity.UserPhoto{}).Where("user_id = ? AND type = ?", userId, photoType).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) != len(photoIds) {
+			return ErrInvalidPhotoOrder
+		}
+		owned := make(map[uuid.UUID]bool, len(existing))
+		for _, id := range existing {
+			owned[id] = true
+		}
+		for _, id := range photoIds {
+			if !owned[id] {
+				return ErrInvalidPhotoOrder
+			}
+			delete(owned, id) // id ganda ikut tertolak
+		}
+
+		for position, id := range photoIds {
+			if err := tx.Model(&entity.UserPhoto{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (g *photoGallery) PrimaryImage(userId uuid.UUID) *string {
+	var photo entity.UserPhoto
+	if err := g.db.Where("user_id = ? AND is_primary", userId).First(&photo).Error; err != nil {
+		return nil
+	}
+	return &photo.Url
+}
+
+func (g *photoGallery) checkCapacity(db *gorm.DB, userId uuid.UUID, photoType string) error {
+	limit := g.cfg.MaxPhotos(photoType)
+	if limit <= 0 {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&entity.UserPhoto{}).Where("user_id = ? AND type = ?", userId, photoType).Count(&count).Error; err != nil {
+		return err
+	}
+	if int(count) >= limit {
+		return fmt.Errorf("%w: maksimal %d foto %s", ErrPhotoLimitReached, limit, photoType)
+	}
+	return nil
+}
+
+// lockGallery mengunci row user agar perubahan galeri user yang sama berjalan berurutan.
+func lockGallery(tx *gorm.DB, userId uuid.UUID) error {
+	var user entity.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, "id = ?", userId).Error
+}
+
+func nextPosition(tx *gorm.DB, userId uuid.UUID, photoType string) (int, error) {
+	var last *int
+	err := tx.Model(&entity.UserPhoto{}).Where("user_id = ? AND type = ?", userId, photoType).
+		Select("MAX(position)").Scan(&last).Error
+	if err != nil || last == nil {
+		return 0, err
+	}
+	return *last + 1, nil
+}
+
+// setPrimary menukar foto utama dan menyalin URL-nya ke runner_profiles.image.
+func setPrimary(tx *gorm.DB, photo *entity.UserPhoto) error {
+	if err := tx.Model(&entity.UserPhoto{}).
+		Where("user_id = ? AND id <> ? AND is_primary", photo.UserId, photo.Id).
+		Update("is_primary", false).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&entity.UserPhoto{}).Where("id = ?", photo.Id).Update("is_primary", true).Error; err != nil {
+		return err
+	}
+	photo.IsPrimary = true
+	return syncProfileImage(tx, photo.UserId, &photo.Url)
+}
+
+func syncProfileImage(tx *gorm.DB, userId uuid.UUID, image *string) error {
+	return tx.Model(&entity.RunnerProfile{}).Where("user_id = ?", userId).
+		Updates(map[string]interface{}{"image": image, "updated_at": time.Now()}).Error
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"run-sync/config"
+	"run-sync/entity"
+	"run-sync/repository"
+
+	"github.com/google/uuid"
+)
+
+// ProfilePace menurunkan avg_pace runner profile dari aktivitas terbaru user.
+type ProfilePace interface {
+	// Refresh menghitung ulang avg_pace dari jendela aktivitas terbaru. Profil dengan
+	// avg_pace_manual atau tanpa aktivitas dalam jendela dibiarkan apa adanya.
+	Refresh(userId uuid.UUID) error
+}
+
+const (
+	// Konstanta MAD agar sebanding dengan simpangan baku pada distribusi normal
+	madScale = 1.4826
+	// MAD minimal (menit/km) supaya riwayat yang sangat seragam tidak membuang lari santai biasa
+	minPaceMAD = 0.25
+
+	minProfilePace = 3.0
+	maxProfilePace = 12.0
+)
+
+type profilePace struct {
+	activityRepo repository.RunActivityRepository
+	profileRepo  repository.RunnerProfileRepository
+	cfg          *config.ActivityConfig
+}
+
+func NewProfilePace(activityRepo repository.RunActivityRepository, profileRepo repository.RunnerProfileRepository, cfg *config.ActivityConfig) ProfilePace {
+	return &profilePace{activityRepo: activityRepo, profileRepo: profileRepo, cfg: cfg}
+}
+
+func (p *profilePace) Refresh(userId uuid.UUID) error {
+	profile, err := p.profileRepo.FindByUserId(userId)
+	if err != nil || profile.AvgPaceManual {
+		return nil
+	}
+
+	activities, err := p.activityRepo.FindRecentByUserId(userId, time.Now().Add(-p.cfg.PaceWindow), p.cfg.PaceWindowRuns)
+	if err != nil {
+		return err
+	}
+	pace, ok := derivePace(activities, p.cfg.PaceOutlierFactor)
+	if !ok {
+		return nil
+	}
+	return p.profileRepo.UpdateDerivedPace(userId, pace)
+}
+
+// derivePace membuang outlier (|pace - median| > factor * MAD) lalu menghitung pace
+// tertimbang jarak: total durasi dibagi total jarak aktivitas yang tersisa.
+func derivePace(activities []entity.RunActivity, factor float64) (float64, bool) {
+	var valid []entity.RunActivity
+	var paces []float64
+	for _, a := range activities {
+		if a.Distance <= 0 || a.Duration <= 0 {
+			continue
+		}
+		valid = append(valid, a)
+		paces = append(paces, activityPace(a))
+	}
+	if len(valid) == 0 {
+		return 0, false
+	}
+
+	median := medianOf(paces)
+	deviations := make([]float64, len(paces))
+	for i, pace := range paces {
+		deviations[i] = math.Abs(pace - median)
+	}
+	limit := factor * madScale * math.Max(medianOf(deviations), minPaceMAD)
+
+	var distance float64
+	var duration int
+	for i, a := range valid {
+		if deviations[i] > limit {
+			continue
+		}
+		distance += a.Distance
+		duration += a.Duration
+	}
+	if distance <= 0 {
+		return 0, false
+	}
+
+	pace := float64(duration) / 60 / distance
+	pace = math.Min(math.Max(pace, minProfilePace), maxProfilePace)
+	return math.Round(pace*100) / 100, true
+}
+
+func activityPace(a entity.RunActivity) float64 {
+	return float64(a.Duration) / 60 / a.Distance
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"run-sync/data/response"
+	"run-sync/entity"
+	"run-sync/helper"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecordsEngine menghitung personal record (best effort) dan badge pencapaian dari
+// riwayat aktivitas user, lalu memberi tahu user lewat NotificationService.
+type RecordsEngine interface {
+	// Recompute menghitung ulang semua PR dan badge setelah riwayat aktivitas user berubah.
+	// activityId adalah aktivitas yang baru dibuat / diubah (nil saat menghapus); hanya PR
+	// yang dipegang aktivitas tersebut yang dinotifikasi.
+	Recompute(userId uuid.UUID, activityId *uuid.UUID) error
+	Records(userId uuid.UUID) ([]response.PersonalRecordResponse, error)
+	Badges(userId uuid.UUID) ([]response.UserBadgeResponse, error)
+}
+
+type recordCategory struct {
+	key   string
+	label string
+	km    float64 // 0 = lari terjauh
+}
+
+var recordCategories = []recordCategory{
+	{"1k", "1K", 1},
+	{"5k", "5K", 5},
+	{"10k", "10K", 10},
+	{"half_marathon", "Half marathon", 21.0975},
+	{"marathon", "Marathon", 42.195},
+	{"longest_run", "Lari terjauh", 0},
+}
+
+const (
+	badgeFirstGroupRun = "first_group_run"
+	badgeMonthly100Km  = "monthly_100km"
+	badgeStreak7Day    = "streak_7_day"
+
+	monthlyBadgeKm = 100.0
+	streakBadgeDay = 7
+
+	// GPS sering mencatat 4,98 km untuk lomba 5K; kekurangan sampai 1% tetap dihitung
+	recordDistanceTolerance = 0.01
+)
+
+var badgeNames = map[string]string{
+	badgeFirstGroupRun: "Group run pertama",
+	badgeMonthly100Km:  "100 km sebulan",
+	badgeStreak7Day:    "7 hari beruntun",
+}
+
+type recordsEngine struct {
+	db       *gorm.DB
+	notifSvc NotificationService
+}
+
+func NewRecordsEngine(db *gorm.DB, notifSvc NotificationService) RecordsEngine {
+	return &recordsEngine{db: db, notifSvc: notifSvc}
+}
+
+func (e *recordsEngine) Recompute(userId uuid.UUID, activityId *uuid.UUID) error {
+	var (
+		newRecords []entity.PersonalRecord
+		newBadges  []entity.UserBadge
+	)
+	err := e.db.Transaction(func(tx *gorm.DB) error {
+		// Recompute bersamaan untuk user yang sama dijalankan berurutan
+		var user entity.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, "id = ?", userId).Error; err != nil {
+			return err
+		}
+
+		var activities []entity.RunActivity
+		if err := tx.Preload("Splits", func(db *gorm.DB) *gorm.DB { return db.Order("split_number") }).
+			Where("user_id = ?", userId).Order("created_at").Find(&activities).Error; err != nil {
+			return err
+		}
+		var existing []entity.PersonalRecord
+		if err := tx.Where("user_id = ?", userId).Find(&existing).Error; err != nil {
+			return err
+		}
+		old := make(map[string]entity.PersonalRecord, len(existing))
+		for _, r := range existing {
+			old[r.Category] = r
+		}
+
+		var err error
+		computed := computeRecords(userId, activities)
+		for _, c := range recordCategories {
+			rec, ok := computed[c.key]
+			prev, had := old[c.key]
+			switch {
+			case !ok && had:
+				if err := tx.Delete(&entity.PersonalRecord{}, "id = ?", prev.Id).Error; err != nil {
+					return err
+				}
+				continue
+			case !ok:
+				continue
+			case had && prev.ActivityId == rec.ActivityId && prev.Duration == rec.Duration && prev.Distance == rec.Distance:
+				continue
+			}
+
+			rec.UpdatedAt = time.Now()
+			if had {
+				rec.Id = prev.Id
+				err = tx.Save(&rec).Error
+			} else {
+				rec.Id = uuid.New()
+				err = tx.Create(&rec).Error
+			}
+			if err != nil {
+				return err
+			}
+			if activityId != nil && rec.ActivityId == *activityId && (!had || recordImproved(c, rec, prev)) {
+				newRecords = append(newRecords, rec)
+			}
+		}
+
+		earned, err := earnedBadges(tx, userId, activities)
+		if err != nil {
+			return err
+		}
+		for i := range earned {
+			badge := earned[i]
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&badge)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected > 0 {
+				newBadges = append(newBadges, badge)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range newRecords {
+		e.notifyRecord(rec)
+	}
+	for _, badge := range newBadges {
+		e.notifyBadge(badge)
+	}
+	return nil
+}
+
+func (e *recordsEngine) Records(userId uuid.UUID) ([]response.PersonalRecordResponse, error) {
+	var records []entity.PersonalRecord
+	if err := e.db.Where("user_id = ?", userId).Find(&records).Error; err != nil {
+		return nil, err
+	}
+	byCategory := make(map[string]entity.PersonalRecord, len(records))
+	for _, r := range records {
+		byCategory[r.Category] = r
+	}
+
+	res := []response.PersonalRecordResponse{}
+	for _, c := range recordCategories {
+		r, ok := byCategory[c.key]
+		if !ok {
+			continue
+		}
+		res = append(res, response.PersonalRecordResponse{
+			Category:   r.Category,
+			Label:      c.label,
+			ActivityId: r.ActivityId.String(),
+			Distance:   r.Distance,
+			Duration:   r.Duration,
+			Pace:       r.Pace,
+			AchievedAt: r.AchievedAt,
+		})
+	}
+	return res, nil
+}
+
+func (e *recordsEngine) Badges(userId uuid.UUID) ([]response.UserBadgeResponse, error) {
+	var badges []entity.UserBadge
+	if err := e.db.Where("user_id = ?", userId).Order("earned_at, period").Find(&badges).Error; err != nil {
+		return nil, err
+	}
+	res := make([]response.UserBadgeResponse, 0, len(badges))
+	for _, b := range badges {
+		res = append(res, response.UserBadgeResponse{
+			Badge:    b.Badge,
+			Name:     badgeNames[b.Badge],
+			Period:   b.Period,
+			EarnedAt: b.EarnedAt,
+		})
+	}
+	return res, nil
+}
+
+func (e *recordsEngine) notifyRecord(rec entity.PersonalRecord) {
+	var body string
+	if rec.Category == "longest_run" {
+		body = fmt.Sprintf("Selamat! Lari terjauh baru kamu: %.2f km.", rec.Distance)
+	} else {
+		body = fmt.Sprintf("Selamat! Rekor %s baru kamu: %s (pace %s/km).",
+			recordLabel(rec.Category), formatSeconds(rec.Duration), formatPace(rec.Pace))
+	}
+	refId := rec.ActivityId.String()
+	refType := "activity"
+	if err := e.notifSvc.Send(rec.UserId, entity.NotifPersonalRecord, "Personal record baru", body, nil, &refId, &refType); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi %s ke %s: %v", entity.NotifPersonalRecord, rec.UserId, err)
+	}
+}
+
+func (e *recordsEngine) notifyBadge(badge entity.UserBadge) {
+	body := fmt.Sprintf("Selamat! Kamu meraih badge \"%s\".", badgeNames[badge.Badge])
+	if badge.Period != "" {
+		body = fmt.Sprintf("Selamat! Kamu meraih badge \"%s\" untuk bulan %s.", badgeNames[badge.Badge], badge.Period)
+	}
+	refId := badge.Id.String()
+	refType := "badge"
+	if err := e.notifSvc.Send(badge.UserId, entity.NotifBadgeEarned, "Badge baru", body, nil, &refId, &refType); err != nil {
+		log.Printf("⚠️ Gagal kirim notifikasi %s ke %s: %v", entity.NotifBadgeEarned, badge.UserId, err)
+	}
+}
+
+// -- Perhitungan --
+
+// computeRecords mengembalikan PR terbaik per kategori. Jika sama cepat, aktivitas
+// yang lebih dulu tetap memegang rekor.
+func computeRecords(userId uuid.UUID, activities []entity.RunActivity) map[string]entity.PersonalRecord {
+	best := map[string]entity.PersonalRecord{}
+	bestSeconds := map[string]float64{}
+	for i := range activities {
+		a := &activities[i]
+		achievedAt := activityTime(a)
+		for _, c := range recordCategories {
+			if c.km == 0 {
+				cur, ok := best[c.key]
+				if a.Distance > 0 && (!ok || a.Distance > cur.Distance) {
+					best[c.key] = entity.PersonalRecord{
+						UserId: userId, Category: c.key, ActivityId: a.Id,
+						Distance: a.Distance, Duration: a.Duration, Pace: a.AvgPace, AchievedAt: achievedAt,
+					}
+				}
+				continue
+			}
+
+			seconds, ok := bestEffortSeconds(a, c.km)
+			if !ok {
+				continue
+			}
+			if cur, had := bestSeconds[c.key]; had && seconds >= cur {
+				continue
+			}
+			bestSeconds[c.key] = seconds
+			best[c.key] = entity.PersonalRecord{
+				UserId: userId, Category: c.key, ActivityId: a.Id,
+				Distance:   c.km,
+				Duration:   int(math.Round(seconds)),
+				Pace:       math.Round(seconds/60/c.km*100) / 100,
+				AchievedAt: achievedAt,
+			}
+		}
+	}
+	return best
+}
+
+// bestEffortSeconds mencari waktu tercepat menempuh km dalam satu aktivitas. Dengan split,
+// dicari jendela split berurutan tercepat (split terakhir jendela dihitung proporsional);
+// tanpa split dipakai pace rata-rata aktivitas.
+func bestEffortSeconds(a *entity.RunActivity, km float64) (float64, bool) {
+	if a.Distance <= 0 || a.Duration <= 0 || a.Distance < km*(1-recordDistanceTolerance) {
+		return 0, false
+	}
+
+	best := -1.0
+	for i := range a.Splits {
+		var covered, seconds float64
+		for _, sp := range a.Splits[i:] {
+			if sp.Distance <= 0 || sp.Duration <= 0 {
+				break
+			}
+			need := km - covered
+			if sp.Distance >= need {
+				seconds += float64(sp.Duration) * need / sp.Distance
+				covered = km
+				break
+			}
+			covered += sp.Distance
+			seconds += float64(sp.Duration)
+		}
+		if covered >= km && (best < 0 || seconds < best) {
+			best = seconds
+		}
+	}
+	if best >= 0 {
+		return best, true
+	}
+	return float64(a.Duration) * km / a.Distance, true
+}
+
+func recordImproved(c recordCategory, rec, prev entity.PersonalRecord) bool {
+	if c.km == 0 {
+		return rec.Distance > prev.Distance
+	}
+	return rec.Duration < prev.Duration
+}
+
+// earnedBadges mengembalikan semua badge yang memenuhi syarat dari riwayat saat ini;
+// badge yang sudah tersimpan dilewati oleh ON CONFLICT.
+func earnedBadges(tx *gorm.DB, userId uuid.UUID, activities []entity.RunActivity) ([]entity.UserBadge, error) {
+	days := map[string]bool{}
+	monthly := map[string]float64{}
+	for i := range activities {
+		t := activityTime(&activities[i]).In(helper.JakartaLocation)
+		days[t.Format("2006-01-02")] = true
+		monthly[t.Format("2006-01")] += activities[i].Distance
+	}
+
+	now := time.Now()
+	var badges []entity.UserBadge
+	add := func(badge, period string) {
+		badges = append(badges, entity.UserBadge{Id: uuid.New(), UserId: userId, Badge: badge, Period: period, EarnedAt: now})
+	}
+
+	// Group run pertama: ada aktivitas di hari group run (yang sudah lewat) yang diikuti user
+	var groupTimes []time.Time
+	if err := tx.Table("run_groups g").
+		Joins("JOIN run_group_members m ON m.group_id = g.id").
+		Where("m.user_id = ? AND m.status = ? AND g.scheduled_at <= ? AND g.status <> ?", userId, "joined", now, "cancelled").
+		Pluck("g.scheduled_at", &groupTimes).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range groupTimes {
+		if days[helper.JakartaDate(t)] {
+			add(badgeFirstGroupRun, "")
+			break
+		}
+	}
+
+	months := make([]string, 0, len(monthly))
+	for month, km := range monthly {
+		if km >= monthlyBadgeKm {
+			months = append(months, month)
+		}
+	}
+	sort.Strings(months)
+	for _, month := range months {
+		add(badgeMonthly100Km, month)
+	}
+
+	if longestStreak(days) >= streakBadgeDay {
+		add(badgeStreak7Day, "")
+	}
+	return badges, nil
+}
+
+// longestStreak menghitung jumlah hari berturut-turut terpanjang (tanggal WIB).
+func longestStreak(days map[string]bool) int {
+	longest := 0
+	for day := range days {
+		t, err := time.Parse("2006-01-02", day)
+		if err != nil || days[t.AddDate(0, 0, -1).Format("2006-01-02")] {
+			continue // bukan awal streak
+		}
+		n := 1
+		for days[t.AddDate(0, 0, n).Format("2006-01-02")] {
+			n++
+		}
+		if n > longest {
+			longest = n
+		}
+	}
+	return longest
+}
+
+// activityTime adalah waktu mulai aktivitas; input manual memakai waktu dicatat.
+func activityTime(a *entity.RunActivity) time.Time {
+	if a.StartTime != nil {
+		return *a.StartTime
+	}
+	return a.CreatedAt
+}
+
+func recordLabel(category string) string {
+	for _, c := range recordCategories {
+		if c.key == category {
+			return c.label
+		}
+	}
+	return category
+}
+
+// formatSeconds memformat durasi sebagai j:mm:ss atau m:ss.
+func formatSeconds(seconds int) string {
+	h, m, s := seconds/3600, seconds%3600/60, seconds%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// formatPace memformat pace menit desimal (5.5) menjadi m:ss (5:30).
+func formatPace(pace float64) string {
+	return formatSeconds(int(math.Round(pace * 60)))
+}
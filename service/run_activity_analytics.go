@@ -0,0 +1,286 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"run-sync/data/response"
+	"run-sync/helper"
+	"run-sync/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidAnalyticsRange dikembalikan saat weeks / months di luar batas.
+	ErrInvalidAnalyticsRange = errors.New("rentang analytics tidak valid")
+	// ErrAnalyticsUserNotFound dikembalikan saat user tidak ada.
+	ErrAnalyticsUserNotFound = errors.New("user tidak ditemukan")
+)
+
+const (
+	DefaultAnalyticsWeeks  = 12
+	MaxAnalyticsWeeks      = 52
+	DefaultAnalyticsMonths = 6
+	MaxAnalyticsMonths     = 24
+
+	// Perubahan pace di bawah ini (menit/km per minggu) dianggap stabil
+	paceTrendStableSlope = 0.02
+	minPaceTrendWeeks    = 3
+)
+
+// GetAnalytics menghitung analytics latihan user; hasil di-cache di Redis sampai
+// riwayat aktivitas berubah atau TTL habis.
+func (s *runActivityService) GetAnalytics(viewerId, userId uuid.UUID, weeks, months int) (response.TrainingAnalyticsResponse, error) {
+	if weeks < 1 || weeks > MaxAnalyticsWeeks {
+		return response.TrainingAnalyticsResponse{}, fmt.Errorf("%w: weeks harus antara 1 dan %d", ErrInvalidAnalyticsRange, MaxAnalyticsWeeks)
+	}
+	if months < 1 || months > MaxAnalyticsMonths {
+		return response.TrainingAnalyticsResponse{}, fmt.Errorf("%w: months harus antara 1 dan %d", ErrInvalidAnalyticsRange, MaxAnalyticsMonths)
+	}
+	if _, err := s.userRepo.FindById(userId); errors.Is(err, gorm.ErrRecordNotFound) {
+		return response.TrainingAnalyticsResponse{}, ErrAnalyticsUserNotFound
+	} else if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+	if err := s.checkAccess(viewerId, userId); err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+
+	now := time.Now().In(helper.JakartaLocation)
+	// Tanggal masuk ke varian cache karena streak & periode berjalan bergantung pada hari ini
+	variant := fmt.Sprintf("%s:w%d:m%d", now.Format("2006-01-02"), weeks, months)
+
+	var cached response.TrainingAnalyticsResponse
+	if found, err := s.redisHelper.GetActivityAnalytics(userId.String(), variant, &cached); err != nil {
+		log.Printf("⚠️ Gagal membaca cache analytics user %s: %v", userId, err)
+	} else if found {
+		return cached, nil
+	}
+
+	result, err := s.buildAnalytics(userId, now, weeks, months)
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+	if err := s.redisHelper.SaveActivityAnalytics(userId.String(), variant, result, s.cfg.AnalyticsCacheTTL); err != nil {
+		log.Printf("⚠️ Gagal menyimpan cache analytics user %s: %v", userId, err)
+	}
+	return result, nil
+}
+
+func (s *runActivityService) buildAnalytics(userId uuid.UUID, now time.Time, weeks, months int) (response.TrainingAnalyticsResponse, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, helper.JakartaLocation)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7)) // Senin
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, helper.JakartaLocation)
+
+	weekly, err := s.volumeSeries(userId, "week", weekStart.AddDate(0, 0, -7*(weeks-1)), weeks, func(t time.Time) time.Time { return t.AddDate(0, 0, 7) })
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+	monthly, err := s.volumeSeries(userId, "month", monthStart.AddDate(0, -(months-1), 0), months, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) })
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+
+	days, err := s.repo.GetActivityDays(userId)
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+
+	load, err := s.trainingLoad(userId, now)
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+
+	weekComparison, err := s.comparePeriods(userId, now, weekStart, weekStart.AddDate(0, 0, -7))
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+	monthComparison, err := s.comparePeriods(userId, now, monthStart, monthStart.AddDate(0, -1, 0))
+	if err != nil {
+		return response.TrainingAnalyticsResponse{}, err
+	}
+
+	return response.TrainingAnalyticsResponse{
+		UserId:       userId.String(),
+		Timezone:     "Asia/Jakarta",
+		Weekly:       weekly,
+		Monthly:      monthly,
+		PaceTrend:    paceTrend(weekly),
+		Streak:       buildStreak(days, today),
+		TrainingLoad: load,
+		Comparison:   response.PeriodComparisonsResponse{Week: weekComparison, Month: monthComparison},
+		GeneratedAt:  time.Now(),
+	}, nil
+}
+
+// volumeSeries mengisi periode tanpa aktivitas dengan nol agar grafik tidak bolong.
+func (s *runActivityService) volumeSeries(userId uuid.UUID, unit string, since time.Time, count int, next func(time.Time) time.Time) ([]response.VolumePointResponse, error) {
+	buckets, err := s.repo.GetVolumeSeries(userId, unit, since)
+	if err != nil {
+		return nil, err
+	}
+	// period_start adalah jam dinding WIB tanpa zona, cukup dicocokkan per tanggal
+	byPeriod := make(map[string]repository.VolumeBucket, len(buckets))
+	for _, b := range buckets {
+		byPeriod[b.PeriodStart.Format("2006-01-02")] = b
+	}
+
+	series := make([]response.VolumePointResponse, 0, count)
+	for period, i := since, 0; i < count; period, i = next(period), i+1 {
+		key := period.Format("2006-01-02")
+		series = append(series, volumePoint(key, byPeriod[key]))
+	}
+	return series, nil
+}
+
+func volumePoint(periodStart string, b repository.VolumeBucket) response.VolumePointResponse {
+	point := response.VolumePointResponse{
+		PeriodStart: periodStart,
+		Runs:        b.Runs,
+		Distance:    math.Round(b.Distance*100) / 100,
+		Duration:    b.Duration,
+	}
+	if b.Distance > 0 && b.Duration > 0 {
+		point.AvgPace = math.Round(float64(b.Duration)/60/b.Distance*100) / 100
+	}
+	return point
+}
+
+// paceTrend menghitung kemiringan regresi linear pace mingguan (minggu tanpa lari dilewati).
+func paceTrend(weekly []response.VolumePointResponse) response.PaceTrendResponse {
+	var n, sumX, sumY, sumXY, sumXX float64
+	for i, w := range weekly {
+		if w.AvgPace <= 0 {
+			continue
+		}
+		x := float64(i)
+		n++
+		sumX += x
+		sumY += w.AvgPace
+		sumXY += x * w.AvgPace
+		sumXX += x * x
+	}
+	if n < minPaceTrendWeeks || n*sumXX-sumX*sumX == 0 {
+		return response.PaceTrendResponse{Direction: "insufficient_data"}
+	}
+
+	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+	trend := response.PaceTrendResponse{SlopePerWeek: math.Round(slope*1000) / 1000}
+	switch {
+	case math.Abs(slope) < paceTrendStableSlope:
+		trend.Direction = "stable"
+	case slope < 0:
+		trend.Direction = "improving"
+	default:
+		trend.Direction = "declining"
+	}
+	return trend
+}
+
+// buildStreak menghitung streak dari tanggal aktivitas (WIB, urut naik). Streak berjalan
+// tetap dihitung bila hari ini belum lari tapi kemarin lari.
+func buildStreak(days []string, today time.Time) response.StreakResponse {
+	streak := response.StreakResponse{}
+	if len(days) == 0 {
+		return streak
+	}
+	set := make(map[string]bool, len(days))
+	for _, d := range days {
+		set[d] = true
+	}
+	last := days[len(days)-1]
+	streak.LastRunDate = &last
+	streak.Longest = longestStreak(set)
+
+	day := today
+	if !set[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for set[day.Format("2006-01-02")] {
+		streak.Current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
+
+// trainingLoad menghitung rasio beban akut:kronis (acute:chronic workload ratio) dari jarak.
+func (s *runActivityService) trainingLoad(userId uuid.UUID, now time.Time) (response.TrainingLoadResponse, error) {
+	end := now.Add(time.Second)
+	acute, err := s.repo.GetVolumeTotals(userId, now.AddDate(0, 0, -7), end)
+	if err != nil {
+		return response.TrainingLoadResponse{}, err
+	}
+	chronic, err := s.repo.GetVolumeTotals(userId, now.AddDate(0, 0, -28), end)
+	if err != nil {
+		return response.TrainingLoadResponse{}, err
+	}
+
+	load := response.TrainingLoadResponse{
+		AcuteKm:   math.Round(acute.Distance*100) / 100,
+		ChronicKm: math.Round(chronic.Distance/4*100) / 100,
+	}
+	switch {
+	case acute.Distance == 0 && chronic.Distance == 0:
+		load.Status = "no_data"
+		return load, nil
+	case chronic.Distance == acute.Distance:
+		// Semua lari terjadi dalam 7 hari terakhir, rasio belum bermakna
+		load.Status = "insufficient_history"
+		return load, nil
+	}
+
+	ratio := acute.Distance / (chronic.Distance / 4)
+	load.Ratio = math.Round(ratio*100) / 100
+	switch {
+	case ratio < 0.8:
+		load.Status = "low"
+	case ratio <= 1.3:
+		load.Status = "optimal"
+	case ratio <= 1.5:
+		load.Status = "high"
+	default:
+		load.Status = "very_high"
+	}
+	return load, nil
+}
+
+// comparePeriods membandingkan periode berjalan (currentStart..now) dengan periode
+// sebelumnya sepanjang waktu yang sama sejak awal periode.
+func (s *runActivityService) comparePeriods(userId uuid.UUID, now, currentStart, previousStart time.Time) (response.PeriodComparisonResponse, error) {
+	elapsed := now.Sub(currentStart)
+	previousEnd := previousStart.Add(elapsed)
+	if previousEnd.After(currentStart) {
+		previousEnd = currentStart // bulan lalu lebih pendek dari bulan berjalan
+	}
+
+	current, err := s.repo.GetVolumeTotals(userId, currentStart, now.Add(time.Second))
+	if err != nil {
+		return response.PeriodComparisonResponse{}, err
+	}
+	previous, err := s.repo.GetVolumeTotals(userId, previousStart, previousEnd)
+	if err != nil {
+		return response.PeriodComparisonResponse{}, err
+	}
+
+	comparison := response.PeriodComparisonResponse{
+		Current:    volumePoint(currentStart.Format("2006-01-02"), current),
+		Previous:   volumePoint(previousStart.Format("2006-01-02"), previous),
+		RunsChange: current.Runs - previous.Runs,
+	}
+	comparison.DistanceChangePct = percentChange(current.Distance, previous.Distance)
+	comparison.DurationChangePct = percentChange(float64(current.Duration), float64(previous.Duration))
+	return comparison, nil
+}
+
+func percentChange(current, previous float64) *float64 {
+	if previous <= 0 {
+		return nil
+	}
+	pct := math.Round((current-previous)/previous*1000) / 10
+	return &pct
+}
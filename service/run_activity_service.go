@@ -1,10 +1,15 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"math"
+	"run-sync/config"
 	"run-sync/data/request"
 	"run-sync/data/response"
 	"run-sync/entity"
+	"run-sync/helper"
 	"run-sync/repository"
 	"time"
 
@@ -13,30 +18,80 @@ import (
 
 type RunActivityService interface {
 	Create(userId uuid.UUID, req request.CreateRunActivityRequest) (response.RunActivityDetailResponse, error)
-	Update(id uuid.UUID, req request.UpdateRunActivityRequest) (response.RunActivityDetailResponse, error)
-	FindById(id uuid.UUID) (response.RunActivityDetailResponse, error)
-	FindByUserId(userId uuid.UUID) ([]response.RunActivityDetailResponse, error)
-	FindAll() ([]response.RunActivityResponse, error)
-	Delete(id uuid.UUID) error
-	GetUserStats(userId uuid.UUID) (map[string]interface{}, error)
+	// Import membuat aktivitas dari file GPX/TCX/FIT (boleh di-gzip). activityType kosong = easy.
+	Import(userId uuid.UUID, filename, activityType string, data []byte) (response.RunActivityDetailResponse, error)
+	// Update dan Delete hanya untuk pemilik aktivitas.
+	Update(userId, id uuid.UUID, req request.UpdateRunActivityRequest) (response.RunActivityDetailResponse, error)
+	// Method baca hanya untuk pemilik dan user yang terhubung dengannya (match accepted
+	// atau satu run group); polyline (rute GPS lengkap) hanya dikembalikan ke pemilik.
+	FindById(viewerId, id uuid.UUID) (response.RunActivityDetailResponse, error)
+	FindByUserId(viewerId, userId uuid.UUID) ([]response.RunActivityDetailResponse, error)
+	// FindAll mengembalikan satu halaman feed aktivitas yang boleh dilihat viewer.
+	FindAll(viewerId uuid.UUID, page, limit int) ([]response.RunActivityResponse, int64, error)
+	Delete(userId, id uuid.UUID) error
+	GetUserStats(viewerId, userId uuid.UUID) (map[string]interface{}, error)
+	// GetAnalytics mengembalikan volume mingguan & bulanan, tren pace, streak, beban
+	// latihan dan perbandingan dengan periode sebelumnya.
+	GetAnalytics(viewerId, userId uuid.UUID, weeks, months int) (response.TrainingAnalyticsResponse, error)
+
+	// SyncExternal dan RemoveExternal dipakai ConnectorService untuk aktivitas dari provider.
+	SyncExternal(userId uuid.UUID, provider string, activities []helper.ProviderActivity) (ExternalSyncResult, error)
+	RemoveExternal(userId uuid.UUID, provider, externalId string) error
+}
+
+var (
+	// ErrDuplicateActivityFile dikembalikan saat file yang sama sudah pernah diimpor.
+	ErrDuplicateActivityFile = errors.New("file aktivitas ini sudah pernah diimpor")
+	// ErrActivityNotFound dikembalikan saat aktivitas tidak ada atau bukan milik user.
+	ErrActivityNotFound = errors.New("aktivitas lari tidak ditemukan")
+	// ErrActivityAccessDenied dikembalikan saat viewer tidak terhubung dengan pemilik aktivitas.
+	ErrActivityAccessDenied = errors.New("tidak punya akses ke aktivitas user ini")
+)
+
+// Jenis latihan yang valid untuk RunActivity.ActivityType
+var activityTypes = map[string]bool{"easy": true, "tempo": true, "interval": true, "long_run": true, "race": true}
+
+// Batas zona detak jantung dalam persen detak maksimal (zona 1 mencakup semua di bawah 60%).
+var heartRateZones = []struct {
+	name   string
+	minPct float64
+}{
+	{"Pemulihan", 0},
+	{"Aerobik ringan", 0.6},
+	{"Aerobik", 0.7},
+	{"Ambang laktat", 0.8},
+	{"Maksimal", 0.9},
 }
 
 type runActivityService struct {
 	repo        repository.RunActivityRepository
 	userRepo    repository.UserRepository
-	profileRepo repository.RunnerProfileRepository
+	records     RecordsEngine
+	profilePace ProfilePace
+	redisHelper *helper.RedisHelper
+	cfg         *config.ActivityConfig
 }
 
 func NewRunActivityService(
 	repo repository.RunActivityRepository,
 	userRepo repository.UserRepository,
-	profileRepo repository.RunnerProfileRepository,
+	records RecordsEngine,
+	profilePace ProfilePace,
+	redisHelper *helper.RedisHelper,
+	cfg *config.ActivityConfig,
 ) RunActivityService {
-	return &runActivityService{repo: repo, userRepo: userRepo, profileRepo: profileRepo}
+	return &runActivityService{
+		repo:        repo,
+		userRepo:    userRepo,
+		records:     records,
+		profilePace: profilePace,
+		redisHelper: redisHelper,
+		cfg:         cfg,
+	}
 }
 
 // Create auto-calculates AvgPace if not provided (pace = duration_min / distance_km).
-// After saving, it updates the runner profile AvgPace as a running average.
+// After saving, the runner profile AvgPace is re-derived from recent activities.
 func (s *runActivityService) Create(userId uuid.UUID, req request.CreateRunActivityRequest) (response.RunActivityDetailResponse, error) {
 	user, err := s.userRepo.FindById(userId)
 	if err != nil {
@@ -52,61 +107,114 @@ func (s *runActivityService) Create(userId uuid.UUID, req request.CreateRunActiv
 		avgPace = math.Round(avgPace*100) / 100
 	}
 
+	activityType, err := normalizeActivityType(req.ActivityType)
+	if err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+	splits, err := buildManualSplits(req.Splits, req.Distance)
+	if err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+
 	activity := entity.RunActivity{
-		Id:        uuid.New(),
-		UserId:    userId,
-		Distance:  req.Distance,
-		Duration:  req.Duration,
-		AvgPace:   avgPace,
-		Calories:  req.Calories,
-		Source:    req.Source,
-		CreatedAt: time.Now(),
+		Id:           uuid.New(),
+		UserId:       userId,
+		Distance:     req.Distance,
+		Duration:     req.Duration,
+		AvgPace:      avgPace,
+		Calories:     req.Calories,
+		Source:       req.Source,
+		ActivityType: activityType,
+		AvgHeartRate: req.AvgHeartRate,
+		MaxHeartRate: req.MaxHeartRate,
+		AvgCadence:   req.AvgCadence,
+		Splits:       splits,
+		CreatedAt:    time.Now(),
+	}
+	fillHeartRateFromSplits(&activity)
+	if err := validateHeartRate(activity.AvgHeartRate, activity.MaxHeartRate); err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+	if err := s.checkPlausibility(&activity); err != nil {
+		return response.RunActivityDetailResponse{}, err
 	}
 
 	if err := s.repo.Create(&activity); err != nil {
 		return response.RunActivityDetailResponse{}, err
 	}
 
-	// Update runner profile AvgPace as running average
-	s.updateProfileAvgPace(userId, avgPace)
+	s.historyChanged(userId, &activity.Id)
 
 	return s.buildDetailResponse(&activity, user), nil
 }
 
-// updateProfileAvgPace recalculates the runner profile average pace
-// based on all recorded activities.
-func (s *runActivityService) updateProfileAvgPace(userId uuid.UUID, latestPace float64) {
-	profile, err := s.profileRepo.FindByUserId(userId)
-	if err != nil || profile == nil {
-		return
+func (s *runActivityService) Import(userId uuid.UUID, filename, activityType string, data []byte) (response.RunActivityDetailResponse, error) {
+	user, err := s.userRepo.FindById(userId)
+	if err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+	activityType, err = normalizeActivityType(activityType)
+	if err != nil {
+		return response.RunActivityDetailResponse{}, err
 	}
 
-	activities, err := s.repo.FindByUserId(userId)
-	if err != nil || len(activities) == 0 {
-		return
+	file, err := helper.ParseActivityFile(filename, data)
+	if err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+	if exists, err := s.repo.ExistsByFileHash(userId, file.Hash); err != nil {
+		return response.RunActivityDetailResponse{}, err
+	} else if exists {
+		return response.RunActivityDetailResponse{}, ErrDuplicateActivityFile
 	}
 
-	var totalPace float64
-	var count int
-	for _, a := range activities {
-		if a.AvgPace > 0 {
-			totalPace += a.AvgPace
-			count++
-		}
+	summary := helper.SummarizeTrack(file.Points)
+	if summary.DistanceKm <= 0 || summary.MovingTime <= 0 {
+		return response.RunActivityDetailResponse{}, errors.New("file aktivitas tidak berisi pergerakan (jarak atau waktu bergerak kosong)")
 	}
 
-	if count > 0 {
-		newAvg := math.Round((totalPace/float64(count))*100) / 100
-		profile.AvgPace = newAvg
-		profile.UpdatedAt = time.Now()
-		_ = s.profileRepo.Update(profile)
+	startTime := summary.StartTime
+	activity := entity.RunActivity{
+		Id:            uuid.New(),
+		UserId:        userId,
+		Distance:      summary.DistanceKm,
+		Duration:      summary.MovingTime,
+		AvgPace:       math.Round(float64(summary.MovingTime)/60/summary.DistanceKm*100) / 100,
+		Source:        file.Format,
+		StartTime:     &startTime,
+		ElapsedTime:   summary.ElapsedTime,
+		ElevationGain: summary.ElevationGain,
+		Polyline:      summary.Polyline,
+		FileHash:      &file.Hash,
+		ActivityType:  activityType,
+		AvgHeartRate:  summary.AvgHeartRate,
+		MaxHeartRate:  summary.MaxHeartRate,
+		AvgCadence:    summary.AvgCadence,
+		MaxSpeed:      math.Round(summary.MaxSpeedKmh*10) / 10,
+		Splits:        splitsFromTrack(summary.Splits),
+		CreatedAt:     time.Now(),
 	}
+	if err := s.checkPlausibility(&activity); err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+
+	if err := s.repo.Create(&activity); err != nil {
+		// Upload bersamaan file yang sama tertahan unique index (user_id, file_hash)
+		if exists, _ := s.repo.ExistsByFileHash(userId, file.Hash); exists {
+			return response.RunActivityDetailResponse{}, ErrDuplicateActivityFile
+		}
+		return response.RunActivityDetailResponse{}, err
+	}
+
+	s.historyChanged(userId, &activity.Id)
+
+	return s.buildDetailResponse(&activity, user), nil
 }
 
-func (s *runActivityService) Update(id uuid.UUID, req request.UpdateRunActivityRequest) (response.RunActivityDetailResponse, error) {
+func (s *runActivityService) Update(userId, id uuid.UUID, req request.UpdateRunActivityRequest) (response.RunActivityDetailResponse, error) {
 	activity, err := s.repo.FindById(id)
-	if err != nil {
-		return response.RunActivityDetailResponse{}, err
+	if err != nil || activity.UserId != userId {
+		return response.RunActivityDetailResponse{}, ErrActivityNotFound
 	}
 
 	if req.Distance != nil {
@@ -124,6 +232,20 @@ func (s *runActivityService) Update(id uuid.UUID, req request.UpdateRunActivityR
 	if req.Source != nil {
 		activity.Source = *req.Source
 	}
+	if req.ActivityType != nil {
+		if activity.ActivityType, err = normalizeActivityType(*req.ActivityType); err != nil {
+			return response.RunActivityDetailResponse{}, err
+		}
+	}
+	if req.AvgHeartRate != nil {
+		activity.AvgHeartRate = *req.AvgHeartRate
+	}
+	if req.MaxHeartRate != nil {
+		activity.MaxHeartRate = *req.MaxHeartRate
+	}
+	if req.AvgCadence != nil {
+		activity.AvgCadence = *req.AvgCadence
+	}
 
 	// Auto-recalculate pace if distance/duration changed but pace not explicitly set
 	if req.AvgPace == nil && (req.Distance != nil || req.Duration != nil) {
@@ -133,28 +255,61 @@ func (s *runActivityService) Update(id uuid.UUID, req request.UpdateRunActivityR
 		}
 	}
 
-	if err := s.repo.Update(activity); err != nil {
+	if req.Splits != nil {
+		splits, err := buildManualSplits(*req.Splits, activity.Distance)
+		if err != nil {
+			return response.RunActivityDetailResponse{}, err
+		}
+		activity.Splits = splits
+		fillHeartRateFromSplits(activity)
+	}
+	if err := validateHeartRate(activity.AvgHeartRate, activity.MaxHeartRate); err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+	if err := s.checkPlausibility(activity); err != nil {
 		return response.RunActivityDetailResponse{}, err
 	}
 
-	// Update profile avg pace
-	s.updateProfileAvgPace(activity.UserId, activity.AvgPace)
+	if req.Splits != nil {
+		err = s.repo.UpdateWithSplits(activity, activity.Splits)
+	} else {
+		err = s.repo.Update(activity)
+	}
+	if err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
+
+	s.historyChanged(activity.UserId, &activity.Id)
 
 	user, _ := s.userRepo.FindById(activity.UserId)
 	return s.buildDetailResponse(activity, user), nil
 }
 
-func (s *runActivityService) FindById(id uuid.UUID) (response.RunActivityDetailResponse, error) {
+func (s *runActivityService) FindById(viewerId, id uuid.UUID) (response.RunActivityDetailResponse, error) {
 	activity, err := s.repo.FindById(id)
 	if err != nil {
 		return response.RunActivityDetailResponse{}, err
 	}
+	// Aktivitas yang tidak boleh dilihat diperlakukan seperti tidak ada
+	if err := s.checkAccess(viewerId, activity.UserId); errors.Is(err, ErrActivityAccessDenied) {
+		return response.RunActivityDetailResponse{}, ErrActivityNotFound
+	} else if err != nil {
+		return response.RunActivityDetailResponse{}, err
+	}
 
 	user, _ := s.userRepo.FindById(activity.UserId)
-	return s.buildDetailResponse(activity, user), nil
+	res := s.buildDetailResponse(activity, user)
+	if viewerId != activity.UserId {
+		hideOwnerPrivateFields(&res)
+	}
+	return res, nil
 }
 
-func (s *runActivityService) FindByUserId(userId uuid.UUID) ([]response.RunActivityDetailResponse, error) {
+func (s *runActivityService) FindByUserId(viewerId, userId uuid.UUID) ([]response.RunActivityDetailResponse, error) {
+	if err := s.checkAccess(viewerId, userId); err != nil {
+		return nil, err
+	}
+
 	activities, err := s.repo.FindByUserId(userId)
 	if err != nil {
 		return nil, err
@@ -164,41 +319,133 @@ func (s *runActivityService) FindByUserId(userId uuid.UUID) ([]response.RunActiv
 
 	var responses []response.RunActivityDetailResponse
 	for _, activity := range activities {
-		responses = append(responses, s.buildDetailResponse(&activity, user))
+		res := s.buildDetailResponse(&activity, user)
+		if viewerId != userId {
+			hideOwnerPrivateFields(&res)
+		}
+		responses = append(responses, res)
 	}
 
 	return responses, nil
 }
 
-func (s *runActivityService) FindAll() ([]response.RunActivityResponse, error) {
-	activities, err := s.repo.FindAll()
+func (s *runActivityService) FindAll(viewerId uuid.UUID, page, limit int) ([]response.RunActivityResponse, int64, error) {
+	activities, total, err := s.repo.FindVisible(viewerId, limit, (page-1)*limit)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	var responses []response.RunActivityResponse
 	for _, activity := range activities {
 		responses = append(responses, response.RunActivityResponse{
-			Id:        activity.Id.String(),
-			UserId:    activity.UserId.String(),
-			Distance:  activity.Distance,
-			Duration:  activity.Duration,
-			AvgPace:   activity.AvgPace,
-			Calories:  activity.Calories,
-			Source:    activity.Source,
-			CreatedAt: activity.CreatedAt,
+			Id:           activity.Id.String(),
+			UserId:       activity.UserId.String(),
+			Distance:     activity.Distance,
+			Duration:     activity.Duration,
+			AvgPace:      activity.AvgPace,
+			Calories:     activity.Calories,
+			Source:       activity.Source,
+			ActivityType: activity.ActivityType,
+			StartTime:    activity.StartTime,
+			CreatedAt:    activity.CreatedAt,
 		})
 	}
 
-	return responses, nil
+	return responses, total, nil
 }
 
-func (s *runActivityService) Delete(id uuid.UUID) error {
-	return s.repo.Delete(id)
+func (s *runActivityService) Delete(userId, id uuid.UUID) error {
+	activity, err := s.repo.FindById(id)
+	if err != nil || activity.UserId != userId {
+		return ErrActivityNotFound
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.historyChanged(activity.UserId, nil)
+	return nil
+}
+
+// historyChanged menghitung ulang PR, badge dan avg_pace profil serta membuang cache
+// analytics setelah riwayat aktivitas berubah; kegagalan tidak membatalkan perubahan aktivitas.
+func (s *runActivityService) historyChanged(userId uuid.UUID, activityId *uuid.UUID) {
+	if err := s.redisHelper.InvalidateActivityAnalytics(userId.String()); err != nil {
+		log.Printf("⚠️ Gagal menghapus cache analytics user %s: %v", userId, err)
+	}
+	if err := s.records.Recompute(userId, activityId); err != nil {
+		log.Printf("⚠️ Gagal menghitung ulang personal record user %s: %v", userId, err)
+	}
+	if err := s.profilePace.Refresh(userId); err != nil {
+		log.Printf("⚠️ Gagal memperbarui avg_pace profil user %s: %v", userId, err)
+	}
+}
+
+// GetUserStats menambahkan split 1 km tercepat dan distribusi zona detak jantung ke
+// statistik agregat. Zona dihitung dari detak maksimal tertinggi yang pernah tercatat.
+func (s *runActivityService) GetUserStats(viewerId, userId uuid.UUID) (map[string]interface{}, error) {
+	if err := s.checkAccess(viewerId, userId); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetUserStats(userId)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = map[string]interface{}{}
+	}
+
+	fastest, err := s.repo.FindFastestSplit(userId)
+	if err != nil {
+		return nil, err
+	}
+	if fastest != nil {
+		stats["fastest_split"] = response.FastestSplitResponse{
+			ActivityId:   fastest.ActivityId.String(),
+			SplitNumber:  fastest.SplitNumber,
+			Duration:     fastest.Duration,
+			Pace:         fastest.Pace,
+			AvgHeartRate: fastest.AvgHeartRate,
+			RecordedAt:   fastest.RecordedAt,
+		}
+	} else {
+		stats["fastest_split"] = nil
+	}
+
+	samples, err := s.repo.GetHeartRateSamples(userId)
+	if err != nil {
+		return nil, err
+	}
+	maxHR, err := s.repo.MaxHeartRate(userId)
+	if err != nil {
+		return nil, err
+	}
+	stats["max_heart_rate"] = maxHR
+	stats["heart_rate_zones"] = buildHeartRateZones(samples, maxHR)
+
+	if stats["personal_records"], err = s.records.Records(userId); err != nil {
+		return nil, err
+	}
+	if stats["badges"], err = s.records.Badges(userId); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
 }
 
-func (s *runActivityService) GetUserStats(userId uuid.UUID) (map[string]interface{}, error) {
-	return s.repo.GetUserStats(userId)
+// checkAccess memastikan viewer adalah pemilik atau terhubung dengan pemilik aktivitas.
+func (s *runActivityService) checkAccess(viewerId, ownerId uuid.UUID) error {
+	if viewerId == ownerId {
+		return nil
+	}
+	connected, err := s.repo.IsConnected(viewerId, ownerId)
+	if err != nil {
+		return err
+	}
+	if !connected {
+		return ErrActivityAccessDenied
+	}
+	return nil
 }
 
 // -- Response builder --
@@ -221,14 +468,194 @@ func (s *runActivityService) buildDetailResponse(activity *entity.RunActivity, u
 	}
 
 	return response.RunActivityDetailResponse{
-		Id:        activity.Id.String(),
-		UserId:    activity.UserId.String(),
-		User:      userRes,
-		Distance:  activity.Distance,
-		Duration:  activity.Duration,
-		AvgPace:   activity.AvgPace,
-		Calories:  activity.Calories,
-		Source:    activity.Source,
-		CreatedAt: activity.CreatedAt,
+		Id:            activity.Id.String(),
+		UserId:        activity.UserId.String(),
+		User:          userRes,
+		Distance:      activity.Distance,
+		Duration:      activity.Duration,
+		AvgPace:       activity.AvgPace,
+		Calories:      activity.Calories,
+		Source:        activity.Source,
+		StartTime:     activity.StartTime,
+		ElapsedTime:   activity.ElapsedTime,
+		ElevationGain: activity.ElevationGain,
+		Polyline:      activity.Polyline,
+		ActivityType:  activity.ActivityType,
+		AvgHeartRate:  activity.AvgHeartRate,
+		MaxHeartRate:  activity.MaxHeartRate,
+		AvgCadence:    activity.AvgCadence,
+		MaxSpeed:      activity.MaxSpeed,
+		Splits:        mapActivitySplits(activity.Splits),
+		CreatedAt:     activity.CreatedAt,
+	}
+}
+
+// hideOwnerPrivateFields membuang data yang hanya untuk pemilik aktivitas: rute lengkap
+// (membuka lokasi rumah / kantor) serta email dan nomor telepon.
+func hideOwnerPrivateFields(res *response.RunActivityDetailResponse) {
+	res.Polyline = ""
+	if res.User != nil {
+		res.User.Email = nil
+		res.User.PhoneNumber = ""
+	}
+}
+
+// mapActivitySplits converts split entities to response DTOs.
+func mapActivitySplits(splits []*entity.RunActivitySplit) []response.RunActivitySplitResponse {
+	res := make([]response.RunActivitySplitResponse, 0, len(splits))
+	for _, sp := range splits {
+		res = append(res, response.RunActivitySplitResponse{
+			SplitNumber:   sp.SplitNumber,
+			Distance:      sp.Distance,
+			Duration:      sp.Duration,
+			Pace:          sp.Pace,
+			ElevationGain: sp.ElevationGain,
+			ElevationLoss: sp.ElevationLoss,
+			AvgHeartRate:  sp.AvgHeartRate,
+			MaxHeartRate:  sp.MaxHeartRate,
+		})
+	}
+	return res
+}
+
+// -- Splits & heart rate --
+
+func normalizeActivityType(activityType string) (string, error) {
+	if activityType == "" {
+		return "easy", nil
+	}
+	if !activityTypes[activityType] {
+		return "", fmt.Errorf("activity_type tidak valid: %s (easy, tempo, interval, long_run, race)", activityType)
+	}
+	return activityType, nil
+}
+
+func splitPace(distanceKm float64, seconds int) float64 {
+	if distanceKm <= 0 || seconds <= 0 {
+		return 0
+	}
+	return math.Round(float64(seconds)/60/distanceKm*100) / 100
+}
+
+// buildManualSplits mengubah split input manual menjadi entity. Hanya split terakhir
+// yang boleh kurang dari 1 km, dan total jarak split harus sama dengan jarak aktivitas
+// (toleransi 2% atau 50 m).
+func buildManualSplits(reqs []request.RunActivitySplitRequest, distanceKm float64) ([]*entity.RunActivitySplit, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+	splits := make([]*entity.RunActivitySplit, 0, len(reqs))
+	var total float64
+	for i, r := range reqs {
+		distance := r.Distance
+		if distance == 0 {
+			distance = 1
+		}
+		if distance < 1 && i != len(reqs)-1 {
+			return nil, fmt.Errorf("split ke-%d: hanya split terakhir yang boleh kurang dari 1 km", i+1)
+		}
+		if err := validateHeartRate(r.AvgHeartRate, r.MaxHeartRate); err != nil {
+			return nil, fmt.Errorf("split ke-%d: %w", i+1, err)
+		}
+		total += distance
+		splits = append(splits, &entity.RunActivitySplit{
+			Id:            uuid.New(),
+			SplitNumber:   i + 1,
+			Distance:      distance,
+			Duration:      r.Duration,
+			Pace:          splitPace(distance, r.Duration),
+			ElevationGain: r.ElevationGain,
+			ElevationLoss: r.ElevationLoss,
+			AvgHeartRate:  r.AvgHeartRate,
+			MaxHeartRate:  r.MaxHeartRate,
+		})
+	}
+	if math.Abs(total-distanceKm) > math.Max(0.05, distanceKm*0.02) {
+		return nil, fmt.Errorf("total jarak split (%.2f km) tidak sesuai jarak aktivitas (%.2f km)", total, distanceKm)
+	}
+	return splits, nil
+}
+
+func splitsFromTrack(track []helper.TrackSplit) []*entity.RunActivitySplit {
+	splits := make([]*entity.RunActivitySplit, 0, len(track))
+	for _, t := range track {
+		splits = append(splits, &entity.RunActivitySplit{
+			Id:            uuid.New(),
+			SplitNumber:   t.SplitNumber,
+			Distance:      t.DistanceKm,
+			Duration:      t.MovingTime,
+			Pace:          splitPace(t.DistanceKm, t.MovingTime),
+			ElevationGain: t.ElevationGain,
+			ElevationLoss: t.ElevationLoss,
+			AvgHeartRate:  t.AvgHeartRate,
+			MaxHeartRate:  t.MaxHeartRate,
+		})
+	}
+	return splits
+}
+
+// fillHeartRateFromSplits mengisi detak rata-rata (tertimbang durasi) dan maksimal
+// aktivitas dari split bila tidak diinput.
+func fillHeartRateFromSplits(activity *entity.RunActivity) {
+	var sum, seconds float64
+	maxHR := 0
+	for _, sp := range activity.Splits {
+		if sp.AvgHeartRate > 0 {
+			sum += float64(sp.AvgHeartRate * sp.Duration)
+			seconds += float64(sp.Duration)
+		}
+		if sp.MaxHeartRate > maxHR {
+			maxHR = sp.MaxHeartRate
+		}
+	}
+	if activity.AvgHeartRate == 0 && seconds > 0 {
+		activity.AvgHeartRate = int(math.Round(sum / seconds))
+	}
+	if activity.MaxHeartRate == 0 {
+		activity.MaxHeartRate = maxHR
+	}
+}
+
+func validateHeartRate(avg, max int) error {
+	if avg > 0 && max > 0 && avg > max {
+		return errors.New("detak jantung rata-rata tidak boleh melebihi detak maksimal")
+	}
+	return nil
+}
+
+// buildHeartRateZones membagi total waktu per bpm ke lima zona berdasarkan maxHR.
+func buildHeartRateZones(samples []repository.HeartRateSample, maxHR int) []response.HeartRateZoneResponse {
+	if maxHR <= 0 {
+		return []response.HeartRateZoneResponse{}
+	}
+	zones := make([]response.HeartRateZoneResponse, len(heartRateZones))
+	for i, z := range heartRateZones {
+		zones[i] = response.HeartRateZoneResponse{
+			Zone:   i + 1,
+			Name:   z.name,
+			MinBpm: int(math.Ceil(z.minPct * float64(maxHR))),
+			MaxBpm: maxHR,
+		}
+		if i > 0 {
+			zones[i-1].MaxBpm = zones[i].MinBpm - 1
+		}
+	}
+
+	var total int
+	for _, sample := range samples {
+		zone := 0
+		for i := range zones {
+			if sample.HeartRate >= zones[i].MinBpm {
+				zone = i
+			}
+		}
+		zones[zone].Seconds += sample.Seconds
+		total += sample.Seconds
+	}
+	if total > 0 {
+		for i := range zones {
+			zones[i].Percentage = math.Round(float64(zones[i].Seconds)/float64(total)*1000) / 10
+		}
 	}
+	return zones
 }
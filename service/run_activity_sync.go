@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"run-sync/entity"
+	"run-sync/helper"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Hasil sinkronisasi satu aktivitas provider
+const (
+	syncCreated = "created"
+	syncUpdated = "updated"
+	syncMerged  = "merged"
+	syncSkipped = "skipped"
+)
+
+// ExternalSyncResult merangkum hasil SyncExternal.
+type ExternalSyncResult struct {
+	Created int // aktivitas baru
+	Updated int // aktivitas yang sudah tertaut diperbarui
+	Merged  int // input manual / impor file yang ditautkan ke aktivitas provider (dedup)
+	Skipped int // bukan lari, tidak wajar, atau milik user lain
+}
+
+// SyncExternal menyimpan aktivitas dari connector. Aktivitas yang sudah tertaut
+// diperbarui; aktivitas yang bertumpuk dengan input manual / impor file ditautkan ke
+// aktivitas tersebut alih-alih membuat duplikat; sisanya dibuat baru dengan Source = provider.
+func (s *runActivityService) SyncExternal(userId uuid.UUID, provider string, activities []helper.ProviderActivity) (ExternalSyncResult, error) {
+	var (
+		result  ExternalSyncResult
+		changed []uuid.UUID
+		syncErr error
+	)
+	for _, a := range activities {
+		if !a.IsRun || a.Distance <= 0 || a.MovingTime <= 0 {
+			result.Skipped++
+			continue
+		}
+
+		id, outcome, err := s.syncOne(userId, provider, a)
+		if err != nil {
+			syncErr = err
+			break
+		}
+		switch outcome {
+		case syncCreated:
+			result.Created++
+		case syncUpdated:
+			result.Updated++
+		case syncMerged:
+			result.Merged++
+		default:
+			result.Skipped++
+			continue
+		}
+		changed = append(changed, id)
+	}
+
+	if len(changed) > 0 {
+		// PR baru hanya dinotifikasi untuk satu aktivitas (webhook), bukan saat backfill
+		var notifyId *uuid.UUID
+		if len(changed) == 1 {
+			notifyId = &changed[0]
+		}
+		s.historyChanged(userId, notifyId)
+	}
+	return result, syncErr
+}
+
+func (s *runActivityService) syncOne(userId uuid.UUID, provider string, a helper.ProviderActivity) (uuid.UUID, string, error) {
+	existing, err := s.repo.FindByExternalId(provider, a.ExternalId)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return uuid.Nil, "", err
+	}
+	if existing != nil {
+		if existing.UserId != userId {
+			log.Printf("⚠️ Aktivitas %s %s sudah tertaut ke user lain, dilewati", provider, a.ExternalId)
+			return uuid.Nil, syncSkipped, nil
+		}
+		if existing.Source == provider {
+			applyProviderActivity(existing, a)
+		} else {
+			fillFromProvider(existing, a)
+		}
+		if err := s.checkPlausibility(existing); err != nil {
+			log.Printf("⚠️ Pembaruan aktivitas %s %s dilewati: %v", provider, a.ExternalId, err)
+			return uuid.Nil, syncSkipped, nil
+		}
+		return existing.Id, syncUpdated, s.repo.Update(existing)
+	}
+
+	if match, err := s.findDuplicate(userId, a); err != nil {
+		return uuid.Nil, "", err
+	} else if match != nil {
+		match.ExternalProvider = &provider
+		match.ExternalId = &a.ExternalId
+		fillFromProvider(match, a)
+		if err := s.repo.Update(match); err != nil {
+			return uuid.Nil, "", err
+		}
+		return match.Id, syncMerged, nil
+	}
+
+	activity := entity.RunActivity{
+		Id:               uuid.New(),
+		UserId:           userId,
+		Source:           provider,
+		ActivityType:     "easy",
+		ExternalProvider: &provider,
+		ExternalId:       &a.ExternalId,
+		CreatedAt:        time.Now(),
+	}
+	applyProviderActivity(&activity, a)
+	if err := s.checkPlausibility(&activity); err != nil {
+		log.Printf("⚠️ Aktivitas %s %s tidak disimpan: %v", provider, a.ExternalId, err)
+		return uuid.Nil, syncSkipped, nil
+	}
+	if err := s.repo.Create(&activity); err != nil {
+		// Webhook dan backfill bisa membawa aktivitas yang sama bersamaan
+		if dup, _ := s.repo.FindByExternalId(provider, a.ExternalId); dup != nil {
+			return uuid.Nil, syncSkipped, nil
+		}
+		return uuid.Nil, "", err
+	}
+	return activity.Id, syncCreated, nil
+}
+
+// findDuplicate mencari aktivitas belum tertaut yang waktunya bertumpuk dengan aktivitas
+// provider dan jaraknya paling dekat (maksimal DedupDistancePct). Input manual tanpa
+// start_time memakai created_at, yang biasanya dicatat tidak lama setelah lari selesai.
+func (s *runActivityService) findDuplicate(userId uuid.UUID, a helper.ProviderActivity) (*entity.RunActivity, error) {
+	elapsed := time.Duration(max(a.ElapsedTime, a.MovingTime)) * time.Second
+	candidates, err := s.repo.FindUnlinkedBetween(userId, a.StartTime.Add(-s.cfg.DedupWindow), a.StartTime.Add(elapsed+s.cfg.DedupWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	var best *entity.RunActivity
+	bestDiff := s.cfg.DedupDistancePct
+	for i := range candidates {
+		diff := math.Abs(candidates[i].Distance-a.Distance) / a.Distance
+		if diff <= bestDiff {
+			best, bestDiff = &candidates[i], diff
+		}
+	}
+	return best, nil
+}
+
+// applyProviderActivity menimpa data aktivitas dengan data provider (aktivitas buatan connector).
+func applyProviderActivity(activity *entity.RunActivity, a helper.ProviderActivity) {
+	start := a.StartTime
+	activity.StartTime = &start
+	activity.Distance = a.Distance
+	activity.Duration = a.MovingTime
+	activity.AvgPace = math.Round(float64(a.MovingTime)/60/a.Distance*100) / 100
+	activity.ElapsedTime = a.ElapsedTime
+	activity.ElevationGain = a.ElevationGain
+	activity.AvgHeartRate = a.AvgHeartRate
+	activity.MaxHeartRate = a.MaxHeartRate
+	activity.AvgCadence = a.AvgCadence
+	activity.Calories = a.Calories
+	activity.MaxSpeed = a.MaxSpeedKmh
+	activity.Polyline = a.Polyline
+	if activityTypes[a.WorkoutType] {
+		activity.ActivityType = a.WorkoutType
+	}
+}
+
+// fillFromProvider hanya mengisi data yang kosong; jarak, durasi dan jenis latihan
+// yang dicatat user tetap dipakai.
+func fillFromProvider(activity *entity.RunActivity, a helper.ProviderActivity) {
+	if activity.StartTime == nil {
+		start := a.StartTime
+		activity.StartTime = &start
+	}
+	if activity.ElapsedTime == 0 {
+		activity.ElapsedTime = a.ElapsedTime
+	}
+	if activity.ElevationGain == 0 {
+		activity.ElevationGain = a.ElevationGain
+	}
+	if activity.AvgHeartRate == 0 && activity.MaxHeartRate == 0 {
+		activity.AvgHeartRate, activity.MaxHeartRate = a.AvgHeartRate, a.MaxHeartRate
+	}
+	if activity.AvgCadence == 0 {
+		activity.AvgCadence = a.AvgCadence
+	}
+	if activity.Calories == 0 {
+		activity.Calories = a.Calories
+	}
+	if activity.MaxSpeed == 0 {
+		activity.MaxSpeed = a.MaxSpeedKmh
+	}
+	if activity.Polyline == "" {
+		activity.Polyline = a.Polyline
+	}
+}
+
+// RemoveExternal dipanggil saat aktivitas dihapus di provider: aktivitas buatan connector
+// ikut dihapus, input manual yang hanya ditautkan dilepas tautannya.
+func (s *runActivityService) RemoveExternal(userId uuid.UUID, provider, externalId string) error {
+	activity, err := s.repo.FindByExternalId(provider, externalId)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if activity.UserId != userId {
+		return nil
+	}
+
+	if activity.Source == provider {
+		if err := s.repo.Delete(activity.Id); err != nil {
+			return err
+		}
+		s.historyChanged(userId, nil)
+		return nil
+	}
+	activity.ExternalProvider, activity.ExternalId = nil, nil
+	return s.repo.Update(activity)
+}
@@ -30,6 +30,7 @@ type runGroupMemberService struct {
 	userRepo  repository.UserRepository
 	groupRepo repository.RunGroupRepository
 	db        *gorm.DB
+	womenOnly WomenOnlyPolicy
 }
 
 func NewRunGroupMemberService(
@@ -37,8 +38,9 @@ func NewRunGroupMemberService(
 	userRepo repository.UserRepository,
 	groupRepo repository.RunGroupRepository,
 	db *gorm.DB,
+	womenOnly WomenOnlyPolicy,
 ) RunGroupMemberService {
-	return &runGroupMemberService{repo: repo, userRepo: userRepo, groupRepo: groupRepo, db: db}
+	return &runGroupMemberService{repo: repo, userRepo: userRepo, groupRepo: groupRepo, db: db, womenOnly: womenOnly}
 }
 
 func (s *runGroupMemberService) Create(req request.CreateRunGroupMemberRequest) (response.RunGroupMemberDetailResponse, error) {
@@ -50,9 +52,15 @@ func (s *runGroupMemberService) Create(req request.CreateRunGroupMemberRequest)
 		return response.RunGroupMemberDetailResponse{}, errors.New("user tidak ditemukan")
 	}
 
-	if _, err := s.groupRepo.FindById(groupId); err != nil {
+	group, err := s.groupRepo.FindById(groupId)
+	if err != nil {
 		return response.RunGroupMemberDetailResponse{}, errors.New("grup tidak ditemukan")
 	}
+	if group.IsWomenOnly {
+		if err := s.womenOnly.CanEnterGroup(user); err != nil {
+			return response.RunGroupMemberDetailResponse{}, err
+		}
+	}
 
 	member := entity.RunGroupMember{
 		Id:       uuid.New(),
@@ -241,7 +249,7 @@ func (s *runGroupMemberService) KickMember(requesterId uuid.UUID, memberId uuid.
 
 // JoinGroup uses a GORM transaction to:
 // 1. Validate user + group exist
-// 2. Check womenOnly gender restriction
+// 2. Check womenOnly restriction (WomenOnlyPolicy)
 // 3. Check if already a member
 // 4. Check if group is full
 // 5. Insert member
@@ -261,10 +269,10 @@ func (s *runGroupMemberService) JoinGroup(userId uuid.UUID, groupId uuid.UUID) (
 		return response.RunGroupMemberDetailResponse{}, errors.New("grup sudah penuh atau sudah selesai")
 	}
 
-	// Women-only gender check
+	// Women-only: gender ternormalisasi (dan review wajah jika diwajibkan)
 	if group.IsWomenOnly {
-		if user.Gender == nil || *user.Gender != "female" {
-			return response.RunGroupMemberDetailResponse{}, errors.New("grup ini hanya untuk perempuan")
+		if err := s.womenOnly.CanEnterGroup(user); err != nil {
+			return response.RunGroupMemberDetailResponse{}, err
 		}
 	}
 
@@ -28,10 +28,11 @@ type runGroupService struct {
 	repo       repository.RunGroupRepository
 	userRepo   repository.UserRepository
 	memberRepo repository.RunGroupMemberRepository
+	womenOnly  WomenOnlyPolicy
 }
 
-func NewRunGroupService(repo repository.RunGroupRepository, userRepo repository.UserRepository, memberRepo repository.RunGroupMemberRepository) RunGroupService {
-	return &runGroupService{repo: repo, userRepo: userRepo, memberRepo: memberRepo}
+func NewRunGroupService(repo repository.RunGroupRepository, userRepo repository.UserRepository, memberRepo repository.RunGroupMemberRepository, womenOnly WomenOnlyPolicy) RunGroupService {
+	return &runGroupService{repo: repo, userRepo: userRepo, memberRepo: memberRepo, womenOnly: womenOnly}
 }
 
 func (s *runGroupService) Create(createdBy uuid.UUID, req request.CreateRunGroupRequest) (response.RunGroupDetailResponse, error) {
@@ -40,6 +41,13 @@ func (s *runGroupService) Create(createdBy uuid.UUID, req request.CreateRunGroup
 		return response.RunGroupDetailResponse{}, errors.New("user tidak ditemukan")
 	}
 
+	// Pembuat otomatis menjadi owner, jadi harus memenuhi syarat women-only
+	if req.IsWomenOnly {
+		if err := s.womenOnly.CanEnterGroup(user); err != nil {
+			return response.RunGroupDetailResponse{}, err
+		}
+	}
+
 	scheduledAt, _ := time.Parse(time.RFC3339, req.ScheduledAt)
 
 	group := entity.RunGroup{
@@ -143,6 +151,16 @@ func (s *runGroupService) Update(id uuid.UUID, req request.UpdateRunGroupRequest
 		group.MaxMember = *req.MaxMember
 	}
 	if req.IsWomenOnly != nil {
+		if *req.IsWomenOnly && !group.IsWomenOnly {
+			// Grup tidak boleh dijadikan women-only selama ada member yang bukan perempuan
+			count, err := s.memberRepo.CountNonFemaleJoined(group.Id)
+			if err != nil {
+				return response.RunGroupDetailResponse{}, err
+			}
+			if count > 0 {
+				return response.RunGroupDetailResponse{}, errors.New("grup masih memiliki member yang bukan perempuan, tidak bisa dijadikan women-only")
+			}
+		}
 		group.IsWomenOnly = *req.IsWomenOnly
 	}
 	if req.Status != nil {
@@ -2,6 +2,8 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"run-sync/data/request"
 	"run-sync/data/response"
 	"run-sync/entity"
@@ -20,15 +22,24 @@ type RunnerProfileService interface {
 	FindByUserId(userId uuid.UUID) (response.RunnerProfileDetailResponse, error)
 	FindAll() ([]response.RunnerProfileResponse, error)
 	Delete(id uuid.UUID) error
+
+	// Discovery preferences milik profil user sendiri
+	GetDiscoveryPreference(userId uuid.UUID) (response.DiscoveryPreferenceResponse, error)
+	UpdateDiscoveryPreference(userId uuid.UUID, req request.UpdateDiscoveryPreferenceRequest) (response.DiscoveryPreferenceResponse, error)
 }
 
 type runnerProfileService struct {
-	repo     repository.RunnerProfileRepository
-	userRepo repository.UserRepository
+	repo      repository.RunnerProfileRepository
+	userRepo  repository.UserRepository
+	prefRepo  repository.DiscoveryPreferenceRepository
+	womenOnly WomenOnlyPolicy
+	media     MediaStorageService
+	gallery   PhotoGallery
+	pace      ProfilePace
 }
 
-func NewRunnerProfileService(repo repository.RunnerProfileRepository, userRepo repository.UserRepository) RunnerProfileService {
-	return &runnerProfileService{repo: repo, userRepo: userRepo}
+func NewRunnerProfileService(repo repository.RunnerProfileRepository, userRepo repository.UserRepository, prefRepo repository.DiscoveryPreferenceRepository, womenOnly WomenOnlyPolicy, media MediaStorageService, gallery PhotoGallery, pace ProfilePace) RunnerProfileService {
+	return &runnerProfileService{repo: repo, userRepo: userRepo, prefRepo: prefRepo, womenOnly: womenOnly, media: media, gallery: gallery, pace: pace}
 }
 
 // CreateOrUpdate enforces one profile per user.
@@ -44,6 +55,16 @@ func (s *runnerProfileService) CreateOrUpdate(userId uuid.UUID, req request.Crea
 		return response.RunnerProfileDetailResponse{}, errors.New("avg_pace harus antara 3.0 - 12.0 min/km")
 	}
 
+	// Gender dinormalisasi sebelum syarat women-only dicek
+	if err := s.womenOnly.ApplyGender(user, req.Gender); err != nil {
+		return response.RunnerProfileDetailResponse{}, err
+	}
+	if req.WomenOnlyMode {
+		if err := s.womenOnly.CanEnableMode(user); err != nil {
+			return response.RunnerProfileDetailResponse{}, err
+		}
+	}
+
 	// Check if profile already exists for this user
 	existing, _ := s.repo.FindByUserId(userId)
 	if existing != nil {
@@ -54,7 +75,6 @@ func (s *runnerProfileService) CreateOrUpdate(userId uuid.UUID, req request.Crea
 			userUpdated = true
 		}
 		if req.Gender != nil {
-			user.Gender = req.Gender
 			userUpdated = true
 		}
 		if userUpdated {
@@ -63,34 +83,33 @@ func (s *runnerProfileService) CreateOrUpdate(userId uuid.UUID, req request.Crea
 
 		// Update existing profile
 		existing.AvgPace = req.AvgPace
+		existing.AvgPaceManual = req.AvgPaceManual
 		existing.PreferredDistance = req.PreferredDistance
 		existing.PreferredTime = req.PreferredTime
 		existing.Latitude = req.Latitude
 		existing.Longitude = req.Longitude
 		existing.WomenOnlyMode = req.WomenOnlyMode
-		if req.Image != nil && *req.Image != "" {
-			// Upload to Cloudinary if base64 image provided
-			imageUrl, err := helper.UploadBase64ToCloudinary(*req.Image, "run-sync/profiles")
-			if err != nil {
-				return response.RunnerProfileDetailResponse{}, errors.New("gagal upload gambar profil: " + err.Error())
-			}
-			existing.Image = &imageUrl
+		oldImage, err := s.replaceImage(existing, req.Image)
+		if err != nil {
+			return response.RunnerProfileDetailResponse{}, err
 		}
 		existing.UpdatedAt = time.Now()
 
 		if err := s.repo.Update(existing); err != nil {
 			return response.RunnerProfileDetailResponse{}, err
 		}
+		s.media.Release(oldImage)
+		s.refreshDerivedPace(existing)
 
 		return s.buildDetailResponse(existing, user), nil
 	}
 
-	// Upload image to Cloudinary if provided
-	var imagePtr *string
+	// Gambar profil selalu foto utama galeri
+	imagePtr := s.gallery.PrimaryImage(userId)
 	if req.Image != nil && *req.Image != "" {
-		imageUrl, err := helper.UploadBase64ToCloudinary(*req.Image, "run-sync/profiles")
+		imageUrl, err := s.addPrimaryPhoto(userId, *req.Image)
 		if err != nil {
-			return response.RunnerProfileDetailResponse{}, errors.New("gagal upload gambar profil: " + err.Error())
+			return response.RunnerProfileDetailResponse{}, err
 		}
 		imagePtr = &imageUrl
 	}
@@ -100,6 +119,7 @@ func (s *runnerProfileService) CreateOrUpdate(userId uuid.UUID, req request.Crea
 		Id:                uuid.New(),
 		UserId:            userId,
 		AvgPace:           req.AvgPace,
+		AvgPaceManual:     req.AvgPaceManual,
 		PreferredDistance: req.PreferredDistance,
 		PreferredTime:     req.PreferredTime,
 		Latitude:          req.Latitude,
@@ -115,14 +135,14 @@ func (s *runnerProfileService) CreateOrUpdate(userId uuid.UUID, req request.Crea
 		return response.RunnerProfileDetailResponse{}, err
 	}
 
+	// avg_pace yang dikirim hanya nilai awal sampai ada aktivitas, kecuali manual
+	s.refreshDerivedPace(&profile)
+
 	// Mark user as having a profile and update name/gender if provided
 	user.HasProfile = true
 	if req.Name != nil {
 		user.Name = req.Name
 	}
-	if req.Gender != nil {
-		user.Gender = req.Gender
-	}
 	_ = s.userRepo.Update(user)
 
 	return s.buildDetailResponse(&profile, user), nil
@@ -134,11 +154,29 @@ func (s *runnerProfileService) Update(id uuid.UUID, req request.UpdateRunnerProf
 		return response.RunnerProfileDetailResponse{}, err
 	}
 
+	user, err := s.userRepo.FindById(profile.UserId)
+	if err != nil {
+		return response.RunnerProfileDetailResponse{}, errors.New("user tidak ditemukan")
+	}
+	if err := s.womenOnly.ApplyGender(user, req.Gender); err != nil {
+		return response.RunnerProfileDetailResponse{}, err
+	}
+	if req.WomenOnlyMode != nil && *req.WomenOnlyMode {
+		if err := s.womenOnly.CanEnableMode(user); err != nil {
+			return response.RunnerProfileDetailResponse{}, err
+		}
+	}
+
 	if req.AvgPace != nil {
 		if *req.AvgPace < 3.0 || *req.AvgPace > 12.0 {
 			return response.RunnerProfileDetailResponse{}, errors.New("avg_pace harus antara 3.0 - 12.0 min/km")
 		}
 		profile.AvgPace = *req.AvgPace
+		// Pace yang diisi sendiri tidak ditimpa aktivitas kecuali avg_pace_manual=false
+		profile.AvgPaceManual = true
+	}
+	if req.AvgPaceManual != nil {
+		profile.AvgPaceManual = *req.AvgPaceManual
 	}
 	if req.PreferredDistance != nil {
 		profile.PreferredDistance = *req.PreferredDistance
@@ -155,29 +193,31 @@ func (s *runnerProfileService) Update(id uuid.UUID, req request.UpdateRunnerProf
 	if req.WomenOnlyMode != nil {
 		profile.WomenOnlyMode = *req.WomenOnlyMode
 	}
-	if req.Image != nil && *req.Image != "" {
-		// Upload to Cloudinary if base64 image provided
-		imageUrl, err := helper.UploadBase64ToCloudinary(*req.Image, "run-sync/profiles")
-		if err != nil {
-			return response.RunnerProfileDetailResponse{}, errors.New("gagal upload gambar profil: " + err.Error())
-		}
-		profile.Image = &imageUrl
+	if profile.WomenOnlyMode && s.womenOnly.CanEnableMode(user) != nil {
+		// Flag lama dari user yang bukan perempuan dimatikan
+		profile.WomenOnlyMode = false
+	}
+	oldImage, err := s.replaceImage(profile, req.Image)
+	if err != nil {
+		return response.RunnerProfileDetailResponse{}, err
 	}
 	profile.UpdatedAt = time.Now()
 
 	if err := s.repo.Update(profile); err != nil {
 		return response.RunnerProfileDetailResponse{}, err
 	}
+	s.media.Release(oldImage)
+	if req.AvgPace != nil || req.AvgPaceManual != nil {
+		s.refreshDerivedPace(profile)
+	}
 
 	// Update user name and gender if provided
-	user, _ := s.userRepo.FindById(profile.UserId)
 	userUpdated := false
 	if req.Name != nil {
 		user.Name = req.Name
 		userUpdated = true
 	}
 	if req.Gender != nil {
-		user.Gender = req.Gender
 		userUpdated = true
 	}
 	if userUpdated {
@@ -219,11 +259,13 @@ func (s *runnerProfileService) FindAll() ([]response.RunnerProfileResponse, erro
 			Id:                profile.Id.String(),
 			UserId:            profile.UserId.String(),
 			AvgPace:           profile.AvgPace,
+			AvgPaceManual:     profile.AvgPaceManual,
 			PreferredDistance: profile.PreferredDistance,
 			PreferredTime:     profile.PreferredTime,
 			Latitude:          profile.Latitude,
 			Longitude:         profile.Longitude,
 			WomenOnlyMode:     profile.WomenOnlyMode,
+			VerifiedRunner:    isVerifiedRunner(profile.User),
 			Image:             profile.Image,
 			IsActive:          profile.IsActive,
 			CreatedAt:         profile.CreatedAt,
@@ -235,7 +277,83 @@ func (s *runnerProfileService) FindAll() ([]response.RunnerProfileResponse, erro
 }
 
 func (s *runnerProfileService) Delete(id uuid.UUID) error {
-	return s.repo.Delete(id)
+	profile, err := s.repo.FindById(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	// Gambar dari galeri tetap dipakai user_photos sehingga Release tidak menghapusnya
+	if profile.Image != nil {
+		s.media.Release(*profile.Image)
+	}
+	return nil
+}
+
+// refreshDerivedPace menurunkan ulang avg_pace dari aktivitas terbaru bila profil tidak
+// manual, lalu menyalin hasilnya ke profile agar response langsung sesuai.
+func (s *runnerProfileService) refreshDerivedPace(profile *entity.RunnerProfile) {
+	if profile.AvgPaceManual {
+		return
+	}
+	if err := s.pace.Refresh(profile.UserId); err != nil {
+		log.Printf("⚠️ Gagal memperbarui avg_pace profil user %s: %v", profile.UserId, err)
+		return
+	}
+	if fresh, err := s.repo.FindByUserId(profile.UserId); err == nil {
+		profile.AvgPace = fresh.AvgPace
+		profile.UpdatedAt = fresh.UpdatedAt
+	}
+}
+
+// replaceImage menambahkan gambar profil baru (base64) sebagai foto utama galeri dan
+// mengembalikan URL lama yang dilepas setelah profil tersimpan (hanya terhapus jika
+// bukan bagian galeri).
+func (s *runnerProfileService) replaceImage(profile *entity.RunnerProfile, image *string) (string, error) {
+	if image == nil || *image == "" {
+		return "", nil
+	}
+	imageUrl, err := s.addPrimaryPhoto(profile.UserId, *image)
+	if err != nil {
+		return "", err
+	}
+	old := helper.StringValue(profile.Image)
+	profile.Image = &imageUrl
+	return old, nil
+}
+
+func (s *runnerProfileService) addPrimaryPhoto(userId uuid.UUID, base64Image string) (string, error) {
+	if err := s.gallery.CheckCapacity(userId, "profile"); err != nil {
+		return "", err
+	}
+	data, err := helper.DecodeBase64Image(base64Image)
+	if err != nil || len(data) == 0 {
+		return "", errors.New("gambar profil bukan base64 yang valid")
+	}
+	uploaded, err := s.media.UploadImage(data, StorageFolderPhotos, true)
+	if err != nil {
+		return "", fmt.Errorf("gagal upload gambar profil: %w", err)
+	}
+
+	photo := entity.UserPhoto{
+		Id:           uuid.New(),
+		UserId:       userId,
+		Url:          uploaded.Url,
+		ThumbnailUrl: uploaded.ThumbnailUrl,
+		Width:        uploaded.Width,
+		Height:       uploaded.Height,
+		Type:         "profile",
+		IsPrimary:    true,
+		ReviewStatus: entity.PhotoReviewPending,
+		CreatedAt:    time.Now(),
+	}
+	if err := s.gallery.Add(&photo); err != nil {
+		s.media.Release(uploaded.Url)
+		s.media.Release(uploaded.ThumbnailUrl)
+		return "", err
+	}
+	return photo.Url, nil
 }
 
 // -- Response builder --
@@ -262,14 +380,102 @@ func (s *runnerProfileService) buildDetailResponse(profile *entity.RunnerProfile
 		UserId:            profile.UserId.String(),
 		User:              userRes,
 		AvgPace:           profile.AvgPace,
+		AvgPaceManual:     profile.AvgPaceManual,
 		PreferredDistance: profile.PreferredDistance,
 		PreferredTime:     profile.PreferredTime,
 		Latitude:          profile.Latitude,
 		Longitude:         profile.Longitude,
 		WomenOnlyMode:     profile.WomenOnlyMode,
+		VerifiedRunner:    isVerifiedRunner(user),
 		Image:             profile.Image,
 		IsActive:          profile.IsActive,
 		CreatedAt:         profile.CreatedAt,
 		UpdatedAt:         profile.UpdatedAt,
 	}
 }
+
+// GetDiscoveryPreference mengembalikan preferensi tersimpan, atau default (tanpa batas) jika belum ada.
+func (s *runnerProfileService) GetDiscoveryPreference(userId uuid.UUID) (response.DiscoveryPreferenceResponse, error) {
+	profile, err := s.repo.FindByUserId(userId)
+	if err != nil {
+		return response.DiscoveryPreferenceResponse{}, errors.New("profil runner tidak ditemukan")
+	}
+
+	pref, err := s.prefRepo.FindByUserId(userId)
+	if err != nil {
+		pref = &entity.DiscoveryPreference{RunnerProfileId: profile.Id, UserId: userId, GenderPreference: "any"}
+	}
+	return toDiscoveryPreferenceResponse(pref), nil
+}
+
+func (s *runnerProfileService) UpdateDiscoveryPreference(userId uuid.UUID, req request.UpdateDiscoveryPreferenceRequest) (response.DiscoveryPreferenceResponse, error) {
+	profile, err := s.repo.FindByUserId(userId)
+	if err != nil {
+		return response.DiscoveryPreferenceResponse{}, errors.New("profil runner tidak ditemukan")
+	}
+
+	pref, err := s.prefRepo.FindByUserId(userId)
+	if err != nil {
+		pref = &entity.DiscoveryPreference{
+			Id:               uuid.New(),
+			RunnerProfileId:  profile.Id,
+			UserId:           userId,
+			GenderPreference: "any",
+			CreatedAt:        time.Now(),
+		}
+	}
+
+	if req.MaxRadiusKm != nil {
+		pref.MaxRadiusKm = *req.MaxRadiusKm
+	}
+	if req.MinPace != nil {
+		pref.MinPace = *req.MinPace
+	}
+	if req.MaxPace != nil {
+		pref.MaxPace = *req.MaxPace
+	}
+	if req.MinDistance != nil {
+		pref.MinDistance = *req.MinDistance
+	}
+	if req.MaxDistance != nil {
+		pref.MaxDistance = *req.MaxDistance
+	}
+	if req.GenderPreference != nil {
+		pref.GenderPreference = *req.GenderPreference
+	}
+	if req.HideFromDiscovery != nil {
+		pref.HideFromDiscovery = *req.HideFromDiscovery
+	}
+	if req.Incognito != nil {
+		pref.Incognito = *req.Incognito
+	}
+
+	if pref.MinPace > 0 && pref.MaxPace > 0 && pref.MinPace > pref.MaxPace {
+		return response.DiscoveryPreferenceResponse{}, errors.New("min_pace tidak boleh lebih besar dari max_pace")
+	}
+	if pref.MinDistance > 0 && pref.MaxDistance > 0 && pref.MinDistance > pref.MaxDistance {
+		return response.DiscoveryPreferenceResponse{}, errors.New("min_distance tidak boleh lebih besar dari max_distance")
+	}
+
+	pref.UpdatedAt = time.Now()
+	if err := s.prefRepo.Save(pref); err != nil {
+		return response.DiscoveryPreferenceResponse{}, err
+	}
+
+	return toDiscoveryPreferenceResponse(pref), nil
+}
+
+func toDiscoveryPreferenceResponse(pref *entity.DiscoveryPreference) response.DiscoveryPreferenceResponse {
+	return response.DiscoveryPreferenceResponse{
+		RunnerProfileId:   pref.RunnerProfileId.String(),
+		MaxRadiusKm:       pref.MaxRadiusKm,
+		MinPace:           pref.MinPace,
+		MaxPace:           pref.MaxPace,
+		MinDistance:       pref.MinDistance,
+		MaxDistance:       pref.MaxDistance,
+		GenderPreference:  pref.GenderPreference,
+		HideFromDiscovery: pref.HideFromDiscovery,
+		Incognito:         pref.Incognito,
+		UpdatedAt:         pref.UpdatedAt,
+	}
+}
@@ -2,122 +2,348 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"run-sync/config"
 	"run-sync/data/request"
 	"run-sync/data/response"
 	"run-sync/entity"
 	"run-sync/helper"
 	"run-sync/repository"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-func toUserPhotoResponse(photo *entity.UserPhoto) response.UserPhotoResponse {
-	return response.UserPhotoResponse{
-		Id:        photo.Id.String(),
-		UserId:    photo.UserId.String(),
-		Url:       photo.Url,
-		Type:      photo.Type,
-		IsPrimary: photo.IsPrimary,
-		CreatedAt: photo.CreatedAt,
+// toUserPhotoResponse memberi URL bertanda tangan (sementara) untuk foto verifikasi
+// yang disimpan di folder privat. Hanya untuk respons ke pemilik foto; lookup publik
+// tidak pernah mengembalikan foto verifikasi.
+func (s *userPhotoService) toUserPhotoResponse(photo *entity.UserPhoto) response.UserPhotoResponse {
+	res := response.UserPhotoResponse{
+		Id:           photo.Id.String(),
+		UserId:       photo.UserId.String(),
+		Url:          photo.Url,
+		ThumbnailUrl: photo.ThumbnailUrl,
+		Width:        photo.Width,
+		Height:       photo.Height,
+		Type:         photo.Type,
+		IsPrimary:    photo.IsPrimary,
+		Position:     photo.Position,
+		Caption:      photo.Caption,
+		CreatedAt:    photo.CreatedAt,
 	}
+	if photo.Type == "verification" {
+		res.Url = s.media.SignedURL(photo.Url)
+		res.ReviewStatus = photo.ReviewStatus
+	}
+	return res
 }
 
 type UserPhotoService interface {
 	Create(userId uuid.UUID, req request.UploadUserPhotoRequest) (response.UserPhotoResponse, error)
-	Update(id uuid.UUID, req request.UpdateUserPhotoRequest) (response.UserPhotoResponse, error)
+	CreateFromFile(userId uuid.UUID, req request.UploadUserPhotoFormRequest, file io.Reader) (response.UserPhotoResponse, error)
+	RequestUpload(userId uuid.UUID, req request.RequestPhotoUploadRequest) (response.PhotoUploadResponse, error)
+	CompleteUpload(userId, uploadId uuid.UUID) (response.UserPhotoResponse, error)
+	Update(userId, id uuid.UUID, req request.UpdateUserPhotoRequest) (response.UserPhotoResponse, error)
+	Reorder(userId uuid.UUID, req request.ReorderUserPhotosRequest) ([]response.UserPhotoResponse, error)
+	// FindById, FindByUserId dan FindPrimaryPhoto adalah lookup publik tanpa foto verifikasi.
 	FindById(id uuid.UUID) (response.UserPhotoResponse, error)
 	FindByUserId(userId uuid.UUID) ([]response.UserPhotoResponse, error)
 	FindPrimaryPhoto(userId uuid.UUID) (response.UserPhotoResponse, error)
-	Delete(id uuid.UUID) error
+	// FindMyPhotos mengembalikan semua foto milik user, termasuk foto verifikasi.
+	FindMyPhotos(userId uuid.UUID) ([]response.UserPhotoResponse, error)
+	Delete(userId, id uuid.UUID) error
+	StartFaceChallenge(userId uuid.UUID) (response.FaceChallengeResponse, error)
 	VerifyFace(userId uuid.UUID, req request.FaceVerifyRequest) (response.FaceVerifyResponse, error)
+	GetFaceVerificationStatus(userId uuid.UUID) (response.FaceVerificationStatusResponse, error)
+	FaceReviewQueue(page, limit int) ([]response.FaceReviewResponse, int64, error)
+	ReviewFaceVerification(reviewerId, attemptId uuid.UUID, req request.ReviewFaceVerificationRequest) (response.FaceReviewResponse, error)
 }
 
+// ErrPhotoUploadNotFound dikembalikan saat sesi upload tidak ada atau milik user lain.
+var ErrPhotoUploadNotFound = errors.New("sesi upload tidak ditemukan")
+
 type userPhotoService struct {
-	repo     repository.UserPhotoRepository
-	userRepo repository.UserRepository
+	repo       repository.UserPhotoRepository
+	userRepo   repository.UserRepository
+	faceRepo   repository.FaceVerificationRepository
+	uploadRepo repository.PhotoUploadRepository
+	gallery    PhotoGallery
+	notifSvc   NotificationService
+	media      MediaStorageService
+	face       helper.FaceVerifier
+	faceCfg    *config.FaceVerificationConfig
+	photoCfg   *config.PhotoConfig
+	db         *gorm.DB
 }
 
-func NewUserPhotoService(repo repository.UserPhotoRepository, userRepo repository.UserRepository) UserPhotoService {
-	return &userPhotoService{repo: repo, userRepo: userRepo}
+func NewUserPhotoService(repo repository.UserPhotoRepository, userRepo repository.UserRepository, faceRepo repository.FaceVerificationRepository, uploadRepo repository.PhotoUploadRepository, gallery PhotoGallery, notifSvc NotificationService, media MediaStorageService, face helper.FaceVerifier, faceCfg *config.FaceVerificationConfig, photoCfg *config.PhotoConfig, db *gorm.DB) UserPhotoService {
+	return &userPhotoService{repo: repo, userRepo: userRepo, faceRepo: faceRepo, uploadRepo: uploadRepo, gallery: gallery, notifSvc: notifSvc, media: media, face: face, faceCfg: faceCfg, photoCfg: photoCfg, db: db}
 }
 
+// Create menerima foto base64 lewat JSON (dipertahankan untuk client lama).
 func (s *userPhotoService) Create(userId uuid.UUID, req request.UploadUserPhotoRequest) (response.UserPhotoResponse, error) {
 	log.Printf("[UserPhoto] Create — type=%s isPrimary=%v imageLen=%d", req.Type, req.IsPrimary, len(req.Image))
 
-	var faceWarning string
-	// Jika tipe verification, coba validasi wajah — tapi jangan gagal jika tidak terdeteksi
+	image, err := helper.DecodeBase64Image(req.Image)
+	if err != nil || len(image) == 0 {
+		return response.UserPhotoResponse{}, errors.New("gambar bukan base64 yang valid")
+	}
+	return s.createPhoto(userId, image, req.Type, req.IsPrimary, req.Caption)
+}
+
+// CreateFromFile menerima foto dari multipart/form-data.
+func (s *userPhotoService) CreateFromFile(userId uuid.UUID, req request.UploadUserPhotoFormRequest, file io.Reader) (response.UserPhotoResponse, error) {
+	maxBytes := s.photoCfg.MaxBytes
+	image, err := io.ReadAll(io.LimitReader(file, maxBytes+1))
+	if err != nil {
+		return response.UserPhotoResponse{}, errors.New("gagal membaca file")
+	}
+	if int64(len(image)) > maxBytes {
+		return response.UserPhotoResponse{}, fmt.Errorf("%w (maksimal %d KB)", helper.ErrImageTooLarge, maxBytes/1024)
+	}
+	log.Printf("[UserPhoto] CreateFromFile — type=%s isPrimary=%v size=%d", req.Type, req.IsPrimary, len(image))
+	return s.createPhoto(userId, image, req.Type, req.IsPrimary, req.Caption)
+}
+
+// RequestUpload membuat sesi upload langsung ke storage. Client mengunggah file ke
+// target yang dikembalikan lalu memanggil CompleteUpload.
+func (s *userPhotoService) RequestUpload(userId uuid.UUID, req request.RequestPhotoUploadRequest) (response.PhotoUploadResponse, error) {
+	caption, err := validatePhotoMeta(req.Type, req.IsPrimary, req.Caption)
+	if err != nil {
+		return response.PhotoUploadResponse{}, err
+	}
+	if err := s.gallery.CheckCapacity(userId, req.Type); err != nil {
+		return response.PhotoUploadResponse{}, err
+	}
 	if req.Type == "verification" {
-		if err := helper.DetectFrontFace(req.Image); err != nil {
-			log.Printf("[UserPhoto] DetectFrontFace warning: %v", err)
-			faceWarning = "Foto kurang jelas, pastikan wajah tampak jelas dan pencahayaan cukup. Foto tetap tersimpan namun verifikasi mungkin tidak akurat."
+		user, err := s.userRepo.FindById(userId)
+		if err != nil {
+			return response.PhotoUploadResponse{}, errors.New("user tidak ditemukan")
+		}
+		if faceStatus(user) == entity.FacePending {
+			return response.PhotoUploadResponse{}, errors.New("verifikasi wajah kamu sedang ditinjau, foto verifikasi belum bisa diganti")
 		}
 	}
 
-	// Upload image to Cloudinary
-	imageUrl, err := helper.UploadBase64ToCloudinary(req.Image, "run-sync/photos")
+	staged, err := s.media.StageUpload(strings.ToLower(req.ContentType))
 	if err != nil {
-		log.Printf("[UserPhoto] Cloudinary error: %v", err)
-		return response.UserPhotoResponse{}, errors.New("gagal upload gambar ke Cloudinary: " + err.Error())
+		return response.PhotoUploadResponse{}, err
+	}
+
+	upload := entity.PhotoUpload{
+		Id:          uuid.New(),
+		UserId:      userId,
+		Key:         staged.Key,
+		ContentType: strings.ToLower(req.ContentType),
+		Type:        req.Type,
+		IsPrimary:   req.IsPrimary,
+		Caption:     caption,
+		Status:      entity.PhotoUploadPending,
+		ExpiresAt:   time.Now().Add(s.photoCfg.UploadURLTTL),
 	}
-	if imageUrl == "" {
-		return response.UserPhotoResponse{}, errors.New("gambar tidak boleh kosong")
+	if err := s.uploadRepo.Create(&upload); err != nil {
+		return response.PhotoUploadResponse{}, err
 	}
 
-	// If this is primary, set other photos to non-primary
-	if req.IsPrimary {
-		photos, _ := s.repo.FindByUserId(userId)
-		for _, photo := range photos {
-			photo.IsPrimary = false
-			s.repo.Update(&photo)
-		}
+	return response.PhotoUploadResponse{
+		UploadId:  upload.Id.String(),
+		Method:    staged.Target.Method,
+		Url:       staged.Target.URL,
+		Headers:   staged.Target.Headers,
+		Fields:    staged.Target.Fields,
+		FileField: staged.Target.FileField,
+		MaxBytes:  s.photoCfg.MaxBytes,
+		ExpiresAt: upload.ExpiresAt,
+	}, nil
+}
+
+// CompleteUpload memproses file yang sudah diunggah langsung ke storage: validasi,
+// buang EXIF, buat thumbnail, lalu simpan sebagai UserPhoto. File mentah dihapus.
+func (s *userPhotoService) CompleteUpload(userId, uploadId uuid.UUID) (response.UserPhotoResponse, error) {
+	upload, err := s.uploadRepo.FindById(uploadId)
+	if err != nil || upload.UserId != userId {
+		return response.UserPhotoResponse{}, ErrPhotoUploadNotFound
+	}
+	if upload.Status != entity.PhotoUploadPending {
+		return response.UserPhotoResponse{}, errors.New("upload ini sudah diproses")
+	}
+	// URL upload bisa dipakai sampai ExpiresAt; beri jeda agar upload yang baru selesai tetap diterima
+	if time.Now().After(upload.ExpiresAt.Add(s.photoCfg.UploadURLTTL)) {
+		upload.Status = entity.PhotoUploadFailed
+		s.uploadRepo.Update(upload)
+		s.media.DiscardStaged(upload.Key)
+		return response.UserPhotoResponse{}, errors.New("sesi upload sudah kedaluwarsa, minta URL upload baru")
 	}
 
-	photo := entity.UserPhoto{
-		Id:        uuid.New(),
-		UserId:    userId,
-		Url:       imageUrl,
-		Type:      req.Type,
-		IsPrimary: req.IsPrimary,
-		CreatedAt: time.Now(),
+	image, err := s.media.FetchStaged(upload.Key)
+	if errors.Is(err, helper.ErrStorageObjectNotFound) {
+		return response.UserPhotoResponse{}, errors.New("file belum diunggah ke storage")
+	}
+	if err != nil {
+		if errors.Is(err, helper.ErrImageTooLarge) {
+			upload.Status = entity.PhotoUploadFailed
+			s.uploadRepo.Update(upload)
+			s.media.DiscardStaged(upload.Key)
+		}
+		return response.UserPhotoResponse{}, err
 	}
 
-	if err := s.repo.Create(&photo); err != nil {
+	res, err := s.createPhoto(userId, image, upload.Type, upload.IsPrimary, upload.Caption)
+	if err != nil {
+		// File yang tidak lolos validasi tidak akan berubah, tutup sesinya
+		if errors.Is(err, helper.ErrImageTooLarge) || errors.Is(err, helper.ErrImageTypeNotAllowed) || errors.Is(err, helper.ErrImageDimensions) {
+			upload.Status = entity.PhotoUploadFailed
+			s.uploadRepo.Update(upload)
+			s.media.DiscardStaged(upload.Key)
+		}
 		return response.UserPhotoResponse{}, err
 	}
 
-	result := toUserPhotoResponse(&photo)
-	result.Warning = faceWarning
-	return result, nil
+	photoId, _ := uuid.Parse(res.Id)
+	upload.Status = entity.PhotoUploadCompleted
+	upload.PhotoId = &photoId
+	if err := s.uploadRepo.Update(upload); err != nil {
+		log.Printf("[UserPhoto] gagal menandai upload %s selesai: %v", upload.Id, err)
+	}
+	s.media.DiscardStaged(upload.Key)
+	return res, nil
 }
 
-func (s *userPhotoService) Update(id uuid.UUID, req request.UpdateUserPhotoRequest) (response.UserPhotoResponse, error) {
-	photo, err := s.repo.FindById(id)
+// createPhoto adalah jalur bersama semua cara upload: cek wajah untuk foto verifikasi,
+// lalu validasi, strip EXIF dan thumbnail lewat MediaStorageService.UploadImage.
+func (s *userPhotoService) createPhoto(userId uuid.UUID, image []byte, photoType string, isPrimary bool, caption *string) (response.UserPhotoResponse, error) {
+	caption, err := validatePhotoMeta(photoType, isPrimary, caption)
 	if err != nil {
 		return response.UserPhotoResponse{}, err
 	}
+	if err := s.gallery.CheckCapacity(userId, photoType); err != nil {
+		return response.UserPhotoResponse{}, err
+	}
 
-	if req.Type != nil {
-		photo.Type = *req.Type
-	}
-	if req.IsPrimary != nil {
-		// If setting as primary, set others to non-primary
-		if *req.IsPrimary {
-			photos, _ := s.repo.FindByUserId(photo.UserId)
-			for _, p := range photos {
-				p.IsPrimary = false
-				s.repo.Update(&p)
-			}
+	var user *entity.User
+	// Foto verifikasi wajib menampilkan tepat satu wajah menghadap depan
+	if photoType == "verification" {
+		user, err = s.userRepo.FindById(userId)
+		if err != nil {
+			return response.UserPhotoResponse{}, errors.New("user tidak ditemukan")
+		}
+		if faceStatus(user) == entity.FacePending {
+			return response.UserPhotoResponse{}, errors.New("verifikasi wajah kamu sedang ditinjau, foto verifikasi belum bisa diganti")
+		}
+		reason, err := s.checkFrontFace(image)
+		switch {
+		case errors.Is(err, helper.ErrFaceServiceUnavailable) && s.faceCfg.SkipWhenUnavailable:
+			// Policy skip: foto diterima, wajah tetap dicek saat menjawab challenge
+			log.Printf("[UserPhoto] provider %s tidak tersedia, validasi wajah dilewati: %v", s.face.Name(), err)
+		case errors.Is(err, helper.ErrFaceServiceUnavailable):
+			return response.UserPhotoResponse{}, errors.New("layanan verifikasi wajah sedang tidak tersedia, coba lagi nanti")
+		case err != nil:
+			return response.UserPhotoResponse{}, errors.New("gagal memeriksa wajah: " + err.Error())
+		case reason != "":
+			return response.UserPhotoResponse{}, errors.New("foto verifikasi ditolak: " + reason + ". Pastikan wajah tampak jelas dan pencahayaan cukup")
 		}
-		photo.IsPrimary = *req.IsPrimary
 	}
 
-	if err := s.repo.Update(photo); err != nil {
+	// Foto verifikasi hanya untuk reviewer dan pemiliknya: folder privat, tanpa thumbnail publik
+	folder, withThumbnail := StorageFolderPhotos, true
+	if photoType == "verification" {
+		folder, withThumbnail = StorageFolderVerifications, false
+	}
+	uploaded, err := s.media.UploadImage(image, folder, withThumbnail)
+	if err != nil {
+		log.Printf("[UserPhoto] storage error: %v", err)
+		return response.UserPhotoResponse{}, fmt.Errorf("gagal upload gambar: %w", err)
+	}
+
+	photo := entity.UserPhoto{
+		Id:           uuid.New(),
+		UserId:       userId,
+		Url:          uploaded.Url,
+		ThumbnailUrl: uploaded.ThumbnailUrl,
+		Width:        uploaded.Width,
+		Height:       uploaded.Height,
+		Type:         photoType,
+		IsPrimary:    isPrimary,
+		Caption:      caption,
+		ReviewStatus: entity.PhotoReviewPending,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := s.gallery.Add(&photo); err != nil {
+		// File sudah terunggah tapi tidak jadi dipakai
+		s.media.Release(uploaded.Url)
+		s.media.Release(uploaded.ThumbnailUrl)
 		return response.UserPhotoResponse{}, err
 	}
 
-	return toUserPhotoResponse(photo), nil
+	// Foto verifikasi baru membatalkan status sebelumnya, user harus menjawab challenge lagi
+	if user != nil {
+		if err := s.faceRepo.ExpireOpenChallenges(userId); err != nil {
+			log.Printf("[UserPhoto] gagal menutup challenge lama: %v", err)
+		}
+		if faceStatus(user) != entity.FaceUnverified {
+			user.FaceVerificationStatus = entity.FaceUnverified
+			user.FaceVerifiedAt = nil
+			if err := s.userRepo.Update(user); err != nil {
+				return response.UserPhotoResponse{}, err
+			}
+		}
+	}
+
+	return s.toUserPhotoResponse(&photo), nil
+}
+
+func (s *userPhotoService) Update(userId, id uuid.UUID, req request.UpdateUserPhotoRequest) (response.UserPhotoResponse, error) {
+	photo, err := s.repo.FindById(id)
+	if err != nil || photo.UserId != userId {
+		return response.UserPhotoResponse{}, errors.New("foto tidak ditemukan")
+	}
+
+	if req.Type != nil {
+		if err := s.gallery.ChangeType(photo, *req.Type); err != nil {
+			return response.UserPhotoResponse{}, err
+		}
+	}
+	if req.Caption != nil {
+		caption, err := validatePhotoMeta(photo.Type, false, req.Caption)
+		if err != nil {
+			return response.UserPhotoResponse{}, err
+		}
+		photo.Caption = caption
+		if err := s.db.Model(photo).Update("caption", caption).Error; err != nil {
+			return response.UserPhotoResponse{}, err
+		}
+	}
+	if req.IsPrimary != nil && *req.IsPrimary != photo.IsPrimary {
+		if !*req.IsPrimary {
+			return response.UserPhotoResponse{}, errors.New("pilih foto lain sebagai foto utama untuk mengganti foto utama")
+		}
+		if err := s.gallery.SetPrimary(userId, photo.Id); err != nil {
+			return response.UserPhotoResponse{}, err
+		}
+		photo.IsPrimary = true
+	}
+
+	return s.toUserPhotoResponse(photo), nil
+}
+
+func (s *userPhotoService) Reorder(userId uuid.UUID, req request.ReorderUserPhotosRequest) ([]response.UserPhotoResponse, error) {
+	ids := make([]uuid.UUID, 0, len(req.PhotoIds))
+	for _, raw := range req.PhotoIds {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, ErrInvalidPhotoOrder
+		}
+		ids = append(ids, id)
+	}
+	if err := s.gallery.Reorder(userId, req.Type, ids); err != nil {
+		return nil, err
+	}
+	return s.FindMyPhotos(userId)
 }
 
 func (s *userPhotoService) FindById(id uuid.UUID) (response.UserPhotoResponse, error) {
@@ -125,11 +351,22 @@ func (s *userPhotoService) FindById(id uuid.UUID) (response.UserPhotoResponse, e
 	if err != nil {
 		return response.UserPhotoResponse{}, err
 	}
+	if photo.Type == "verification" {
+		return response.UserPhotoResponse{}, gorm.ErrRecordNotFound
+	}
 
-	return toUserPhotoResponse(photo), nil
+	return s.toUserPhotoResponse(photo), nil
 }
 
 func (s *userPhotoService) FindByUserId(userId uuid.UUID) ([]response.UserPhotoResponse, error) {
+	return s.findPhotos(userId, false)
+}
+
+func (s *userPhotoService) FindMyPhotos(userId uuid.UUID) ([]response.UserPhotoResponse, error) {
+	return s.findPhotos(userId, true)
+}
+
+func (s *userPhotoService) findPhotos(userId uuid.UUID, withVerification bool) ([]response.UserPhotoResponse, error) {
 	photos, err := s.repo.FindByUserId(userId)
 	if err != nil {
 		return nil, err
@@ -137,8 +374,11 @@ func (s *userPhotoService) FindByUserId(userId uuid.UUID) ([]response.UserPhotoR
 
 	var responses []response.UserPhotoResponse
 	for _, photo := range photos {
+		if photo.Type == "verification" && !withVerification {
+			continue
+		}
 		p := photo
-		responses = append(responses, toUserPhotoResponse(&p))
+		responses = append(responses, s.toUserPhotoResponse(&p))
 	}
 
 	return responses, nil
@@ -149,52 +389,42 @@ func (s *userPhotoService) FindPrimaryPhoto(userId uuid.UUID) (response.UserPhot
 	if err != nil {
 		return response.UserPhotoResponse{}, err
 	}
+	if photo.Type == "verification" {
+		return response.UserPhotoResponse{}, gorm.ErrRecordNotFound
+	}
 
-	return toUserPhotoResponse(photo), nil
+	return s.toUserPhotoResponse(photo), nil
 }
 
-func (s *userPhotoService) Delete(id uuid.UUID) error {
+func (s *userPhotoService) Delete(userId, id uuid.UUID) error {
 	photo, err := s.repo.FindById(id)
-	if err != nil {
-		return err
+	if err != nil || photo.UserId != userId {
+		return errors.New("foto tidak ditemukan")
 	}
 	if photo.Type == "verification" {
 		return errors.New("foto verifikasi tidak dapat dihapus")
 	}
-	return s.repo.Delete(id)
-}
-
-func (s *userPhotoService) VerifyFace(userId uuid.UUID, req request.FaceVerifyRequest) (response.FaceVerifyResponse, error) {
-	// Ambil foto verifikasi milik user
-	photo, err := s.repo.FindVerificationPhoto(userId)
-	if err != nil {
-		return response.FaceVerifyResponse{}, errors.New("foto verifikasi belum diunggah, silakan upload foto verifikasi terlebih dahulu")
+	if err := s.gallery.Remove(photo); err != nil {
+		return err
 	}
+	s.media.Release(photo.Url)
+	s.media.Release(photo.ThumbnailUrl)
+	return nil
+}
 
-	// Bandingkan wajah kamera dengan foto verifikasi
-	similarity, matched, err := helper.VerifyFaces(req.Image, photo.Url)
-	if err != nil {
-		return response.FaceVerifyResponse{}, errors.New("gagal melakukan verifikasi wajah: " + err.Error())
+// validatePhotoMeta memeriksa aturan galeri yang tidak bisa dinyatakan di binding:
+// hanya foto profile yang bisa menjadi foto utama dan hanya foto run yang punya caption.
+// Caption kosong dinormalisasi menjadi nil.
+func validatePhotoMeta(photoType string, isPrimary bool, caption *string) (*string, error) {
+	if isPrimary && photoType != "profile" {
+		return nil, ErrPrimaryNotProfile
 	}
-
-	result := response.FaceVerifyResponse{
-		Matched:    matched,
-		Similarity: similarity,
+	if caption == nil || strings.TrimSpace(*caption) == "" {
+		return nil, nil
 	}
-	if matched {
-		p := toUserPhotoResponse(photo)
-		result.Photo = &p
-
-		// Set akun sebagai terverifikasi setelah wajah cocok
-		user, err := s.userRepo.FindById(userId)
-		if err != nil {
-			return response.FaceVerifyResponse{}, errors.New("gagal mengambil data user: " + err.Error())
-		}
-		user.IsVerified = true
-		if err := s.userRepo.Update(user); err != nil {
-			return response.FaceVerifyResponse{}, errors.New("gagal memperbarui status verifikasi: " + err.Error())
-		}
-		result.IsVerified = true
+	if photoType != "run" {
+		return nil, errors.New("caption hanya bisa ditambahkan pada foto run")
 	}
-	return result, nil
+	trimmed := strings.TrimSpace(*caption)
+	return &trimmed, nil
 }
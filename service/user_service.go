@@ -7,6 +7,7 @@ import (
 	"run-sync/entity"
 	"run-sync/helper"
 	"run-sync/repository"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,19 +19,25 @@ type UserService interface {
 	FindById(id uuid.UUID) (response.UserDetailResponse, error)
 	FindByEmail(email string) (response.UserResponse, error)
 	FindByPhone(phone string) (response.UserResponse, error)
+	FindByEmailOrPhone(identifier string) (response.UserResponse, error)
 	FindAll() ([]response.UserResponse, error)
 	Delete(id uuid.UUID) error
 	ChangePassword(id uuid.UUID, req request.ChangePasswordRequest) error
+	ResetPassword(id uuid.UUID, newPassword string) error
+	RequestEmailChange(id uuid.UUID, newEmail string) (string, error)
+	UpdateOTPChannel(id uuid.UUID, channel *string) error
+	ConfirmEmailChange(id uuid.UUID) (oldEmail *string, user response.UserResponse, err error)
 	Login(req request.LoginRequest) (response.UserResponse, error)
 	VerifyAndActivate(phoneNumber string) (response.UserResponse, error)
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo      repository.UserRepository
+	womenOnly WomenOnlyPolicy
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(repo repository.UserRepository, womenOnly WomenOnlyPolicy) UserService {
+	return &userService{repo: repo, womenOnly: womenOnly}
 }
 
 func (s *userService) Create(req request.CreateUserRequest) (response.UserDetailResponse, error) {
@@ -44,6 +51,11 @@ func (s *userService) Create(req request.CreateUserRequest) (response.UserDetail
 		return response.UserDetailResponse{}, errors.New("nomor telepon sudah terdaftar")
 	}
 
+	gender, err := helper.NormalizeGender(req.Gender)
+	if err != nil {
+		return response.UserDetailResponse{}, err
+	}
+
 	hashedPassword := helper.HashPassword(req.Password)
 
 	user := entity.User{
@@ -51,7 +63,8 @@ func (s *userService) Create(req request.CreateUserRequest) (response.UserDetail
 		Name:        req.Name,
 		Email:       req.Email,
 		PhoneNumber: req.PhoneNumber,
-		Gender:      req.Gender,
+		OTPChannel:  req.OTPChannel,
+		Gender:      gender,
 		Password:    hashedPassword,
 		IsVerified:  false,
 		IsActive:    false,
@@ -64,16 +77,19 @@ func (s *userService) Create(req request.CreateUserRequest) (response.UserDetail
 	}
 
 	return response.UserDetailResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
 
@@ -86,11 +102,8 @@ func (s *userService) Update(id uuid.UUID, req request.UpdateUserRequest) (respo
 	if req.Name != nil {
 		user.Name = req.Name
 	}
-	if req.Gender != nil {
-		user.Gender = req.Gender
-	}
-	if req.PendingEmail != nil {
-		user.PendingEmail = req.PendingEmail
+	if err := s.womenOnly.ApplyGender(user, req.Gender); err != nil {
+		return response.UserResponse{}, err
 	}
 	user.UpdatedAt = time.Now()
 
@@ -99,16 +112,19 @@ func (s *userService) Update(id uuid.UUID, req request.UpdateUserRequest) (respo
 	}
 
 	return response.UserResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
 
@@ -119,16 +135,19 @@ func (s *userService) FindById(id uuid.UUID) (response.UserDetailResponse, error
 	}
 
 	return response.UserDetailResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
 
@@ -139,16 +158,19 @@ func (s *userService) FindByEmail(email string) (response.UserResponse, error) {
 	}
 
 	return response.UserResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
 
@@ -159,16 +181,42 @@ func (s *userService) FindByPhone(phone string) (response.UserResponse, error) {
 	}
 
 	return response.UserResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}, nil
+}
+
+func (s *userService) FindByEmailOrPhone(identifier string) (response.UserResponse, error) {
+	user, err := s.repo.FindByEmailOrPhone(identifier)
+	if err != nil {
+		return response.UserResponse{}, err
+	}
+
+	return response.UserResponse{
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
 
@@ -181,16 +229,19 @@ func (s *userService) FindAll() ([]response.UserResponse, error) {
 	var responses []response.UserResponse
 	for _, user := range users {
 		responses = append(responses, response.UserResponse{
-			Id:          user.Id.String(),
-			Name:        user.Name,
-			Email:       user.Email,
-			PhoneNumber: user.PhoneNumber,
-			Gender:      user.Gender,
-			HasProfile:  user.HasProfile,
-			IsVerified:  user.IsVerified,
-			IsActive:    user.IsActive,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
+			Id:                  user.Id.String(),
+			Name:                user.Name,
+			Email:               user.Email,
+			EmailVerified:       user.EmailVerified,
+			PhoneNumber:         user.PhoneNumber,
+			OTPChannel:          user.OTPChannel,
+			Gender:              user.Gender,
+			HasProfile:          user.HasProfile,
+			IsVerified:          user.IsVerified,
+			IsActive:            user.IsActive,
+			DeletionScheduledAt: user.DeletionScheduledAt,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.UpdatedAt,
 		})
 	}
 
@@ -217,6 +268,108 @@ func (s *userService) ChangePassword(id uuid.UUID, req request.ChangePasswordReq
 	return s.repo.Update(user)
 }
 
+// ResetPassword mengganti password tanpa password lama. Hanya dipanggil setelah
+// user lolos verifikasi OTP reset password.
+func (s *userService) ResetPassword(id uuid.UUID, newPassword string) error {
+	user, err := s.repo.FindById(id)
+	if err != nil {
+		return err
+	}
+
+	user.Password = helper.HashPassword(newPassword)
+	user.UpdatedAt = time.Now()
+
+	return s.repo.Update(user)
+}
+
+// UpdateOTPChannel menyimpan preferensi channel pengiriman OTP (nil = ikuti urutan default).
+func (s *userService) UpdateOTPChannel(id uuid.UUID, channel *string) error {
+	user, err := s.repo.FindById(id)
+	if err != nil {
+		return errors.New("user tidak ditemukan")
+	}
+
+	user.OTPChannel = channel
+	user.UpdatedAt = time.Now()
+
+	return s.repo.Update(user)
+}
+
+// RequestEmailChange menyimpan email baru sebagai PendingEmail sampai dikonfirmasi
+// dengan kode OTP. Email yang sama boleh diminta ulang jika belum terverifikasi.
+func (s *userService) RequestEmailChange(id uuid.UUID, newEmail string) (string, error) {
+	user, err := s.repo.FindById(id)
+	if err != nil {
+		return "", errors.New("user tidak ditemukan")
+	}
+
+	newEmail = strings.ToLower(strings.TrimSpace(newEmail))
+	isCurrent := user.Email != nil && strings.EqualFold(*user.Email, newEmail)
+	if isCurrent && user.EmailVerified {
+		return "", errors.New("email sudah terverifikasi")
+	}
+	if !isCurrent && s.repo.IsDuplicateEmail(newEmail) {
+		return "", errors.New("email sudah terdaftar")
+	}
+
+	user.PendingEmail = &newEmail
+	user.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(user); err != nil {
+		return "", err
+	}
+
+	return newEmail, nil
+}
+
+// ConfirmEmailChange memindahkan PendingEmail ke Email dan menandainya terverifikasi.
+// oldEmail berisi email sebelumnya (nil jika belum ada) untuk dikirimi pemberitahuan.
+func (s *userService) ConfirmEmailChange(id uuid.UUID) (*string, response.UserResponse, error) {
+	user, err := s.repo.FindById(id)
+	if err != nil {
+		return nil, response.UserResponse{}, errors.New("user tidak ditemukan")
+	}
+
+	if user.PendingEmail == nil {
+		return nil, response.UserResponse{}, errors.New("tidak ada permintaan perubahan email")
+	}
+
+	oldEmail := user.Email
+	isCurrent := oldEmail != nil && strings.EqualFold(*oldEmail, *user.PendingEmail)
+	if !isCurrent && s.repo.IsDuplicateEmail(*user.PendingEmail) {
+		return nil, response.UserResponse{}, errors.New("email sudah terdaftar")
+	}
+
+	user.Email = user.PendingEmail
+	user.PendingEmail = nil
+	user.EmailVerified = true
+	user.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(user); err != nil {
+		return nil, response.UserResponse{}, err
+	}
+
+	if isCurrent {
+		oldEmail = nil
+	}
+
+	return oldEmail, response.UserResponse{
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+	}, nil
+}
+
 func (s *userService) Login(req request.LoginRequest) (response.UserResponse, error) {
 	// Find user by email or phone number
 	user, err := s.repo.FindByEmailOrPhone(req.Identifier)
@@ -235,16 +388,19 @@ func (s *userService) Login(req request.LoginRequest) (response.UserResponse, er
 	}
 
 	return response.UserResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
 
@@ -264,15 +420,18 @@ func (s *userService) VerifyAndActivate(phoneNumber string) (response.UserRespon
 	}
 
 	return response.UserResponse{
-		Id:          user.Id.String(),
-		Name:        user.Name,
-		Email:       user.Email,
-		PhoneNumber: user.PhoneNumber,
-		Gender:      user.Gender,
-		HasProfile:  user.HasProfile,
-		IsVerified:  user.IsVerified,
-		IsActive:    user.IsActive,
-		CreatedAt:   user.CreatedAt,
-		UpdatedAt:   user.UpdatedAt,
+		Id:                  user.Id.String(),
+		Name:                user.Name,
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerified,
+		PhoneNumber:         user.PhoneNumber,
+		OTPChannel:          user.OTPChannel,
+		Gender:              user.Gender,
+		HasProfile:          user.HasProfile,
+		IsVerified:          user.IsVerified,
+		IsActive:            user.IsActive,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
 	}, nil
 }
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"run-sync/entity"
+	"run-sync/helper"
+)
+
+var (
+	// ErrWomenOnly dikembalikan saat user bukan perempuan mencoba masuk ruang women-only.
+	ErrWomenOnly = errors.New("fitur ini hanya untuk perempuan")
+	// ErrFaceReviewRequired dikembalikan saat user belum berstatus verified runner.
+	ErrFaceReviewRequired = errors.New("wajah kamu harus terverifikasi sebelum masuk grup women-only")
+	// ErrGenderLocked dikembalikan saat gender diubah setelah verifikasi wajah.
+	ErrGenderLocked = errors.New("gender tidak dapat diubah setelah verifikasi wajah, hubungi support")
+)
+
+// WomenOnlyPolicy menegakkan ruang women-only di server berdasarkan gender yang
+// sudah dinormalisasi, bukan flag yang diisi sendiri oleh user.
+type WomenOnlyPolicy interface {
+	// CanEnterGroup: syarat bergabung / membuat grup women-only.
+	CanEnterGroup(user *entity.User) error
+	// CanEnableMode: syarat mengaktifkan WomenOnlyMode di runner profile.
+	CanEnableMode(user *entity.User) error
+	// CanMatch: jika salah satu pihak memakai WomenOnlyMode, pihak lain harus perempuan.
+	CanMatch(sender, receiver *entity.User, senderProfile, receiverProfile *entity.RunnerProfile) error
+	// ApplyGender menormalisasi gender baru dan menolak perubahan setelah wajah
+	// terverifikasi.
+	ApplyGender(user *entity.User, raw *string) error
+}
+
+type womenOnlyPolicy struct {
+	requireFaceVerified bool
+}
+
+// NewWomenOnlyPolicy membaca WOMEN_ONLY_REQUIRE_FACE_VERIFIED (default false).
+func NewWomenOnlyPolicy() WomenOnlyPolicy {
+	return &womenOnlyPolicy{
+		requireFaceVerified: os.Getenv("WOMEN_ONLY_REQUIRE_FACE_VERIFIED") == "true",
+	}
+}
+
+func (p *womenOnlyPolicy) CanEnterGroup(user *entity.User) error {
+	if !helper.IsFemale(user.Gender) {
+		return ErrWomenOnly
+	}
+	if p.requireFaceVerified && !isVerifiedRunner(user) {
+		return ErrFaceReviewRequired
+	}
+	return nil
+}
+
+func (p *womenOnlyPolicy) CanEnableMode(user *entity.User) error {
+	if !helper.IsFemale(user.Gender) {
+		return ErrWomenOnly
+	}
+	return nil
+}
+
+func (p *womenOnlyPolicy) CanMatch(sender, receiver *entity.User, senderProfile, receiverProfile *entity.RunnerProfile) error {
+	if senderProfile != nil && senderProfile.WomenOnlyMode && !helper.IsFemale(receiver.Gender) {
+		return errors.New("mode women-only aktif, kamu hanya bisa match dengan sesama perempuan")
+	}
+	if receiverProfile != nil && receiverProfile.WomenOnlyMode && !helper.IsFemale(sender.Gender) {
+		return errors.New("pengguna ini hanya menerima match dari sesama perempuan")
+	}
+	return nil
+}
+
+func (p *womenOnlyPolicy) ApplyGender(user *entity.User, raw *string) error {
+	gender, err := helper.NormalizeGender(raw)
+	if err != nil {
+		return err
+	}
+	if gender == nil {
+		return nil
+	}
+	current, _ := helper.NormalizeGender(user.Gender)
+	if current != nil && *current != *gender && isVerifiedRunner(user) {
+		return ErrGenderLocked
+	}
+	user.Gender = gender
+	return nil
+}
@@ -0,0 +1,43 @@
+-- Migrasi galeri foto: isi posisi dari urutan upload lama, rapikan foto utama dan
+-- samakan runner_profiles.image dengan foto utama. Jalankan sekali setelah deploy galeri.
+
+-- Foto utama hanya boleh bertipe profile, satu per user (yang terbaru dipertahankan)
+UPDATE user_photos SET is_primary = false
+WHERE is_primary AND type <> 'profile';
+
+UPDATE user_photos p SET is_primary = false
+WHERE p.is_primary AND EXISTS (
+    SELECT 1 FROM user_photos q
+    WHERE q.user_id = p.user_id AND q.is_primary AND (q.created_at, q.id) > (p.created_at, p.id)
+);
+
+-- Gambar profil lama yang belum ada di galeri dimasukkan sebagai foto profile
+INSERT INTO user_photos (id, user_id, url, type, is_primary, position, review_status, created_at)
+SELECT uuid_generate_v4(), rp.user_id, rp.image, 'profile', false, 0, 'pending', rp.updated_at
+FROM runner_profiles rp
+WHERE rp.image IS NOT NULL AND rp.image <> ''
+  AND NOT EXISTS (SELECT 1 FROM user_photos p WHERE p.user_id = rp.user_id AND p.url = rp.image);
+
+-- User tanpa foto utama: gambar profil saat ini (atau foto profile pertama) menjadi foto utama
+UPDATE user_photos p SET is_primary = true
+FROM (
+    SELECT DISTINCT ON (p.user_id) p.id
+    FROM user_photos p
+    LEFT JOIN runner_profiles rp ON rp.user_id = p.user_id
+    WHERE p.type = 'profile'
+      AND NOT EXISTS (SELECT 1 FROM user_photos q WHERE q.user_id = p.user_id AND q.is_primary)
+    ORDER BY p.user_id, (p.url = rp.image) DESC NULLS LAST, p.created_at
+) first
+WHERE p.id = first.id;
+
+-- Posisi per user & tipe mengikuti urutan upload
+UPDATE user_photos p SET position = o.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, type ORDER BY is_primary DESC, created_at) - 1 AS position
+    FROM user_photos
+) o
+WHERE o.id = p.id;
+
+UPDATE runner_profiles rp SET image = p.url
+FROM user_photos p
+WHERE p.user_id = rp.user_id AND p.is_primary AND rp.image IS DISTINCT FROM p.url;